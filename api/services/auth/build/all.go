@@ -2,8 +2,11 @@
 package build
 
 import (
+	"slices"
+
 	"github.com/ardanlabs/service/app/domain/authapp"
 	"github.com/ardanlabs/service/app/domain/checkapp"
+	"github.com/ardanlabs/service/app/domain/openapiapp"
 	"github.com/ardanlabs/service/app/sdk/mux"
 	"github.com/ardanlabs/service/foundation/web"
 )
@@ -24,7 +27,20 @@ func (all) Add(app *web.App, cfg mux.Config) {
 	})
 
 	authapp.Routes(app, authapp.Config{
-		UserBus: cfg.BusConfig.UserBus,
-		Auth:    cfg.AuthConfig.Auth,
+		UserBus:     cfg.BusConfig.UserBus,
+		Auth:        cfg.AuthConfig.Auth,
+		RateLimiter: cfg.RateLimiter,
+		TokenLimit:  cfg.AuthConfig.TokenLimit,
+		ClientIP:    cfg.ClientIP,
+	})
+
+	openapiapp.Routes(app, openapiapp.Config{
+		Build: cfg.Build,
+		Title: "Auth",
+		Operations: slices.Concat(
+			checkapp.Operations(),
+			authapp.Operations(),
+			openapiapp.Operations(),
+		),
 	})
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/debug"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
 	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
@@ -27,6 +28,8 @@ import (
 	"github.com/ardanlabs/service/foundation/keystore"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var tag = "develop"
@@ -40,7 +43,8 @@ func main() {
 		},
 	}
 
-	log = logger.NewWithEvents(os.Stdout, logger.LevelInfo, "AUTH", otel.GetTraceID, events)
+	log = logger.NewWithEvents(os.Stdout, logger.LevelInfo, "AUTH", otel.GetTraceID, events).
+		WithContextValue("request_id", web.GetRequestID)
 
 	// -------------------------------------------------------------------------
 
@@ -64,21 +68,46 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	cfg := struct {
 		conf.Version
+		Log struct {
+			Level    string   `conf:"default:INFO"`
+			Packages []string `conf:"help:package level overrides as package=level"`
+			Sampling []string `conf:"default:INFO=100/10/1s,help:sampling as level=first/thereafter/interval"`
+			Events   []string `conf:"default:ERROR=10/1m,help:event limits as level=max/interval"`
+		}
 		Web struct {
-			ReadTimeout        time.Duration `conf:"default:5s"`
-			WriteTimeout       time.Duration `conf:"default:10s"`
-			IdleTimeout        time.Duration `conf:"default:120s"`
-			ShutdownTimeout    time.Duration `conf:"default:20s"`
-			APIHost            string        `conf:"default:0.0.0.0:6000"`
-			DebugHost          string        `conf:"default:0.0.0.0:6010"`
-			GRPCHost           string        `conf:"default:0.0.0.0:6001"`
-			CORSAllowedOrigins []string      `conf:"default:*"`
+			ReadTimeout           time.Duration `conf:"default:5s"`
+			WriteTimeout          time.Duration `conf:"default:10s"`
+			IdleTimeout           time.Duration `conf:"default:120s"`
+			ShutdownTimeout       time.Duration `conf:"default:20s"`
+			APIHost               string        `conf:"default:0.0.0.0:6000"`
+			DebugHost             string        `conf:"default:0.0.0.0:6010"`
+			GRPCHost              string        `conf:"default:0.0.0.0:6001"`
+			CORSAllowedOrigins    []string      `conf:"default:*"`
+			CORSAllowedMethods    []string      `conf:"default:POST;PATCH;GET;OPTIONS;PUT;DELETE"`
+			CORSAllowedHeaders    []string      `conf:"default:Accept;Content-Type;Content-Length;Accept-Encoding;X-CSRF-Token;Authorization;X-Request-ID"`
+			CORSExposedHeaders    []string      `conf:"default:X-Request-ID;RateLimit-Policy;RateLimit-Limit;RateLimit-Remaining;RateLimit-Reset;Retry-After"`
+			CORSAllowCredentials  bool          `conf:"default:false"`
+			CORSMaxAge            time.Duration `conf:"default:24h"`
+			HSTSMaxAge            time.Duration `conf:"default:17520h"`
+			HSTSIncludeSubDomains bool          `conf:"default:true"`
+			HSTSPreload           bool          `conf:"default:true"`
+			CSP                   string        `conf:"default:default-src 'none'; frame-ancestors 'none'"`
+			ContentTypeOptions    string        `conf:"default:nosniff"`
+			FrameOptions          string        `conf:"default:DENY"`
+			ReferrerPolicy        string        `conf:"default:no-referrer"`
 		}
 		Auth struct {
-			KeysJSON   string `conf:"mask"`
-			KeysFolder string `conf:"default:zarf/keys/"`
-			ActiveKID  string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
-			Issuer     string `conf:"default:service project"`
+			KeysJSON        string        `conf:"mask"`
+			KeysFolder      string        `conf:"default:zarf/keys/"`
+			KeysEnvPrefix   string        `conf:"help:load the keys from the variables with the prefix"`
+			MasterKey       string        `conf:"mask,help:master key to decrypt the .pem.enc files"`
+			VaultAddress    string        `conf:"help:load the keys from a Vault KV v2 secret"`
+			VaultToken      string        `conf:"mask"`
+			VaultMount      string        `conf:"default:secret"`
+			VaultPath       string        `conf:"default:auth/keys"`
+			RefreshInterval time.Duration `conf:"default:1m,help:time between reloading the keys, 0 disables it"`
+			ActiveKID       string        `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
+			Issuer          string        `conf:"default:service project"`
 		}
 		DB struct {
 			User         string `conf:"default:postgres"`
@@ -90,9 +119,35 @@ func run(ctx context.Context, log *logger.Logger) error {
 			DisableTLS   bool   `conf:"default:true"`
 		}
 		Tempo struct {
-			Host        string  `conf:"default:tempo:4317"`
-			ServiceName string  `conf:"default:auth"`
-			Probability float64 `conf:"default:0.05"`
+			Host           string   `conf:"default:tempo:4317"`
+			ServiceName    string   `conf:"default:auth"`
+			Probability    float64  `conf:"default:0.05"`
+			Protocol       string   `conf:"default:grpc,help:grpc or http for traces and logs and metrics"`
+			Sampler        string   `conf:"default:parentbased_traceidratio"`
+			SampleErrors   bool     `conf:"default:true"`
+			ExcludedRoutes []string `conf:"default:/v1/liveness;/v1/readiness"`
+		}
+		Otel struct {
+			LogHost        string        `conf:"help:OTLP host for logs such as otel-collector:4317"`
+			MetricHost     string        `conf:"help:OTLP host for metrics such as otel-collector:4317"`
+			MetricInterval time.Duration `conf:"default:30s"`
+			Insecure       bool          `conf:"default:true"`
+			CAFile         string
+			CertFile       string
+			KeyFile        string
+			Headers        []string `conf:"mask,help:exporter headers as key=value"`
+			Environment    string   `conf:"default:development"`
+			Attributes     []string `conf:"help:resource attributes as key=value"`
+		}
+		RateLimit struct {
+			Rate           int           `conf:"default:0"`
+			Period         time.Duration `conf:"default:1s"`
+			Burst          int           `conf:"default:0"`
+			TokenRate      int           `conf:"default:10,help:token requests per period for each client ip"`
+			TokenPeriod    time.Duration `conf:"default:1m"`
+			TokenBurst     int           `conf:"default:5"`
+			ClientIPHeader string        `conf:"help:X-Forwarded-For or X-Real-IP to read the client ip set by a trusted proxy"`
+			TrustedProxies []string      `conf:"help:ip addresses or cidr ranges of the proxies in front of the service"`
 		}
 	}{
 		Version: conf.Version{
@@ -111,6 +166,39 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("parsing config: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Log Levels
+
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return fmt.Errorf("parsing log level: %w", err)
+	}
+	log.SetLevel(level, 0)
+
+	pkgLevels, err := logger.ParsePackageLevels(cfg.Log.Packages)
+	if err != nil {
+		return fmt.Errorf("parsing log package levels: %w", err)
+	}
+	for pkg, level := range pkgLevels {
+		log.SetPackageLevel(pkg, level, 0)
+	}
+
+	sampling, err := logger.ParseSampling(cfg.Log.Sampling)
+	if err != nil {
+		return fmt.Errorf("parsing log sampling: %w", err)
+	}
+	for level, s := range sampling {
+		log.SetSampling(level, s)
+	}
+
+	eventLimits, err := logger.ParseEventLimits(cfg.Log.Events)
+	if err != nil {
+		return fmt.Errorf("parsing log event limits: %w", err)
+	}
+	for level, limit := range eventLimits {
+		log.SetEventLimit(level, limit)
+	}
+
 	// -------------------------------------------------------------------------
 	// App Starting
 
@@ -147,6 +235,13 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	defer db.Close()
 
+	unregister, err := sqldb.RegisterMetrics(db, cfg.DB.Name)
+	if err != nil {
+		return fmt.Errorf("registering db metrics: %w", err)
+	}
+
+	defer unregister()
+
 	// -------------------------------------------------------------------------
 	// Create Business Packages
 
@@ -158,27 +253,62 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	log.Info(ctx, "startup", "status", "initializing authentication support")
 
-	// Check the environment first to see if a key is being provided. Then
-	// load any private keys files from disk. We can assume some system like
-	// Vault has created these files already. How that happens is not our
-	// concern.
+	// Keys can be provided in the environment, in files on disk that may be
+	// encrypted with a master key, and in a Vault secret. The sources are
+	// synced in that order so a later source wins for the same kid.
 
-	ks := keystore.New()
+	sources := []keystore.Source{
+		keystore.NewDocumentSource(cfg.Auth.KeysJSON),
+		keystore.NewFileSource(os.DirFS(cfg.Auth.KeysFolder), []byte(cfg.Auth.MasterKey)),
+	}
 
-	n1, err := ks.LoadByJSON(cfg.Auth.KeysJSON)
-	if err != nil {
-		return fmt.Errorf("loading keys by env: %w", err)
+	if cfg.Auth.KeysEnvPrefix != "" {
+		sources = append(sources, keystore.NewEnvSource(cfg.Auth.KeysEnvPrefix))
 	}
 
-	n2, err := ks.LoadByFileSystem(os.DirFS(cfg.Auth.KeysFolder))
+	if cfg.Auth.VaultAddress != "" {
+		vault, err := keystore.NewVaultSource(keystore.VaultConfig{
+			Address: cfg.Auth.VaultAddress,
+			Token:   cfg.Auth.VaultToken,
+			Mount:   cfg.Auth.VaultMount,
+			Path:    cfg.Auth.VaultPath,
+		})
+		if err != nil {
+			return fmt.Errorf("constructing vault source: %w", err)
+		}
+
+		sources = append(sources, vault)
+	}
+
+	ks := keystore.New()
+
+	ks.OnChange(func(change keystore.Change) {
+		log.Info(ctx, "keystore", "status", "keys changed", "added", change.Added, "updated", change.Updated, "removed", change.Removed)
+	})
+
+	n, err := ks.Sync(ctx, sources...)
 	if err != nil {
-		return fmt.Errorf("loading keys by fs: %w", err)
+		return fmt.Errorf("loading keys: %w", err)
 	}
 
-	if n1+n2 == 0 {
+	if n == 0 {
 		return errors.New("no keys exist")
 	}
 
+	// The auth package looks the keys up on every use, so keys added or
+	// rotated by a refresh are picked up without a restart.
+
+	if cfg.Auth.RefreshInterval > 0 {
+		refreshCtx, cancelRefresh := context.WithCancel(ctx)
+		defer cancelRefresh()
+
+		onError := func(err error) {
+			log.Error(ctx, "keystore", "status", "refreshing keys", "msg", err)
+		}
+
+		go ks.Refresh(refreshCtx, cfg.Auth.RefreshInterval, onError, sources...)
+	}
+
 	authCfg := auth.Config{
 		Log:       log,
 		UserBus:   userBus,
@@ -189,25 +319,67 @@ func run(ctx context.Context, log *logger.Logger) error {
 	ath := auth.New(authCfg)
 
 	// -------------------------------------------------------------------------
-	// Start Tracing Support
+	// Start Telemetry Support
 
-	log.Info(ctx, "startup", "status", "initializing tracing support")
+	log.Info(ctx, "startup", "status", "initializing telemetry support")
 
-	traceProvider, teardown, err := otel.InitTracing(otel.Config{
-		ServiceName: cfg.Tempo.ServiceName,
-		Host:        cfg.Tempo.Host,
-		ExcludedRoutes: map[string]struct{}{
-			"/v1/liveness":  {},
-			"/v1/readiness": {},
-		},
-		Probability: cfg.Tempo.Probability,
-	})
+	headers, err := otel.ParseKeyValues(cfg.Otel.Headers)
+	if err != nil {
+		return fmt.Errorf("parsing otel headers: %w", err)
+	}
+
+	attributes, err := otel.ParseKeyValues(cfg.Otel.Attributes)
+	if err != nil {
+		return fmt.Errorf("parsing otel attributes: %w", err)
+	}
+
+	registry := prometheus.NewRegistry()
+
+	otelCfg := otel.Config{
+		ServiceName:    cfg.Tempo.ServiceName,
+		ServiceVersion: cfg.Build,
+		Environment:    cfg.Otel.Environment,
+		Attributes:     attributes,
+		Host:           cfg.Tempo.Host,
+		Protocol:       cfg.Tempo.Protocol,
+		Insecure:       cfg.Otel.Insecure,
+		CAFile:         cfg.Otel.CAFile,
+		CertFile:       cfg.Otel.CertFile,
+		KeyFile:        cfg.Otel.KeyFile,
+		Headers:        headers,
+		ExcludedRoutes: cfg.Tempo.ExcludedRoutes,
+		Sampler:        cfg.Tempo.Sampler,
+		Probability:    cfg.Tempo.Probability,
+		SampleErrors:   cfg.Tempo.SampleErrors,
+		LogHost:        cfg.Otel.LogHost,
+		MetricHost:     cfg.Otel.MetricHost,
+		MetricInterval: cfg.Otel.MetricInterval,
+		Registerer:     registry,
+	}
+
+	traceProvider, teardown, err := otel.InitTracing(otelCfg)
 	if err != nil {
 		return fmt.Errorf("starting tracing: %w", err)
 	}
 
 	defer teardown(context.Background())
 
+	logHandler, logTeardown, err := otel.InitLogging(otelCfg)
+	if err != nil {
+		return fmt.Errorf("starting log export: %w", err)
+	}
+
+	defer logTeardown(context.Background())
+
+	log.AddHandler(logHandler)
+
+	_, metricTeardown, err := otel.InitMetrics(otelCfg)
+	if err != nil {
+		return fmt.Errorf("starting metric export: %w", err)
+	}
+
+	defer metricTeardown(context.Background())
+
 	tracer := traceProvider.Tracer(cfg.Tempo.ServiceName)
 
 	// -------------------------------------------------------------------------
@@ -216,7 +388,7 @@ func run(ctx context.Context, log *logger.Logger) error {
 	go func() {
 		log.Info(ctx, "startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
 
-		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux()); err != nil {
+		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux(debug.WithLogLevel(log), debug.WithMetrics(registry))); err != nil {
 			log.Error(ctx, "shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "msg", err)
 		}
 	}()
@@ -229,22 +401,63 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	clientIP, err := ratelimit.NewClientIP(cfg.RateLimit.ClientIPHeader, cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("constructing client ip: %w", err)
+	}
+
 	cfgMux := mux.Config{
-		Build:  tag,
-		Log:    log,
-		DB:     db,
-		Tracer: tracer,
+		Build:       tag,
+		Log:         log,
+		DB:          db,
+		Tracer:      tracer,
+		RateLimiter: ratelimit.NewMemoryStore(),
+		ClientIP:    clientIP,
 		BusConfig: mux.BusConfig{
 			UserBus: userBus,
 		},
 		AuthConfig: mux.AuthConfig{
 			Auth: ath,
+			TokenLimit: ratelimit.Limit{
+				Rate:   cfg.RateLimit.TokenRate,
+				Period: cfg.RateLimit.TokenPeriod,
+				Burst:  cfg.RateLimit.TokenBurst,
+			},
 		},
 	}
 
+	cors := web.CORS{
+		AllowedOrigins:   cfg.Web.CORSAllowedOrigins,
+		AllowedMethods:   cfg.Web.CORSAllowedMethods,
+		AllowedHeaders:   cfg.Web.CORSAllowedHeaders,
+		ExposedHeaders:   cfg.Web.CORSExposedHeaders,
+		AllowCredentials: cfg.Web.CORSAllowCredentials,
+		MaxAge:           cfg.Web.CORSMaxAge,
+	}
+
+	hsts := web.HSTS{
+		MaxAge:            cfg.Web.HSTSMaxAge,
+		IncludeSubDomains: cfg.Web.HSTSIncludeSubDomains,
+		Preload:           cfg.Web.HSTSPreload,
+	}
+
+	secure := web.SecureHeaders{
+		ContentSecurityPolicy:   cfg.Web.CSP,
+		ContentTypeOptions:      cfg.Web.ContentTypeOptions,
+		FrameOptions:            cfg.Web.FrameOptions,
+		ReferrerPolicy:          cfg.Web.ReferrerPolicy,
+		CrossOriginOpenerPolicy: web.DefaultSecureHeaders.CrossOriginOpenerPolicy,
+	}
+
+	rateLimit := ratelimit.Limit{
+		Rate:   cfg.RateLimit.Rate,
+		Period: cfg.RateLimit.Period,
+		Burst:  cfg.RateLimit.Burst,
+	}
+
 	api := http.Server{
 		Addr:         cfg.Web.APIHost,
-		Handler:      mux.WebAPI(cfgMux, build.Routes(), mux.WithCORS(cfg.Web.CORSAllowedOrigins)),
+		Handler:      mux.WebAPI(cfgMux, build.Routes(), mux.WithCORS(cors), mux.WithHSTS(hsts), mux.WithSecureHeaders(secure), mux.WithRateLimit(rateLimit)),
 		ReadTimeout:  cfg.Web.ReadTimeout,
 		WriteTimeout: cfg.Web.WriteTimeout,
 		IdleTimeout:  cfg.Web.IdleTimeout,
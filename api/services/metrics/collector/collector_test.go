@@ -0,0 +1,75 @@
+package collector_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/api/services/metrics/collector"
+)
+
+func Test_Targets(t *testing.T) {
+	sales := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"goroutines": 12, "requests": 100}`))
+	}))
+	defer sales.Close()
+
+	auth := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# HELP goroutines Number of goroutines.\n# TYPE goroutines gauge\ngoroutines 7\nhttp_server_requests_total{http_route=\"/v1/auth/{kid}\",label=\"a b\\\"}\"} 3 1700000000000\n"))
+	}))
+	defer auth.Close()
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	targets, err := collector.ParseTargets([]string{
+		"sales=" + sales.URL,
+		"auth=prometheus:" + auth.URL,
+		"down=expvar:" + down.URL,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to parse the targets: %s", err)
+	}
+
+	col, err := collector.New(targets)
+	if err != nil {
+		t.Fatalf("Should be able to construct the collector: %s", err)
+	}
+
+	samples, err := col.Collect()
+	if err == nil || !strings.Contains(err.Error(), `target "down"`) {
+		t.Errorf("Should get an error for the down target: %v", err)
+	}
+
+	if len(samples) != 2 {
+		t.Fatalf("Should get a sample for each target that is up: got %d", len(samples))
+	}
+
+	if samples[0].Target != "sales" || samples[0].Data["goroutines"] != 12.0 {
+		t.Errorf("Should get the expvar sample for sales: %+v", samples[0])
+	}
+
+	if samples[1].Target != "auth" || samples[1].Data["goroutines"] != 7.0 {
+		t.Errorf("Should get the prometheus sample for auth: %+v", samples[1])
+	}
+
+	key := `http_server_requests_total{http_route="/v1/auth/{kid}",label="a b\"}"}`
+	if samples[1].Data[key] != 3.0 {
+		t.Errorf("Should key the labeled series by name and labels: %+v", samples[1].Data)
+	}
+}
+
+func Test_ParseTargets(t *testing.T) {
+	for _, value := range []string{"sales", "sales=", "sales=expvar"} {
+		if _, err := collector.ParseTargets([]string{value}); err == nil {
+			t.Errorf("Should not be able to parse %q", value)
+		}
+	}
+
+	if _, err := collector.New([]collector.Target{{Name: "sales", Kind: "statsd", URL: "localhost"}}); err == nil {
+		t.Error("Should not be able to construct a collector for an unknown kind")
+	}
+}
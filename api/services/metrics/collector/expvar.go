@@ -1,14 +1,14 @@
-// Package collector is a simple collector for
+// Package collector provides support for collecting metrics from the
+// services using expvar or the Prometheus text format.
 package collector
 
 import (
+	"encoding/json"
 	"errors"
 	"io"
 	"net"
 	"net/http"
 	"time"
-
-	"encoding/json"
 )
 
 // Expvar provides the ability to receive metrics
@@ -19,25 +19,15 @@ type Expvar struct {
 	client http.Client
 }
 
-// New creates a Expvar for collection metrics.
-func New(host string) (*Expvar, error) {
-	tr := http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-		DialContext: (&net.Dialer{
-			Timeout:   30 * time.Second,
-			KeepAlive: 30 * time.Second,
-		}).DialContext,
-		MaxIdleConns:          2,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   10 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	}
+// NewExpvar creates a Expvar for collection metrics.
+func NewExpvar(host string) (*Expvar, error) {
+	tr := newTransport()
 
 	exp := Expvar{
 		host: host,
-		tr:   &tr,
+		tr:   tr,
 		client: http.Client{
-			Transport: &tr,
+			Transport: tr,
 			Timeout:   1 * time.Second,
 		},
 	}
@@ -73,3 +63,18 @@ func (exp *Expvar) Collect() (map[string]any, error) {
 
 	return data, nil
 }
+
+// newTransport constructs the transport used by the collectors.
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          2,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+}
@@ -0,0 +1,127 @@
+package collector
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Prometheus provides the ability to receive metrics from services that
+// expose them in the Prometheus text format.
+type Prometheus struct {
+	host   string
+	tr     *http.Transport
+	client http.Client
+}
+
+// NewPrometheus creates a Prometheus for collection metrics.
+func NewPrometheus(host string) (*Prometheus, error) {
+	tr := newTransport()
+
+	prom := Prometheus{
+		host: host,
+		tr:   tr,
+		client: http.Client{
+			Transport: tr,
+			Timeout:   1 * time.Second,
+		},
+	}
+
+	return &prom, nil
+}
+
+// Collect captures metrics on the host configure to this endpoint. The
+// series with labels are keyed by the name and labels as they are written,
+// name{key="value"}.
+func (prom *Prometheus) Collect() (map[string]any, error) {
+	req, err := http.NewRequest("GET", prom.host, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/plain")
+
+	resp, err := prom.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.New(string(msg))
+	}
+
+	return parsePrometheus(resp.Body)
+}
+
+// parsePrometheus reads the samples from the Prometheus text format. The
+// comments and timestamps are ignored.
+func parsePrometheus(r io.Reader) (map[string]any, error) {
+	data := make(map[string]any)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, rest, err := splitSeries(line)
+		if err != nil {
+			return nil, err
+		}
+
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("parsing %q: missing value", line)
+		}
+
+		value, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %q: %w", line, err)
+		}
+
+		data[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// splitSeries separates the name and labels of a sample line from the value
+// that follows them.
+func splitSeries(line string) (string, string, error) {
+	i := strings.IndexAny(line, "{ \t")
+	if i == -1 {
+		return "", "", fmt.Errorf("parsing %q: missing value", line)
+	}
+
+	if line[i] != '{' {
+		return line[:i], line[i:], nil
+	}
+
+	var quoted bool
+	for j := i + 1; j < len(line); j++ {
+		switch {
+		case quoted && line[j] == '\\':
+			j++
+		case line[j] == '"':
+			quoted = !quoted
+		case !quoted && line[j] == '}':
+			return line[:j+1], line[j+1:], nil
+		}
+	}
+
+	return "", "", fmt.Errorf("parsing %q: unterminated labels", line)
+}
@@ -0,0 +1,152 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
+)
+
+// Set of the kinds of targets that can be collected.
+const (
+	KindExpvar     = "expvar"
+	KindPrometheus = "prometheus"
+)
+
+// Target describes a service to collect metrics from.
+type Target struct {
+	Name string
+	Kind string
+	URL  string
+}
+
+// ParseTargets parses a static list of targets in the form
+// name=kind:url, such as sales=expvar:http://localhost:3010/debug/vars.
+// The kind can be left out for expvar targets.
+func ParseTargets(values []string) ([]Target, error) {
+	targets := make([]Target, 0, len(values))
+
+	for _, value := range values {
+		name, rest, ok := strings.Cut(strings.TrimSpace(value), "=")
+		if !ok || name == "" || rest == "" {
+			return nil, fmt.Errorf("parsing target %q: expecting name=kind:url", value)
+		}
+
+		target := Target{
+			Name: name,
+			Kind: KindExpvar,
+			URL:  rest,
+		}
+
+		if !strings.HasPrefix(rest, "http://") && !strings.HasPrefix(rest, "https://") {
+			kind, url, ok := strings.Cut(rest, ":")
+			if !ok {
+				return nil, fmt.Errorf("parsing target %q: expecting name=kind:url", value)
+			}
+
+			target.Kind = kind
+			target.URL = url
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// =============================================================================
+
+type scraper interface {
+	Collect() (map[string]any, error)
+}
+
+type scrapeTarget struct {
+	name    string
+	scraper scraper
+}
+
+// Targets collects the metrics from a static set of targets and tags each
+// sample with the name of its target.
+type Targets struct {
+	targets []scrapeTarget
+}
+
+// New constructs a Targets for collecting metrics from the targets.
+func New(targets []Target) (*Targets, error) {
+	if len(targets) == 0 {
+		return nil, errors.New("no targets configured")
+	}
+
+	t := Targets{
+		targets: make([]scrapeTarget, 0, len(targets)),
+	}
+
+	names := make(map[string]bool)
+
+	for _, target := range targets {
+		if names[target.Name] {
+			return nil, fmt.Errorf("target %q: duplicate name", target.Name)
+		}
+		names[target.Name] = true
+
+		var s scraper
+		var err error
+
+		switch target.Kind {
+		case KindExpvar:
+			s, err = NewExpvar(target.URL)
+
+		case KindPrometheus:
+			s, err = NewPrometheus(target.URL)
+
+		default:
+			return nil, fmt.Errorf("target %q: unknown kind %q", target.Name, target.Kind)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("target %q: %w", target.Name, err)
+		}
+
+		t.targets = append(t.targets, scrapeTarget{name: target.Name, scraper: s})
+	}
+
+	return &t, nil
+}
+
+// Collect captures the metrics from all the targets concurrently. The
+// samples for the targets that could be collected are returned along with
+// the errors for the ones that failed.
+func (t *Targets) Collect() ([]publisher.Sample, error) {
+	samples := make([]publisher.Sample, len(t.targets))
+	errs := make([]error, len(t.targets))
+
+	var wg sync.WaitGroup
+	wg.Add(len(t.targets))
+
+	for i, target := range t.targets {
+		go func() {
+			defer wg.Done()
+
+			data, err := target.scraper.Collect()
+			if err != nil {
+				errs[i] = fmt.Errorf("target %q: %w", target.name, err)
+				return
+			}
+
+			samples[i] = publisher.Sample{Target: target.name, Data: data}
+		}()
+	}
+
+	wg.Wait()
+
+	collected := make([]publisher.Sample, 0, len(samples))
+	for _, sample := range samples {
+		if sample.Data != nil {
+			collected = append(collected, sample)
+		}
+	}
+
+	return collected, errors.Join(errs...)
+}
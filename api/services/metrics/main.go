@@ -15,7 +15,9 @@ import (
 	"github.com/ardanlabs/service/api/services/metrics/collector"
 	"github.com/ardanlabs/service/api/services/metrics/publisher"
 	expvarsrv "github.com/ardanlabs/service/api/services/metrics/publisher/expvar"
+	"github.com/ardanlabs/service/api/services/metrics/publisher/otlp"
 	prometheussrv "github.com/ardanlabs/service/api/services/metrics/publisher/prometheus"
+	"github.com/ardanlabs/service/api/services/metrics/publisher/statsd"
 	"github.com/ardanlabs/service/app/sdk/debug"
 	"github.com/ardanlabs/service/foundation/logger"
 )
@@ -77,11 +79,30 @@ func run(ctx context.Context, log *logger.Logger) error {
 			ShutdownTimeout time.Duration `conf:"default:5s"`
 		}
 		Collect struct {
-			From string `conf:"default:http://localhost:3010/debug/vars"`
+			Targets []string `conf:"default:sales=expvar:http://localhost:3010/debug/vars"`
 		}
 		Publish struct {
-			To       string        `conf:"default:console"`
-			Interval time.Duration `conf:"default:5s"`
+			To         string        `conf:"default:console"`
+			Interval   time.Duration `conf:"default:5s"`
+			BufferSize int           `conf:"default:60"`
+			MinBackoff time.Duration `conf:"default:1s"`
+			MaxBackoff time.Duration `conf:"default:1m"`
+		}
+		StatsD struct {
+			Host          string
+			Prefix        string `conf:"default:ardanlabs"`
+			DogStatsD     bool   `conf:"default:false"`
+			MaxPacketSize int    `conf:"default:1432"`
+			Retries       int    `conf:"default:2"`
+		}
+		OTLP struct {
+			Host     string
+			Protocol string            `conf:"default:grpc"`
+			Insecure bool              `conf:"default:true"`
+			Headers  map[string]string `conf:"mask"`
+			MaxBatch int               `conf:"default:1000"`
+			Retries  int               `conf:"default:3"`
+			Timeout  time.Duration     `conf:"default:10s"`
 		}
 	}{
 		Version: conf.Version{
@@ -120,7 +141,7 @@ func run(ctx context.Context, log *logger.Logger) error {
 	go func() {
 		log.Info(ctx, "startup", "status", "debug router started", "host", cfg.Web.DebugHost)
 
-		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux()); err != nil {
+		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux(debug.WithLogLevel(log))); err != nil {
 			log.Error(ctx, "shutdown", "status", "debug router closed", "host", cfg.Web.DebugHost, "err", err)
 		}
 	}()
@@ -140,14 +161,70 @@ func run(ctx context.Context, log *logger.Logger) error {
 	// -------------------------------------------------------------------------
 	// Start collectors and publishers
 
-	collector, err := collector.New(cfg.Collect.From)
+	targets, err := collector.ParseTargets(cfg.Collect.Targets)
+	if err != nil {
+		return fmt.Errorf("parsing collect targets: %w", err)
+	}
+
+	collector, err := collector.New(targets)
 	if err != nil {
 		return fmt.Errorf("starting collector: %w", err)
 	}
 
 	stdout := publisher.NewStdout(log)
 
-	publish, err := publisher.New(log, collector, cfg.Publish.Interval, prom.Publish, exp.Publish, stdout.Publish)
+	publishers := map[string]publisher.Publisher{
+		publisher.TypePrometheus: prom.Publish,
+		publisher.TypeExpvar:     exp.Publish,
+		publisher.TypeStdout:     stdout.Publish,
+	}
+
+	if cfg.StatsD.Host != "" {
+		statsdCfg := statsd.Config{
+			Host:          cfg.StatsD.Host,
+			Prefix:        cfg.StatsD.Prefix,
+			DogStatsD:     cfg.StatsD.DogStatsD,
+			MaxPacketSize: cfg.StatsD.MaxPacketSize,
+			Retries:       cfg.StatsD.Retries,
+		}
+
+		sd, err := statsd.New(log, statsdCfg)
+		if err != nil {
+			return fmt.Errorf("starting statsd publisher: %w", err)
+		}
+		defer sd.Close()
+
+		publishers[publisher.TypeStatsD] = sd.Publish
+	}
+
+	if cfg.OTLP.Host != "" {
+		otlpCfg := otlp.Config{
+			Host:     cfg.OTLP.Host,
+			Protocol: cfg.OTLP.Protocol,
+			Insecure: cfg.OTLP.Insecure,
+			Headers:  cfg.OTLP.Headers,
+			MaxBatch: cfg.OTLP.MaxBatch,
+			Retries:  cfg.OTLP.Retries,
+			Timeout:  cfg.OTLP.Timeout,
+		}
+
+		op, err := otlp.New(log, otlpCfg)
+		if err != nil {
+			return fmt.Errorf("starting otlp publisher: %w", err)
+		}
+		defer op.Close()
+
+		publishers[publisher.TypeOTLP] = op.Publish
+	}
+
+	publishCfg := publisher.Config{
+		Interval:   cfg.Publish.Interval,
+		BufferSize: cfg.Publish.BufferSize,
+		MinBackoff: cfg.Publish.MinBackoff,
+		MaxBackoff: cfg.Publish.MaxBackoff,
+	}
+
+	publish, err := publisher.New(log, collector, publishCfg, publishers)
 	if err != nil {
 		return fmt.Errorf("starting publisher: %w", err)
 	}
@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"net"
 	"net/http"
+	"slices"
 	"time"
+
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
 )
 
 // Datadog provides the ability to publish metrics to Datadog.
@@ -51,23 +55,25 @@ func New(log *log.Logger, apiKey string, host string) *Datadog {
 
 // Publish handles the processing of metrics for deliver
 // to the DataDog.
-func (d *Datadog) Publish(data map[string]any) {
-	doc, err := marshalDatadog(d.log, data)
+func (d *Datadog) Publish(samples []publisher.Sample) error {
+	doc, err := marshalDatadog(d.log, samples)
 	if err != nil {
-		d.log.Println("datadog.publish :", err)
-		return
+		return fmt.Errorf("marshal: %w", err)
 	}
 
 	if err := sendDatadog(d, doc); err != nil {
-		d.log.Println("datadog.publish :", err)
-		return
+		return fmt.Errorf("send: %w", err)
 	}
 
-	log.Println("datadog.publish : published :", string(doc))
+	d.log.Println("datadog.publish : published :", string(doc))
+
+	return nil
 }
 
-// marshalDatadog converts the data map to datadog JSON document.
-func marshalDatadog(log *log.Logger, data map[string]any) ([]byte, error) {
+// marshalDatadog converts the samples to datadog JSON document. Each series
+// is tagged with the target it was collected from and the labels carried
+// by its key.
+func marshalDatadog(log *log.Logger, samples []publisher.Sample) ([]byte, error) {
 	/*
 		{ "series" : [
 				{
@@ -88,18 +94,6 @@ func marshalDatadog(log *log.Logger, data map[string]any) ([]byte, error) {
 		}
 	*/
 
-	// Extract the base keys/values.
-	mType := "gauge"
-	host, ok := data["host"].(string)
-	if !ok {
-		host = "unknown"
-	}
-	env := "dev"
-	if host != "localhost" {
-		env = "prod"
-	}
-	envTag := "environment:" + env
-
 	// Define the Datadog data format.
 	type series struct {
 		Metric string   `json:"metric"`
@@ -113,16 +107,38 @@ func marshalDatadog(log *log.Logger, data map[string]any) ([]byte, error) {
 	var doc struct {
 		Series []series `json:"series"`
 	}
-	for key, value := range data {
-		switch value.(type) {
-		case int, float64:
-			doc.Series = append(doc.Series, series{
-				Metric: env + "." + key,
-				Points: [][]any{{"$currenttime", value}},
-				Type:   mType,
-				Host:   host,
-				Tags:   []string{envTag},
-			})
+	for _, sample := range samples {
+		// Extract the base keys/values.
+		mType := "gauge"
+		host, ok := sample.Data["host"].(string)
+		if !ok {
+			host = "unknown"
+		}
+		env := "dev"
+		if host != "localhost" {
+			env = "prod"
+		}
+		envTag := "environment:" + env
+		targetTag := "target:" + sample.Target
+
+		for key, value := range sample.Data {
+			switch value.(type) {
+			case int, float64:
+				name, labels := publisher.SplitKey(key)
+
+				tags := []string{envTag, targetTag}
+				for _, k := range slices.Sorted(maps.Keys(labels)) {
+					tags = append(tags, k+":"+labels[k])
+				}
+
+				doc.Series = append(doc.Series, series{
+					Metric: env + "." + name,
+					Points: [][]any{{"$currenttime", value}},
+					Type:   mType,
+					Host:   host,
+					Tags:   tags,
+				})
+			}
 		}
 	}
 
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
 	"github.com/ardanlabs/service/foundation/logger"
 )
 
@@ -65,13 +66,21 @@ func (exp *Expvar) Stop(shutdownTimeout time.Duration) {
 	}
 }
 
-// Publish is called by the publisher goroutine and saves the raw stats.
-func (exp *Expvar) Publish(data map[string]any) {
+// Publish is called by the publisher goroutine and saves the raw stats
+// keyed by the target they were collected from.
+func (exp *Expvar) Publish(samples []publisher.Sample) error {
+	data := make(map[string]any, len(samples))
+	for _, sample := range samples {
+		data[sample.Target] = sample.Data
+	}
+
 	exp.mu.Lock()
 	{
 		exp.data = data
 	}
 	exp.mu.Unlock()
+
+	return nil
 }
 
 // handler is what consumers call to get the raw stats.
@@ -0,0 +1,37 @@
+package otlp
+
+import "fmt"
+
+// rawMessage is a request or reply that is already encoded.
+type rawMessage []byte
+
+// rawCodec passes the encoded messages through to the gRPC transport. It's
+// named proto so the content type matches what the collector expects.
+type rawCodec struct{}
+
+// Marshal implements the encoding.Codec interface.
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+
+	return msg, nil
+}
+
+// Unmarshal implements the encoding.Codec interface.
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+
+	*msg = append((*msg)[:0], data...)
+
+	return nil
+}
+
+// Name implements the encoding.Codec interface.
+func (rawCodec) Name() string {
+	return "proto"
+}
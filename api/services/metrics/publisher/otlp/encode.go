@@ -0,0 +1,140 @@
+package otlp
+
+import (
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// The metrics protos aren't part of the vendored OTLP module so the export
+// request is encoded by hand. These are the field numbers of the messages in
+// opentelemetry/proto/metrics/v1/metrics.proto that are written.
+const (
+	fieldResourceMetrics = 1 // ExportMetricsServiceRequest.resource_metrics
+
+	fieldResource     = 1 // ResourceMetrics.resource
+	fieldScopeMetrics = 2 // ResourceMetrics.scope_metrics
+
+	fieldScope   = 1 // ScopeMetrics.scope
+	fieldMetrics = 2 // ScopeMetrics.metrics
+
+	fieldName  = 1 // Metric.name
+	fieldGauge = 5 // Metric.gauge
+
+	fieldDataPoints = 1 // Gauge.data_points
+
+	fieldStartTime  = 2 // NumberDataPoint.start_time_unix_nano
+	fieldTime       = 3 // NumberDataPoint.time_unix_nano
+	fieldAsDouble   = 4 // NumberDataPoint.as_double
+	fieldAttributes = 7 // NumberDataPoint.attributes
+)
+
+// point is a series collected from a target.
+type point struct {
+	target string
+	series publisher.Series
+}
+
+// encodeRequest encodes the points as an ExportMetricsServiceRequest with a
+// resource for each target. Every series is written as a gauge.
+func encodeRequest(scope string, points []point, start uint64, now uint64) ([]byte, error) {
+	var targets []string
+	byTarget := make(map[string][]publisher.Series)
+
+	for _, p := range points {
+		if _, exists := byTarget[p.target]; !exists {
+			targets = append(targets, p.target)
+		}
+		byTarget[p.target] = append(byTarget[p.target], p.series)
+	}
+
+	scopeData, err := proto.Marshal(&commonpb.InstrumentationScope{Name: scope})
+	if err != nil {
+		return nil, err
+	}
+
+	var req []byte
+
+	for _, target := range targets {
+		resource := resourcepb.Resource{
+			Attributes: []*commonpb.KeyValue{stringKeyValue("service.name", target)},
+		}
+
+		resourceData, err := proto.Marshal(&resource)
+		if err != nil {
+			return nil, err
+		}
+
+		var sm []byte
+		sm = protowire.AppendTag(sm, fieldScope, protowire.BytesType)
+		sm = protowire.AppendBytes(sm, scopeData)
+
+		for _, series := range byTarget[target] {
+			metric, err := encodeMetric(series, start, now)
+			if err != nil {
+				return nil, err
+			}
+
+			sm = protowire.AppendTag(sm, fieldMetrics, protowire.BytesType)
+			sm = protowire.AppendBytes(sm, metric)
+		}
+
+		var rm []byte
+		rm = protowire.AppendTag(rm, fieldResource, protowire.BytesType)
+		rm = protowire.AppendBytes(rm, resourceData)
+		rm = protowire.AppendTag(rm, fieldScopeMetrics, protowire.BytesType)
+		rm = protowire.AppendBytes(rm, sm)
+
+		req = protowire.AppendTag(req, fieldResourceMetrics, protowire.BytesType)
+		req = protowire.AppendBytes(req, rm)
+	}
+
+	return req, nil
+}
+
+func encodeMetric(series publisher.Series, start uint64, now uint64) ([]byte, error) {
+	var dp []byte
+	dp = protowire.AppendTag(dp, fieldStartTime, protowire.Fixed64Type)
+	dp = protowire.AppendFixed64(dp, start)
+	dp = protowire.AppendTag(dp, fieldTime, protowire.Fixed64Type)
+	dp = protowire.AppendFixed64(dp, now)
+	dp = protowire.AppendTag(dp, fieldAsDouble, protowire.Fixed64Type)
+	dp = protowire.AppendFixed64(dp, math.Float64bits(series.Value))
+
+	for _, k := range slices.Sorted(maps.Keys(series.Labels)) {
+		kv, err := proto.Marshal(stringKeyValue(k, series.Labels[k]))
+		if err != nil {
+			return nil, err
+		}
+
+		dp = protowire.AppendTag(dp, fieldAttributes, protowire.BytesType)
+		dp = protowire.AppendBytes(dp, kv)
+	}
+
+	var gauge []byte
+	gauge = protowire.AppendTag(gauge, fieldDataPoints, protowire.BytesType)
+	gauge = protowire.AppendBytes(gauge, dp)
+
+	var metric []byte
+	metric = protowire.AppendTag(metric, fieldName, protowire.BytesType)
+	metric = protowire.AppendString(metric, series.Name)
+	metric = protowire.AppendTag(metric, fieldGauge, protowire.BytesType)
+	metric = protowire.AppendBytes(metric, gauge)
+
+	return metric, nil
+}
+
+func stringKeyValue(key string, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key: key,
+		Value: &commonpb.AnyValue{
+			Value: &commonpb.AnyValue_StringValue{StringValue: value},
+		},
+	}
+}
@@ -0,0 +1,258 @@
+// Package otlp provides support for publishing metrics to an OpenTelemetry
+// collector using OTLP over gRPC or HTTP.
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
+	"github.com/ardanlabs/service/foundation/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Set of protocols that can be used to export the metrics.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// exportMethod is the full name of the gRPC method of the metrics service.
+const exportMethod = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+
+// Config represents the settings for publishing to a collector.
+type Config struct {
+	Host     string
+	Protocol string
+	Insecure bool
+	Headers  map[string]string
+
+	// MaxBatch is the largest number of data points sent in a single
+	// request.
+	MaxBatch int
+
+	// Retries is the number of times a request that failed with a transient
+	// error is sent again.
+	Retries int
+
+	Timeout time.Duration
+}
+
+// OTLP provides the ability to publish metrics to a collector.
+type OTLP struct {
+	log   *logger.Logger
+	cfg   Config
+	start uint64
+	send  func(ctx context.Context, data []byte) error
+	close func() error
+}
+
+// New constructs an OTLP for publishing metrics to the collector.
+func New(log *logger.Logger, cfg Config) (*OTLP, error) {
+	if cfg.MaxBatch <= 0 {
+		cfg.MaxBatch = 1000
+	}
+
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	o := OTLP{
+		log:   log,
+		cfg:   cfg,
+		start: uint64(time.Now().UnixNano()),
+	}
+
+	switch cfg.Protocol {
+	case "", ProtocolGRPC:
+		creds := insecure.NewCredentials()
+		if !cfg.Insecure {
+			creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+		}
+
+		conn, err := grpc.NewClient(cfg.Host, grpc.WithTransportCredentials(creds))
+		if err != nil {
+			return nil, fmt.Errorf("grpc client: %w", err)
+		}
+
+		o.send = o.grpcSender(conn)
+		o.close = conn.Close
+
+	case ProtocolHTTP:
+		o.send = o.httpSender()
+		o.close = func() error { return nil }
+
+	default:
+		return nil, fmt.Errorf("unknown protocol %q", cfg.Protocol)
+	}
+
+	return &o, nil
+}
+
+// Close releases the connection to the collector.
+func (o *OTLP) Close() error {
+	return o.close()
+}
+
+// Publish sends the samples to the collector in batches of at most MaxBatch
+// data points.
+func (o *OTLP) Publish(samples []publisher.Sample) error {
+	ctx := context.Background()
+
+	var points []point
+	for _, sample := range samples {
+		for _, series := range sample.Series(".") {
+			points = append(points, point{target: sample.Target, series: series})
+		}
+	}
+
+	now := uint64(time.Now().UnixNano())
+
+	for batch := range slices.Chunk(points, o.cfg.MaxBatch) {
+		data, err := encodeRequest("github.com/ardanlabs/service/api/services/metrics", batch, o.start, now)
+		if err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+
+		if err := o.sendWithRetry(ctx, data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sendWithRetry sends the request, retrying with an exponential backoff
+// while the error is transient.
+func (o *OTLP) sendWithRetry(ctx context.Context, data []byte) error {
+	backoff := 100 * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		err := o.send(ctx, data)
+		if err == nil {
+			return nil
+		}
+
+		var te transientError
+		if attempt >= o.cfg.Retries || !errors.As(err, &te) {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		backoff *= 2
+	}
+}
+
+// =============================================================================
+
+// transientError marks the failures that can succeed when the request is
+// sent again.
+type transientError struct {
+	err error
+}
+
+func (te transientError) Error() string {
+	return te.err.Error()
+}
+
+func (te transientError) Unwrap() error {
+	return te.err
+}
+
+func (o *OTLP) grpcSender(conn *grpc.ClientConn) func(ctx context.Context, data []byte) error {
+	f := func(ctx context.Context, data []byte) error {
+		ctx, cancel := context.WithTimeout(ctx, o.cfg.Timeout)
+		defer cancel()
+
+		for k, v := range o.cfg.Headers {
+			ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(k), v)
+		}
+
+		var reply rawMessage
+		err := conn.Invoke(ctx, exportMethod, rawMessage(data), &reply, grpc.ForceCodec(rawCodec{}))
+		if err == nil {
+			return nil
+		}
+
+		switch status.Code(err) {
+		case codes.Unavailable, codes.ResourceExhausted, codes.Aborted, codes.DeadlineExceeded:
+			return transientError{err: fmt.Errorf("export: %w", err)}
+		}
+
+		return fmt.Errorf("export: %w", err)
+	}
+
+	return f
+}
+
+func (o *OTLP) httpSender() func(ctx context.Context, data []byte) error {
+	scheme := "https://"
+	if o.cfg.Insecure {
+		scheme = "http://"
+	}
+
+	url := o.cfg.Host
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		url = scheme + url
+	}
+	url = strings.TrimSuffix(url, "/") + "/v1/metrics"
+
+	client := http.Client{
+		Timeout: o.cfg.Timeout,
+	}
+
+	f := func(ctx context.Context, data []byte) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("create request: %w", err)
+		}
+
+		for k, v := range o.cfg.Headers {
+			req.Header.Set(k, v)
+		}
+		req.Header.Set("Content-Type", "application/x-protobuf")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) {
+				return transientError{err: fmt.Errorf("do: %w", err)}
+			}
+			return fmt.Errorf("do: %w", err)
+		}
+		defer resp.Body.Close()
+
+		io.Copy(io.Discard, resp.Body)
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			return nil
+
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return transientError{err: fmt.Errorf("export %s: status %d", url, resp.StatusCode)}
+		}
+
+		return fmt.Errorf("export %s: status %d", url, resp.StatusCode)
+	}
+
+	return f
+}
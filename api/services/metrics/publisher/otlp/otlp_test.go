@@ -0,0 +1,199 @@
+package otlp_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
+	"github.com/ardanlabs/service/api/services/metrics/publisher/otlp"
+	"github.com/ardanlabs/service/foundation/logger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var samples = []publisher.Sample{
+	{Target: "sales", Data: map[string]any{"goroutines": 12.0, "requests": 100.0}},
+	{Target: "auth", Data: map[string]any{`requests{route="/v1/auth"}`: 5.0}},
+}
+
+// requests captures the export requests received by a test collector. The
+// first requests fail with a transient error.
+type requests struct {
+	mu       sync.Mutex
+	failures int
+	bodies   [][]byte
+	headers  []string
+}
+
+func (r *requests) add(body []byte, header string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+		return false
+	}
+
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, header)
+
+	return true
+}
+
+func (r *requests) check(t *testing.T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.bodies) != 2 {
+		t.Fatalf("Should send the data points in batches of 2: got %d requests", len(r.bodies))
+	}
+
+	all := string(bytes.Join(r.bodies, nil))
+	for _, exp := range []string{"goroutines", "requests", "sales", "auth", "route", "/v1/auth", "service.name"} {
+		if !strings.Contains(all, exp) {
+			t.Errorf("Should export %s", exp)
+		}
+	}
+
+	for _, header := range r.headers {
+		if header != "Bearer token" {
+			t.Errorf("Should send the headers: got %q", header)
+		}
+	}
+}
+
+func newLogger() *logger.Logger {
+	return logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+}
+
+func Test_HTTP(t *testing.T) {
+	rcv := requests{failures: 1}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if r.URL.Path != "/v1/metrics" || r.Header.Get("Content-Type") != "application/x-protobuf" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if !rcv.add(body, r.Header.Get("Authorization")) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	cfg := otlp.Config{
+		Host:     srv.URL,
+		Protocol: otlp.ProtocolHTTP,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		MaxBatch: 2,
+		Retries:  1,
+	}
+
+	op, err := otlp.New(newLogger(), cfg)
+	if err != nil {
+		t.Fatalf("Should be able to construct the publisher: %s", err)
+	}
+	defer op.Close()
+
+	if err := op.Publish(samples); err != nil {
+		t.Fatalf("Should be able to publish: %s", err)
+	}
+
+	rcv.check(t)
+}
+
+func Test_GRPC(t *testing.T) {
+	rcv := requests{failures: 1}
+
+	export := func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		var body rawMessage
+		if err := dec(&body); err != nil {
+			return nil, err
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		if !rcv.add(body, strings.Join(md.Get("authorization"), "")) {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		}
+
+		return rawMessage{}, nil
+	}
+
+	desc := grpc.ServiceDesc{
+		ServiceName: "opentelemetry.proto.collector.metrics.v1.MetricsService",
+		HandlerType: (*any)(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: "Export", Handler: export}},
+	}
+
+	srv := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}))
+	srv.RegisterService(&desc, struct{}{})
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Should be able to listen: %s", err)
+	}
+
+	go srv.Serve(lis)
+	defer srv.Stop()
+
+	cfg := otlp.Config{
+		Host:     lis.Addr().String(),
+		Protocol: otlp.ProtocolGRPC,
+		Insecure: true,
+		Headers:  map[string]string{"Authorization": "Bearer token"},
+		MaxBatch: 2,
+		Retries:  1,
+	}
+
+	op, err := otlp.New(newLogger(), cfg)
+	if err != nil {
+		t.Fatalf("Should be able to construct the publisher: %s", err)
+	}
+	defer op.Close()
+
+	if err := op.Publish(samples); err != nil {
+		t.Fatalf("Should be able to publish: %s", err)
+	}
+
+	rcv.check(t)
+}
+
+// =============================================================================
+
+type rawMessage []byte
+
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.(rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return msg, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*msg = append((*msg)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
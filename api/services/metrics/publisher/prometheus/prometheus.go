@@ -5,11 +5,15 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
-	"sync"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
 	"github.com/ardanlabs/service/foundation/logger"
 )
 
@@ -17,7 +21,7 @@ import (
 type Exporter struct {
 	log    *logger.Logger
 	server http.Server
-	data   map[string]any
+	data   []publisher.Sample
 	mu     sync.Mutex
 }
 
@@ -52,12 +56,14 @@ func New(log *logger.Logger, host string, route string, readTimeout, writeTimeou
 	return &exp
 }
 
-// Publish stores a deep copy of the data for publishing.
-func (exp *Exporter) Publish(data map[string]any) {
+// Publish stores a deep copy of the samples for publishing.
+func (exp *Exporter) Publish(samples []publisher.Sample) error {
 	exp.mu.Lock()
 	defer exp.mu.Unlock()
 
-	exp.data = deepCopyMap(data)
+	exp.data = deepCopySamples(samples)
+
+	return nil
 }
 
 // Stop turns off all the prometheus support.
@@ -83,18 +89,33 @@ func (exp *Exporter) handler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
 	w.WriteHeader(http.StatusOK)
 
-	var data map[string]any
+	var samples []publisher.Sample
 	exp.mu.Lock()
 	{
-		data = deepCopyMap(exp.data)
+		samples = deepCopySamples(exp.data)
 	}
 	exp.mu.Unlock()
 
-	out(w, "", data)
+	for _, sample := range samples {
+		out(w, "", sample.Target, sample.Data)
+	}
 
 	exp.log.Info(ctx, "prometheus", "metrics", fmt.Sprintf("expvar : (%d) : %s %s -> %s", http.StatusOK, r.Method, r.URL.Path, r.RemoteAddr))
 }
 
+func deepCopySamples(source []publisher.Sample) []publisher.Sample {
+	result := make([]publisher.Sample, len(source))
+
+	for i, sample := range source {
+		result[i] = publisher.Sample{
+			Target: sample.Target,
+			Data:   deepCopyMap(sample.Data),
+		}
+	}
+
+	return result
+}
+
 func deepCopyMap(source map[string]any) map[string]any {
 	result := make(map[string]any)
 
@@ -135,23 +156,60 @@ func sanitize(s string) string {
 	return result
 }
 
-func out(w io.Writer, prefix string, data map[string]any) {
+// out writes the data with the target and the labels carried by the keys.
+func out(w io.Writer, prefix string, target string, data map[string]any) {
 	if prefix != "" {
 		prefix += "_"
 	}
 
 	for k, v := range data {
-		writeKey := fmt.Sprintf("%s%s", prefix, sanitize(k))
+		name, labels := publisher.SplitKey(k)
+		writeKey := fmt.Sprintf("%s%s", prefix, sanitize(name))
 
 		switch vm := v.(type) {
 		case float64:
-			fmt.Fprintf(w, "%s %.f\n", writeKey, vm)
+			fmt.Fprintf(w, "%s%s %s\n", writeKey, formatLabels(target, labels), strconv.FormatFloat(vm, 'g', -1, 64))
 
 		case map[string]any:
-			out(w, writeKey, vm)
+			out(w, writeKey, target, vm)
 
 		default:
 			// Discard this value.
 		}
 	}
 }
+
+// formatLabels writes the target label followed by the labels of the series
+// in a stable order.
+func formatLabels(target string, labels map[string]string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+
+	if target != "" {
+		fmt.Fprintf(&b, `target="%s"`, escape(target))
+	}
+
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		if k == "target" {
+			continue
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, sanitize(k), escape(labels[k]))
+	}
+
+	if b.Len() == 1 {
+		return ""
+	}
+
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escape(s string) string {
+	return labelReplacer.Replace(s)
+}
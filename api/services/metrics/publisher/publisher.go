@@ -4,6 +4,9 @@ package publisher
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -12,46 +15,97 @@ import (
 
 // Set of possible publisher types.
 const (
-	TypeStdout  = "stdout"
-	TypeDatadog = "datadog"
+	TypeStdout     = "stdout"
+	TypeDatadog    = "datadog"
+	TypeStatsD     = "statsd"
+	TypeOTLP       = "otlp"
+	TypePrometheus = "prometheus"
+	TypeExpvar     = "expvar"
 )
 
+// Sample represents the metrics collected from a single target. The keys of
+// the data may carry Prometheus style labels, name{key="value"}.
+type Sample struct {
+	Target string
+	Data   map[string]any
+}
+
 // Collector defines a contract a collector must support
-// so a consumer can retrieve metrics.
+// so a consumer can retrieve metrics. A collector with several
+// targets returns the samples it could collect along with the
+// error for the targets that failed.
 type Collector interface {
-	Collect() (map[string]any, error)
+	Collect() ([]Sample, error)
 }
 
 // Publisher defines a handler function that will be called
-// on each interval.
-type Publisher func(map[string]any)
+// on each interval. A publisher that returns an error is called
+// again with the same samples after a backoff.
+type Publisher func([]Sample) error
+
+// Config represents the settings for publishing the metrics.
+type Config struct {
+	Interval time.Duration
+
+	// BufferSize is the number of collections held for a publisher that is
+	// failing. The oldest collection is dropped when the buffer is full.
+	BufferSize int
+
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
 
 // Publish provides the ability to receive metrics
 // on an interval.
 type Publish struct {
 	log       *logger.Logger
 	collector Collector
-	publisher []Publisher
+	queues    []*queue
 	wg        sync.WaitGroup
 	timer     *time.Timer
 	shutdown  chan struct{}
 }
 
-// New creates a Publish for consuming and publishing metrics.
-func New(log *logger.Logger, collector Collector, interval time.Duration, publisher ...Publisher) (*Publish, error) {
+// New creates a Publish for consuming and publishing metrics. Each publisher
+// is fed from its own buffer on its own goroutine so a slow or failing
+// publisher doesn't delay the others. The publishers are named for the
+// metrics reported about them.
+func New(log *logger.Logger, collector Collector, cfg Config, publishers map[string]Publisher) (*Publish, error) {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 1
+	}
+
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = time.Second
+	}
+
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = cfg.MinBackoff
+	}
+
 	p := Publish{
 		log:       log,
 		collector: collector,
-		publisher: publisher,
-		timer:     time.NewTimer(interval),
+		timer:     time.NewTimer(cfg.Interval),
 		shutdown:  make(chan struct{}),
 	}
 
+	for name, publisher := range publishers {
+		q := newQueue(log, name, publisher, cfg)
+		p.queues = append(p.queues, q)
+
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			q.run(p.shutdown)
+		}()
+	}
+
 	p.wg.Add(1)
 	go func() {
 		defer p.wg.Done()
 		for {
-			p.timer.Reset(interval)
+			p.timer.Reset(cfg.Interval)
 			select {
 			case <-p.timer.C:
 				p.update()
@@ -64,22 +118,27 @@ func New(log *logger.Logger, collector Collector, interval time.Duration, publis
 	return &p, nil
 }
 
-// Stop is used to shut down the goroutine collecting metrics.
+// Stop is used to shut down the goroutines collecting and publishing
+// metrics.
 func (p *Publish) Stop() {
 	close(p.shutdown)
 	p.wg.Wait()
 }
 
-// update pulls the metrics and publishes them to the specified system.
+// update pulls the metrics and hands them to the publishers. The samples
+// that could be collected are published even when some targets failed.
 func (p *Publish) update() {
-	data, err := p.collector.Collect()
+	samples, err := p.collector.Collect()
 	if err != nil {
 		p.log.Error(context.Background(), "publish", "status", "collect data", "err", err)
+	}
+
+	if len(samples) == 0 {
 		return
 	}
 
-	for _, pub := range p.publisher {
-		pub(data)
+	for _, q := range p.queues {
+		q.push(samples)
 	}
 }
 
@@ -94,19 +153,27 @@ func NewStdout(log *logger.Logger) *Stdout {
 }
 
 // Publish publishers for writing to stdout.
-func (s *Stdout) Publish(data map[string]any) {
+func (s *Stdout) Publish(samples []Sample) error {
+	for _, sample := range samples {
+		if err := s.publish(sample); err != nil {
+			return fmt.Errorf("target %q: %w", sample.Target, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Stdout) publish(sample Sample) error {
 	ctx := context.Background()
 
-	rawJSON, err := json.Marshal(data)
+	rawJSON, err := json.Marshal(sample.Data)
 	if err != nil {
-		s.log.Error(ctx, "stdout", "status", "marshal data", "err", err)
-		return
+		return fmt.Errorf("marshal data: %w", err)
 	}
 
 	var d map[string]any
 	if err := json.Unmarshal(rawJSON, &d); err != nil {
-		s.log.Error(ctx, "stdout", "status", "unmarshal data", "err", err)
-		return
+		return fmt.Errorf("unmarshal data: %w", err)
 	}
 
 	// Add heap value into the data set.
@@ -121,7 +188,119 @@ func (s *Stdout) Publish(data map[string]any) {
 
 	out, err := json.Marshal(d)
 	if err != nil {
-		return
+		return fmt.Errorf("marshal output: %w", err)
 	}
-	s.log.Info(ctx, "stdout", "data", string(out))
+	s.log.Info(ctx, "stdout", "target", sample.Target, "data", string(out))
+
+	return nil
+}
+
+// =============================================================================
+
+// Series represents a single value of a sample.
+type Series struct {
+	Name   string
+	Labels map[string]string
+	Value  float64
+}
+
+// Series flattens the data of the sample into the series it holds, sorted by
+// name. The keys of nested maps are joined with the separator. Values that
+// are not numbers or booleans are discarded.
+func (s Sample) Series(sep string) []Series {
+	var series []Series
+	flatten(&series, "", sep, s.Data)
+
+	slices.SortFunc(series, func(a, b Series) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return series
+}
+
+func flatten(series *[]Series, prefix string, sep string, data map[string]any) {
+	for k, v := range data {
+		name, labels := SplitKey(k)
+		if prefix != "" {
+			name = prefix + sep + name
+		}
+
+		var value float64
+
+		switch vm := v.(type) {
+		case float64:
+			value = vm
+
+		case int:
+			value = float64(vm)
+
+		case int64:
+			value = float64(vm)
+
+		case bool:
+			if vm {
+				value = 1
+			}
+
+		case map[string]any:
+			flatten(series, name, sep, vm)
+			continue
+
+		default:
+			continue
+		}
+
+		*series = append(*series, Series{Name: name, Labels: labels, Value: value})
+	}
+}
+
+// SplitKey separates the metric name from the Prometheus style labels a key
+// may carry.
+func SplitKey(key string) (string, map[string]string) {
+	i := strings.IndexByte(key, '{')
+	if i == -1 || !strings.HasSuffix(key, "}") {
+		return key, nil
+	}
+
+	labels := make(map[string]string)
+
+	rest := key[i+1 : len(key)-1]
+	for rest != "" {
+		eq := strings.IndexByte(rest, '=')
+		if eq == -1 || len(rest) < eq+2 || rest[eq+1] != '"' {
+			break
+		}
+
+		name := strings.TrimSpace(rest[:eq])
+		rest = rest[eq+2:]
+
+		var value strings.Builder
+		var end int
+		for end = 0; end < len(rest); end++ {
+			c := rest[end]
+			if c == '\\' && end+1 < len(rest) {
+				end++
+				switch rest[end] {
+				case 'n':
+					value.WriteByte('\n')
+				default:
+					value.WriteByte(rest[end])
+				}
+				continue
+			}
+			if c == '"' {
+				break
+			}
+			value.WriteByte(c)
+		}
+
+		labels[name] = value.String()
+
+		if end >= len(rest) {
+			break
+		}
+		rest = strings.TrimPrefix(rest[end+1:], ",")
+	}
+
+	return key[:i], labels
 }
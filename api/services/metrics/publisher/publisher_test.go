@@ -0,0 +1,137 @@
+package publisher_test
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"io"
+	"maps"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_SplitKey(t *testing.T) {
+	tests := []struct {
+		key    string
+		name   string
+		labels map[string]string
+	}{
+		{key: "goroutines", name: "goroutines"},
+		{key: `requests{route="/v1/users",status="200"}`, name: "requests", labels: map[string]string{"route": "/v1/users", "status": "200"}},
+		{key: `requests{path="a\"b,c=\\d\ne"}`, name: "requests", labels: map[string]string{"path": "a\"b,c=\\d\ne"}},
+	}
+
+	for _, tt := range tests {
+		name, labels := publisher.SplitKey(tt.key)
+		if name != tt.name || !maps.Equal(labels, tt.labels) {
+			t.Errorf("%s: got %s %v, exp %s %v", tt.key, name, labels, tt.name, tt.labels)
+		}
+	}
+}
+
+type collector struct {
+	n atomic.Int64
+}
+
+func (c *collector) Collect() ([]publisher.Sample, error) {
+	n := c.n.Add(1)
+	return []publisher.Sample{{Target: "test", Data: map[string]any{"n": float64(n)}}}, nil
+}
+
+func Test_Publish(t *testing.T) {
+	fast := make(chan float64, 100)
+	release := make(chan struct{})
+
+	var flakyCalls atomic.Int64
+	var flakyLast atomic.Int64
+
+	publishers := map[string]publisher.Publisher{
+		"fast": func(samples []publisher.Sample) error {
+			fast <- samples[0].Data["n"].(float64)
+			return nil
+		},
+		"slow": func(samples []publisher.Sample) error {
+			<-release
+			return nil
+		},
+		"flaky": func(samples []publisher.Sample) error {
+			if flakyCalls.Add(1) <= 3 {
+				return errors.New("unavailable")
+			}
+			flakyLast.Store(int64(samples[0].Data["n"].(float64)))
+			return nil
+		},
+	}
+
+	cfg := publisher.Config{
+		Interval:   5 * time.Millisecond,
+		BufferSize: 2,
+		MinBackoff: time.Millisecond,
+		MaxBackoff: 4 * time.Millisecond,
+	}
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	dropped := stat("slow.dropped")
+	failures := stat("flaky.failures")
+
+	p, err := publisher.New(log, &collector{}, cfg, publishers)
+	if err != nil {
+		t.Fatalf("Should be able to construct the publisher: %s", err)
+	}
+
+	// The fast publisher keeps receiving the collections while the slow
+	// publisher is blocked.
+	for i := range 10 {
+		select {
+		case <-fast:
+		case <-time.After(time.Second):
+			t.Fatalf("Should receive collection %d while the slow publisher is blocked", i)
+		}
+	}
+
+	close(release)
+
+	deadline := time.Now().Add(time.Second)
+	for flakyLast.Load() < 10 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	p.Stop()
+
+	if n := stat("slow.dropped") - dropped; n == 0 {
+		t.Error("Should drop the oldest samples for the slow publisher")
+	}
+
+	// Each collection holds a single sample so no more samples than the
+	// buffer size can be waiting.
+	if n := stat("slow.buffered"); n < 0 || n > int64(cfg.BufferSize) {
+		t.Errorf("Should count the buffered samples for the slow publisher: got %d", n)
+	}
+
+	if n := stat("flaky.failures") - failures; n != 3 {
+		t.Errorf("Should count the failures of the flaky publisher: got %d", n)
+	}
+
+	if flakyLast.Load() < 10 {
+		t.Errorf("Should retry the flaky publisher until it delivers: got %d", flakyLast.Load())
+	}
+}
+
+func stat(name string) int64 {
+	stats, ok := expvar.Get("publisher").(*expvar.Map)
+	if !ok {
+		return 0
+	}
+
+	v, ok := stats.Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+
+	return v.Value()
+}
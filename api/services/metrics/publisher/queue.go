@@ -0,0 +1,134 @@
+package publisher
+
+import (
+	"context"
+	"expvar"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// stats reports the delivery of the samples for each publisher by name. The
+// buffered, dropped and published stats count samples while failures counts
+// the attempts that failed.
+var stats = expvar.NewMap("publisher")
+
+// queue holds the collections waiting to be delivered to a publisher. When
+// the publisher fails, the collection is retried with an exponential backoff
+// while new collections are buffered up to the configured size.
+type queue struct {
+	log       *logger.Logger
+	name      string
+	publisher Publisher
+	cfg       Config
+	signal    chan struct{}
+
+	mu      sync.Mutex
+	pending []batch
+	nextID  uint64
+}
+
+// batch is a collection waiting to be delivered.
+type batch struct {
+	id      uint64
+	samples []Sample
+}
+
+func newQueue(log *logger.Logger, name string, publisher Publisher, cfg Config) *queue {
+	return &queue{
+		log:       log,
+		name:      name,
+		publisher: publisher,
+		cfg:       cfg,
+		signal:    make(chan struct{}, 1),
+	}
+}
+
+// push adds the samples to the buffer, dropping the oldest collection when
+// the buffer is full.
+func (q *queue) push(samples []Sample) {
+	q.mu.Lock()
+	{
+		if len(q.pending) >= q.cfg.BufferSize {
+			dropped := int64(len(q.pending[0].samples))
+			stats.Add(q.name+".dropped", dropped)
+			stats.Add(q.name+".buffered", -dropped)
+			q.pending = q.pending[1:]
+		}
+
+		q.pending = append(q.pending, batch{id: q.nextID, samples: samples})
+		stats.Add(q.name+".buffered", int64(len(samples)))
+
+		q.nextID++
+	}
+	q.mu.Unlock()
+
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+func (q *queue) peek() (batch, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		return batch{}, false
+	}
+
+	return q.pending[0], true
+}
+
+// pop removes the batch that was delivered unless it was already dropped to
+// make room for a newer one.
+func (q *queue) pop(id uint64) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) > 0 && q.pending[0].id == id {
+		stats.Add(q.name+".buffered", -int64(len(q.pending[0].samples)))
+		q.pending = q.pending[1:]
+	}
+}
+
+// run delivers the buffered collections in order until shutdown.
+func (q *queue) run(shutdown <-chan struct{}) {
+	ctx := context.Background()
+	backoff := q.cfg.MinBackoff
+
+	for {
+		select {
+		case <-q.signal:
+		case <-shutdown:
+			return
+		}
+
+		for {
+			b, ok := q.peek()
+			if !ok {
+				break
+			}
+
+			if err := q.publisher(b.samples); err != nil {
+				stats.Add(q.name+".failures", 1)
+				q.log.Error(ctx, "publish", "status", "publisher failed", "publisher", q.name, "backoff", backoff, "err", err)
+
+				select {
+				case <-time.After(backoff):
+				case <-shutdown:
+					return
+				}
+
+				backoff = min(backoff*2, q.cfg.MaxBackoff)
+				continue
+			}
+
+			stats.Add(q.name+".published", int64(len(b.samples)))
+			backoff = q.cfg.MinBackoff
+
+			q.pop(b.id)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+// Package statsd provides support for publishing metrics to StatsD or
+// DogStatsD over UDP.
+package statsd
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// Config represents the settings for publishing to StatsD.
+type Config struct {
+	Host string
+
+	// Prefix is added to the name of every metric.
+	Prefix string
+
+	// DogStatsD writes the target and labels as tags. Plain StatsD has no
+	// tags so they are added to the name of the metric instead.
+	DogStatsD bool
+
+	// MaxPacketSize is the largest payload written in a single datagram.
+	// The default fits in the MTU of most networks.
+	MaxPacketSize int
+
+	// Retries is the number of times a datagram that failed to be written
+	// is written again.
+	Retries int
+}
+
+// StatsD provides the ability to publish metrics to StatsD.
+type StatsD struct {
+	log  *logger.Logger
+	cfg  Config
+	conn net.Conn
+}
+
+// New constructs a StatsD for publishing metrics to the host.
+func New(log *logger.Logger, cfg Config) (*StatsD, error) {
+	if cfg.MaxPacketSize <= 0 {
+		cfg.MaxPacketSize = 1432
+	}
+
+	conn, err := net.Dial("udp", cfg.Host)
+	if err != nil {
+		return nil, fmt.Errorf("dial: %w", err)
+	}
+
+	s := StatsD{
+		log:  log,
+		cfg:  cfg,
+		conn: conn,
+	}
+
+	return &s, nil
+}
+
+// Close closes the connection.
+func (s *StatsD) Close() error {
+	return s.conn.Close()
+}
+
+// Publish writes the samples as gauges, batching the lines into as few
+// datagrams as possible.
+func (s *StatsD) Publish(samples []publisher.Sample) error {
+	for _, packet := range s.packets(samples) {
+		if err := s.write(packet); err != nil {
+			return fmt.Errorf("write packet: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *StatsD) write(packet []byte) error {
+	var err error
+
+	backoff := 50 * time.Millisecond
+	for attempt := 0; attempt <= s.cfg.Retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		if _, err = s.conn.Write(packet); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// packets formats the samples and groups the lines into datagrams no larger
+// than the max packet size.
+func (s *StatsD) packets(samples []publisher.Sample) [][]byte {
+	var packets [][]byte
+	var packet bytes.Buffer
+
+	add := func(line string) {
+		if packet.Len() > 0 && packet.Len()+1+len(line) > s.cfg.MaxPacketSize {
+			packets = append(packets, bytes.Clone(packet.Bytes()))
+			packet.Reset()
+		}
+
+		if packet.Len() > 0 {
+			packet.WriteByte('\n')
+		}
+		packet.WriteString(line)
+	}
+
+	for _, sample := range samples {
+		for _, series := range sample.Series(".") {
+			for _, line := range s.lines(sample.Target, series) {
+				add(line)
+			}
+		}
+	}
+
+	if packet.Len() > 0 {
+		packets = append(packets, packet.Bytes())
+	}
+
+	return packets
+}
+
+// lines formats the series as a gauge. A gauge with a sign is applied as a
+// delta by StatsD, so a negative value is written after setting the gauge
+// to zero.
+func (s *StatsD) lines(target string, series publisher.Series) []string {
+	var name strings.Builder
+	if s.cfg.Prefix != "" {
+		name.WriteString(s.cfg.Prefix)
+		name.WriteByte('.')
+	}
+
+	var tags string
+
+	switch {
+	case s.cfg.DogStatsD:
+		name.WriteString(sanitize(series.Name))
+		tags = formatTags(target, series.Labels)
+
+	default:
+		name.WriteString(sanitize(target))
+		name.WriteByte('.')
+		name.WriteString(sanitize(series.Name))
+		for _, k := range slices.Sorted(maps.Keys(series.Labels)) {
+			name.WriteByte('.')
+			name.WriteString(sanitize(k))
+			name.WriteByte('.')
+			name.WriteString(sanitize(series.Labels[k]))
+		}
+	}
+
+	value := strconv.FormatFloat(series.Value, 'f', -1, 64)
+	line := name.String() + ":" + value + "|g" + tags
+
+	if series.Value < 0 {
+		return []string{name.String() + ":0|g" + tags, line}
+	}
+
+	return []string{line}
+}
+
+func formatTags(target string, labels map[string]string) string {
+	tags := []string{"target:" + sanitizeTag(target)}
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		if k == "target" {
+			continue
+		}
+		tags = append(tags, sanitizeTag(k)+":"+sanitizeTag(labels[k]))
+	}
+
+	return "|#" + strings.Join(tags, ",")
+}
+
+// sanitize replaces the characters that are reserved by the StatsD line
+// format in a name.
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ':', '|', '@', '#', ',', ' ', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// sanitizeTag replaces the characters that are reserved by the DogStatsD
+// tag format.
+func sanitizeTag(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '|', ',', '#', ' ', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,114 @@
+package statsd_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/api/services/metrics/publisher"
+	"github.com/ardanlabs/service/api/services/metrics/publisher/statsd"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_StatsD(t *testing.T) {
+	samples := []publisher.Sample{
+		{
+			Target: "sales",
+			Data: map[string]any{
+				"goroutines":                  12.0,
+				"memstats":                    map[string]any{"Alloc": 1024.0},
+				"delta":                       -3.0,
+				`requests{route="/v1/users"}`: 5.0,
+				"cmdline":                     []any{"sales"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name  string
+		cfg   statsd.Config
+		lines []string
+	}{
+		{
+			name: "statsd",
+			cfg:  statsd.Config{Prefix: "ardan"},
+			lines: []string{
+				"ardan.sales.delta:0|g",
+				"ardan.sales.delta:-3|g",
+				"ardan.sales.goroutines:12|g",
+				"ardan.sales.memstats.Alloc:1024|g",
+				"ardan.sales.requests.route./v1/users:5|g",
+			},
+		},
+		{
+			name: "dogstatsd",
+			cfg:  statsd.Config{DogStatsD: true, MaxPacketSize: 64},
+			lines: []string{
+				"delta:0|g|#target:sales",
+				"delta:-3|g|#target:sales",
+				"goroutines:12|g|#target:sales",
+				"memstats.Alloc:1024|g|#target:sales",
+				"requests:5|g|#target:sales,route:/v1/users",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatalf("Should be able to listen: %s", err)
+			}
+			defer conn.Close()
+
+			tt.cfg.Host = conn.LocalAddr().String()
+
+			log := logger.New(&bytes.Buffer{}, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+			sd, err := statsd.New(log, tt.cfg)
+			if err != nil {
+				t.Fatalf("Should be able to construct the publisher: %s", err)
+			}
+			defer sd.Close()
+
+			if err := sd.Publish(samples); err != nil {
+				t.Fatalf("Should be able to publish: %s", err)
+			}
+
+			var lines []string
+			var packets int
+
+			buf := make([]byte, 2048)
+			for len(lines) < len(tt.lines) {
+				conn.SetReadDeadline(time.Now().Add(time.Second))
+
+				n, _, err := conn.ReadFrom(buf)
+				if err != nil {
+					t.Fatalf("Should be able to read the packets: %s: got %v", err, lines)
+				}
+
+				if tt.cfg.MaxPacketSize > 0 && n > tt.cfg.MaxPacketSize {
+					t.Errorf("Should not exceed the max packet size: %d", n)
+				}
+
+				packets++
+				lines = append(lines, strings.Split(string(buf[:n]), "\n")...)
+			}
+
+			if strings.Join(lines, "\n") != strings.Join(tt.lines, "\n") {
+				t.Errorf("Should get the lines:\ngot:\n%s\nexp:\n%s", strings.Join(lines, "\n"), strings.Join(tt.lines, "\n"))
+			}
+
+			if tt.cfg.MaxPacketSize == 0 && packets != 1 {
+				t.Errorf("Should batch the lines into a single packet: got %d", packets)
+			}
+
+			if tt.cfg.MaxPacketSize > 0 && packets < 2 {
+				t.Errorf("Should split the lines into several packets: got %d", packets)
+			}
+		})
+	}
+}
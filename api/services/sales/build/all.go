@@ -4,15 +4,20 @@
 package build
 
 import (
+	"slices"
+
 	"github.com/ardanlabs/service/app/domain/auditapp"
 	"github.com/ardanlabs/service/app/domain/checkapp"
 	"github.com/ardanlabs/service/app/domain/homeapp"
+	"github.com/ardanlabs/service/app/domain/jobapp"
+	"github.com/ardanlabs/service/app/domain/openapiapp"
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/domain/rawapp"
 	"github.com/ardanlabs/service/app/domain/tranapp"
 	"github.com/ardanlabs/service/app/domain/userapp"
 	"github.com/ardanlabs/service/app/domain/vproductapp"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -25,6 +30,11 @@ type all struct{}
 
 // Add implements the RouterAdder interface.
 func (all) Add(app *web.App, cfg mux.Config) {
+	subjectLimit := ratelimit.Config{
+		Store: cfg.RateLimiter,
+		Limit: cfg.SalesConfig.SubjectLimit,
+	}
+
 	checkapp.Routes(app, checkapp.Config{
 		Build: cfg.Build,
 		Log:   cfg.Log,
@@ -35,34 +45,41 @@ func (all) Add(app *web.App, cfg mux.Config) {
 		Log:        cfg.Log,
 		HomeBus:    cfg.BusConfig.HomeBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
+		RateLimit:  subjectLimit,
 	})
 
 	productapp.Routes(app, productapp.Config{
-		Log:        cfg.Log,
-		ProductBus: cfg.BusConfig.ProductBus,
-		AuthClient: cfg.SalesConfig.AuthClient,
+		Log:         cfg.Log,
+		ProductBus:  cfg.BusConfig.ProductBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+		RateLimit:   subjectLimit,
+		Idempotency: cfg.SalesConfig.Idempotency,
 	})
 
 	rawapp.Routes(app)
 
 	tranapp.Routes(app, tranapp.Config{
-		Log:        cfg.Log,
-		DB:         cfg.DB,
-		UserBus:    cfg.BusConfig.UserBus,
-		ProductBus: cfg.BusConfig.ProductBus,
-		AuthClient: cfg.SalesConfig.AuthClient,
+		Log:         cfg.Log,
+		DB:          cfg.DB,
+		UserBus:     cfg.BusConfig.UserBus,
+		ProductBus:  cfg.BusConfig.ProductBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+		RateLimit:   subjectLimit,
+		Idempotency: cfg.SalesConfig.Idempotency,
 	})
 
 	userapp.Routes(app, userapp.Config{
 		Log:        cfg.Log,
 		UserBus:    cfg.BusConfig.UserBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
+		RateLimit:  subjectLimit,
 	})
 
 	auditapp.Routes(app, auditapp.Config{
 		Log:        cfg.Log,
 		AuditBus:   cfg.BusConfig.AuditBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
+		RateLimit:  subjectLimit,
 	})
 
 	vproductapp.Routes(app, vproductapp.Config{
@@ -70,5 +87,29 @@ func (all) Add(app *web.App, cfg mux.Config) {
 		UserBus:     cfg.BusConfig.UserBus,
 		VProductBus: cfg.BusConfig.VProductBus,
 		AuthClient:  cfg.SalesConfig.AuthClient,
+		RateLimit:   subjectLimit,
+	})
+
+	jobapp.Routes(app, jobapp.Config{
+		Log:        cfg.Log,
+		JobBus:     cfg.BusConfig.JobBus,
+		AuthClient: cfg.SalesConfig.AuthClient,
+		RateLimit:  subjectLimit,
+	})
+
+	openapiapp.Routes(app, openapiapp.Config{
+		Build: cfg.Build,
+		Title: "Sales",
+		Operations: slices.Concat(
+			checkapp.Operations(),
+			homeapp.Operations(),
+			productapp.Operations(),
+			tranapp.Operations(),
+			userapp.Operations(),
+			auditapp.Operations(),
+			vproductapp.Operations(),
+			jobapp.Operations(),
+			openapiapp.Operations(),
+		),
 	})
 }
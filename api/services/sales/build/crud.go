@@ -3,13 +3,18 @@
 package build
 
 import (
+	"slices"
+
 	"github.com/ardanlabs/service/app/domain/auditapp"
 	"github.com/ardanlabs/service/app/domain/checkapp"
 	"github.com/ardanlabs/service/app/domain/homeapp"
+	"github.com/ardanlabs/service/app/domain/jobapp"
+	"github.com/ardanlabs/service/app/domain/openapiapp"
 	"github.com/ardanlabs/service/app/domain/productapp"
 	"github.com/ardanlabs/service/app/domain/tranapp"
 	"github.com/ardanlabs/service/app/domain/userapp"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -22,6 +27,11 @@ type crud struct{}
 
 // Add implements the RouterAdder interface.
 func (crud) Add(app *web.App, cfg mux.Config) {
+	subjectLimit := ratelimit.Config{
+		Store: cfg.RateLimiter,
+		Limit: cfg.SalesConfig.SubjectLimit,
+	}
+
 	checkapp.Routes(app, checkapp.Config{
 		Build: cfg.Build,
 		Log:   cfg.Log,
@@ -31,29 +41,59 @@ func (crud) Add(app *web.App, cfg mux.Config) {
 	homeapp.Routes(app, homeapp.Config{
 		HomeBus:    cfg.BusConfig.HomeBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
+		RateLimit:  subjectLimit,
 	})
 
 	productapp.Routes(app, productapp.Config{
-		ProductBus: cfg.BusConfig.ProductBus,
-		AuthClient: cfg.SalesConfig.AuthClient,
+		Log:         cfg.Log,
+		ProductBus:  cfg.BusConfig.ProductBus,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+		RateLimit:   subjectLimit,
+		Idempotency: cfg.SalesConfig.Idempotency,
 	})
 
 	tranapp.Routes(app, tranapp.Config{
-		UserBus:    cfg.BusConfig.UserBus,
-		ProductBus: cfg.BusConfig.ProductBus,
-		Log:        cfg.Log,
-		AuthClient: cfg.SalesConfig.AuthClient,
-		DB:         cfg.DB,
+		UserBus:     cfg.BusConfig.UserBus,
+		ProductBus:  cfg.BusConfig.ProductBus,
+		Log:         cfg.Log,
+		AuthClient:  cfg.SalesConfig.AuthClient,
+		RateLimit:   subjectLimit,
+		DB:          cfg.DB,
+		Idempotency: cfg.SalesConfig.Idempotency,
 	})
 
 	userapp.Routes(app, userapp.Config{
 		UserBus:    cfg.BusConfig.UserBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
+		RateLimit:  subjectLimit,
 	})
 
 	auditapp.Routes(app, auditapp.Config{
 		Log:        cfg.Log,
 		AuditBus:   cfg.BusConfig.AuditBus,
 		AuthClient: cfg.SalesConfig.AuthClient,
+		RateLimit:  subjectLimit,
+	})
+
+	jobapp.Routes(app, jobapp.Config{
+		Log:        cfg.Log,
+		JobBus:     cfg.BusConfig.JobBus,
+		AuthClient: cfg.SalesConfig.AuthClient,
+		RateLimit:  subjectLimit,
+	})
+
+	openapiapp.Routes(app, openapiapp.Config{
+		Build: cfg.Build,
+		Title: "Sales",
+		Operations: slices.Concat(
+			checkapp.Operations(),
+			homeapp.Operations(),
+			productapp.Operations(),
+			tranapp.Operations(),
+			userapp.Operations(),
+			auditapp.Operations(),
+			jobapp.Operations(),
+			openapiapp.Operations(),
+		),
 	})
 }
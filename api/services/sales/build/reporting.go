@@ -3,9 +3,13 @@
 package build
 
 import (
+	"slices"
+
 	"github.com/ardanlabs/service/app/domain/checkapp"
+	"github.com/ardanlabs/service/app/domain/openapiapp"
 	"github.com/ardanlabs/service/app/domain/vproductapp"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
@@ -18,6 +22,11 @@ type rpt struct{}
 
 // Add implements the RouterAdder interface.
 func (rpt) Add(app *web.App, cfg mux.Config) {
+	subjectLimit := ratelimit.Config{
+		Store: cfg.RateLimiter,
+		Limit: cfg.SalesConfig.SubjectLimit,
+	}
+
 	checkapp.Routes(app, checkapp.Config{
 		Build: cfg.Build,
 		Log:   cfg.Log,
@@ -28,5 +37,16 @@ func (rpt) Add(app *web.App, cfg mux.Config) {
 		UserBus:     cfg.BusConfig.UserBus,
 		VProductBus: cfg.BusConfig.VProductBus,
 		AuthClient:  cfg.SalesConfig.AuthClient,
+		RateLimit:   subjectLimit,
+	})
+
+	openapiapp.Routes(app, openapiapp.Config{
+		Build: cfg.Build,
+		Title: "Sales",
+		Operations: slices.Concat(
+			checkapp.Operations(),
+			vproductapp.Operations(),
+			openapiapp.Operations(),
+		),
 	})
 }
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"embed"
+	"encoding/json"
 	"errors"
 	"expvar"
 	"fmt"
@@ -19,13 +20,19 @@ import (
 	"github.com/ardanlabs/service/app/sdk/authclient/grpc"
 	http2 "github.com/ardanlabs/service/app/sdk/authclient/http"
 	"github.com/ardanlabs/service/app/sdk/debug"
+	"github.com/ardanlabs/service/app/sdk/idempotency"
+	"github.com/ardanlabs/service/app/sdk/idempotency/stores/idempotencydb"
 	"github.com/ardanlabs/service/app/sdk/mux"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/auditbus/extensions/auditotel"
 	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/homebus/extensions/homeotel"
 	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/domain/jobbus/extensions/jobotel"
+	"github.com/ardanlabs/service/business/domain/jobbus/stores/jobdb"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/productbus/extensions/productotel"
 	"github.com/ardanlabs/service/business/domain/productbus/stores/productpg"
@@ -41,6 +48,10 @@ import (
 	"github.com/ardanlabs/service/business/sdk/sqldb"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/scheduler"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/ardanlabs/service/foundation/worker"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 /*
@@ -61,7 +72,8 @@ func main() {
 		},
 	}
 
-	log = logger.NewWithEvents(os.Stdout, logger.LevelInfo, "SALES", otel.GetTraceID, events)
+	log = logger.NewWithEvents(os.Stdout, logger.LevelInfo, "SALES", otel.GetTraceID, events).
+		WithContextValue("request_id", web.GetRequestID)
 
 	// -------------------------------------------------------------------------
 
@@ -85,14 +97,32 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	cfg := struct {
 		conf.Version
+		Log struct {
+			Level    string   `conf:"default:INFO"`
+			Packages []string `conf:"help:package level overrides as package=level"`
+			Sampling []string `conf:"default:INFO=100/10/1s,help:sampling as level=first/thereafter/interval"`
+			Events   []string `conf:"default:ERROR=10/1m,help:event limits as level=max/interval"`
+		}
 		Web struct {
-			ReadTimeout        time.Duration `conf:"default:5s"`
-			WriteTimeout       time.Duration `conf:"default:10s"`
-			IdleTimeout        time.Duration `conf:"default:120s"`
-			ShutdownTimeout    time.Duration `conf:"default:20s"`
-			APIHost            string        `conf:"default:0.0.0.0:3000"`
-			DebugHost          string        `conf:"default:0.0.0.0:3010"`
-			CORSAllowedOrigins []string      `conf:"default:*"`
+			ReadTimeout           time.Duration `conf:"default:5s"`
+			WriteTimeout          time.Duration `conf:"default:10s"`
+			IdleTimeout           time.Duration `conf:"default:120s"`
+			ShutdownTimeout       time.Duration `conf:"default:20s"`
+			APIHost               string        `conf:"default:0.0.0.0:3000"`
+			DebugHost             string        `conf:"default:0.0.0.0:3010"`
+			CORSAllowedOrigins    []string      `conf:"default:*"`
+			CORSAllowedMethods    []string      `conf:"default:POST;PATCH;GET;OPTIONS;PUT;DELETE"`
+			CORSAllowedHeaders    []string      `conf:"default:Accept;Content-Type;Content-Length;Accept-Encoding;X-CSRF-Token;Authorization;X-Request-ID;Idempotency-Key"`
+			CORSExposedHeaders    []string      `conf:"default:X-Request-ID;RateLimit-Policy;RateLimit-Limit;RateLimit-Remaining;RateLimit-Reset;Retry-After;Idempotent-Replayed"`
+			CORSAllowCredentials  bool          `conf:"default:false"`
+			CORSMaxAge            time.Duration `conf:"default:24h"`
+			HSTSMaxAge            time.Duration `conf:"default:17520h"`
+			HSTSIncludeSubDomains bool          `conf:"default:true"`
+			HSTSPreload           bool          `conf:"default:true"`
+			CSP                   string        `conf:"default:default-src 'none'; img-src 'self'; frame-ancestors 'none'"`
+			ContentTypeOptions    string        `conf:"default:nosniff"`
+			FrameOptions          string        `conf:"default:DENY"`
+			ReferrerPolicy        string        `conf:"default:no-referrer"`
 		}
 		Auth struct {
 			Host      string `conf:"default:http://auth-service:6000"`
@@ -117,6 +147,47 @@ func run(ctx context.Context, log *logger.Logger) error {
 			// Shouldn't use a high Probability value in non-developer systems.
 			// 0.05 should be enough for most systems. Some might want to have
 			// this even lower.
+			Protocol       string   `conf:"default:grpc,help:grpc or http for traces and logs and metrics"`
+			Sampler        string   `conf:"default:parentbased_traceidratio"`
+			SampleErrors   bool     `conf:"default:true"`
+			ExcludedRoutes []string `conf:"default:/v1/liveness;/v1/readiness"`
+		}
+		Otel struct {
+			LogHost        string        `conf:"help:OTLP host for logs such as otel-collector:4317"`
+			MetricHost     string        `conf:"help:OTLP host for metrics such as otel-collector:4317"`
+			MetricInterval time.Duration `conf:"default:30s"`
+			Insecure       bool          `conf:"default:true"`
+			CAFile         string
+			CertFile       string
+			KeyFile        string
+			Headers        []string `conf:"mask,help:exporter headers as key=value"`
+			Environment    string   `conf:"default:development"`
+			Attributes     []string `conf:"help:resource attributes as key=value"`
+		}
+		Idempotency struct {
+			TTL   time.Duration `conf:"default:24h"`
+			Lease time.Duration `conf:"default:1m,help:how long a request holds its key before a retry can take it over"`
+		}
+		RateLimit struct {
+			Rate           int           `conf:"default:0,help:requests per period for each client ip"`
+			Period         time.Duration `conf:"default:1s"`
+			Burst          int           `conf:"default:0"`
+			SubjectRate    int           `conf:"default:0,help:requests per period for each authenticated user"`
+			SubjectPeriod  time.Duration `conf:"default:1s"`
+			SubjectBurst   int           `conf:"default:0"`
+			ClientIPHeader string        `conf:"help:X-Forwarded-For or X-Real-IP to read the client ip set by a trusted proxy"`
+			TrustedProxies []string      `conf:"help:ip addresses or cidr ranges of the proxies in front of the service"`
+		}
+		Jobs struct {
+			MaxRunning   int           `conf:"default:4"`
+			PollInterval time.Duration `conf:"default:1s"`
+			Lease        time.Duration `conf:"default:5m,help:time a job can run before it is claimed again"`
+			MinBackoff   time.Duration `conf:"default:5s"`
+			MaxBackoff   time.Duration `conf:"default:10m"`
+		}
+		Scheduler struct {
+			MaxRunning       int    `conf:"default:2"`
+			IdempotencyPurge string `conf:"default:@hourly,help:schedule to queue the purge of expired idempotency keys"`
 		}
 	}{
 		Version: conf.Version{
@@ -135,6 +206,39 @@ func run(ctx context.Context, log *logger.Logger) error {
 		return fmt.Errorf("parsing config: %w", err)
 	}
 
+	// -------------------------------------------------------------------------
+	// Log Levels
+
+	level, err := logger.ParseLevel(cfg.Log.Level)
+	if err != nil {
+		return fmt.Errorf("parsing log level: %w", err)
+	}
+	log.SetLevel(level, 0)
+
+	pkgLevels, err := logger.ParsePackageLevels(cfg.Log.Packages)
+	if err != nil {
+		return fmt.Errorf("parsing log package levels: %w", err)
+	}
+	for pkg, level := range pkgLevels {
+		log.SetPackageLevel(pkg, level, 0)
+	}
+
+	sampling, err := logger.ParseSampling(cfg.Log.Sampling)
+	if err != nil {
+		return fmt.Errorf("parsing log sampling: %w", err)
+	}
+	for level, s := range sampling {
+		log.SetSampling(level, s)
+	}
+
+	eventLimits, err := logger.ParseEventLimits(cfg.Log.Events)
+	if err != nil {
+		return fmt.Errorf("parsing log event limits: %w", err)
+	}
+	for level, limit := range eventLimits {
+		log.SetEventLimit(level, limit)
+	}
+
 	// -------------------------------------------------------------------------
 	// App Starting
 
@@ -171,6 +275,13 @@ func run(ctx context.Context, log *logger.Logger) error {
 
 	defer db.Close()
 
+	unregister, err := sqldb.RegisterMetrics(db, cfg.DB.Name)
+	if err != nil {
+		return fmt.Errorf("registering db metrics: %w", err)
+	}
+
+	defer unregister()
+
 	// -------------------------------------------------------------------------
 	// Create Business Packages
 
@@ -197,6 +308,10 @@ func run(ctx context.Context, log *logger.Logger) error {
 	vproductStorage := vproductdb.NewStore(log, db)
 	vproductBus := vproductbus.NewBusiness(vproductStorage, vproductOtelExt)
 
+	jobOtelExt := jobotel.NewExtension()
+	jobStorage := jobdb.NewStore(log, db)
+	jobBus := jobbus.NewBusiness(log, jobStorage, jobOtelExt)
+
 	// -------------------------------------------------------------------------
 	// Initialize authentication support
 
@@ -218,38 +333,175 @@ func run(ctx context.Context, log *logger.Logger) error {
 	defer authClient.Close()
 
 	// -------------------------------------------------------------------------
-	// Start Tracing Support
+	// Start Telemetry Support
 
-	log.Info(ctx, "startup", "status", "initializing tracing support")
+	log.Info(ctx, "startup", "status", "initializing telemetry support")
 
-	traceProvider, teardown, err := otel.InitTracing(otel.Config{
-		ServiceName: cfg.Tempo.ServiceName,
-		Host:        cfg.Tempo.Host,
-		ExcludedRoutes: map[string]struct{}{
-			"/v1/liveness":  {},
-			"/v1/readiness": {},
-		},
-		Probability: cfg.Tempo.Probability,
-	})
+	headers, err := otel.ParseKeyValues(cfg.Otel.Headers)
+	if err != nil {
+		return fmt.Errorf("parsing otel headers: %w", err)
+	}
+
+	attributes, err := otel.ParseKeyValues(cfg.Otel.Attributes)
+	if err != nil {
+		return fmt.Errorf("parsing otel attributes: %w", err)
+	}
+
+	registry := prometheus.NewRegistry()
+
+	otelCfg := otel.Config{
+		ServiceName:    cfg.Tempo.ServiceName,
+		ServiceVersion: cfg.Build,
+		Environment:    cfg.Otel.Environment,
+		Attributes:     attributes,
+		Host:           cfg.Tempo.Host,
+		Protocol:       cfg.Tempo.Protocol,
+		Insecure:       cfg.Otel.Insecure,
+		CAFile:         cfg.Otel.CAFile,
+		CertFile:       cfg.Otel.CertFile,
+		KeyFile:        cfg.Otel.KeyFile,
+		Headers:        headers,
+		ExcludedRoutes: cfg.Tempo.ExcludedRoutes,
+		Sampler:        cfg.Tempo.Sampler,
+		Probability:    cfg.Tempo.Probability,
+		SampleErrors:   cfg.Tempo.SampleErrors,
+		LogHost:        cfg.Otel.LogHost,
+		MetricHost:     cfg.Otel.MetricHost,
+		MetricInterval: cfg.Otel.MetricInterval,
+		Registerer:     registry,
+	}
+
+	traceProvider, teardown, err := otel.InitTracing(otelCfg)
 	if err != nil {
 		return fmt.Errorf("starting tracing: %w", err)
 	}
 
 	defer teardown(context.Background())
 
+	logHandler, logTeardown, err := otel.InitLogging(otelCfg)
+	if err != nil {
+		return fmt.Errorf("starting log export: %w", err)
+	}
+
+	defer logTeardown(context.Background())
+
+	log.AddHandler(logHandler)
+
+	_, metricTeardown, err := otel.InitMetrics(otelCfg)
+	if err != nil {
+		return fmt.Errorf("starting metric export: %w", err)
+	}
+
+	defer metricTeardown(context.Background())
+
 	tracer := traceProvider.Tracer(cfg.Tempo.ServiceName)
 
+	// -------------------------------------------------------------------------
+	// Start Scheduler
+
+	log.Info(ctx, "startup", "status", "initializing scheduler")
+
+	idemStore := idempotencydb.NewStore(log, db)
+
+	idemPurge, err := scheduler.Parse(cfg.Scheduler.IdempotencyPurge)
+	if err != nil {
+		return fmt.Errorf("parsing idempotency purge schedule: %w", err)
+	}
+
+	schedWorker, err := worker.New(cfg.Scheduler.MaxRunning)
+	if err != nil {
+		return fmt.Errorf("constructing scheduler worker: %w", err)
+	}
+
+	onTaskError := func(ctx context.Context, task string, err error) {
+		log.Error(ctx, "scheduler", "task", task, "msg", err)
+	}
+
+	sched := scheduler.New(schedWorker,
+		scheduler.WithLocker(sqldb.NewAdvisoryLocker(db)),
+		scheduler.WithErrorHandler(onTaskError),
+	)
+
+	// The scheduler only queues the purge so it runs with the job runner's
+	// retries and shows up in the job admin endpoints.
+	err = sched.Add(scheduler.Task{
+		Name:     "idempotency-purge",
+		Schedule: idemPurge,
+		Leader:   true,
+		Fn: func(ctx context.Context) error {
+			_, err := jobBus.Enqueue(ctx, jobbus.NewJob{
+				Type:    idempotency.PurgeJob,
+				Payload: idempotency.PurgePayload{Before: time.Now()},
+			})
+			return err
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("adding idempotency purge task: %w", err)
+	}
+
+	sched.Start()
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Web.ShutdownTimeout)
+		defer cancel()
+
+		if err := sched.Shutdown(ctx); err != nil {
+			log.Error(ctx, "shutdown", "status", "scheduler did not stop gracefully", "msg", err)
+		}
+
+		if err := schedWorker.Shutdown(ctx); err != nil {
+			log.Error(ctx, "shutdown", "status", "scheduler worker did not stop gracefully", "msg", err)
+		}
+	}()
+
 	// -------------------------------------------------------------------------
 	// Start Debug Service
 
 	go func() {
 		log.Info(ctx, "startup", "status", "debug v1 router started", "host", cfg.Web.DebugHost)
 
-		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux()); err != nil {
+		if err := http.ListenAndServe(cfg.Web.DebugHost, debug.Mux(debug.WithLogLevel(log), debug.WithMetrics(registry), debug.WithScheduler(sched))); err != nil {
 			log.Error(ctx, "shutdown", "status", "debug v1 router closed", "host", cfg.Web.DebugHost, "msg", err)
 		}
 	}()
 
+	// -------------------------------------------------------------------------
+	// Start Job Runner
+
+	log.Info(ctx, "startup", "status", "initializing job runner")
+
+	jobRunner, err := jobbus.NewRunner(log, jobBus, jobbus.RunnerConfig{
+		MaxRunning:   cfg.Jobs.MaxRunning,
+		PollInterval: cfg.Jobs.PollInterval,
+		Lease:        cfg.Jobs.Lease,
+		MinBackoff:   cfg.Jobs.MinBackoff,
+		MaxBackoff:   cfg.Jobs.MaxBackoff,
+	})
+	if err != nil {
+		return fmt.Errorf("constructing job runner: %w", err)
+	}
+
+	jobRunner.Register(idempotency.PurgeJob, func(ctx context.Context, job jobbus.Job) error {
+		var payload idempotency.PurgePayload
+		if err := json.Unmarshal(job.Payload, &payload); err != nil {
+			return fmt.Errorf("unmarshal payload: %w", err)
+		}
+
+		return idemStore.Purge(ctx, payload.Before)
+	})
+
+	jobRunner.Start()
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Web.ShutdownTimeout)
+		defer cancel()
+
+		if err := jobRunner.Shutdown(ctx); err != nil {
+			log.Error(ctx, "shutdown", "status", "job runner did not stop gracefully", "msg", err)
+		}
+	}()
+
 	// -------------------------------------------------------------------------
 	// Start API Service
 
@@ -258,26 +510,76 @@ func run(ctx context.Context, log *logger.Logger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	clientIP, err := ratelimit.NewClientIP(cfg.RateLimit.ClientIPHeader, cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		return fmt.Errorf("constructing client ip: %w", err)
+	}
+
 	cfgMux := mux.Config{
-		Build:  tag,
-		Log:    log,
-		DB:     db,
-		Tracer: tracer,
+		Build:       tag,
+		Log:         log,
+		DB:          db,
+		Tracer:      tracer,
+		RateLimiter: ratelimit.NewMemoryStore(),
+		ClientIP:    clientIP,
 		BusConfig: mux.BusConfig{
 			AuditBus:    auditBus,
 			UserBus:     userBus,
 			ProductBus:  productBus,
 			HomeBus:     homeBus,
 			VProductBus: vproductBus,
+			JobBus:      jobBus,
 		},
 		SalesConfig: mux.SalesConfig{
 			AuthClient: authClient,
+			Idempotency: idempotency.Config{
+				Store: idemStore,
+				TTL:   cfg.Idempotency.TTL,
+				Lease: cfg.Idempotency.Lease,
+			},
+			SubjectLimit: ratelimit.Limit{
+				Rate:   cfg.RateLimit.SubjectRate,
+				Period: cfg.RateLimit.SubjectPeriod,
+				Burst:  cfg.RateLimit.SubjectBurst,
+			},
 		},
 	}
 
+	cors := web.CORS{
+		AllowedOrigins:   cfg.Web.CORSAllowedOrigins,
+		AllowedMethods:   cfg.Web.CORSAllowedMethods,
+		AllowedHeaders:   cfg.Web.CORSAllowedHeaders,
+		ExposedHeaders:   cfg.Web.CORSExposedHeaders,
+		AllowCredentials: cfg.Web.CORSAllowCredentials,
+		MaxAge:           cfg.Web.CORSMaxAge,
+	}
+
+	hsts := web.HSTS{
+		MaxAge:            cfg.Web.HSTSMaxAge,
+		IncludeSubDomains: cfg.Web.HSTSIncludeSubDomains,
+		Preload:           cfg.Web.HSTSPreload,
+	}
+
+	secure := web.SecureHeaders{
+		ContentSecurityPolicy:   cfg.Web.CSP,
+		ContentTypeOptions:      cfg.Web.ContentTypeOptions,
+		FrameOptions:            cfg.Web.FrameOptions,
+		ReferrerPolicy:          cfg.Web.ReferrerPolicy,
+		CrossOriginOpenerPolicy: web.DefaultSecureHeaders.CrossOriginOpenerPolicy,
+	}
+
+	rateLimit := ratelimit.Limit{
+		Rate:   cfg.RateLimit.Rate,
+		Period: cfg.RateLimit.Period,
+		Burst:  cfg.RateLimit.Burst,
+	}
+
 	webAPI := mux.WebAPI(cfgMux,
 		build.Routes(),
-		mux.WithCORS(cfg.Web.CORSAllowedOrigins),
+		mux.WithCORS(cors),
+		mux.WithHSTS(hsts),
+		mux.WithSecureHeaders(secure),
+		mux.WithRateLimit(rateLimit),
 		mux.WithFileServer(false, static, "static", "/"),
 	)
 
@@ -0,0 +1,91 @@
+package job_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/jobapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/google/go-cmp/cmp"
+)
+
+func cancel200(sd apitest.SeedData) []apitest.Table {
+	exp := toAppJob(sd.Jobs[1])
+	exp.Status = jobstatus.Cancelled.String()
+
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/jobs/%s/cancel", sd.Jobs[1].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodPost,
+			GotResp:    &jobapp.Job{},
+			ExpResp:    &exp,
+			CmpFunc: func(got any, exp any) string {
+				return cmpJob(*got.(*jobapp.Job), *exp.(*jobapp.Job))
+			},
+		},
+	}
+
+	return table
+}
+
+func cancel400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "not-queued",
+			URL:        fmt.Sprintf("/v1/jobs/%s/cancel", sd.Jobs[1].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodPost,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Errorf(errs.FailedPrecondition, "job is not queued"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func retry200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "cancelled",
+			URL:        fmt.Sprintf("/v1/jobs/%s/retry", sd.Jobs[1].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodPost,
+			GotResp:    &jobapp.Job{},
+			ExpResp:    toAppJobPtr(sd.Jobs[1]),
+			CmpFunc: func(got any, exp any) string {
+				return cmpJob(*got.(*jobapp.Job), *exp.(*jobapp.Job))
+			},
+		},
+	}
+
+	return table
+}
+
+func retry400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "queued",
+			URL:        fmt.Sprintf("/v1/jobs/%s/retry", sd.Jobs[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodPost,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Errorf(errs.FailedPrecondition, "job is not dead or cancelled"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
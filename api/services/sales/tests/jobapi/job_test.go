@@ -0,0 +1,33 @@
+package job_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+)
+
+func Test_Job(t *testing.T) {
+	t.Parallel()
+
+	test := apitest.New(t, "Test_Job")
+
+	// -------------------------------------------------------------------------
+
+	sd, err := insertSeedData(test.DB, test.Auth)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	test.Run(t, query200(sd), "query-200")
+	test.Run(t, query400(sd), "query-400")
+	test.Run(t, query401(sd), "query-401")
+	test.Run(t, queryByID200(sd), "querybyid-200")
+	test.Run(t, queryByID404(sd), "querybyid-404")
+
+	test.Run(t, cancel200(sd), "cancel-200")
+	test.Run(t, cancel400(sd), "cancel-400")
+	test.Run(t, retry200(sd), "retry-200")
+	test.Run(t, retry400(sd), "retry-400")
+}
@@ -0,0 +1,62 @@
+package job_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/app/domain/jobapp"
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/google/go-cmp/cmp"
+)
+
+func toAppJob(bus jobbus.Job) jobapp.Job {
+	var lockedUntil string
+	if !bus.LockedUntil.IsZero() {
+		lockedUntil = bus.LockedUntil.Format(time.RFC3339)
+	}
+
+	return jobapp.Job{
+		ID:          bus.ID.String(),
+		Type:        bus.Type,
+		Payload:     bus.Payload,
+		Status:      bus.Status.String(),
+		Attempts:    bus.Attempts,
+		MaxAttempts: bus.MaxAttempts,
+		LastError:   bus.LastError,
+		ScheduledAt: bus.ScheduledAt.Format(time.RFC3339),
+		LockedUntil: lockedUntil,
+		DateCreated: bus.DateCreated.Format(time.RFC3339),
+		DateUpdated: bus.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppJobs(jobs []jobbus.Job) []jobapp.Job {
+	app := make([]jobapp.Job, len(jobs))
+	for i, job := range jobs {
+		app[i] = toAppJob(job)
+	}
+
+	return app
+}
+
+func toAppJobPtr(bus jobbus.Job) *jobapp.Job {
+	app := toAppJob(bus)
+	return &app
+}
+
+// cmpJob ignores the formatting of the payload, which is changed when it is
+// stored, and the times that are set when the job changes.
+func cmpJob(got jobapp.Job, exp jobapp.Job) string {
+	var gotPayload, expPayload bytes.Buffer
+	json.Compact(&gotPayload, got.Payload)
+	json.Compact(&expPayload, exp.Payload)
+
+	got.Payload = gotPayload.Bytes()
+	exp.Payload = expPayload.Bytes()
+
+	exp.ScheduledAt = got.ScheduledAt
+	exp.DateUpdated = got.DateUpdated
+
+	return cmp.Diff(got, exp)
+}
@@ -0,0 +1,145 @@
+package job_test
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/domain/jobapp"
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func query200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        "/v1/jobs?page=1&rows=10&orderBy=scheduled_at,ASC&type=test.job",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &query.Result[jobapp.Job]{},
+			ExpResp: &query.Result[jobapp.Job]{
+				Page:        1,
+				RowsPerPage: 10,
+				Total:       len(sd.Jobs),
+				Items:       toAppJobs(sd.Jobs),
+			},
+			CmpFunc: func(got any, exp any) string {
+				gotResp, exists := got.(*query.Result[jobapp.Job])
+				if !exists {
+					return "error occurred"
+				}
+
+				expResp := exp.(*query.Result[jobapp.Job])
+
+				if len(gotResp.Items) != len(expResp.Items) {
+					return cmp.Diff(gotResp, expResp)
+				}
+
+				for i := range gotResp.Items {
+					if diff := cmpJob(gotResp.Items[i], expResp.Items[i]); diff != "" {
+						return diff
+					}
+				}
+
+				gotResp.Items = nil
+				expResp.Items = nil
+
+				return cmp.Diff(gotResp, expResp)
+			},
+		},
+	}
+
+	return table
+}
+
+func query400(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "bad-query-filter",
+			URL:        "/v1/jobs?page=1&rows=10&status=UNKNOWN",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Errorf(errs.InvalidArgument, "[{\"field\":\"status\",\"error\":\"invalid job status \\\"UNKNOWN\\\"\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+		{
+			Name:       "bad-orderby-value",
+			URL:        "/v1/jobs?page=1&rows=10&orderBy=ob_id,ASC",
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Errorf(errs.InvalidArgument, "[{\"field\":\"order\",\"error\":\"unknown order: ob_id\"}]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func query401(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "not-admin",
+			URL:        "/v1/jobs?page=1&rows=10",
+			Token:      sd.Users[0].Token,
+			StatusCode: http.StatusUnauthorized,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Errorf(errs.Unauthenticated, "authorize: you are not authorized for that action, claims[[USER]] rule[rule_admin_only]"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID200(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "basic",
+			URL:        fmt.Sprintf("/v1/jobs/%s", sd.Jobs[0].ID),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusOK,
+			Method:     http.MethodGet,
+			GotResp:    &jobapp.Job{},
+			ExpResp:    toAppJobPtr(sd.Jobs[0]),
+			CmpFunc: func(got any, exp any) string {
+				return cmpJob(*got.(*jobapp.Job), *exp.(*jobapp.Job))
+			},
+		},
+	}
+
+	return table
+}
+
+func queryByID404(sd apitest.SeedData) []apitest.Table {
+	table := []apitest.Table{
+		{
+			Name:       "not-found",
+			URL:        fmt.Sprintf("/v1/jobs/%s", uuid.New()),
+			Token:      sd.Admins[0].Token,
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			GotResp:    &errs.Error{},
+			ExpResp:    errs.Errorf(errs.NotFound, "job not found"),
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
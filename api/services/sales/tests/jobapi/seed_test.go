@@ -0,0 +1,57 @@
+package job_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/app/sdk/apitest"
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/types/role"
+)
+
+func insertSeedData(db *dbtest.Database, ath *auth.Auth) (apitest.SeedData, error) {
+	ctx := context.Background()
+	busDomain := db.BusDomain
+
+	usrs, err := userbus.TestSeedUsers(ctx, 1, role.User, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu1 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	usrs, err = userbus.TestSeedUsers(ctx, 1, role.Admin, busDomain.User)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding users : %w", err)
+	}
+
+	tu2 := apitest.User{
+		User:  usrs[0],
+		Token: apitest.Token(db.BusDomain.User, ath, usrs[0].Email.Address),
+	}
+
+	// -------------------------------------------------------------------------
+
+	jobs, err := jobbus.TestSeedJobs(ctx, 2, "test.job", busDomain.Job)
+	if err != nil {
+		return apitest.SeedData{}, fmt.Errorf("seeding jobs : %w", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	sd := apitest.SeedData{
+		Users:  []apitest.User{tu1},
+		Admins: []apitest.User{tu2},
+		Jobs:   jobs,
+	}
+
+	return sd, nil
+}
@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ardanlabs/service/foundation/keystore"
+)
+
+// EncryptKey encrypts a private key file with the master key so it can be
+// stored as a .pem.enc file in the keys folder.
+func EncryptKey(masterKey string, fileName string) error {
+	if fileName == "" {
+		fmt.Println("help: encryptkey <private.pem>")
+		return ErrHelp
+	}
+
+	if masterKey == "" {
+		return errors.New("the master key is required")
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		return fmt.Errorf("reading key file: %w", err)
+	}
+
+	sealed, err := keystore.Encrypt([]byte(masterKey), data)
+	if err != nil {
+		return fmt.Errorf("encrypting key: %w", err)
+	}
+
+	out := fileName + ".enc"
+	if err := os.WriteFile(out, sealed, 0600); err != nil {
+		return fmt.Errorf("writing encrypted file: %w", err)
+	}
+
+	fmt.Println("encrypted key file generated:", out)
+	return nil
+}
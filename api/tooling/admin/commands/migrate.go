@@ -4,30 +4,179 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"text/tabwriter"
 	"time"
 
 	"github.com/ardanlabs/service/business/sdk/migrate"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dialect"
+	"github.com/jmoiron/sqlx"
 )
 
 // ErrHelp provides context that help was given.
 var ErrHelp = errors.New("provided help")
 
-// Migrate creates the schema in the database.
-func Migrate(cfg sqldb.Config) error {
-	db, err := sqldb.Open(cfg)
+// Set of dialects the schema can be migrated for.
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// Migrate manages the schema in the database. The action is one of status,
+// up, down, to or dry-run and defaults to up. The dialect is postgres or,
+// when the tool is built with cgo, sqlite where the database name is the
+// path to the SQLite file.
+func Migrate(cfg sqldb.Config, dialectName string, action string, version string) error {
+	db, d, err := openDialect(cfg, dialectName)
 	if err != nil {
 		return fmt.Errorf("connect database: %w", err)
 	}
 	defer db.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := migrate.Migrate(ctx, db); err != nil {
-		return fmt.Errorf("migrate database: %w", err)
+	if err := sqldb.StatusCheck(ctx, db); err != nil {
+		return fmt.Errorf("status check database: %w", err)
+	}
+
+	m, err := migrate.New(db, d)
+	if err != nil {
+		return fmt.Errorf("construct migrator: %w", err)
+	}
+
+	switch action {
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return fmt.Errorf("migration status: %w", err)
+		}
+
+		printStatus(statuses)
+		return nil
+
+	case "", "up":
+		steps, err := m.Up(ctx)
+		if err != nil {
+			return fmt.Errorf("migrate database: %w", err)
+		}
+
+		printSteps(steps, "migrations complete")
+		return nil
+
+	case "down":
+		steps, err := m.Down(ctx)
+		if err != nil {
+			return fmt.Errorf("rollback database: %w", err)
+		}
+
+		printSteps(steps, "rollback complete")
+		return nil
+
+	case "to":
+		target, err := migrate.ParseVersion(version)
+		if err != nil {
+			fmt.Println("help: migrate to <version>, version 0 rolls back every migration")
+			return ErrHelp
+		}
+
+		steps, err := m.To(ctx, target)
+		if err != nil {
+			return fmt.Errorf("migrate database to %s: %w", version, err)
+		}
+
+		printSteps(steps, "migrations complete")
+		return nil
+
+	case "dry-run":
+		steps, err := dryRun(ctx, m, version)
+		if err != nil {
+			return fmt.Errorf("plan migrations: %w", err)
+		}
+
+		for _, step := range steps {
+			fmt.Printf("-- %s %s: %s\n%s\n\n", step.Direction, step.Migration, step.Migration.Description, step.Script())
+		}
+
+		printSteps(steps, "dry run complete, nothing was applied")
+		return nil
+	}
+
+	fmt.Println("migrate status:       show the state of every version")
+	fmt.Println("migrate up:           apply the pending versions")
+	fmt.Println("migrate down:         roll back the latest version")
+	fmt.Println("migrate to <version>: apply or roll back to the version")
+	fmt.Println("migrate dry-run [v]:  show the scripts up or to the version would run")
+	return ErrHelp
+}
+
+// openDialect opens the database for the dialect. SQLite only allows one
+// writer so a single connection is used.
+func openDialect(cfg sqldb.Config, dialectName string) (*sqlx.DB, dialect.Dialect, error) {
+	switch dialectName {
+	case "", DialectPostgres:
+		db, err := sqldb.Open(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return db, dialect.Postgres{}, nil
+
+	case DialectSQLite:
+		db, err := openSQLite(cfg.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		db.SetMaxOpenConns(1)
+
+		return db, dialect.SQLite{}, nil
+	}
+
+	if !sqliteSupported {
+		return nil, nil, fmt.Errorf("unknown dialect %q, expecting %s", dialectName, DialectPostgres)
+	}
+
+	return nil, nil, fmt.Errorf("unknown dialect %q, expecting %s or %s", dialectName, DialectPostgres, DialectSQLite)
+}
+
+func dryRun(ctx context.Context, m *migrate.Migrator, version string) ([]migrate.Step, error) {
+	if version == "" {
+		return m.PlanUp(ctx)
+	}
+
+	target, err := migrate.ParseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.PlanTo(ctx, target)
+}
+
+func printStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintln(w, "VERSION\tSTATE\tAPPLIED\tDOWN\tDESCRIPTION")
+
+	for _, s := range statuses {
+		applied := "-"
+		if !s.AppliedAt.IsZero() {
+			applied = s.AppliedAt.Format(time.RFC3339)
+		}
+
+		fmt.Fprintf(w, "%v\t%s\t%s\t%t\t%s\n", s.Version, s.State, applied, s.HasDown, s.Description)
+	}
+}
+
+func printSteps(steps []migrate.Step, done string) {
+	for _, step := range steps {
+		fmt.Printf("%-5s %s: %s\n", step.Direction, step.Migration, step.Migration.Description)
+	}
+
+	if len(steps) == 0 {
+		fmt.Println("database is at the requested version")
 	}
 
-	fmt.Println("migrations complete")
-	return nil
+	fmt.Println(done)
 }
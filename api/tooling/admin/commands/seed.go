@@ -3,27 +3,113 @@ package commands
 import (
 	"context"
 	"fmt"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
+	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productpg"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/extensions/useraudit"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/sdk/delegate"
 	"github.com/ardanlabs/service/business/sdk/migrate"
+	"github.com/ardanlabs/service/business/sdk/seed"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
 )
 
-// Seed loads test data into the database.
-func Seed(cfg sqldb.Config) error {
+// loadTestSet is the seed set that generates the data through the business
+// layer instead of running a script.
+const loadTestSet = "load-test"
+
+// seedAdmin is the email of the admin in the dev seed set.
+const seedAdmin = "admin@example.com"
+
+// Seed loads a seed set into the database. The set defaults to dev. The
+// load-test set generates the number of users provided, 100 by default,
+// each with a few products and homes. The dev set is loaded first so the
+// users are created by the seeded admin.
+func Seed(log *logger.Logger, cfg sqldb.Config, set string, count string) error {
+	if set == "" {
+		set = "dev"
+	}
+
+	sets := append(migrate.SeedSets(), loadTestSet)
+	if !slices.Contains(sets, set) {
+		fmt.Printf("help: seed [%s] [count]\n", strings.Join(sets, "|"))
+		return ErrHelp
+	}
+
 	db, err := sqldb.Open(cfg)
 	if err != nil {
 		return fmt.Errorf("connect database: %w", err)
 	}
 	defer db.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if set != loadTestSet {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := migrate.Seed(ctx, db, set); err != nil {
+			return fmt.Errorf("seed database: %w", err)
+		}
+
+		fmt.Println("seed data complete:", set)
+		return nil
+	}
+
+	users := 100
+	if count != "" {
+		if users, err = strconv.Atoi(count); err != nil || users <= 0 {
+			return fmt.Errorf("invalid count %q", count)
+		}
+	}
+
+	// Hashing the passwords is the slow part, so the timeout grows with the
+	// number of users.
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second+time.Duration(users)*time.Second)
 	defer cancel()
 
-	if err := migrate.Seed(ctx, db); err != nil {
+	if err := migrate.Seed(ctx, db, "dev"); err != nil {
 		return fmt.Errorf("seed database: %w", err)
 	}
 
-	fmt.Println("seed data complete")
+	delegate := delegate.New(log)
+	auditBus := auditbus.NewBusiness(log, auditdb.NewStore(log, db))
+	userBus := userbus.NewBusiness(log, delegate, userdb.NewStore(log, db), useraudit.NewExtension(auditBus))
+
+	admin, err := userBus.QueryByEmail(ctx, mail.Address{Address: seedAdmin})
+	if err != nil {
+		return fmt.Errorf("query admin: %w", err)
+	}
+
+	buses := seed.Buses{
+		Beginner: sqldb.NewBeginner(db),
+		User:     userBus,
+		Product:  productbus.NewBusiness(log, userBus, delegate, productpg.NewStore(log, db)),
+		Home:     homebus.NewBusiness(log, userBus, delegate, homedb.NewStore(log, db)),
+	}
+
+	seedCfg := seed.Config{
+		Users:           users,
+		ProductsPerUser: 3,
+		HomesPerUser:    1,
+		RandomSeed:      1,
+		ActorID:         admin.ID,
+	}
+
+	result, err := seed.Generate(ctx, buses, seedCfg)
+	if err != nil {
+		return fmt.Errorf("generate data: %w", err)
+	}
+
+	fmt.Printf("seed data complete: %s: users[%d] products[%d] homes[%d] skipped[%d]\n", set, result.Users, result.Products, result.Homes, result.Skipped)
 	return nil
 }
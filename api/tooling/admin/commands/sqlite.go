@@ -0,0 +1,17 @@
+//go:build cgo
+
+package commands
+
+import (
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSupported reports the sqlite dialect can be used. The sqlite3
+// driver is written in C so it's only linked when the tool is built with cgo.
+const sqliteSupported = true
+
+// openSQLite opens the SQLite file with foreign keys enforced.
+func openSQLite(name string) (*sqlx.DB, error) {
+	return sqlx.Open("sqlite3", "file:"+name+"?_foreign_keys=on")
+}
@@ -0,0 +1,19 @@
+//go:build !cgo
+
+package commands
+
+import (
+	"errors"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// sqliteSupported reports the sqlite dialect can be used. The sqlite3
+// driver is written in C so it's only linked when the tool is built with cgo.
+const sqliteSupported = false
+
+// openSQLite fails since the tool was built without cgo, as it is in the
+// service images.
+func openSQLite(name string) (*sqlx.DB, error) {
+	return nil, errors.New("the sqlite dialect needs the admin tool built with CGO_ENABLED=1")
+}
@@ -28,10 +28,12 @@ type config struct {
 		MaxIdleConns int    `conf:"default:0"`
 		MaxOpenConns int    `conf:"default:0"`
 		DisableTLS   bool   `conf:"default:true"`
+		Dialect      string `conf:"default:postgres,help:postgres or sqlite where the name is the sqlite file and the tool is built with cgo"`
 	}
 	Auth struct {
 		KeysFolder string `conf:"default:zarf/keys/"`
 		DefaultKID string `conf:"default:54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"`
+		MasterKey  string `conf:"mask"`
 	}
 }
 
@@ -89,20 +91,26 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 
 	switch args.Num(0) {
 	case "migrate":
-		if err := commands.Migrate(dbConfig); err != nil {
+		if err := commands.Migrate(dbConfig, cfg.DB.Dialect, args.Num(1), args.Num(2)); err != nil {
 			return fmt.Errorf("migrating database: %w", err)
 		}
 
 	case "seed":
-		if err := commands.Seed(dbConfig); err != nil {
+		if cfg.DB.Dialect == commands.DialectSQLite {
+			return errors.New("seeding database: only postgres can be seeded")
+		}
+		if err := commands.Seed(log, dbConfig, args.Num(1), args.Num(2)); err != nil {
 			return fmt.Errorf("seeding database: %w", err)
 		}
 
 	case "migrate-seed":
-		if err := commands.Migrate(dbConfig); err != nil {
+		if cfg.DB.Dialect == commands.DialectSQLite {
+			return errors.New("seeding database: only postgres can be seeded")
+		}
+		if err := commands.Migrate(dbConfig, cfg.DB.Dialect, "up", ""); err != nil {
 			return fmt.Errorf("migrating database: %w", err)
 		}
-		if err := commands.Seed(dbConfig); err != nil {
+		if err := commands.Seed(log, dbConfig, args.Num(1), args.Num(2)); err != nil {
 			return fmt.Errorf("seeding database: %w", err)
 		}
 
@@ -126,6 +134,11 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 			return fmt.Errorf("key generation: %w", err)
 		}
 
+	case "encryptkey":
+		if err := commands.EncryptKey(cfg.Auth.MasterKey, args.Num(1)); err != nil {
+			return fmt.Errorf("encrypting key: %w", err)
+		}
+
 	case "gentoken":
 		userID, err := uuid.Parse(args.Num(1))
 		if err != nil {
@@ -140,11 +153,12 @@ func processCommands(args conf.Args, log *logger.Logger, cfg config) error {
 		}
 
 	default:
-		fmt.Println("migrate:    create the schema in the database")
-		fmt.Println("seed:       add data to the database")
+		fmt.Println("migrate:    manage the schema in the database: status, up, down, to, dry-run")
+		fmt.Println("seed:       add a seed set to the database: dev, demo or load-test [count]")
 		fmt.Println("useradd:    add a new user to the database")
 		fmt.Println("users:      get a list of users from the database")
 		fmt.Println("genkey:     generate a set of private/public key files")
+		fmt.Println("encryptkey: encrypt a private key file with the master key")
 		fmt.Println("gentoken:   generate a JWT for a user with claims")
 		fmt.Println("provide a command to get more help.")
 		return commands.ErrHelp
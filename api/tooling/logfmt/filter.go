@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// zeroTraceID is written by the services when there is no trace.
+const zeroTraceID = "00000000-0000-0000-0000-000000000000"
+
+// record represents a single structured log line.
+type record map[string]any
+
+func parseRecord(line string) (record, bool) {
+	var rec record
+	if err := json.Unmarshal([]byte(line), &rec); err != nil || rec == nil {
+		return nil, false
+	}
+
+	return rec, true
+}
+
+// str returns the value for the key as a string. Values that aren't strings
+// are formatted so records from other sources don't cause a failure.
+func (rec record) str(key string) string {
+	v, exists := rec[key]
+	if !exists || v == nil {
+		return ""
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v
+
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+
+	return fmt.Sprint(v)
+}
+
+func (rec record) traceID() string {
+	id := rec.str("trace_id")
+	if id == zeroTraceID {
+		return ""
+	}
+
+	return id
+}
+
+func (rec record) time() (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339Nano, rec.str("time"))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
+
+// =============================================================================
+
+// filter decides which records are written.
+type filter struct {
+	service string
+	level   int
+	traceID string
+	since   time.Time
+	until   time.Time
+	where   expressions
+}
+
+// empty reports whether no filters are set, in which case lines that aren't
+// structured records are written as they are.
+func (f filter) empty() bool {
+	return f.service == "" && f.level == 0 && f.traceID == "" && f.since.IsZero() && f.until.IsZero() && len(f.where) == 0
+}
+
+func (f filter) match(rec record) bool {
+	if f.service != "" && strings.ToLower(rec.str("service")) != f.service {
+		return false
+	}
+
+	if f.level != 0 {
+		lvl, ok := levelRank(rec.str("level"))
+		if !ok || lvl < f.level {
+			return false
+		}
+	}
+
+	if f.traceID != "" && rec.str("trace_id") != f.traceID {
+		return false
+	}
+
+	if !f.since.IsZero() || !f.until.IsZero() {
+		t, ok := rec.time()
+		if !ok {
+			return false
+		}
+
+		if !f.since.IsZero() && t.Before(f.since) {
+			return false
+		}
+
+		if !f.until.IsZero() && t.After(f.until) {
+			return false
+		}
+	}
+
+	for _, expr := range f.where {
+		if !expr.match(rec) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Set of ranks for the levels.
+const (
+	rankDebug = iota + 1
+	rankInfo
+	rankWarn
+	rankError
+)
+
+// levelRank orders the levels so they can be compared. Levels written with
+// an offset, such as INFO+2, rank with their base level.
+func levelRank(level string) (int, bool) {
+	level = strings.ToUpper(level)
+	if i := strings.IndexAny(level, "+-"); i != -1 {
+		level = level[:i]
+	}
+
+	switch level {
+	case "DEBUG":
+		return rankDebug, true
+	case "INFO":
+		return rankInfo, true
+	case "WARN", "WARNING":
+		return rankWarn, true
+	case "ERROR":
+		return rankError, true
+	}
+
+	return 0, false
+}
+
+// parseTime parses a time in RFC3339 or a duration before now.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// =============================================================================
+
+// Set of operators supported by an expression.
+const (
+	opEqual    = "="
+	opNotEqual = "!="
+	opContains = "~"
+)
+
+// expression compares the value of a key in a record.
+type expression struct {
+	key   string
+	op    string
+	value string
+}
+
+func parseExpression(s string) (expression, error) {
+	for i := 0; i < len(s); i++ {
+		var op string
+
+		switch {
+		case strings.HasPrefix(s[i:], opNotEqual):
+			op = opNotEqual
+		case s[i] == '~':
+			op = opContains
+		case s[i] == '=':
+			op = opEqual
+		default:
+			continue
+		}
+
+		if i == 0 {
+			return expression{}, fmt.Errorf("expression %q: missing key", s)
+		}
+
+		return expression{key: s[:i], op: op, value: s[i+len(op):]}, nil
+	}
+
+	return expression{}, fmt.Errorf("expression %q: expecting key=value, key!=value or key~substring", s)
+}
+
+func (expr expression) match(rec record) bool {
+	v := rec.str(expr.key)
+
+	switch expr.op {
+	case opNotEqual:
+		return v != expr.value
+
+	case opContains:
+		return strings.Contains(v, expr.value)
+	}
+
+	return v == expr.value
+}
+
+// expressions implements flag.Value so the where flag can be repeated.
+type expressions []expression
+
+func (exprs *expressions) String() string {
+	var s []string
+	for _, expr := range *exprs {
+		s = append(s, expr.key+expr.op+expr.value)
+	}
+
+	return strings.Join(s, ",")
+}
+
+func (exprs *expressions) Set(value string) error {
+	if value == "" {
+		return errors.New("empty expression")
+	}
+
+	expr, err := parseExpression(value)
+	if err != nil {
+		return err
+	}
+
+	*exprs = append(*exprs, expr)
+
+	return nil
+}
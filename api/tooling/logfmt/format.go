@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Set of output formats.
+const (
+	outputPretty = "pretty"
+	outputLogfmt = "logfmt"
+	outputJSON   = "json"
+)
+
+// Set of colors for the levels in the pretty output.
+const (
+	colorReset  = "\033[0m"
+	colorGray   = "\033[90m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+// knownKeys are written first, in this order, by the pretty and logfmt
+// formats.
+var knownKeys = []string{"service", "time", "file", "level", "trace_id", "msg"}
+
+// format writes the record in the configured format. The json format passes
+// the original line through.
+func format(rec record, line string, cfg config) string {
+	switch cfg.output {
+	case outputJSON:
+		return line
+
+	case outputLogfmt:
+		return formatLogfmt(rec)
+	}
+
+	return formatPretty(rec, cfg.color)
+}
+
+// formatPretty builds out the known portions of the log in a fixed order
+// followed by the rest of the keys sorted.
+func formatPretty(rec record, color bool) string {
+	var b strings.Builder
+
+	// I like always having a traceid present in the logs.
+	traceID := rec.str("trace_id")
+	if traceID == "" {
+		traceID = zeroTraceID
+	}
+
+	level := rec.str("level")
+	if color {
+		level = levelColor(level) + level + colorReset
+	}
+
+	fmt.Fprintf(&b, "%s: %s: %s: %s: %s: %s",
+		rec.str("service"),
+		rec.str("time"),
+		rec.str("file"),
+		level,
+		traceID,
+		rec.str("msg"),
+	)
+
+	// It's nice to see the key[value] in this format.
+	for _, k := range extraKeys(rec) {
+		fmt.Fprintf(&b, ": %s[%s]", k, rec.str(k))
+	}
+
+	return b.String()
+}
+
+// formatLogfmt writes the record as key=value pairs.
+func formatLogfmt(rec record) string {
+	var b strings.Builder
+
+	write := func(k string) {
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(rec.str(k)))
+	}
+
+	for _, k := range knownKeys {
+		if _, exists := rec[k]; exists {
+			write(k)
+		}
+	}
+
+	for _, k := range extraKeys(rec) {
+		write(k)
+	}
+
+	return b.String()
+}
+
+func extraKeys(rec record) []string {
+	keys := slices.Sorted(maps.Keys(rec))
+
+	return slices.DeleteFunc(keys, func(k string) bool {
+		return slices.Contains(knownKeys, k)
+	})
+}
+
+func logfmtValue(v string) string {
+	if v == "" || strings.ContainsAny(v, " =\"\t\n") {
+		return strconv.Quote(v)
+	}
+
+	return v
+}
+
+func levelColor(level string) string {
+	lvl, _ := levelRank(level)
+
+	switch lvl {
+	case rankDebug:
+		return colorGray
+	case rankWarn:
+		return colorYellow
+	case rankError:
+		return colorRed
+	}
+
+	return colorGreen
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// traceGroups holds the formatted records by trace id in the order the
+// traces were first seen.
+type traceGroups struct {
+	order    []string
+	lines    map[string][]string
+	services map[string][]string
+}
+
+func newTraceGroups() *traceGroups {
+	return &traceGroups{
+		lines:    make(map[string][]string),
+		services: make(map[string][]string),
+	}
+}
+
+func (tg *traceGroups) add(traceID string, line string, service string) {
+	if _, exists := tg.lines[traceID]; !exists {
+		tg.order = append(tg.order, traceID)
+	}
+
+	tg.lines[traceID] = append(tg.lines[traceID], line)
+
+	if service != "" && !slices.Contains(tg.services[traceID], service) {
+		tg.services[traceID] = append(tg.services[traceID], service)
+	}
+}
+
+func (tg *traceGroups) write(w io.Writer) {
+	for _, traceID := range tg.order {
+		lines := tg.lines[traceID]
+
+		fmt.Fprintf(w, "=== trace %s: %d records: %s\n", traceID, len(lines), strings.Join(tg.services[traceID], ", "))
+		for _, line := range lines {
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w)
+	}
+}
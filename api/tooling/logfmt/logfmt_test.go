@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+const logs = `not json
+{"time":"2026-10-18T10:00:00Z","level":"INFO","msg":"startup","service":"SALES","trace_id":"00000000-0000-0000-0000-000000000000"}
+{"time":"2026-10-18T10:00:01Z","level":"ERROR","msg":"handled error during request","service":"SALES","trace_id":"t1","err":"boom"}
+{"time":"2026-10-18T10:00:01Z","level":"INFO","msg":"request completed","service":"SALES","trace_id":"t1","method":"GET","path":"/v1/users/1","route":"/v1/users/{user_id}"}
+{"time":"2026-10-18T10:00:02Z","level":"INFO","msg":"request completed","service":"AUTH","trace_id":"t1","method":"GET","path":"/v1/auth/authenticate?x=1"}
+{"time":"2026-10-18T10:00:03Z","level":"WARN","msg":"odd","service":42,"trace_id":"t2"}
+`
+
+func Test_Run(t *testing.T) {
+	now := time.Date(2026, 10, 18, 10, 0, 5, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		cfg   func(*config)
+		exp   []string
+		notEx []string
+	}{
+		{
+			name:  "service not a string",
+			cfg:   func(cfg *config) { cfg.filter.service = "42" },
+			exp:   []string{"42: 2026-10-18T10:00:03Z: : WARN: t2: odd"},
+			notEx: []string{"not json", "SALES"},
+		},
+		{
+			name:  "level",
+			cfg:   func(cfg *config) { cfg.filter.level = rankWarn },
+			exp:   []string{"ERROR: t1", "WARN: t2"},
+			notEx: []string{"INFO"},
+		},
+		{
+			name: "since and where",
+			cfg: func(cfg *config) {
+				cfg.filter.since, _ = parseTime("4s", now)
+				expr, _ := parseExpression("path~auth")
+				cfg.filter.where = expressions{expr}
+				cfg.output = outputLogfmt
+			},
+			exp:   []string{`service=AUTH time=2026-10-18T10:00:02Z level=INFO trace_id=t1 msg="request completed" method=GET path="/v1/auth/authenticate?x=1"`},
+			notEx: []string{"SALES"},
+		},
+		{
+			name:  "group",
+			cfg:   func(cfg *config) { cfg.group = true; cfg.filter.traceID = "t1"; cfg.output = outputJSON },
+			exp:   []string{"=== trace t1: 3 records: SALES, AUTH\n{\"time\":\"2026-10-18T10:00:01Z\",\"level\":\"ERROR\""},
+			notEx: []string{"t2"},
+		},
+		{
+			name: "summary",
+			cfg:  func(cfg *config) { cfg.summary = true },
+			exp:  []string{"other lines  1", "GET /v1/users/{user_id}    1         1", "GET /v1/auth/authenticate  1         0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config{output: outputPretty}
+			tt.cfg(&cfg)
+
+			var buf bytes.Buffer
+			if err := run(strings.NewReader(logs), &buf, cfg); err != nil {
+				t.Fatalf("Should be able to run: %s", err)
+			}
+
+			for _, exp := range tt.exp {
+				if !strings.Contains(buf.String(), exp) {
+					t.Errorf("Should write %q:\n%s", exp, buf.String())
+				}
+			}
+
+			for _, exp := range tt.notEx {
+				if strings.Contains(buf.String(), exp) {
+					t.Errorf("Should not write %q:\n%s", exp, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func Test_ParseExpression(t *testing.T) {
+	tests := []struct {
+		value string
+		exp   expression
+	}{
+		{value: "route=/v1/users", exp: expression{key: "route", op: opEqual, value: "/v1/users"}},
+		{value: "level!=INFO", exp: expression{key: "level", op: opNotEqual, value: "INFO"}},
+		{value: "err~a=b", exp: expression{key: "err", op: opContains, value: "a=b"}},
+		{value: "path=a~b", exp: expression{key: "path", op: opEqual, value: "a~b"}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseExpression(tt.value)
+		if err != nil || got != tt.exp {
+			t.Errorf("%s: got %+v %v, exp %+v", tt.value, got, err, tt.exp)
+		}
+	}
+
+	for _, value := range []string{"route", "=value"} {
+		if _, err := parseExpression(value); err == nil {
+			t.Errorf("%s: Should not be able to parse", value)
+		}
+	}
+}
@@ -1,89 +1,195 @@
-// This program takes the structured log output and makes it readable.
+// This program takes the structured log output and makes it readable. It can
+// filter the log records, write them in different formats, group the records
+// of a trace across services and summarize a log file.
+//
+//	$ kubectl logs ... | go run ./api/tooling/logfmt -service=sales -level=warn
+//	$ go run ./api/tooling/logfmt -trace=<trace_id> -group < sales.log
+//	$ go run ./api/tooling/logfmt -summary < sales.log
 package main
 
 import (
 	"bufio"
-	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
 )
 
-var service string
+var (
+	service string
+	level   string
+	traceID string
+	since   string
+	until   string
+	where   expressions
+	output  string
+	color   string
+	group   bool
+	summary bool
+)
 
 func init() {
 	flag.StringVar(&service, "service", "", "filter which service to see")
+	flag.StringVar(&level, "level", "", "filter the records below the level: debug, info, warn, error")
+	flag.StringVar(&traceID, "trace", "", "filter the records for the trace id")
+	flag.StringVar(&since, "since", "", "filter the records before the time, RFC3339 or a duration ago such as 15m")
+	flag.StringVar(&until, "until", "", "filter the records after the time, RFC3339 or a duration ago such as 5m")
+	flag.Var(&where, "where", "filter by key=value, key!=value or key~substring, can be repeated")
+	flag.StringVar(&output, "output", outputPretty, "output format: pretty, logfmt or json")
+	flag.StringVar(&color, "color", "auto", "color the pretty output: auto, always or never")
+	flag.BoolVar(&group, "group", false, "group the records by trace id, written when the input ends")
+	flag.BoolVar(&summary, "summary", false, "write summary stats instead of the records when the input ends")
 
 	signal.Ignore(syscall.SIGINT)
 }
 
 func main() {
 	flag.Parse()
-	var b strings.Builder
 
-	service := strings.ToLower(service)
+	cfg, err := newConfig(time.Now())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(os.Stdin, os.Stdout, cfg); err != nil {
+		log.Println(err)
+	}
+}
+
+// config represents the settings provided on the command line.
+type config struct {
+	filter  filter
+	output  string
+	color   bool
+	group   bool
+	summary bool
+}
+
+func newConfig(now time.Time) (config, error) {
+	f := filter{
+		service: strings.ToLower(service),
+		traceID: traceID,
+		where:   where,
+	}
+
+	if level != "" {
+		lvl, ok := levelRank(level)
+		if !ok {
+			return config{}, fmt.Errorf("unknown level %q", level)
+		}
+		f.level = lvl
+	}
+
+	var err error
+	if f.since, err = parseTime(since, now); err != nil {
+		return config{}, fmt.Errorf("parsing since: %w", err)
+	}
+
+	if f.until, err = parseTime(until, now); err != nil {
+		return config{}, fmt.Errorf("parsing until: %w", err)
+	}
+
+	switch output {
+	case outputPretty, outputLogfmt, outputJSON:
+	default:
+		return config{}, fmt.Errorf("unknown output %q", output)
+	}
+
+	cfg := config{
+		filter:  f,
+		output:  output,
+		group:   group,
+		summary: summary,
+	}
+
+	switch color {
+	case "always":
+		cfg.color = true
+
+	case "never":
+
+	case "auto":
+		if fi, err := os.Stdout.Stat(); err == nil {
+			cfg.color = fi.Mode()&os.ModeCharDevice != 0
+		}
+
+	default:
+		return config{}, fmt.Errorf("unknown color %q", color)
+	}
+
+	return cfg, nil
+}
+
+// run reads the log records from r and writes the ones that pass the filter
+// to w in the configured format.
+func run(r io.Reader, w io.Writer, cfg config) error {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+
+	var groups *traceGroups
+	if cfg.group {
+		groups = newTraceGroups()
+	}
+
+	var stats *summaryStats
+	if cfg.summary {
+		stats = newSummaryStats()
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
 
-	scanner := bufio.NewScanner(os.Stdin)
 	for scanner.Scan() {
-		s := scanner.Text()
+		line := scanner.Text()
 
-		m := make(map[string]any)
-		err := json.Unmarshal([]byte(s), &m)
-		if err != nil {
-			if service == "" {
-				fmt.Println(s)
+		rec, ok := parseRecord(line)
+		if !ok {
+			if stats != nil {
+				stats.other++
+				continue
+			}
+
+			if cfg.filter.empty() {
+				fmt.Fprintln(bw, line)
 			}
 			continue
 		}
 
-		// If a service filter was provided, check.
-		if service != "" && strings.ToLower(m["service"].(string)) != service {
+		if !cfg.filter.match(rec) {
 			continue
 		}
 
-		// I like always having a traceid present in the logs.
-		traceID := "00000000-0000-0000-0000-000000000000"
-		if v, ok := m["trace_id"]; ok {
-			traceID = fmt.Sprintf("%v", v)
-		}
+		switch {
+		case stats != nil:
+			stats.add(rec)
 
-		// {"time":"2023-06-01T17:21:11.13704718Z","level":"INFO","msg":"startup","service":"SALES-API","GOMAXPROCS":1}
-
-		// Build out the know portions of the log in the order
-		// I want them in.
-		b.Reset()
-		b.WriteString(fmt.Sprintf("%s: %s: %s: %s: %s: %s: ",
-			m["service"],
-			m["time"],
-			m["file"],
-			m["level"],
-			traceID,
-			m["msg"],
-		))
-
-		// Add the rest of the keys ignoring the ones we already
-		// added for the log.
-		for k, v := range m {
-			switch k {
-			case "service", "time", "file", "level", "trace_id", "msg":
-				continue
-			}
+		case groups != nil && rec.traceID() != "":
+			groups.add(rec.traceID(), format(rec, line, cfg), rec.str("service"))
 
-			// It's nice to see the key[value] in this format
-			// especially since map ordering is random.
-			b.WriteString(fmt.Sprintf("%s[%v]: ", k, v))
+		default:
+			fmt.Fprintln(bw, format(rec, line, cfg))
 		}
 
-		// Write the new log format, removing the last :
-		out := b.String()
-		fmt.Println(out[:len(out)-2])
+		// Flush each line so the output keeps up when following logs.
+		if groups == nil && stats == nil {
+			bw.Flush()
+		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		log.Println(err)
+	if groups != nil {
+		groups.write(bw)
 	}
+
+	if stats != nil {
+		stats.write(bw)
+	}
+
+	return scanner.Err()
 }
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// routeStats counts the requests and errors for a route.
+type routeStats struct {
+	requests int
+	errors   int
+}
+
+// summaryStats accumulates the stats written by the summary.
+type summaryStats struct {
+	records  int
+	other    int
+	first    time.Time
+	last     time.Time
+	levels   map[string]int
+	services map[string]int
+	routes   map[string]*routeStats
+
+	// pending holds the errors by service and trace until the request they
+	// belong to completes.
+	pending map[string]int
+}
+
+func newSummaryStats() *summaryStats {
+	return &summaryStats{
+		levels:   make(map[string]int),
+		services: make(map[string]int),
+		routes:   make(map[string]*routeStats),
+		pending:  make(map[string]int),
+	}
+}
+
+func (ss *summaryStats) add(rec record) {
+	ss.records++
+	ss.levels[strings.ToUpper(rec.str("level"))]++
+	ss.services[rec.str("service")]++
+
+	if t, ok := rec.time(); ok {
+		if ss.first.IsZero() || t.Before(ss.first) {
+			ss.first = t
+		}
+		if t.After(ss.last) {
+			ss.last = t
+		}
+	}
+
+	key := rec.str("service") + "/" + rec.traceID()
+
+	if lvl, _ := levelRank(rec.str("level")); lvl == rankError {
+		ss.pending[key]++
+	}
+
+	if rec.str("msg") != "request completed" {
+		return
+	}
+
+	route := ss.route(rec)
+	route.requests++
+
+	if rec.traceID() != "" {
+		route.errors += ss.pending[key]
+		delete(ss.pending, key)
+	}
+}
+
+// route returns the stats for the route of a completed request. The path is
+// used for the logs written before the route was recorded.
+func (ss *summaryStats) route(rec record) *routeStats {
+	route := rec.str("route")
+	if route == "" {
+		route, _, _ = strings.Cut(rec.str("path"), "?")
+	}
+
+	name := strings.TrimSpace(rec.str("method") + " " + route)
+
+	rs, exists := ss.routes[name]
+	if !exists {
+		rs = &routeStats{}
+		ss.routes[name] = rs
+	}
+
+	return rs
+}
+
+func (ss *summaryStats) write(w io.Writer) {
+	var unattributed int
+	for _, n := range ss.pending {
+		unattributed += n
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "records\t%d\n", ss.records)
+	fmt.Fprintf(tw, "other lines\t%d\n", ss.other)
+	if !ss.first.IsZero() {
+		fmt.Fprintf(tw, "from\t%s\n", ss.first.Format(time.RFC3339))
+		fmt.Fprintf(tw, "to\t%s\n", ss.last.Format(time.RFC3339))
+	}
+
+	fmt.Fprintln(tw, "\nLEVEL\tRECORDS")
+	for _, k := range slices.SortedFunc(maps.Keys(ss.levels), byRank) {
+		fmt.Fprintf(tw, "%s\t%d\n", k, ss.levels[k])
+	}
+
+	fmt.Fprintln(tw, "\nSERVICE\tRECORDS")
+	for _, k := range slices.Sorted(maps.Keys(ss.services)) {
+		fmt.Fprintf(tw, "%s\t%d\n", k, ss.services[k])
+	}
+
+	fmt.Fprintln(tw, "\nROUTE\tREQUESTS\tERRORS")
+	for _, k := range slices.SortedFunc(maps.Keys(ss.routes), ss.byErrors) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\n", k, ss.routes[k].requests, ss.routes[k].errors)
+	}
+	if unattributed > 0 {
+		fmt.Fprintf(tw, "(no request)\t-\t%d\n", unattributed)
+	}
+
+	tw.Flush()
+}
+
+func byRank(a, b string) int {
+	ra, _ := levelRank(a)
+	rb, _ := levelRank(b)
+
+	if ra != rb {
+		return ra - rb
+	}
+
+	return strings.Compare(a, b)
+}
+
+// byErrors orders the routes with the most errors first.
+func (ss *summaryStats) byErrors(a, b string) int {
+	if ea, eb := ss.routes[a].errors, ss.routes[b].errors; ea != eb {
+		return eb - ea
+	}
+
+	return strings.Compare(a, b)
+}
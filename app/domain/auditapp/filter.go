@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/openapi"
 	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/types/domain"
 	"github.com/ardanlabs/service/business/types/name"
@@ -43,6 +44,19 @@ func parseQueryParams(r *http.Request) (queryParams, error) {
 	return filter, nil
 }
 
+// queryParamDocs documents the query parameters read by parseQueryParams.
+var queryParamDocs = []openapi.Param{
+	{Name: "page", Type: "integer"},
+	{Name: "rows", Type: "integer"},
+	{Name: "obj_id", Format: "uuid"},
+	{Name: "obj_domain"},
+	{Name: "obj_name"},
+	{Name: "actor_id", Format: "uuid"},
+	{Name: "action"},
+	{Name: "since", Format: "date-time"},
+	{Name: "until", Format: "date-time"},
+}
+
 func parseFilter(qp queryParams) (auditbus.QueryFilter, error) {
 	var fieldErrors errs.FieldErrors
 	var filter auditbus.QueryFilter
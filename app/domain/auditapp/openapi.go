@@ -0,0 +1,26 @@
+package auditapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/app/sdk/query"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	ops := []openapi.Operation{
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/audits",
+			Tag:         "audits",
+			Summary:     "Query audit records",
+			Security:    openapi.SecurityBearer,
+			QueryParams: queryParamDocs,
+			OrderBy:     orderByFields,
+			Response:    query.Result[Audit]{},
+		},
+	}
+
+	return ops
+}
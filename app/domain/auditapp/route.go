@@ -6,6 +6,7 @@ import (
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
@@ -16,6 +17,7 @@ type Config struct {
 	Log        *logger.Logger
 	AuditBus   auditbus.ExtBusiness
 	AuthClient authclient.Authenticator
+	RateLimit  ratelimit.Config
 }
 
 // Routes adds specific routes for this group.
@@ -23,9 +25,10 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	limit := mid.RateLimit(cfg.RateLimit.Store, cfg.RateLimit.Limit)
 	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
 
 	api := newApp(cfg.AuditBus)
 
-	app.HandlerFunc(http.MethodGet, version, "/audits", api.query, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/audits", api.query, authen, limit, ruleAdmin)
 }
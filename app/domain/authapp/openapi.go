@@ -0,0 +1,42 @@
+package authapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/openapi"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	const tag = "auth"
+
+	ops := []openapi.Operation{
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/auth/token/{kid}",
+			Tag:      tag,
+			Summary:  "Generate a token for the user using the specified key",
+			Security: openapi.SecurityBasic,
+			Response: token{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/auth/authenticate",
+			Tag:      tag,
+			Summary:  "Authenticate a token",
+			Security: openapi.SecurityBearer,
+			Response: authclient.AuthenticateResp{},
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/v1/auth/authorize",
+			Tag:     tag,
+			Summary: "Authorize the claims against a rule",
+			Request: authclient.Authorize{},
+			Status:  http.StatusNoContent,
+		},
+	}
+
+	return ops
+}
@@ -5,14 +5,18 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	UserBus userbus.ExtBusiness
-	Auth    *auth.Auth
+	UserBus     userbus.ExtBusiness
+	Auth        *auth.Auth
+	RateLimiter ratelimit.Storer
+	TokenLimit  ratelimit.Limit
+	ClientIP    ratelimit.ClientIP
 }
 
 // Routes adds specific routes for this group.
@@ -22,9 +26,13 @@ func Routes(app *web.App, cfg Config) {
 	bearer := mid.Bearer(cfg.Auth)
 	basic := mid.Basic(cfg.Auth, cfg.UserBus)
 
+	// The token route accepts a user's password so the limit is applied
+	// per client IP before the credentials are checked to slow down guessing.
+	tokenLimit := mid.RateLimitIP(cfg.RateLimiter, cfg.TokenLimit, cfg.ClientIP)
+
 	api := newApp(cfg.Auth)
 
-	app.HandlerFunc(http.MethodGet, version, "/auth/token/{kid}", api.token, basic)
+	app.HandlerFunc(http.MethodGet, version, "/auth/token/{kid}", api.token, tokenLimit, basic)
 	app.HandlerFunc(http.MethodGet, version, "/auth/authenticate", api.authenticate, bearer)
 	app.HandlerFunc(http.MethodPost, version, "/auth/authorize", api.authorize)
 }
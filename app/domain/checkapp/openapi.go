@@ -0,0 +1,31 @@
+package checkapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	const tag = "checks"
+
+	ops := []openapi.Operation{
+		{
+			Method:  http.MethodGet,
+			Path:    "/v1/readiness",
+			Tag:     tag,
+			Summary: "Check the service is ready to receive traffic",
+			Status:  http.StatusNoContent,
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/liveness",
+			Tag:      tag,
+			Summary:  "Check the service is alive",
+			Response: Info{},
+		},
+	}
+
+	return ops
+}
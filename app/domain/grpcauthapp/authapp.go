@@ -13,6 +13,7 @@ import (
 	"github.com/ardanlabs/service/app/sdk/mid"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
 	"google.golang.org/grpc"
@@ -146,6 +147,8 @@ func (a *App) Authorize(ctx context.Context, req *AuthorizeRequest) (*AuthorizeR
 // =============================================================================
 
 func (a *App) authInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = withRequestID(ctx)
+
 	switch info.FullMethod {
 	case "/auth.Auth/Token":
 		return a.authorize(ctx, req, info, handler)
@@ -196,6 +199,18 @@ func (a *App) authenticate(ctx context.Context, req any, _ *grpc.UnaryServerInfo
 	return handler(ctx, req)
 }
 
+// withRequestID stores the request id provided by the caller in the context
+// or generates a new one so the logs can be correlated across services.
+func withRequestID(ctx context.Context) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(web.RequestIDHeader); len(ids) > 0 && web.ValidRequestID(ids[0]) {
+			return web.SetRequestID(ctx, ids[0])
+		}
+	}
+
+	return web.SetRequestID(ctx, uuid.NewString())
+}
+
 func int64ToND(in int64) *jwt.NumericDate {
 	round, frac := math.Modf(float64(in))
 	return jwt.NewNumericDate(time.Unix(int64(round), int64(frac*1e9)))
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/openapi"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/types/home"
 	"github.com/google/uuid"
@@ -38,6 +39,17 @@ func parseQueryParams(r *http.Request) queryParams {
 	return filter
 }
 
+// queryParamDocs documents the query parameters read by parseQueryParams.
+var queryParamDocs = []openapi.Param{
+	{Name: "page", Type: "integer"},
+	{Name: "rows", Type: "integer"},
+	{Name: "home_id", Format: "uuid"},
+	{Name: "user_id", Format: "uuid"},
+	{Name: "type"},
+	{Name: "start_created_date", Format: "date-time"},
+	{Name: "end_created_date", Format: "date-time"},
+}
+
 func parseFilter(qp queryParams) (homebus.QueryFilter, error) {
 	var fieldErrors errs.FieldErrors
 	var filter homebus.QueryFilter
@@ -0,0 +1,62 @@
+package homeapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/app/sdk/query"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	const tag = "homes"
+
+	ops := []openapi.Operation{
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/homes",
+			Tag:         tag,
+			Summary:     "Query homes",
+			Security:    openapi.SecurityBearer,
+			QueryParams: queryParamDocs,
+			OrderBy:     orderByFields,
+			Response:    query.Result[Home]{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/homes/{home_id}",
+			Tag:      tag,
+			Summary:  "Query a home by id",
+			Security: openapi.SecurityBearer,
+			Response: Home{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/homes",
+			Tag:      tag,
+			Summary:  "Create a home",
+			Security: openapi.SecurityBearer,
+			Request:  NewHome{},
+			Response: Home{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/v1/homes/{home_id}",
+			Tag:      tag,
+			Summary:  "Update a home",
+			Security: openapi.SecurityBearer,
+			Request:  UpdateHome{},
+			Response: Home{},
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/v1/homes/{home_id}",
+			Tag:      tag,
+			Summary:  "Delete a home",
+			Security: openapi.SecurityBearer,
+			Status:   http.StatusNoContent,
+		},
+	}
+
+	return ops
+}
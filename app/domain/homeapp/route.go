@@ -6,6 +6,7 @@ import (
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
@@ -16,6 +17,7 @@ type Config struct {
 	Log        *logger.Logger
 	HomeBus    homebus.ExtBusiness
 	AuthClient authclient.Authenticator
+	RateLimit  ratelimit.Config
 }
 
 // Routes adds specific routes for this group.
@@ -23,15 +25,16 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	limit := mid.RateLimit(cfg.RateLimit.Store, cfg.RateLimit.Limit)
 	ruleAny := mid.Authorize(cfg.AuthClient, auth.RuleAny)
 	ruleUserOnly := mid.Authorize(cfg.AuthClient, auth.RuleUserOnly)
 	ruleAuthorizeHome := mid.AuthorizeHome(cfg.AuthClient, cfg.HomeBus)
 
 	api := newApp(cfg.HomeBus)
 
-	app.HandlerFunc(http.MethodGet, version, "/homes", api.query, authen, ruleAny)
-	app.HandlerFunc(http.MethodGet, version, "/homes/{home_id}", api.queryByID, authen, ruleAuthorizeHome)
-	app.HandlerFunc(http.MethodPost, version, "/homes", api.create, authen, ruleUserOnly)
-	app.HandlerFunc(http.MethodPut, version, "/homes/{home_id}", api.update, authen, ruleAuthorizeHome)
-	app.HandlerFunc(http.MethodDelete, version, "/homes/{home_id}", api.delete, authen, ruleAuthorizeHome)
+	app.HandlerFunc(http.MethodGet, version, "/homes", api.query, authen, limit, ruleAny)
+	app.HandlerFunc(http.MethodGet, version, "/homes/{home_id}", api.queryByID, authen, limit, ruleAuthorizeHome)
+	app.HandlerFunc(http.MethodPost, version, "/homes", api.create, authen, limit, ruleUserOnly)
+	app.HandlerFunc(http.MethodPut, version, "/homes/{home_id}", api.update, authen, limit, ruleAuthorizeHome)
+	app.HandlerFunc(http.MethodDelete, version, "/homes/{home_id}", api.delete, authen, limit, ruleAuthorizeHome)
 }
@@ -0,0 +1,106 @@
+package jobapp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/google/uuid"
+)
+
+type queryParams struct {
+	Page    string
+	Rows    string
+	OrderBy string
+	ID      string
+	Type    string
+	Status  string
+	Since   string
+	Until   string
+}
+
+func parseQueryParams(r *http.Request) (queryParams, error) {
+	values := r.URL.Query()
+
+	filter := queryParams{
+		Page:    values.Get("page"),
+		Rows:    values.Get("rows"),
+		OrderBy: values.Get("orderBy"),
+		ID:      values.Get("job_id"),
+		Type:    values.Get("type"),
+		Status:  values.Get("status"),
+		Since:   values.Get("since"),
+		Until:   values.Get("until"),
+	}
+
+	return filter, nil
+}
+
+// queryParamDocs documents the query parameters read by parseQueryParams.
+var queryParamDocs = []openapi.Param{
+	{Name: "page", Type: "integer"},
+	{Name: "rows", Type: "integer"},
+	{Name: "job_id", Format: "uuid"},
+	{Name: "type"},
+	{Name: "status"},
+	{Name: "since", Format: "date-time"},
+	{Name: "until", Format: "date-time"},
+}
+
+func parseFilter(qp queryParams) (jobbus.QueryFilter, error) {
+	var fieldErrors errs.FieldErrors
+	var filter jobbus.QueryFilter
+
+	if qp.ID != "" {
+		id, err := uuid.Parse(qp.ID)
+		switch err {
+		case nil:
+			filter.ID = &id
+		default:
+			fieldErrors.Add("job_id", err)
+		}
+	}
+
+	if qp.Type != "" {
+		filter.Type = &qp.Type
+	}
+
+	if qp.Status != "" {
+		status, err := jobstatus.Parse(qp.Status)
+		switch err {
+		case nil:
+			filter.Status = &status
+		default:
+			fieldErrors.Add("status", err)
+		}
+	}
+
+	if qp.Since != "" {
+		t, err := time.Parse(time.RFC3339, qp.Since)
+		switch err {
+		case nil:
+			filter.Since = &t
+		default:
+			fieldErrors.Add("since", err)
+		}
+	}
+
+	if qp.Until != "" {
+		t, err := time.Parse(time.RFC3339, qp.Until)
+		switch err {
+		case nil:
+			filter.Until = &t
+		default:
+			fieldErrors.Add("until", err)
+		}
+	}
+
+	if fieldErrors != nil {
+		return jobbus.QueryFilter{}, fieldErrors.ToError()
+	}
+
+	return filter, nil
+}
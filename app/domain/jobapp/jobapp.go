@@ -0,0 +1,123 @@
+// Package jobapp maintains the app layer api for the job domain.
+package jobapp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+type app struct {
+	jobBus jobbus.ExtBusiness
+}
+
+func newApp(jobBus jobbus.ExtBusiness) *app {
+	return &app{
+		jobBus: jobBus,
+	}
+}
+
+func (a *app) query(ctx context.Context, r *http.Request) web.Encoder {
+	qp, err := parseQueryParams(r)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	page, err := page.Parse(qp.Page, qp.Rows)
+	if err != nil {
+		return errs.NewFieldErrors("page", err)
+	}
+
+	filter, err := parseFilter(qp)
+	if err != nil {
+		return err.(*errs.Error)
+	}
+
+	orderBy, err := order.Parse(orderByFields, qp.OrderBy, jobbus.DefaultOrderBy)
+	if err != nil {
+		return errs.NewFieldErrors("order", err)
+	}
+
+	jobs, err := a.jobBus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return errs.Errorf(errs.Internal, "query: %s", err)
+	}
+
+	total, err := a.jobBus.Count(ctx, filter)
+	if err != nil {
+		return errs.Errorf(errs.Internal, "count: %s", err)
+	}
+
+	return query.NewResult(toAppJobs(jobs), total, page)
+}
+
+func (a *app) queryByID(ctx context.Context, r *http.Request) web.Encoder {
+	job, errEnc := a.lookup(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	return toAppJob(job)
+}
+
+func (a *app) retry(ctx context.Context, r *http.Request) web.Encoder {
+	job, errEnc := a.lookup(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	job, err := a.jobBus.Retry(ctx, job)
+	if err != nil {
+		return toError("retry", err)
+	}
+
+	return toAppJob(job)
+}
+
+func (a *app) cancel(ctx context.Context, r *http.Request) web.Encoder {
+	job, errEnc := a.lookup(ctx, r)
+	if errEnc != nil {
+		return errEnc
+	}
+
+	job, err := a.jobBus.Cancel(ctx, job)
+	if err != nil {
+		return toError("cancel", err)
+	}
+
+	return toAppJob(job)
+}
+
+func (a *app) lookup(ctx context.Context, r *http.Request) (jobbus.Job, *errs.Error) {
+	jobID, err := uuid.Parse(web.Param(r, "job_id"))
+	if err != nil {
+		return jobbus.Job{}, errs.NewFieldErrors("job_id", err)
+	}
+
+	job, err := a.jobBus.QueryByID(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, jobbus.ErrNotFound) {
+			return jobbus.Job{}, errs.New(errs.NotFound, jobbus.ErrNotFound)
+		}
+		return jobbus.Job{}, errs.Errorf(errs.Internal, "querybyid: jobID[%s]: %s", jobID, err)
+	}
+
+	return job, nil
+}
+
+func toError(op string, err error) *errs.Error {
+	switch {
+	case errors.Is(err, jobbus.ErrNotRetryable), errors.Is(err, jobbus.ErrNotCancellable), errors.Is(err, jobbus.ErrStatusChanged):
+		return errs.New(errs.FailedPrecondition, err)
+	}
+
+	return errs.Errorf(errs.Internal, "%s: %s", op, err)
+}
@@ -0,0 +1,59 @@
+package jobapp
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/jobbus"
+)
+
+// Job represents information about an individual background job.
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"maxAttempts"`
+	LastError   string          `json:"lastError,omitempty"`
+	ScheduledAt string          `json:"scheduledAt"`
+	LockedUntil string          `json:"lockedUntil,omitempty"`
+	DateCreated string          `json:"dateCreated"`
+	DateUpdated string          `json:"dateUpdated"`
+}
+
+// Encode implements the encoder interface.
+func (app Job) Encode() ([]byte, string, error) {
+	data, err := json.Marshal(app)
+	return data, "application/json", err
+}
+
+func toAppJob(bus jobbus.Job) Job {
+	var lockedUntil string
+	if !bus.LockedUntil.IsZero() {
+		lockedUntil = bus.LockedUntil.Format(time.RFC3339)
+	}
+
+	return Job{
+		ID:          bus.ID.String(),
+		Type:        bus.Type,
+		Payload:     bus.Payload,
+		Status:      bus.Status.String(),
+		Attempts:    bus.Attempts,
+		MaxAttempts: bus.MaxAttempts,
+		LastError:   bus.LastError,
+		ScheduledAt: bus.ScheduledAt.Format(time.RFC3339),
+		LockedUntil: lockedUntil,
+		DateCreated: bus.DateCreated.Format(time.RFC3339),
+		DateUpdated: bus.DateUpdated.Format(time.RFC3339),
+	}
+}
+
+func toAppJobs(jobs []jobbus.Job) []Job {
+	app := make([]Job, len(jobs))
+	for i, job := range jobs {
+		app[i] = toAppJob(job)
+	}
+
+	return app
+}
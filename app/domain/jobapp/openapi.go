@@ -0,0 +1,52 @@
+package jobapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/app/sdk/query"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	const tag = "jobs"
+
+	ops := []openapi.Operation{
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/jobs",
+			Tag:         tag,
+			Summary:     "Query background jobs",
+			Security:    openapi.SecurityBearer,
+			QueryParams: queryParamDocs,
+			OrderBy:     orderByFields,
+			Response:    query.Result[Job]{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/jobs/{job_id}",
+			Tag:      tag,
+			Summary:  "Query a background job by id",
+			Security: openapi.SecurityBearer,
+			Response: Job{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/jobs/{job_id}/retry",
+			Tag:      tag,
+			Summary:  "Queue a dead or cancelled job to run again",
+			Security: openapi.SecurityBearer,
+			Response: Job{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/jobs/{job_id}/cancel",
+			Tag:      tag,
+			Summary:  "Cancel a queued job",
+			Security: openapi.SecurityBearer,
+			Response: Job{},
+		},
+	}
+
+	return ops
+}
@@ -0,0 +1,12 @@
+package jobapp
+
+import "github.com/ardanlabs/service/business/domain/jobbus"
+
+var orderByFields = map[string]string{
+	"job_id":       jobbus.OrderByID,
+	"type":         jobbus.OrderByType,
+	"status":       jobbus.OrderByStatus,
+	"attempts":     jobbus.OrderByAttempts,
+	"scheduled_at": jobbus.OrderByScheduledAt,
+	"date_created": jobbus.OrderByDateCreated,
+}
@@ -0,0 +1,37 @@
+package jobapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Log        *logger.Logger
+	JobBus     jobbus.ExtBusiness
+	AuthClient authclient.Authenticator
+	RateLimit  ratelimit.Config
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	authen := mid.Authenticate(cfg.AuthClient)
+	limit := mid.RateLimit(cfg.RateLimit.Store, cfg.RateLimit.Limit)
+	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
+
+	api := newApp(cfg.JobBus)
+
+	app.HandlerFunc(http.MethodGet, version, "/jobs", api.query, authen, limit, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/jobs/{job_id}", api.queryByID, authen, limit, ruleAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/jobs/{job_id}/retry", api.retry, authen, limit, ruleAdmin)
+	app.HandlerFunc(http.MethodPost, version, "/jobs/{job_id}/cancel", api.cancel, authen, limit, ruleAdmin)
+}
@@ -0,0 +1,21 @@
+package openapiapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	ops := []openapi.Operation{
+		{
+			Method:  http.MethodGet,
+			Path:    "/v1/openapi.json",
+			Tag:     "openapi",
+			Summary: "Retrieve the OpenAPI document for the service",
+		},
+	}
+
+	return ops
+}
@@ -0,0 +1,41 @@
+// Package openapiapp maintains the app layer api for the OpenAPI document.
+package openapiapp
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type app struct {
+	app  *web.App
+	cfg  Config
+	once sync.Once
+	doc  openapi.Document
+}
+
+func newApp(webApp *web.App, cfg Config) *app {
+	return &app{
+		app: webApp,
+		cfg: cfg,
+	}
+}
+
+// document returns the OpenAPI document for the service. The document is
+// generated on first use so every route has been registered by then.
+func (a *app) document(ctx context.Context, r *http.Request) web.Encoder {
+	a.once.Do(func() {
+		a.doc = openapi.New(openapi.Config{
+			Title:       a.cfg.Title,
+			Description: a.cfg.Description,
+			Version:     a.cfg.Build,
+			Routes:      a.app.Routes(),
+			Operations:  a.cfg.Operations,
+		})
+	})
+
+	return a.doc
+}
@@ -0,0 +1,25 @@
+package openapiapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Config contains all the mandatory systems required by handlers.
+type Config struct {
+	Build       string
+	Title       string
+	Description string
+	Operations  []openapi.Operation
+}
+
+// Routes adds specific routes for this group.
+func Routes(app *web.App, cfg Config) {
+	const version = "v1"
+
+	api := newApp(app, cfg)
+
+	app.HandlerFunc(http.MethodGet, version, "/openapi.json", api.document)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/openapi"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/google/uuid"
@@ -36,6 +37,16 @@ func parseQueryParams(r *http.Request) queryParams {
 	return filter
 }
 
+// queryParamDocs documents the query parameters read by parseQueryParams.
+var queryParamDocs = []openapi.Param{
+	{Name: "page", Type: "integer"},
+	{Name: "rows", Type: "integer"},
+	{Name: "product_id", Format: "uuid"},
+	{Name: "name"},
+	{Name: "cost", Type: "number"},
+	{Name: "quantity", Type: "integer"},
+}
+
 func parseFilter(qp queryParams) (productbus.QueryFilter, error) {
 	var fieldErrors errs.FieldErrors
 	var filter productbus.QueryFilter
@@ -0,0 +1,62 @@
+package productapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/app/sdk/query"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	const tag = "products"
+
+	ops := []openapi.Operation{
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/products",
+			Tag:         tag,
+			Summary:     "Query products",
+			Security:    openapi.SecurityBearer,
+			QueryParams: queryParamDocs,
+			OrderBy:     orderByFields,
+			Response:    query.Result[Product]{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/products/{product_id}",
+			Tag:      tag,
+			Summary:  "Query a product by id",
+			Security: openapi.SecurityBearer,
+			Response: Product{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/products",
+			Tag:      tag,
+			Summary:  "Create a product",
+			Security: openapi.SecurityBearer,
+			Request:  NewProduct{},
+			Response: Product{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/v1/products/{product_id}",
+			Tag:      tag,
+			Summary:  "Update a product",
+			Security: openapi.SecurityBearer,
+			Request:  UpdateProduct{},
+			Response: Product{},
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/v1/products/{product_id}",
+			Tag:      tag,
+			Summary:  "Delete a product",
+			Security: openapi.SecurityBearer,
+			Status:   http.StatusNoContent,
+		},
+	}
+
+	return ops
+}
@@ -5,7 +5,9 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/idempotency"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
@@ -13,9 +15,11 @@ import (
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Log        *logger.Logger
-	ProductBus productbus.ExtBusiness
-	AuthClient authclient.Authenticator
+	Log         *logger.Logger
+	ProductBus  productbus.ExtBusiness
+	AuthClient  authclient.Authenticator
+	Idempotency idempotency.Config
+	RateLimit   ratelimit.Config
 }
 
 // Routes adds specific routes for this group.
@@ -23,15 +27,17 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	limit := mid.RateLimit(cfg.RateLimit.Store, cfg.RateLimit.Limit)
 	ruleAny := mid.Authorize(cfg.AuthClient, auth.RuleAny)
 	ruleUserOnly := mid.Authorize(cfg.AuthClient, auth.RuleUserOnly)
 	ruleAuthorizeProduct := mid.AuthorizeProduct(cfg.AuthClient, cfg.ProductBus)
+	idempotent := mid.Idempotency(cfg.Log, cfg.Idempotency.Store, cfg.Idempotency.TTL, cfg.Idempotency.Lease)
 
 	api := newApp(cfg.ProductBus)
 
-	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, ruleAny)
-	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen, ruleAuthorizeProduct)
-	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, ruleUserOnly)
-	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, ruleAuthorizeProduct)
-	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, ruleAuthorizeProduct)
+	app.HandlerFunc(http.MethodGet, version, "/products", api.query, authen, limit, ruleAny)
+	app.HandlerFunc(http.MethodGet, version, "/products/{product_id}", api.queryByID, authen, limit, ruleAuthorizeProduct)
+	app.HandlerFunc(http.MethodPost, version, "/products", api.create, authen, limit, ruleUserOnly, idempotent)
+	app.HandlerFunc(http.MethodPut, version, "/products/{product_id}", api.update, authen, limit, ruleAuthorizeProduct)
+	app.HandlerFunc(http.MethodDelete, version, "/products/{product_id}", api.delete, authen, limit, ruleAuthorizeProduct)
 }
@@ -0,0 +1,24 @@
+package tranapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	ops := []openapi.Operation{
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/tranexample",
+			Tag:      "tranexample",
+			Summary:  "Create a user and product in a single transaction",
+			Security: openapi.SecurityBearer,
+			Request:  NewTran{},
+			Response: Product{},
+		},
+	}
+
+	return ops
+}
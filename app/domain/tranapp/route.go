@@ -5,7 +5,9 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/idempotency"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
@@ -16,11 +18,13 @@ import (
 
 // Config contains all the mandatory systems required by handlers.
 type Config struct {
-	Log        *logger.Logger
-	DB         *sqlx.DB
-	UserBus    userbus.ExtBusiness
-	ProductBus productbus.ExtBusiness
-	AuthClient authclient.Authenticator
+	Log         *logger.Logger
+	DB          *sqlx.DB
+	UserBus     userbus.ExtBusiness
+	ProductBus  productbus.ExtBusiness
+	AuthClient  authclient.Authenticator
+	Idempotency idempotency.Config
+	RateLimit   ratelimit.Config
 }
 
 // Routes adds specific routes for this group.
@@ -28,10 +32,12 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	limit := mid.RateLimit(cfg.RateLimit.Store, cfg.RateLimit.Limit)
 	transaction := mid.BeginCommitRollback(cfg.Log, sqldb.NewBeginner(cfg.DB))
 	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
+	idempotent := mid.Idempotency(cfg.Log, cfg.Idempotency.Store, cfg.Idempotency.TTL, cfg.Idempotency.Lease)
 
 	api := newApp(cfg.UserBus, cfg.ProductBus)
 
-	app.HandlerFunc(http.MethodPost, version, "/tranexample", api.create, authen, ruleAdmin, transaction)
+	app.HandlerFunc(http.MethodPost, version, "/tranexample", api.create, authen, limit, ruleAdmin, idempotent, transaction)
 }
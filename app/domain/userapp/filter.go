@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/openapi"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/google/uuid"
@@ -39,6 +40,17 @@ func parseQueryParams(r *http.Request) (queryParams, error) {
 	return filter, nil
 }
 
+// queryParamDocs documents the query parameters read by parseQueryParams.
+var queryParamDocs = []openapi.Param{
+	{Name: "page", Type: "integer"},
+	{Name: "rows", Type: "integer"},
+	{Name: "user_id", Format: "uuid"},
+	{Name: "name"},
+	{Name: "email", Format: "email"},
+	{Name: "start_created_date", Format: "date-time"},
+	{Name: "end_created_date", Format: "date-time"},
+}
+
 func parseFilter(qp queryParams) (userbus.QueryFilter, error) {
 	var fieldErrors errs.FieldErrors
 	var filter userbus.QueryFilter
@@ -0,0 +1,17 @@
+package userapp
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/openapi/openapitest"
+)
+
+func Test_QueryParamDocs(t *testing.T) {
+	parse := func(r *http.Request) queryParams {
+		qp, _ := parseQueryParams(r)
+		return qp
+	}
+
+	openapitest.QueryParams(t, queryParamDocs, parse, "orderBy")
+}
@@ -0,0 +1,71 @@
+package userapp
+
+import (
+	"net/http"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/app/sdk/query"
+)
+
+// Operations returns the OpenAPI documentation for the routes in this group.
+func Operations() []openapi.Operation {
+	const tag = "users"
+
+	ops := []openapi.Operation{
+		{
+			Method:      http.MethodGet,
+			Path:        "/v1/users",
+			Tag:         tag,
+			Summary:     "Query users",
+			Security:    openapi.SecurityBearer,
+			QueryParams: queryParamDocs,
+			OrderBy:     orderByFields,
+			Response:    query.Result[User]{},
+		},
+		{
+			Method:   http.MethodGet,
+			Path:     "/v1/users/{user_id}",
+			Tag:      tag,
+			Summary:  "Query a user by id",
+			Security: openapi.SecurityBearer,
+			Response: User{},
+		},
+		{
+			Method:   http.MethodPost,
+			Path:     "/v1/users",
+			Tag:      tag,
+			Summary:  "Create a user",
+			Security: openapi.SecurityBearer,
+			Request:  NewUser{},
+			Response: User{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/v1/users/role/{user_id}",
+			Tag:      tag,
+			Summary:  "Update the roles for a user",
+			Security: openapi.SecurityBearer,
+			Request:  UpdateUserRole{},
+			Response: User{},
+		},
+		{
+			Method:   http.MethodPut,
+			Path:     "/v1/users/{user_id}",
+			Tag:      tag,
+			Summary:  "Update a user",
+			Security: openapi.SecurityBearer,
+			Request:  UpdateUser{},
+			Response: User{},
+		},
+		{
+			Method:   http.MethodDelete,
+			Path:     "/v1/users/{user_id}",
+			Tag:      tag,
+			Summary:  "Delete a user",
+			Security: openapi.SecurityBearer,
+			Status:   http.StatusNoContent,
+		},
+	}
+
+	return ops
+}
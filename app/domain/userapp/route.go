@@ -6,6 +6,7 @@ import (
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/web"
@@ -16,6 +17,7 @@ type Config struct {
 	Log        *logger.Logger
 	UserBus    userbus.ExtBusiness
 	AuthClient authclient.Authenticator
+	RateLimit  ratelimit.Config
 }
 
 // Routes adds specific routes for this group.
@@ -23,16 +25,17 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	limit := mid.RateLimit(cfg.RateLimit.Store, cfg.RateLimit.Limit)
 	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
 	ruleAuthorizeUser := mid.AuthorizeUser(cfg.AuthClient, cfg.UserBus, auth.RuleAdminOrSubject)
 	ruleAuthorizeAdmin := mid.AuthorizeUser(cfg.AuthClient, cfg.UserBus, auth.RuleAdminOnly)
 
 	api := newApp(cfg.UserBus)
 
-	app.HandlerFunc(http.MethodGet, version, "/users", api.query, authen, ruleAdmin)
-	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}", api.queryByID, authen, ruleAuthorizeUser)
-	app.HandlerFunc(http.MethodPost, version, "/users", api.create, authen, ruleAdmin)
-	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, ruleAuthorizeAdmin)
-	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, ruleAuthorizeUser)
-	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodGet, version, "/users", api.query, authen, limit, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/users/{user_id}", api.queryByID, authen, limit, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodPost, version, "/users", api.create, authen, limit, ruleAdmin)
+	app.HandlerFunc(http.MethodPut, version, "/users/role/{user_id}", api.updateRole, authen, limit, ruleAuthorizeAdmin)
+	app.HandlerFunc(http.MethodPut, version, "/users/{user_id}", api.update, authen, limit, ruleAuthorizeUser)
+	app.HandlerFunc(http.MethodDelete, version, "/users/{user_id}", api.delete, authen, limit, ruleAuthorizeUser)
 }
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/openapi"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/business/types/name"
 	"github.com/google/uuid"
@@ -38,6 +39,17 @@ func parseQueryParams(r *http.Request) queryParams {
 	return filter
 }
 
+// queryParamDocs documents the query parameters read by parseQueryParams.
+var queryParamDocs = []openapi.Param{
+	{Name: "page", Type: "integer"},
+	{Name: "rows", Type: "integer"},
+	{Name: "product_id", Format: "uuid"},
+	{Name: "name"},
+	{Name: "cost", Type: "number"},
+	{Name: "quantity", Type: "integer"},
+	{Name: "user_name"},
+}
+
 func parseFilter(qp queryParams) (vproductbus.QueryFilter, error) {
 	var fieldErrors errs.FieldErrors
 	var filter vproductbus.QueryFilter
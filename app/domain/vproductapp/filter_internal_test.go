@@ -0,0 +1,11 @@
+package vproductapp
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/openapi/openapitest"
+)
+
+func Test_QueryParamDocs(t *testing.T) {
+	openapitest.QueryParams(t, queryParamDocs, parseQueryParams, "orderBy")
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
 	"github.com/ardanlabs/service/foundation/logger"
@@ -18,6 +19,7 @@ type Config struct {
 	UserBus     userbus.ExtBusiness
 	VProductBus vproductbus.ExtBusiness
 	AuthClient  authclient.Authenticator
+	RateLimit   ratelimit.Config
 }
 
 // Routes adds specific routes for this group.
@@ -25,9 +27,10 @@ func Routes(app *web.App, cfg Config) {
 	const version = "v1"
 
 	authen := mid.Authenticate(cfg.AuthClient)
+	limit := mid.RateLimit(cfg.RateLimit.Store, cfg.RateLimit.Limit)
 	ruleAdmin := mid.Authorize(cfg.AuthClient, auth.RuleAdminOnly)
 
 	api := newApp(cfg.VProductBus)
 
-	app.HandlerFunc(http.MethodGet, version, "/vproducts", api.query, authen, ruleAdmin)
+	app.HandlerFunc(http.MethodGet, version, "/vproducts", api.query, authen, limit, ruleAdmin)
 }
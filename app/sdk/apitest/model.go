@@ -3,6 +3,7 @@ package apitest
 import (
 	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/jobbus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 )
@@ -20,6 +21,7 @@ type User struct {
 type SeedData struct {
 	Users  []User
 	Admins []User
+	Jobs   []jobbus.Job
 }
 
 // Table represent fields needed for running an api test.
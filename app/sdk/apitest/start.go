@@ -53,6 +53,7 @@ func New(t *testing.T, testName string) *Test {
 			ProductBus:  db.BusDomain.Product,
 			HomeBus:     db.BusDomain.Home,
 			VProductBus: db.BusDomain.VProduct,
+			JobBus:      db.BusDomain.Job,
 		},
 		SalesConfig: mux.SalesConfig{
 			AuthClient: authClient,
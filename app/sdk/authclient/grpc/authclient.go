@@ -4,12 +4,17 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/ardanlabs/service/app/domain/grpcauthapp"
 	"github.com/ardanlabs/service/app/sdk/authclient"
 	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -23,7 +28,10 @@ type Client struct {
 
 // New constructs an Auth that can be used to talk with the auth service.
 func New(log *logger.Logger, url string, options ...func(cln *Client)) (*Client, error) {
-	grpcConn, err := grpc.NewClient(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	grpcConn, err := grpc.NewClient(url,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(requestIDInterceptor, metricsInterceptor),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to auth gRPC service: %w", err)
 	}
@@ -86,3 +94,27 @@ func (cln *Client) Close() error {
 
 	return nil
 }
+
+// =============================================================================
+
+// requestIDInterceptor forwards the request id from the context to the auth
+// service as metadata.
+func requestIDInterceptor(ctx context.Context, method string, req any, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if requestID := web.GetRequestID(ctx); requestID != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(web.RequestIDHeader), requestID)
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// metricsInterceptor records the duration of the calls made to the auth
+// service.
+func metricsInterceptor(ctx context.Context, method string, req any, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	start := time.Now()
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+
+	authclient.RecordCall(ctx, "grpc", strings.ToLower(path.Base(method)), err, time.Since(start))
+
+	return err
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/ardanlabs/service/app/sdk/errs"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/ardanlabs/service/foundation/web"
 	"go.opentelemetry.io/otel/attribute"
 )
 
@@ -96,7 +97,7 @@ func (cln *Client) Authorize(ctx context.Context, auth authclient.Authorize) err
 	return nil
 }
 
-func (cln *Client) do(ctx context.Context, method string, endpoint string, headers map[string]string, body any, v any) error {
+func (cln *Client) do(ctx context.Context, method string, endpoint string, headers map[string]string, body any, v any) (err error) {
 	var statusCode int
 
 	u, err := url.Parse(endpoint)
@@ -105,6 +106,10 @@ func (cln *Client) do(ctx context.Context, method string, endpoint string, heade
 	}
 	base := path.Base(u.Path)
 
+	defer func(start time.Time) {
+		authclient.RecordCall(ctx, "http", base, err, time.Since(start))
+	}(time.Now())
+
 	cln.log.Info(ctx, "authclient: rawRequest: started", "method", method, "call", base, "endpoint", endpoint)
 	defer func() {
 		cln.log.Info(ctx, "authclient: rawRequest: completed", "status", statusCode)
@@ -132,11 +137,12 @@ func (cln *Client) do(ctx context.Context, method string, endpoint string, heade
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
 	for key, value := range headers {
-		cln.log.Info(ctx, "authclient: rawRequest", "key", key, "value", value)
+		cln.log.Info(ctx, "authclient: rawRequest", "key", key, "value", logger.Redact(key, value))
 		req.Header.Set(key, value)
 	}
 
 	otel.AddTraceToRequest(ctx, req)
+	web.AddRequestIDToRequest(ctx, req)
 
 	resp, err := cln.http.Do(req)
 	if err != nil {
@@ -0,0 +1,44 @@
+package authclient
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var callDuration metric.Float64Histogram
+
+func init() {
+	meter := otel.Meter("github.com/ardanlabs/service/app/sdk/authclient")
+
+	var err error
+	callDuration, err = meter.Float64Histogram(
+		"authclient.call.duration",
+		metric.WithDescription("Duration of the calls made to the auth service."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RecordCall records the duration of a call to the auth service by the
+// transport used, the call that was made and whether it failed.
+func RecordCall(ctx context.Context, transport string, call string, err error, d time.Duration) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	attrs := metric.WithAttributes(
+		attribute.String("transport", transport),
+		attribute.String("call", call),
+		attribute.String("result", result),
+	)
+
+	callDuration.Record(ctx, d.Seconds(), attrs)
+}
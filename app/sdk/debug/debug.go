@@ -12,8 +12,8 @@ import (
 // Mux registers all the debug routes from the standard library into a new mux
 // bypassing the use of the DefaultServerMux. Using the DefaultServerMux would
 // be a security risk since a dependency could inject a handler into our service
-// without us knowing it.
-func Mux() *http.ServeMux {
+// without us knowing it. Options can register additional debug routes.
+func Mux(options ...func(mux *http.ServeMux)) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -25,5 +25,9 @@ func Mux() *http.ServeMux {
 
 	statsviz.Register(mux)
 
+	for _, option := range options {
+		option(mux)
+	}
+
 	return mux
 }
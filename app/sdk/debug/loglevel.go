@@ -0,0 +1,124 @@
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// defaultRevertAfter is used when a level change doesn't specify how long
+// the change should last so a forgotten debug level doesn't stay on.
+const defaultRevertAfter = 15 * time.Minute
+
+// WithLogLevel registers the /debug/loglevel endpoint to read and change the
+// level of the logger at runtime.
+//
+//	GET    /debug/loglevel
+//	PUT    /debug/loglevel                  {"level":"DEBUG","package":"productbus","revert_after":"10m"}
+//	DELETE /debug/loglevel?package=productbus
+//
+// A level change reverts after 15 minutes unless revert_after is provided. A
+// revert_after of "0s" makes the change permanent.
+func WithLogLevel(log *logger.Logger) func(mux *http.ServeMux) {
+	return func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /debug/loglevel", func(w http.ResponseWriter, r *http.Request) {
+			writeLevelState(w, log)
+		})
+
+		mux.HandleFunc("PUT /debug/loglevel", func(w http.ResponseWriter, r *http.Request) {
+			var req struct {
+				Level       string  `json:"level"`
+				Package     string  `json:"package"`
+				RevertAfter *string `json:"revert_after"`
+			}
+
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("decode request: %s", err), http.StatusBadRequest)
+				return
+			}
+
+			level, err := logger.ParseLevel(req.Level)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			revertAfter := defaultRevertAfter
+			if req.RevertAfter != nil {
+				revertAfter, err = time.ParseDuration(*req.RevertAfter)
+				if err != nil || revertAfter < 0 {
+					http.Error(w, fmt.Sprintf("invalid revert_after %q", *req.RevertAfter), http.StatusBadRequest)
+					return
+				}
+			}
+
+			switch req.Package {
+			case "":
+				log.SetLevel(level, revertAfter)
+				log.Info(r.Context(), "debug", "status", "log level changed", "level", level.String(), "revert_after", revertAfter.String())
+
+			default:
+				log.SetPackageLevel(req.Package, level, revertAfter)
+				log.Info(r.Context(), "debug", "status", "package log level changed", "package", req.Package, "level", level.String(), "revert_after", revertAfter.String())
+			}
+
+			writeLevelState(w, log)
+		})
+
+		mux.HandleFunc("DELETE /debug/loglevel", func(w http.ResponseWriter, r *http.Request) {
+			pkg := r.URL.Query().Get("package")
+			if pkg == "" {
+				http.Error(w, "package is required", http.StatusBadRequest)
+				return
+			}
+
+			log.ResetPackageLevel(pkg)
+			log.Info(r.Context(), "debug", "status", "package log level reset", "package", pkg)
+
+			writeLevelState(w, log)
+		})
+	}
+}
+
+func writeLevelState(w http.ResponseWriter, log *logger.Logger) {
+	type packageLevel struct {
+		Level    string     `json:"level"`
+		RevertAt *time.Time `json:"revert_at,omitempty"`
+	}
+
+	type levelState struct {
+		Level    string                  `json:"level"`
+		Default  string                  `json:"default"`
+		RevertAt *time.Time              `json:"revert_at,omitempty"`
+		Packages map[string]packageLevel `json:"packages"`
+	}
+
+	revertAt := func(t time.Time) *time.Time {
+		if t.IsZero() {
+			return nil
+		}
+		return &t
+	}
+
+	state := log.LevelState()
+
+	resp := levelState{
+		Level:    state.Level.String(),
+		Default:  state.Default.String(),
+		RevertAt: revertAt(state.RevertAt),
+		Packages: make(map[string]packageLevel, len(state.Packages)),
+	}
+
+	for pkg, pl := range state.Packages {
+		resp.Packages[pkg] = packageLevel{
+			Level:    pl.Level.String(),
+			RevertAt: revertAt(pl.RevertAt),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,18 @@
+package debug
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// WithMetrics registers the /metrics endpoint to serve the metrics gathered
+// from the registry in the Prometheus text format.
+//
+//	GET /metrics
+func WithMetrics(gatherer prometheus.Gatherer) func(mux *http.ServeMux) {
+	return func(mux *http.ServeMux) {
+		mux.Handle("GET /metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	}
+}
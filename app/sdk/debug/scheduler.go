@@ -0,0 +1,21 @@
+package debug
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ardanlabs/service/foundation/scheduler"
+)
+
+// WithScheduler registers the /debug/scheduler endpoint to report the status
+// of the scheduled tasks.
+//
+//	GET /debug/scheduler
+func WithScheduler(s *scheduler.Scheduler) func(mux *http.ServeMux) {
+	return func(mux *http.ServeMux) {
+		mux.HandleFunc("GET /debug/scheduler", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(s.Status())
+		})
+	}
+}
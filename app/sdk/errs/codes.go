@@ -116,73 +116,80 @@ var (
 	// system has been broken. If you see one of these errors,
 	// something is very broken. The error message is not sent to the client.
 	InternalOnlyLog = ErrCode{value: 19}
+
+	// UnprocessableEntity indicates the request is well formed but can't be
+	// processed, such as an idempotency key reused for a different request.
+	UnprocessableEntity = ErrCode{value: 20}
 )
 
 var codeNumbers = map[string]ErrCode{
-	"ok":                  None,
-	"no_content":          NoContent,
-	"canceled":            Canceled,
-	"unknown":             Unknown,
-	"invalid_argument":    InvalidArgument,
-	"deadline_exceeded":   DeadlineExceeded,
-	"not_found":           NotFound,
-	"already_exists":      AlreadyExists,
-	"permission_denied":   PermissionDenied,
-	"resource_exhausted":  ResourceExhausted,
-	"failed_precondition": FailedPrecondition,
-	"aborted":             Aborted,
-	"out_of_range":        OutOfRange,
-	"unimplemented":       Unimplemented,
-	"internal":            Internal,
-	"unavailable":         Unavailable,
-	"data_loss":           DataLoss,
-	"unauthenticated":     Unauthenticated,
-	"too_many_requests":   TooManyRequests,
-	"internal_only_log":   InternalOnlyLog,
+	"ok":                   None,
+	"no_content":           NoContent,
+	"canceled":             Canceled,
+	"unknown":              Unknown,
+	"invalid_argument":     InvalidArgument,
+	"deadline_exceeded":    DeadlineExceeded,
+	"not_found":            NotFound,
+	"already_exists":       AlreadyExists,
+	"permission_denied":    PermissionDenied,
+	"resource_exhausted":   ResourceExhausted,
+	"failed_precondition":  FailedPrecondition,
+	"aborted":              Aborted,
+	"out_of_range":         OutOfRange,
+	"unimplemented":        Unimplemented,
+	"internal":             Internal,
+	"unavailable":          Unavailable,
+	"data_loss":            DataLoss,
+	"unauthenticated":      Unauthenticated,
+	"too_many_requests":    TooManyRequests,
+	"internal_only_log":    InternalOnlyLog,
+	"unprocessable_entity": UnprocessableEntity,
 }
 
 var codeNames = map[ErrCode]string{
-	None:               "ok",
-	NoContent:          "ok_no_content",
-	Canceled:           "canceled",
-	Unknown:            "unknown",
-	InvalidArgument:    "invalid_argument",
-	DeadlineExceeded:   "deadline_exceeded",
-	NotFound:           "not_found",
-	AlreadyExists:      "already_exists",
-	PermissionDenied:   "permission_denied",
-	ResourceExhausted:  "resource_exhausted",
-	FailedPrecondition: "failed_precondition",
-	Aborted:            "aborted",
-	OutOfRange:         "out_of_range",
-	Unimplemented:      "unimplemented",
-	Internal:           "internal",
-	Unavailable:        "unavailable",
-	DataLoss:           "data_loss",
-	Unauthenticated:    "unauthenticated",
-	TooManyRequests:    "too_many_requests",
-	InternalOnlyLog:    "internal_only_log",
+	None:                "ok",
+	NoContent:           "ok_no_content",
+	Canceled:            "canceled",
+	Unknown:             "unknown",
+	InvalidArgument:     "invalid_argument",
+	DeadlineExceeded:    "deadline_exceeded",
+	NotFound:            "not_found",
+	AlreadyExists:       "already_exists",
+	PermissionDenied:    "permission_denied",
+	ResourceExhausted:   "resource_exhausted",
+	FailedPrecondition:  "failed_precondition",
+	Aborted:             "aborted",
+	OutOfRange:          "out_of_range",
+	Unimplemented:       "unimplemented",
+	Internal:            "internal",
+	Unavailable:         "unavailable",
+	DataLoss:            "data_loss",
+	Unauthenticated:     "unauthenticated",
+	TooManyRequests:     "too_many_requests",
+	InternalOnlyLog:     "internal_only_log",
+	UnprocessableEntity: "unprocessable_entity",
 }
 
 var httpStatus = map[ErrCode]int{
-	None:               http.StatusOK,
-	NoContent:          http.StatusNoContent,
-	Canceled:           http.StatusGatewayTimeout,
-	Unknown:            http.StatusInternalServerError,
-	InvalidArgument:    http.StatusBadRequest,
-	DeadlineExceeded:   http.StatusGatewayTimeout,
-	NotFound:           http.StatusNotFound,
-	AlreadyExists:      http.StatusConflict,
-	PermissionDenied:   http.StatusForbidden,
-	ResourceExhausted:  http.StatusTooManyRequests,
-	FailedPrecondition: http.StatusBadRequest,
-	Aborted:            http.StatusConflict,
-	OutOfRange:         http.StatusBadRequest,
-	Unimplemented:      http.StatusNotImplemented,
-	Internal:           http.StatusInternalServerError,
-	Unavailable:        http.StatusServiceUnavailable,
-	DataLoss:           http.StatusInternalServerError,
-	Unauthenticated:    http.StatusUnauthorized,
-	TooManyRequests:    http.StatusTooManyRequests,
-	InternalOnlyLog:    http.StatusInternalServerError,
+	None:                http.StatusOK,
+	NoContent:           http.StatusNoContent,
+	Canceled:            http.StatusGatewayTimeout,
+	Unknown:             http.StatusInternalServerError,
+	InvalidArgument:     http.StatusBadRequest,
+	DeadlineExceeded:    http.StatusGatewayTimeout,
+	NotFound:            http.StatusNotFound,
+	AlreadyExists:       http.StatusConflict,
+	PermissionDenied:    http.StatusForbidden,
+	ResourceExhausted:   http.StatusTooManyRequests,
+	FailedPrecondition:  http.StatusBadRequest,
+	Aborted:             http.StatusConflict,
+	OutOfRange:          http.StatusBadRequest,
+	Unimplemented:       http.StatusNotImplemented,
+	Internal:            http.StatusInternalServerError,
+	Unavailable:         http.StatusServiceUnavailable,
+	DataLoss:            http.StatusInternalServerError,
+	Unauthenticated:     http.StatusUnauthorized,
+	TooManyRequests:     http.StatusTooManyRequests,
+	InternalOnlyLog:     http.StatusInternalServerError,
+	UnprocessableEntity: http.StatusUnprocessableEntity,
 }
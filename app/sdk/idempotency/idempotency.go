@@ -0,0 +1,97 @@
+// Package idempotency provides support for replaying the response of a
+// request that is retried with the same Idempotency-Key header.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Header is the header clients use to provide the idempotency key.
+const Header = "Idempotency-Key"
+
+// MaxKeyLen is the maximum length of an idempotency key.
+const MaxKeyLen = 255
+
+// PurgeJob is the background job type that removes the records that expired
+// before the time in its PurgePayload.
+const PurgeJob = "idempotency-purge"
+
+// PurgePayload is the payload of a PurgeJob.
+type PurgePayload struct {
+	Before time.Time `json:"before"`
+}
+
+// Config contains the store, the amount of time a response is kept for
+// replay and the amount of time a request holds the key while it runs.
+type Config struct {
+	Store Storer
+	TTL   time.Duration
+	Lease time.Duration
+}
+
+// Response represents a response stored for replay.
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// Encode implements the encoder interface.
+func (r Response) Encode() ([]byte, string, error) {
+	return r.Body, r.ContentType, nil
+}
+
+// HTTPStatus implements the web package httpStatus interface so the stored
+// status code is used for the replay.
+func (r Response) HTTPStatus() int {
+	if r.StatusCode == 0 {
+		return http.StatusOK
+	}
+
+	return r.StatusCode
+}
+
+// Record represents an idempotency key that has been claimed by a request.
+// The response is only set once the request has completed. Until then the
+// key is locked by the request that claimed it, but only until LockedUntil
+// so a key claimed by a process that crashed can be claimed again.
+type Record struct {
+	SubjectID   uuid.UUID
+	Key         string
+	Fingerprint string
+	Completed   bool
+	Response    Response
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+	DateCreated time.Time
+}
+
+// Storer defines the behavior required to persist idempotency records.
+// Claim stores the record unless a record that has not expired already exists
+// for the subject and key and is either completed or still locked, in which
+// case that record is returned with a value of false. Release only removes
+// a record that has not been completed.
+type Storer interface {
+	Claim(ctx context.Context, rec Record) (Record, bool, error)
+	Complete(ctx context.Context, rec Record) error
+	Release(ctx context.Context, subjectID uuid.UUID, key string) error
+}
+
+// Fingerprint produces a hash of the request method, path and body used to
+// detect a key that is reused for a different request.
+func Fingerprint(method string, path string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(path))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
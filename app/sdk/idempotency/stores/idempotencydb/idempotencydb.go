@@ -0,0 +1,150 @@
+// Package idempotencydb contains the database storage for idempotency records.
+package idempotencydb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/idempotency"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for idempotency record database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the API for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Claim inserts the record unless a record exists for the subject and key
+// that has not expired and is either completed or still locked. An expired
+// record or a record whose lock has lapsed is replaced.
+func (s *Store) Claim(ctx context.Context, rec idempotency.Record) (idempotency.Record, bool, error) {
+	const q = `
+	INSERT INTO idempotency_keys
+		(subject_id, idem_key, fingerprint, completed, status_code, content_type, body, locked_until, expires_at, date_created)
+	VALUES
+		(:subject_id, :idem_key, :fingerprint, false, 0, '', NULL, :locked_until, :expires_at, :date_created)
+	ON CONFLICT (subject_id, idem_key) DO UPDATE SET
+		fingerprint  = EXCLUDED.fingerprint,
+		completed    = false,
+		status_code  = 0,
+		content_type = '',
+		body         = NULL,
+		locked_until = EXCLUDED.locked_until,
+		expires_at   = EXCLUDED.expires_at,
+		date_created = EXCLUDED.date_created
+	WHERE
+		idempotency_keys.expires_at < EXCLUDED.date_created OR
+		(NOT idempotency_keys.completed AND idempotency_keys.locked_until < EXCLUDED.date_created)
+	RETURNING
+		subject_id, idem_key, fingerprint, completed, status_code, content_type, body, locked_until, expires_at, date_created`
+
+	var dbRec record
+	err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, toDBRecord(rec), &dbRec)
+	switch {
+	case err == nil:
+		return toAppRecord(dbRec), true, nil
+
+	case !errors.Is(err, sqldb.ErrDBNotFound):
+		return idempotency.Record{}, false, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	existing, err := s.queryByKey(ctx, rec.SubjectID, rec.Key)
+	if err != nil {
+		return idempotency.Record{}, false, err
+	}
+
+	return existing, false, nil
+}
+
+// Complete stores the response for the claimed record. A record that was
+// claimed again by a different request after its lock lapsed is left alone.
+func (s *Store) Complete(ctx context.Context, rec idempotency.Record) error {
+	const q = `
+	UPDATE
+		idempotency_keys
+	SET
+		completed    = true,
+		status_code  = :status_code,
+		content_type = :content_type,
+		body         = :body
+	WHERE
+		subject_id = :subject_id AND idem_key = :idem_key AND fingerprint = :fingerprint AND completed = false`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBRecord(rec)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Release removes the record so the key can be used again. A completed
+// record is kept so its response can still be replayed.
+func (s *Store) Release(ctx context.Context, subjectID uuid.UUID, key string) error {
+	data := toDBKey(subjectID, key)
+
+	const q = `
+	DELETE FROM
+		idempotency_keys
+	WHERE
+		subject_id = :subject_id AND idem_key = :idem_key AND completed = false`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Purge removes the records that expired before the specified time.
+func (s *Store) Purge(ctx context.Context, before time.Time) error {
+	data := struct {
+		Before time.Time `db:"before"`
+	}{
+		Before: before,
+	}
+
+	const q = `
+	DELETE FROM
+		idempotency_keys
+	WHERE
+		expires_at < :before`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Store) queryByKey(ctx context.Context, subjectID uuid.UUID, key string) (idempotency.Record, error) {
+	data := toDBKey(subjectID, key)
+
+	const q = `
+	SELECT
+		subject_id, idem_key, fingerprint, completed, status_code, content_type, body, locked_until, expires_at, date_created
+	FROM
+		idempotency_keys
+	WHERE
+		subject_id = :subject_id AND idem_key = :idem_key`
+
+	var dbRec record
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbRec); err != nil {
+		return idempotency.Record{}, fmt.Errorf("namedquerystruct: %w", err)
+	}
+
+	return toAppRecord(dbRec), nil
+}
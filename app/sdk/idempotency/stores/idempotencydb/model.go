@@ -0,0 +1,65 @@
+package idempotencydb
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/idempotency"
+	"github.com/google/uuid"
+)
+
+type record struct {
+	SubjectID   uuid.UUID `db:"subject_id"`
+	Key         string    `db:"idem_key"`
+	Fingerprint string    `db:"fingerprint"`
+	Completed   bool      `db:"completed"`
+	StatusCode  int       `db:"status_code"`
+	ContentType string    `db:"content_type"`
+	Body        []byte    `db:"body"`
+	LockedUntil time.Time `db:"locked_until"`
+	ExpiresAt   time.Time `db:"expires_at"`
+	DateCreated time.Time `db:"date_created"`
+}
+
+func toDBRecord(rec idempotency.Record) record {
+	return record{
+		SubjectID:   rec.SubjectID,
+		Key:         rec.Key,
+		Fingerprint: rec.Fingerprint,
+		Completed:   rec.Completed,
+		StatusCode:  rec.Response.StatusCode,
+		ContentType: rec.Response.ContentType,
+		Body:        rec.Response.Body,
+		LockedUntil: rec.LockedUntil.UTC(),
+		ExpiresAt:   rec.ExpiresAt.UTC(),
+		DateCreated: rec.DateCreated.UTC(),
+	}
+}
+
+func toAppRecord(db record) idempotency.Record {
+	return idempotency.Record{
+		SubjectID:   db.SubjectID,
+		Key:         db.Key,
+		Fingerprint: db.Fingerprint,
+		Completed:   db.Completed,
+		Response: idempotency.Response{
+			StatusCode:  db.StatusCode,
+			ContentType: db.ContentType,
+			Body:        db.Body,
+		},
+		LockedUntil: db.LockedUntil.In(time.Local),
+		ExpiresAt:   db.ExpiresAt.In(time.Local),
+		DateCreated: db.DateCreated.In(time.Local),
+	}
+}
+
+type key struct {
+	SubjectID string `db:"subject_id"`
+	Key       string `db:"idem_key"`
+}
+
+func toDBKey(subjectID uuid.UUID, k string) key {
+	return key{
+		SubjectID: subjectID.String(),
+		Key:       k,
+	}
+}
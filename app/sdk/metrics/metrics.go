@@ -5,33 +5,99 @@ import (
 	"context"
 	"expvar"
 	"runtime"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 )
 
+// durationBuckets are the histogram boundaries in seconds for the request
+// duration.
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
 // This holds the single instance of the metrics value needed for
 // collecting metrics. The expvar package is already based on a singleton
 // for the different metrics that are registered with the package so there
 // isn't much choice here.
 var m metrics
 
-// metrics represents the set of metrics we gather. These fields are
-// safe to be accessed concurrently thanks to expvar. No extra abstraction is required.
+// metrics represents the set of metrics we gather. The counters are recorded
+// through open telemetry instruments and are still published with expvar
+// for the metrics service. The goroutines are observed when the metrics are
+// collected. These fields are safe to be accessed concurrently.
 type metrics struct {
-	goroutines *expvar.Int
-	requests   *expvar.Int
-	errors     *expvar.Int
-	panics     *expvar.Int
+	requests *expvar.Int
+	errors   *expvar.Int
+	panics   *expvar.Int
+
+	otelRequests      metric.Int64Counter
+	otelErrors        metric.Int64Counter
+	otelPanics        metric.Int64Counter
+	otelRouteRequests metric.Int64Counter
+	otelDuration      metric.Float64Histogram
 }
 
 // init constructs the metrics value that will be used to capture metrics.
 // The metrics value is stored in a package level variable since everything
-// inside of expvar is registered as a singleton. The use of once will make
-// sure this initialization only happens once.
+// inside of expvar is registered as a singleton. The instruments are created
+// from the global meter provider, which forwards them to the provider the
+// service configures at startup.
 func init() {
+	meter := otel.Meter("github.com/ardanlabs/service/app/sdk/metrics")
+
+	goroutines := func(ctx context.Context, o metric.Int64Observer) error {
+		o.Observe(int64(runtime.NumGoroutine()))
+		return nil
+	}
+
+	if _, err := meter.Int64ObservableGauge("goroutines", metric.WithDescription("Number of goroutines."), metric.WithInt64Callback(goroutines)); err != nil {
+		panic(err)
+	}
+
+	requests, err := meter.Int64Counter("requests", metric.WithDescription("Number of requests handled."))
+	if err != nil {
+		panic(err)
+	}
+
+	errors, err := meter.Int64Counter("errors", metric.WithDescription("Number of requests that failed."))
+	if err != nil {
+		panic(err)
+	}
+
+	panics, err := meter.Int64Counter("panics", metric.WithDescription("Number of requests that panicked."))
+	if err != nil {
+		panic(err)
+	}
+
+	routeRequests, err := meter.Int64Counter("http.server.requests", metric.WithDescription("Number of requests handled by method, route and status."))
+	if err != nil {
+		panic(err)
+	}
+
+	duration, err := meter.Float64Histogram("http.server.request.duration",
+		metric.WithDescription("Duration of the requests handled."),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	expvar.Publish("goroutines", expvar.Func(func() any {
+		return runtime.NumGoroutine()
+	}))
+
 	m = metrics{
-		goroutines: expvar.NewInt("goroutines"),
-		requests:   expvar.NewInt("requests"),
-		errors:     expvar.NewInt("errors"),
-		panics:     expvar.NewInt("panics"),
+		requests: expvar.NewInt("requests"),
+		errors:   expvar.NewInt("errors"),
+		panics:   expvar.NewInt("panics"),
+
+		otelRequests:      requests,
+		otelErrors:        errors,
+		otelPanics:        panics,
+		otelRouteRequests: routeRequests,
+		otelDuration:      duration,
 	}
 }
 
@@ -44,22 +110,12 @@ func Set(ctx context.Context) context.Context {
 	return context.WithValue(ctx, key, &m)
 }
 
-// AddGoroutines refreshes the goroutine metric.
-func AddGoroutines(ctx context.Context) int64 {
-	if v, ok := ctx.Value(key).(*metrics); ok {
-		g := int64(runtime.NumGoroutine())
-		v.goroutines.Set(g)
-		return g
-	}
-
-	return 0
-}
-
 // AddRequests increments the request metric by 1.
 func AddRequests(ctx context.Context) int64 {
 	v, ok := ctx.Value(key).(*metrics)
 	if ok {
 		v.requests.Add(1)
+		v.otelRequests.Add(ctx, 1)
 		return v.requests.Value()
 	}
 
@@ -70,6 +126,7 @@ func AddRequests(ctx context.Context) int64 {
 func AddErrors(ctx context.Context) int64 {
 	if v, ok := ctx.Value(key).(*metrics); ok {
 		v.errors.Add(1)
+		v.otelErrors.Add(ctx, 1)
 		return v.errors.Value()
 	}
 
@@ -80,8 +137,23 @@ func AddErrors(ctx context.Context) int64 {
 func AddPanics(ctx context.Context) int64 {
 	if v, ok := ctx.Value(key).(*metrics); ok {
 		v.panics.Add(1)
+		v.otelPanics.Add(ctx, 1)
 		return v.panics.Value()
 	}
 
 	return 0
 }
+
+// RecordRequest counts the request and records its duration by its method,
+// route pattern and status code.
+func RecordRequest(ctx context.Context, method string, route string, statusCode int, d time.Duration) {
+	if v, ok := ctx.Value(key).(*metrics); ok {
+		attrs := metric.WithAttributeSet(attribute.NewSet(
+			attribute.String("http.request.method", method),
+			attribute.String("http.route", route),
+			attribute.Int("http.response.status_code", statusCode),
+		))
+		v.otelRouteRequests.Add(ctx, 1, attrs)
+		v.otelDuration.Record(ctx, d.Seconds(), attrs)
+	}
+}
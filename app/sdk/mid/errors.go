@@ -10,6 +10,7 @@ import (
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
 	"github.com/ardanlabs/service/foundation/web"
+	"go.opentelemetry.io/otel/codes"
 )
 
 // Errors handles errors coming out of the call chain.
@@ -32,6 +33,12 @@ func Errors(log *logger.Logger) web.MidFunc {
 				appErr = errs.Errorf(errs.Internal, "Internal Server Error")
 			}
 
+			// Server errors mark the span so it can be exported even when
+			// the trace isn't sampled.
+			if appErr.HTTPStatus() >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, appErr.Code.String())
+			}
+
 			log.Error(ctx, "handled error during request",
 				"err", err,
 				"source_err_file", path.Base(appErr.FileName),
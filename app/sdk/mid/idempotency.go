@@ -0,0 +1,164 @@
+package mid
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/idempotency"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Idempotency stores the response of a request that provides an
+// Idempotency-Key header so a retry with the same key replays the response
+// instead of running the handler again. Keys are scoped to the authenticated
+// subject so this must run after authentication. Requests without the header
+// are not affected. Without a store the middleware does nothing.
+//
+// A claimed key is locked for the lease while the handler runs. The key is
+// released when the handler does not produce a response that can be stored,
+// including when it panics, and the lease lets a retry take over a key that
+// was claimed by a process that crashed.
+func Idempotency(log *logger.Logger, store idempotency.Storer, ttl time.Duration, lease time.Duration) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		if store == nil {
+			return next
+		}
+
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			key := r.Header.Get(idempotency.Header)
+			if key == "" {
+				return next(ctx, r)
+			}
+
+			if len(key) > idempotency.MaxKeyLen {
+				return errs.Errorf(errs.InvalidArgument, "idempotency key must be at most %d characters", idempotency.MaxKeyLen)
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return errs.Errorf(errs.InvalidArgument, "reading body: %s", err)
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			now := time.Now()
+
+			rec := idempotency.Record{
+				SubjectID:   GetSubjectID(ctx),
+				Key:         key,
+				Fingerprint: idempotency.Fingerprint(r.Method, r.URL.Path, body),
+				LockedUntil: now.Add(lease),
+				ExpiresAt:   now.Add(ttl),
+				DateCreated: now,
+			}
+
+			existing, claimed, err := store.Claim(ctx, rec)
+			if err != nil {
+				return errs.Errorf(errs.Internal, "claim idempotency key: %s", err)
+			}
+
+			if !claimed {
+				return replay(ctx, rec, existing)
+			}
+
+			// The key is released unless the response is stored so the
+			// client can retry the request. This runs when the handler
+			// panics as well, and on a context the client can't cancel.
+			var completed bool
+			defer func() {
+				if completed {
+					return
+				}
+
+				if err := store.Release(context.WithoutCancel(ctx), rec.SubjectID, rec.Key); err != nil {
+					log.Error(ctx, "idempotency: release", "key", rec.Key, "ERROR", err)
+				}
+			}()
+
+			resp := next(ctx, r)
+
+			if checkIsError(resp) != nil {
+				return resp
+			}
+
+			stored, err := toIdempotencyResponse(resp)
+			if err != nil {
+				return errs.Errorf(errs.Internal, "encode response: %s", err)
+			}
+
+			rec.Completed = true
+			rec.Response = stored
+
+			if err := store.Complete(context.WithoutCancel(ctx), rec); err != nil {
+				log.Error(ctx, "idempotency: complete", "key", rec.Key, "ERROR", err)
+				return stored
+			}
+
+			completed = true
+
+			return stored
+		}
+
+		return h
+	}
+
+	return m
+}
+
+// replay returns the stored response for a key that has already been used.
+func replay(ctx context.Context, rec idempotency.Record, existing idempotency.Record) web.Encoder {
+	switch {
+	case existing.Fingerprint != rec.Fingerprint:
+		return errs.Errorf(errs.UnprocessableEntity, "idempotency key has already been used for a different request")
+
+	case !existing.Completed:
+		return errs.Errorf(errs.Aborted, "a request with this idempotency key is still being processed")
+	}
+
+	if w := web.GetWriter(ctx); w != nil {
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
+
+	return existing.Response
+}
+
+// toIdempotencyResponse encodes the response so it can be stored.
+func toIdempotencyResponse(resp web.Encoder) (idempotency.Response, error) {
+	type httpStatus interface {
+		HTTPStatus() int
+	}
+
+	if _, ok := resp.(web.NoResponse); ok {
+		return idempotency.Response{}, errors.New("responses written by the handler can't be stored")
+	}
+
+	statusCode := http.StatusOK
+
+	switch v := resp.(type) {
+	case httpStatus:
+		statusCode = v.HTTPStatus()
+
+	default:
+		if resp == nil {
+			return idempotency.Response{StatusCode: http.StatusNoContent}, nil
+		}
+	}
+
+	data, contentType, err := resp.Encode()
+	if err != nil {
+		return idempotency.Response{}, err
+	}
+
+	stored := idempotency.Response{
+		StatusCode:  statusCode,
+		ContentType: contentType,
+		Body:        data,
+	}
+
+	return stored, nil
+}
@@ -0,0 +1,200 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/idempotency"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+type memStore struct {
+	mu          sync.Mutex
+	recs        map[string]idempotency.Record
+	completeErr error
+}
+
+func (s *memStore) Claim(ctx context.Context, rec idempotency.Record) (idempotency.Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, exists := s.recs[rec.Key]; exists && existing.ExpiresAt.After(rec.DateCreated) {
+		if existing.Completed || existing.LockedUntil.After(rec.DateCreated) {
+			return existing, false, nil
+		}
+	}
+
+	s.recs[rec.Key] = rec
+	return rec, true, nil
+}
+
+func (s *memStore) Complete(ctx context.Context, rec idempotency.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.completeErr != nil {
+		return s.completeErr
+	}
+
+	s.recs[rec.Key] = rec
+	return nil
+}
+
+func (s *memStore) Release(ctx context.Context, subjectID uuid.UUID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.recs[key].Completed {
+		return nil
+	}
+
+	delete(s.recs, key)
+	return nil
+}
+
+type product struct {
+	ID string
+}
+
+func (p product) Encode() ([]byte, string, error) {
+	return []byte(`{"id":"` + p.ID + `"}`), "application/json", nil
+}
+
+func Test_Idempotency(t *testing.T) {
+	log := logger.New(&strings.Builder{}, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	store := &memStore{recs: make(map[string]idempotency.Record)}
+
+	var calls int
+	var fail bool
+	handler := func(ctx context.Context, r *http.Request) web.Encoder {
+		calls++
+		if fail {
+			return errs.New(errs.Internal, errors.New("failed"))
+		}
+		return product{ID: uuid.NewString()}
+	}
+
+	h := mid.Idempotency(log, store, time.Hour, time.Minute)(handler)
+
+	send := func(key string, body string) web.Encoder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/products", strings.NewReader(body))
+		r.Header.Set(idempotency.Header, key)
+
+		return h(context.Background(), r)
+	}
+
+	first := send("key-1", `{"name":"a"}`)
+	second := send("key-1", `{"name":"a"}`)
+
+	if calls != 1 {
+		t.Fatalf("Should only call the handler once for a retry, got %d calls", calls)
+	}
+
+	data1, _, _ := first.Encode()
+	data2, _, _ := second.Encode()
+	if string(data1) != string(data2) {
+		t.Errorf("Should replay the stored response: got %s, exp %s", data2, data1)
+	}
+
+	resp := send("key-1", `{"name":"b"}`)
+	var appErr *errs.Error
+	if !errors.As(resp.(error), &appErr) || appErr.Code != errs.UnprocessableEntity {
+		t.Errorf("Should reject a key reused for a different payload, got %v", resp)
+	}
+
+	store.recs["key-2"] = idempotency.Record{
+		Key:         "key-2",
+		Fingerprint: idempotency.Fingerprint(http.MethodPost, "/v1/products", []byte(`{}`)),
+		LockedUntil: time.Now().Add(time.Minute),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	resp = send("key-2", `{}`)
+	if !errors.As(resp.(error), &appErr) || appErr.Code != errs.Aborted {
+		t.Errorf("Should reject a request while the key is in flight, got %v", resp)
+	}
+
+	fail = true
+	send("key-3", `{}`)
+	fail = false
+	send("key-3", `{}`)
+
+	if calls != 3 {
+		t.Errorf("Should allow a retry after a failed request, got %d calls", calls)
+	}
+}
+
+func Test_IdempotencyAbandoned(t *testing.T) {
+	log := logger.New(&strings.Builder{}, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	store := &memStore{recs: make(map[string]idempotency.Record)}
+
+	var calls int
+	var panics bool
+	handler := func(ctx context.Context, r *http.Request) web.Encoder {
+		calls++
+		if panics {
+			panic("handler failed")
+		}
+		return product{ID: uuid.NewString()}
+	}
+
+	h := mid.Idempotency(log, store, time.Hour, time.Minute)(handler)
+
+	send := func(key string) web.Encoder {
+		r := httptest.NewRequest(http.MethodPost, "/v1/products", strings.NewReader(`{}`))
+		r.Header.Set(idempotency.Header, key)
+
+		return h(context.Background(), r)
+	}
+
+	// A key whose lease has lapsed was claimed by a process that never
+	// finished the request.
+	store.recs["key-1"] = idempotency.Record{
+		Key:         "key-1",
+		Fingerprint: idempotency.Fingerprint(http.MethodPost, "/v1/products", []byte(`{}`)),
+		LockedUntil: time.Now().Add(-time.Second),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+
+	if _, ok := send("key-1").(error); ok || calls != 1 {
+		t.Errorf("Should take over a key whose lease has lapsed, got %d calls", calls)
+	}
+
+	panics = true
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("Should propagate the panic")
+			}
+		}()
+		send("key-2")
+	}()
+	panics = false
+
+	if _, exists := store.recs["key-2"]; exists {
+		t.Errorf("Should release the key when the handler panics")
+	}
+
+	store.completeErr = errors.New("store failed")
+	send("key-3")
+	store.completeErr = nil
+
+	if _, exists := store.recs["key-3"]; exists {
+		t.Errorf("Should release the key when the response can't be stored")
+	}
+
+	send("key-3")
+	if calls != 4 {
+		t.Errorf("Should allow a retry after the response couldn't be stored, got %d calls", calls)
+	}
+}
@@ -37,7 +37,7 @@ func Logger(log *logger.Logger) web.MidFunc {
 				}
 			}
 
-			log.Info(ctx, "request completed", "method", r.Method, "path", path, "remoteaddr", r.RemoteAddr,
+			log.Info(ctx, "request completed", "method", r.Method, "path", path, "route", route(r), "remoteaddr", r.RemoteAddr,
 				"statuscode", statusCode, "since", time.Since(now).String())
 
 			return resp
@@ -3,29 +3,32 @@ package mid
 import (
 	"context"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/ardanlabs/service/app/sdk/metrics"
 	"github.com/ardanlabs/service/foundation/web"
 )
 
-// Metrics updates program counters.
+// Metrics updates program counters and records the request count and
+// duration by method, route and status.
 func Metrics() web.MidFunc {
 	m := func(next web.HandlerFunc) web.HandlerFunc {
 		h := func(ctx context.Context, r *http.Request) web.Encoder {
 			ctx = metrics.Set(ctx)
 
-			resp := next(ctx, r)
+			now := time.Now()
 
-			n := metrics.AddRequests(ctx)
+			resp := next(ctx, r)
 
-			if n%1000 == 0 {
-				metrics.AddGoroutines(ctx)
-			}
+			metrics.AddRequests(ctx)
 
 			if checkIsError(resp) != nil {
 				metrics.AddErrors(ctx)
 			}
 
+			metrics.RecordRequest(ctx, r.Method, route(r), web.StatusCode(resp), time.Since(now))
+
 			return resp
 		}
 
@@ -34,3 +37,17 @@ func Metrics() web.MidFunc {
 
 	return m
 }
+
+// route returns the pattern that matched the request without the method so
+// the metrics aren't tagged with the ids in the path.
+func route(r *http.Request) string {
+	if r.Pattern == "" {
+		return "unknown"
+	}
+
+	if _, path, found := strings.Cut(r.Pattern, " "); found {
+		return path
+	}
+
+	return r.Pattern
+}
@@ -0,0 +1,90 @@
+package mid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/google/uuid"
+)
+
+// RateLimit restricts the number of requests each authenticated subject can
+// make to the route, so it must run after the authentication middleware. If
+// the store fails the request is allowed so the service remains available.
+// Without a store or a limit the middleware does nothing.
+func RateLimit(store ratelimit.Storer, limit ratelimit.Limit) web.MidFunc {
+	key := func(ctx context.Context, r *http.Request) (string, error) {
+		subjectID := GetSubjectID(ctx)
+		if subjectID == uuid.Nil {
+			return "", errors.New("no subject id, rate limit must run after authentication")
+		}
+
+		return "sub:" + subjectID.String(), nil
+	}
+
+	return rateLimit(store, limit, key)
+}
+
+// RateLimitIP restricts the number of requests each client IP address can
+// make to the route. It's used for the routes that are called before the
+// client is known, with the address resolved by clientIP so clients behind
+// a trusted proxy don't share a single bucket.
+func RateLimitIP(store ratelimit.Storer, limit ratelimit.Limit, clientIP ratelimit.ClientIP) web.MidFunc {
+	key := func(ctx context.Context, r *http.Request) (string, error) {
+		return "ip:" + clientIP.Resolve(r), nil
+	}
+
+	return rateLimit(store, limit, key)
+}
+
+func rateLimit(store ratelimit.Storer, limit ratelimit.Limit, key func(ctx context.Context, r *http.Request) (string, error)) web.MidFunc {
+	m := func(next web.HandlerFunc) web.HandlerFunc {
+		if store == nil || !limit.Enabled() {
+			return next
+		}
+
+		h := func(ctx context.Context, r *http.Request) web.Encoder {
+			k, err := key(ctx, r)
+			if err != nil {
+				return errs.New(errs.Internal, err)
+			}
+
+			res, err := store.Take(ctx, fmt.Sprintf("%s|%s", r.Pattern, k), limit)
+			if err != nil {
+				return next(ctx, r)
+			}
+
+			if w := web.GetWriter(ctx); w != nil {
+				w.Header().Set("RateLimit-Policy", limit.Policy())
+				w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+				w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+				w.Header().Set("RateLimit-Reset", ceilSeconds(res.Reset))
+
+				if !res.Allowed {
+					w.Header().Set("Retry-After", ceilSeconds(res.RetryAfter))
+				}
+			}
+
+			if !res.Allowed {
+				return errs.Errorf(errs.ResourceExhausted, "rate limit exceeded, retry after %s", res.RetryAfter.Round(time.Second))
+			}
+
+			return next(ctx, r)
+		}
+
+		return h
+	}
+
+	return m
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,154 @@
+package mid_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/auth"
+	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
+	"github.com/ardanlabs/service/foundation/web"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// authClient authenticates a request whose authorization header is the
+// subject id.
+type authClient struct{}
+
+func (authClient) Authenticate(ctx context.Context, authorization string) (authclient.AuthenticateResp, error) {
+	subjectID, err := uuid.Parse(authorization)
+	if err != nil {
+		return authclient.AuthenticateResp{}, errors.New("invalid token")
+	}
+
+	resp := authclient.AuthenticateResp{
+		UserID: subjectID,
+		Claims: auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: subjectID.String()}},
+	}
+
+	return resp, nil
+}
+
+func (authClient) Authorize(ctx context.Context, auth authclient.Authorize) error {
+	return nil
+}
+
+func (authClient) Close() error {
+	return nil
+}
+
+func newRateLimitApp(mw ...web.MidFunc) *web.App {
+	log := func(ctx context.Context, msg string, args ...any) {}
+	app := web.NewApp(log, noop.NewTracerProvider().Tracer(""))
+
+	h := func(ctx context.Context, r *http.Request) web.Encoder {
+		return nil
+	}
+
+	app.HandlerFunc(http.MethodGet, "v1", "/test", h, mw...)
+
+	return app
+}
+
+func Test_RateLimitSubject(t *testing.T) {
+	limit := ratelimit.Limit{Rate: 1, Period: time.Hour}
+	app := newRateLimitApp(mid.Authenticate(authClient{}), mid.RateLimit(ratelimit.NewMemoryStore(), limit))
+
+	send := func(subjectID uuid.UUID) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/test", nil)
+		r.RemoteAddr = "10.0.0.1:1234"
+		r.Header.Set("Authorization", subjectID.String())
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		return w
+	}
+
+	alice, bob := uuid.New(), uuid.New()
+
+	if w := send(alice); w.Code != http.StatusNoContent {
+		t.Fatalf("Should allow the first request, got %d", w.Code)
+	}
+
+	w := send(alice)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Should limit the second request of the subject, got %d", w.Code)
+	}
+
+	if got := w.Header().Get("Retry-After"); got != "3600" {
+		t.Errorf("Should get Retry-After 3600, got %q", got)
+	}
+
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("Should get RateLimit-Remaining 0, got %q", got)
+	}
+
+	// The subjects share the remote address, which is what happens behind
+	// a proxy, but each has its own bucket.
+	if w := send(bob); w.Code != http.StatusNoContent {
+		t.Errorf("Should allow another subject from the same address, got %d", w.Code)
+	}
+}
+
+func Test_RateLimitSubjectNotAuthenticated(t *testing.T) {
+	limit := ratelimit.Limit{Rate: 1, Period: time.Hour}
+	app := newRateLimitApp(mid.RateLimit(ratelimit.NewMemoryStore(), limit))
+
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/test", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Should fail when the limit runs before authentication, got %d", w.Code)
+	}
+}
+
+func Test_RateLimitIP(t *testing.T) {
+	clientIP, err := ratelimit.NewClientIP(ratelimit.HeaderForwardedFor, []string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("Should be able to construct the client ip: %s", err)
+	}
+
+	limit := ratelimit.Limit{Rate: 1, Period: time.Hour}
+	app := newRateLimitApp(mid.RateLimitIP(ratelimit.NewMemoryStore(), limit, clientIP))
+
+	send := func(remoteAddr string, forwardedFor string) int {
+		r := httptest.NewRequest(http.MethodGet, "/v1/test", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		return w.Code
+	}
+
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor string
+		statusCode   int
+	}{
+		{"first-client", "10.0.0.1:1234", "203.0.113.1", http.StatusNoContent},
+		{"first-client-again", "10.0.0.2:1234", "203.0.113.1", http.StatusTooManyRequests},
+		{"second-client-same-proxy", "10.0.0.1:1234", "203.0.113.2", http.StatusNoContent},
+		{"spoofed-behind-proxy", "10.0.0.1:1234", "203.0.113.9, 203.0.113.1", http.StatusTooManyRequests},
+		{"untrusted-remote", "198.51.100.1:1234", "203.0.113.3", http.StatusNoContent},
+		{"untrusted-remote-new-header", "198.51.100.1:1234", "203.0.113.4", http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		if got := send(tt.remoteAddr, tt.forwardedFor); got != tt.statusCode {
+			t.Errorf("%s: Should get status %d, got %d", tt.name, tt.statusCode, got)
+		}
+	}
+}
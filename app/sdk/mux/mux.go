@@ -8,9 +8,12 @@ import (
 
 	"github.com/ardanlabs/service/app/sdk/auth"
 	"github.com/ardanlabs/service/app/sdk/authclient"
+	"github.com/ardanlabs/service/app/sdk/idempotency"
 	"github.com/ardanlabs/service/app/sdk/mid"
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
 	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/jobbus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 	"github.com/ardanlabs/service/business/domain/vproductbus"
@@ -30,14 +33,53 @@ type StaticSite struct {
 
 // Options represent optional parameters.
 type Options struct {
-	corsOrigin []string
-	sites      []StaticSite
+	cors      *web.CORS
+	groupCORS map[string]web.CORS
+	hsts      *web.HSTS
+	secure    *web.SecureHeaders
+	rateLimit *ratelimit.Limit
+	sites     []StaticSite
 }
 
 // WithCORS provides configuration options for CORS.
-func WithCORS(origins []string) func(opts *Options) {
+func WithCORS(cors web.CORS) func(opts *Options) {
 	return func(opts *Options) {
-		opts.corsOrigin = origins
+		opts.cors = &cors
+	}
+}
+
+// WithGroupCORS provides configuration options for CORS that only apply
+// to the routes in the specified group.
+func WithGroupCORS(group string, cors web.CORS) func(opts *Options) {
+	return func(opts *Options) {
+		if opts.groupCORS == nil {
+			opts.groupCORS = make(map[string]web.CORS)
+		}
+		opts.groupCORS[group] = cors
+	}
+}
+
+// WithHSTS provides configuration options for the Strict-Transport-Security
+// header.
+func WithHSTS(hsts web.HSTS) func(opts *Options) {
+	return func(opts *Options) {
+		opts.hsts = &hsts
+	}
+}
+
+// WithSecureHeaders provides the policy for the security headers added to
+// every response.
+func WithSecureHeaders(secure web.SecureHeaders) func(opts *Options) {
+	return func(opts *Options) {
+		opts.secure = &secure
+	}
+}
+
+// WithRateLimit provides a limit applied to every route for each client IP
+// address using the rate limit store and client IP from the config.
+func WithRateLimit(limit ratelimit.Limit) func(opts *Options) {
+	return func(opts *Options) {
+		opts.rateLimit = &limit
 	}
 }
 
@@ -55,12 +97,15 @@ func WithFileServer(react bool, static embed.FS, dir string, path string) func(o
 
 // SalesConfig contains sales service specific config.
 type SalesConfig struct {
-	AuthClient authclient.Authenticator
+	AuthClient   authclient.Authenticator
+	Idempotency  idempotency.Config
+	SubjectLimit ratelimit.Limit
 }
 
 // AuthConfig contains auth service specific config.
 type AuthConfig struct {
-	Auth *auth.Auth
+	Auth       *auth.Auth
+	TokenLimit ratelimit.Limit
 }
 
 type BusConfig struct {
@@ -69,6 +114,7 @@ type BusConfig struct {
 	ProductBus  productbus.ExtBusiness
 	HomeBus     homebus.ExtBusiness
 	VProductBus vproductbus.ExtBusiness
+	JobBus      jobbus.ExtBusiness
 }
 
 // Config contains all the mandatory systems required by handlers.
@@ -77,6 +123,8 @@ type Config struct {
 	Log         *logger.Logger
 	DB          *sqlx.DB
 	Tracer      trace.Tracer
+	RateLimiter ratelimit.Storer
+	ClientIP    ratelimit.ClientIP
 	BusConfig   BusConfig
 	SalesConfig SalesConfig
 	AuthConfig  AuthConfig
@@ -90,24 +138,42 @@ type RouteAdder interface {
 
 // WebAPI constructs a http.Handler with all application routes bound.
 func WebAPI(cfg Config, routeAdder RouteAdder, options ...func(opts *Options)) http.Handler {
-	app := web.NewApp(
-		cfg.Log.Info,
-		cfg.Tracer,
+	var opts Options
+	for _, option := range options {
+		option(&opts)
+	}
+
+	mw := []web.MidFunc{
 		mid.Otel(cfg.Tracer),
 		mid.Logger(cfg.Log),
 		mid.Errors(cfg.Log),
 		mid.Metrics(),
 		mid.Panics(),
-	)
+	}
 
-	var opts Options
-	for _, option := range options {
-		option(&opts)
+	if opts.rateLimit != nil {
+		mw = append(mw, mid.RateLimitIP(cfg.RateLimiter, *opts.rateLimit, cfg.ClientIP))
+	}
+
+	app := web.NewApp(cfg.Log.Info, cfg.Tracer, mw...)
+
+	if opts.cors != nil && len(opts.cors.AllowedOrigins) > 0 {
+		app.EnableCORS(*opts.cors)
+	}
+
+	for group, cors := range opts.groupCORS {
+		app.EnableGroupCORS(group, cors)
+	}
+
+	if opts.hsts != nil && opts.hsts.MaxAge > 0 {
+		app.EnableHSTS(*opts.hsts)
 	}
 
-	if len(opts.corsOrigin) > 0 {
-		app.EnableCORS(opts.corsOrigin)
+	secure := web.DefaultSecureHeaders
+	if opts.secure != nil {
+		secure = *opts.secure
 	}
+	app.EnableSecureHeaders(secure)
 
 	routeAdder.Add(app, cfg)
 
@@ -0,0 +1,242 @@
+// Package openapi generates an OpenAPI 3 document from the routes registered
+// with the web framework and the documentation provided by the app layer.
+package openapi
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ardanlabs/service/app/sdk/errs"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+// Set of security schemes an operation can require.
+const (
+	SecurityBearer = "bearerAuth"
+	SecurityBasic  = "basicAuth"
+)
+
+// Param documents a query string parameter.
+type Param struct {
+	Name        string
+	Type        string
+	Format      string
+	Description string
+}
+
+// Operation documents a route registered by the app layer.
+type Operation struct {
+	Method      string
+	Path        string
+	Tag         string
+	Summary     string
+	Security    string
+	QueryParams []Param
+	OrderBy     map[string]string
+	Request     any
+	Response    any
+	Status      int
+}
+
+// Config provides the information needed to build a document.
+type Config struct {
+	Title       string
+	Description string
+	Version     string
+	Routes      []web.Route
+	Operations  []Operation
+}
+
+var pathParam = regexp.MustCompile(`{([^}]+)}`)
+
+// New constructs an OpenAPI document for the registered routes. Routes that
+// are registered but have no documentation are still listed. Documentation
+// for routes that are not registered is ignored.
+func New(cfg Config) Document {
+	docs := make(map[string]Operation, len(cfg.Operations))
+	for _, op := range cfg.Operations {
+		docs[op.Method+" "+op.Path] = op
+	}
+
+	sch := newSchemas()
+	errSchema := sch.of(errs.Error{})
+	security := make(map[string]SecurityScheme)
+
+	paths := make(map[string]PathItem)
+	for _, rt := range cfg.Routes {
+		pattern := rt.Pattern()
+
+		op, exists := docs[rt.Method+" "+pattern]
+		if !exists {
+			op = Operation{
+				Method: rt.Method,
+				Path:   pattern,
+				Tag:    rt.Group,
+			}
+		}
+
+		oo := OperationObject{
+			Summary:     op.Summary,
+			OperationID: operationID(rt.Method, pattern),
+			Parameters:  parameters(pattern, op),
+			Responses:   make(map[string]Response),
+		}
+
+		if op.Tag != "" {
+			oo.Tags = []string{op.Tag}
+		}
+
+		if op.Request != nil {
+			oo.RequestBody = &RequestBody{
+				Required: true,
+				Content: map[string]MediaType{
+					"application/json": {Schema: sch.of(op.Request)},
+				},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		switch {
+		case status == http.StatusNoContent || op.Response == nil:
+			oo.Responses[strconv.Itoa(status)] = Response{Description: http.StatusText(status)}
+
+		default:
+			oo.Responses[strconv.Itoa(status)] = Response{
+				Description: http.StatusText(status),
+				Content: map[string]MediaType{
+					"application/json": {Schema: sch.of(op.Response)},
+				},
+			}
+		}
+
+		errResp := func(status int) Response {
+			return Response{
+				Description: http.StatusText(status),
+				Content: map[string]MediaType{
+					"application/json": {Schema: errSchema},
+				},
+			}
+		}
+
+		if op.Request != nil || len(op.QueryParams) > 0 || op.OrderBy != nil {
+			oo.Responses[strconv.Itoa(http.StatusBadRequest)] = errResp(http.StatusBadRequest)
+		}
+
+		if op.Security != "" {
+			oo.Security = []map[string][]string{{op.Security: {}}}
+			oo.Responses[strconv.Itoa(http.StatusUnauthorized)] = errResp(http.StatusUnauthorized)
+			security[op.Security] = securityScheme(op.Security)
+		}
+
+		oo.Responses[strconv.Itoa(http.StatusInternalServerError)] = errResp(http.StatusInternalServerError)
+
+		item, exists := paths[pattern]
+		if !exists {
+			item = make(PathItem)
+			paths[pattern] = item
+		}
+
+		item[strings.ToLower(rt.Method)] = oo
+	}
+
+	doc := Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       cfg.Title,
+			Description: cfg.Description,
+			Version:     cfg.Version,
+		},
+		Paths: paths,
+		Components: Components{
+			Schemas:         sch.components,
+			SecuritySchemes: security,
+		},
+	}
+
+	return doc
+}
+
+// =============================================================================
+
+func parameters(pattern string, op Operation) []Parameter {
+	var params []Parameter
+
+	for _, match := range pathParam.FindAllStringSubmatch(pattern, -1) {
+		name := strings.TrimSuffix(match[1], "...")
+		if name == "$" {
+			continue
+		}
+
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	for _, qp := range op.QueryParams {
+		typ := qp.Type
+		if typ == "" {
+			typ = "string"
+		}
+
+		params = append(params, Parameter{
+			Name:        qp.Name,
+			In:          "query",
+			Description: qp.Description,
+			Schema:      &Schema{Type: typ, Format: qp.Format},
+		})
+	}
+
+	if op.OrderBy != nil {
+		fields := slices.Sorted(maps.Keys(op.OrderBy))
+
+		params = append(params, Parameter{
+			Name:        "orderBy",
+			In:          "query",
+			Description: fmt.Sprintf("Field to order by with an optional direction: %s", strings.Join(fields, ", ")),
+			Schema: &Schema{
+				Type:    "string",
+				Pattern: fmt.Sprintf(`^(%s)(,(ASC|DESC))?$`, strings.Join(fields, "|")),
+			},
+		})
+	}
+
+	return params
+}
+
+func operationID(method string, pattern string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+
+	f := func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}
+
+	for _, word := range strings.FieldsFunc(pattern, f) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	return b.String()
+}
+
+func securityScheme(name string) SecurityScheme {
+	switch name {
+	case SecurityBasic:
+		return SecurityScheme{Type: "http", Scheme: "basic"}
+
+	default:
+		return SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	}
+}
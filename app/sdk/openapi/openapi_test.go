@@ -0,0 +1,102 @@
+package openapi_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+	"github.com/ardanlabs/service/app/sdk/query"
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+type item struct {
+	ID     string   `json:"id"`
+	Tags   []string `json:"tags"`
+	Secret string   `json:"-"`
+	Count  *int     `json:"count,omitempty"`
+}
+
+func Test_New(t *testing.T) {
+	doc := openapi.New(openapi.Config{
+		Title:   "Test",
+		Version: "develop",
+		Routes: []web.Route{
+			{Method: http.MethodGet, Group: "v1", Path: "/items"},
+			{Method: http.MethodDelete, Group: "v1", Path: "/items/{item_id}"},
+			{Method: http.MethodGet, Group: "v1", Path: "/raw"},
+		},
+		Operations: []openapi.Operation{
+			{
+				Method:      http.MethodGet,
+				Path:        "/v1/items",
+				Security:    openapi.SecurityBearer,
+				QueryParams: []openapi.Param{{Name: "page", Type: "integer"}},
+				OrderBy:     map[string]string{"id": "id", "count": "count"},
+				Response:    query.Result[item]{},
+			},
+			{
+				Method: http.MethodDelete,
+				Path:   "/v1/items/{item_id}",
+				Status: http.StatusNoContent,
+			},
+			{
+				Method: http.MethodPost,
+				Path:   "/v1/notregistered",
+			},
+		},
+	})
+
+	if len(doc.Paths) != 3 {
+		t.Fatalf("Should get 3 paths, got %d", len(doc.Paths))
+	}
+
+	get := doc.Paths["/v1/items"]["get"]
+
+	if len(get.Parameters) != 2 {
+		t.Fatalf("Should get 2 parameters, got %d", len(get.Parameters))
+	}
+
+	orderBy := get.Parameters[1]
+	if exp := "^(count|id)(,(ASC|DESC))?$"; orderBy.Schema.Pattern != exp {
+		t.Errorf("Should get orderBy pattern %q, got %q", exp, orderBy.Schema.Pattern)
+	}
+
+	if _, exists := get.Responses["401"]; !exists {
+		t.Error("Should get a 401 response for a secured operation")
+	}
+
+	if _, exists := doc.Components.SecuritySchemes[openapi.SecurityBearer]; !exists {
+		t.Error("Should get the bearer security scheme")
+	}
+
+	result, exists := doc.Components.Schemas["query.Result_openapi_test.item"]
+	if !exists {
+		t.Fatalf("Should get the result schema, got %v", doc.Components.Schemas)
+	}
+
+	if ref := result.Properties["items"].Items.Ref; ref != "#/components/schemas/openapi_test.item" {
+		t.Errorf("Should reference the item schema, got %q", ref)
+	}
+
+	itm := doc.Components.Schemas["openapi_test.item"]
+	if _, exists := itm.Properties["Secret"]; exists {
+		t.Error("Should not include fields ignored by json")
+	}
+
+	if !itm.Properties["count"].Nullable {
+		t.Error("Should mark pointer fields as nullable")
+	}
+
+	del := doc.Paths["/v1/items/{item_id}"]["delete"]
+	if len(del.Parameters) != 1 || del.Parameters[0].In != "path" {
+		t.Errorf("Should get the item_id path parameter, got %+v", del.Parameters)
+	}
+
+	if _, exists := del.Responses["204"]; !exists {
+		t.Error("Should get a 204 response for delete")
+	}
+
+	if _, exists := doc.Paths["/v1/raw"]["get"]; !exists {
+		t.Error("Should document registered routes without operations")
+	}
+}
@@ -0,0 +1,69 @@
+// Package openapitest provides support for checking the OpenAPI
+// documentation against the code it documents.
+package openapitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/ardanlabs/service/app/sdk/openapi"
+)
+
+// QueryParams checks that the documented query parameters and the ones
+// read by parse are the same set. Every documented parameter must set
+// exactly one string field of the value parse returns, and every string
+// field must be set by a documented parameter. The undocumented names are
+// parameters read by parse that are documented some other way, such as
+// orderBy.
+func QueryParams[T any](t *testing.T, docs []openapi.Param, parse func(r *http.Request) T, undocumented ...string) {
+	t.Helper()
+
+	set := make(map[string]string)
+
+	check := func(name string) {
+		const value = "openapitest"
+
+		r := httptest.NewRequest(http.MethodGet, "/?"+url.Values{name: {value}}.Encode(), nil)
+		v := reflect.ValueOf(parse(r))
+
+		var fields []string
+		for i := range v.NumField() {
+			if f := v.Field(i); f.Kind() == reflect.String && f.String() == value {
+				fields = append(fields, v.Type().Field(i).Name)
+			}
+		}
+
+		switch len(fields) {
+		case 0:
+			t.Errorf("Should read the query parameter %q", name)
+
+		case 1:
+			if prev, exists := set[fields[0]]; exists {
+				t.Errorf("Should set field %s from one query parameter, got %q and %q", fields[0], prev, name)
+			}
+			set[fields[0]] = name
+
+		default:
+			t.Errorf("Should set one field from the query parameter %q, got %v", name, fields)
+		}
+	}
+
+	for _, p := range docs {
+		check(p.Name)
+	}
+
+	for _, name := range undocumented {
+		check(name)
+	}
+
+	typ := reflect.TypeFor[T]()
+	for i := range typ.NumField() {
+		f := typ.Field(i)
+		if _, exists := set[f.Name]; f.Type.Kind() == reflect.String && !exists {
+			t.Errorf("Should document the query parameter for field %s", f.Name)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"path"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	uuidType          = reflect.TypeFor[uuid.UUID]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+)
+
+// schemas generates schemas for Go types and collects the named struct types
+// as reusable components.
+type schemas struct {
+	components map[string]*Schema
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+	}
+}
+
+// of returns the schema for the specified value. Named struct types are
+// added to the components and a reference is returned.
+func (s *schemas) of(v any) *Schema {
+	return s.schema(reflect.TypeOf(v))
+}
+
+func (s *schemas) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+
+	case uuidType:
+		return &Schema{Type: "string", Format: "uuid"}
+	}
+
+	if t.Kind() == reflect.Pointer {
+		sch := s.schema(t.Elem())
+		if sch.Ref == "" {
+			sch.Nullable = true
+		}
+		return sch
+	}
+
+	// Types that marshal themselves as text are represented as strings. Types
+	// that provide their own JSON marshaling can't be described.
+	switch {
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+
+	case t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType):
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16:
+		return &Schema{Type: "integer"}
+
+	case reflect.Int32, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+
+	case reflect.String:
+		return &Schema{Type: "string"}
+
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.schema(t.Elem())}
+
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.schema(t.Elem())}
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+
+		name := componentName(t)
+		if _, exists := s.components[name]; !exists {
+
+			// Reserve the name first so recursive types terminate.
+			s.components[name] = &Schema{}
+			s.components[name] = s.object(t)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+
+	return &Schema{}
+}
+
+// object builds an object schema using the same field rules as the
+// encoding/json package.
+func (s *schemas) object(t reflect.Type) *Schema {
+	sch := Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	s.fields(t, &sch)
+
+	return &sch
+}
+
+func (s *schemas) fields(t reflect.Type, sch *Schema) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, _, _ := strings.Cut(tag, ",")
+
+		// Embedded structs without a name have their fields promoted.
+		if field.Anonymous && name == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				s.fields(ft, sch)
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		sch.Properties[name] = s.schema(field.Type)
+	}
+}
+
+// componentName produces a component name for the type that is qualified by
+// the package name. Generic types have their type arguments appended.
+func componentName(t reflect.Type) string {
+	name := t.Name()
+
+	if i := strings.Index(name, "["); i != -1 {
+		args := strings.Split(name[i+1:len(name)-1], ",")
+		for j, arg := range args {
+			args[j] = path.Base(strings.TrimLeft(arg, "*[]"))
+		}
+
+		name = name[:i] + "_" + strings.Join(args, "_")
+	}
+
+	return path.Base(t.PkgPath()) + "." + name
+}
@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// Set of headers a proxy can use to pass along the address of the client.
+const (
+	HeaderForwardedFor = "X-Forwarded-For"
+	HeaderRealIP       = "X-Real-IP"
+)
+
+// ClientIP resolves the address of the client that made a request. Behind
+// an ingress or load balancer the remote address is the proxy, so the
+// address is taken from the header the proxy sets. The header is only
+// trusted when the request comes from one of the trusted proxies, since
+// anyone else can set it to whatever they want.
+type ClientIP struct {
+	header  string
+	trusted []netip.Prefix
+}
+
+// NewClientIP constructs a ClientIP that reads the X-Forwarded-For or
+// X-Real-IP header from the trusted proxies, which are IP addresses or CIDR
+// ranges. Without a header the remote address of the request is used.
+func NewClientIP(header string, trustedProxies []string) (ClientIP, error) {
+	header = http.CanonicalHeaderKey(header)
+
+	switch header {
+	case "", http.CanonicalHeaderKey(HeaderForwardedFor), http.CanonicalHeaderKey(HeaderRealIP):
+	default:
+		return ClientIP{}, fmt.Errorf("unknown client ip header %q, expecting %s or %s", header, HeaderForwardedFor, HeaderRealIP)
+	}
+
+	trusted := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		if proxy == "" {
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			addr, aErr := netip.ParseAddr(proxy)
+			if aErr != nil {
+				return ClientIP{}, fmt.Errorf("parse trusted proxy %q: %w", proxy, err)
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+
+		trusted = append(trusted, prefix.Masked())
+	}
+
+	if header != "" && len(trusted) == 0 {
+		return ClientIP{}, fmt.Errorf("the %s header needs at least one trusted proxy", header)
+	}
+
+	ci := ClientIP{
+		header:  header,
+		trusted: trusted,
+	}
+
+	return ci, nil
+}
+
+// Resolve returns the address of the client. X-Forwarded-For is read from
+// the right, skipping the trusted proxies, so a client can't pick its own
+// address by adding entries to the front of the list.
+func (ci ClientIP) Resolve(r *http.Request) string {
+	remote := remoteAddr(r)
+
+	if ci.header == "" || !ci.isTrusted(remote) {
+		return remote
+	}
+
+	switch ci.header {
+	case http.CanonicalHeaderKey(HeaderRealIP):
+		if addr, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get(HeaderRealIP))); err == nil {
+			return addr.Unmap().String()
+		}
+
+	default:
+		hops := strings.Split(strings.Join(r.Header.Values(HeaderForwardedFor), ","), ",")
+
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+
+			client = addr.Unmap().String()
+			if !ci.isTrusted(client) {
+				break
+			}
+		}
+
+		return client
+	}
+
+	return remote
+}
+
+func (ci ClientIP) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	for _, prefix := range ci.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func remoteAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return addr.Unmap().String()
+	}
+
+	return host
+}
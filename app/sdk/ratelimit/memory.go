@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval defines how often buckets that are full again are removed
+// from the memory store.
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	limit  Limit
+}
+
+// MemoryStore keeps the buckets in memory. It's only suitable for a single
+// instance of a service since the quotas are not shared.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore constructs a store that keeps the buckets in memory.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Take implements the Storer interface.
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.lastSweep) > sweepInterval {
+		s.sweep(now)
+	}
+
+	capacity := float64(limit.Capacity())
+	refill := limit.refill()
+
+	b, exists := s.buckets[key]
+	switch {
+	case !exists:
+		b = &bucket{tokens: capacity, last: now, limit: limit}
+		s.buckets[key] = b
+
+	default:
+		b.tokens = math.Min(capacity, b.tokens+now.Sub(b.last).Seconds()*refill)
+		b.last = now
+		b.limit = limit
+	}
+
+	res := Result{
+		Limit: limit.Capacity(),
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	}
+
+	if !res.Allowed {
+		res.RetryAfter = seconds((1 - b.tokens) / refill)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / refill)
+
+	return res, nil
+}
+
+// sweep removes the buckets that have been refilled since they were last
+// used. Recreating them later produces the same result.
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		elapsed := now.Sub(b.last).Seconds()
+		if b.tokens+elapsed*b.limit.refill() >= float64(b.limit.Capacity()) {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
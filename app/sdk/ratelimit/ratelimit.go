@@ -0,0 +1,63 @@
+// Package ratelimit provides support for token bucket rate limiting with a
+// pluggable store for the buckets.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Limit defines the quota for a bucket. Rate tokens are added to the bucket
+// every Period and the bucket can hold at most Burst tokens. Burst defaults
+// to Rate when it's not provided.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// Enabled reports whether the limit has been configured.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Period > 0
+}
+
+// Capacity returns the maximum number of tokens the bucket can hold.
+func (l Limit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+
+	return l.Rate
+}
+
+// Policy returns the limit formatted for the RateLimit-Policy header.
+func (l Limit) Policy() string {
+	return fmt.Sprintf("%d;w=%d", l.Capacity(), int(l.Period.Seconds()))
+}
+
+// refill returns the number of tokens added per second.
+func (l Limit) refill() float64 {
+	return float64(l.Rate) / l.Period.Seconds()
+}
+
+// Result represents the state of a bucket after a token was requested.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Storer defines the behavior required to keep track of the buckets. Take
+// removes a token from the bucket identified by the key if one is available.
+type Storer interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Config contains the store and the limit applied to each subject.
+type Config struct {
+	Store Storer
+	Limit Limit
+}
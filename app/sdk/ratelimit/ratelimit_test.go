@@ -0,0 +1,137 @@
+package ratelimit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/app/sdk/ratelimit"
+)
+
+func Test_MemoryStore(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+
+	limit := ratelimit.Limit{
+		Rate:   1,
+		Period: time.Hour,
+		Burst:  2,
+	}
+
+	for i := range 2 {
+		res, err := store.Take(context.Background(), "key", limit)
+		if err != nil {
+			t.Fatalf("Should be able to take a token: %s", err)
+		}
+
+		if !res.Allowed {
+			t.Fatalf("Should allow request %d within the burst", i)
+		}
+
+		if exp := 1 - i; res.Remaining != exp {
+			t.Errorf("Should have %d tokens remaining, got %d", exp, res.Remaining)
+		}
+	}
+
+	res, err := store.Take(context.Background(), "key", limit)
+	if err != nil {
+		t.Fatalf("Should be able to take a token: %s", err)
+	}
+
+	if res.Allowed {
+		t.Fatalf("Should deny a request once the burst is used")
+	}
+
+	if res.RetryAfter <= 0 || res.RetryAfter > time.Hour {
+		t.Errorf("Should provide a retry after within the period, got %v", res.RetryAfter)
+	}
+
+	res, err = store.Take(context.Background(), "other", limit)
+	if err != nil {
+		t.Fatalf("Should be able to take a token: %s", err)
+	}
+
+	if !res.Allowed {
+		t.Errorf("Should track each key in its own bucket")
+	}
+}
+
+func Test_MemoryStoreRefill(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+
+	limit := ratelimit.Limit{
+		Rate:   1,
+		Period: 50 * time.Millisecond,
+	}
+
+	if res, _ := store.Take(context.Background(), "key", limit); !res.Allowed {
+		t.Fatalf("Should allow the first request")
+	}
+
+	if res, _ := store.Take(context.Background(), "key", limit); res.Allowed {
+		t.Fatalf("Should deny the second request")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if res, _ := store.Take(context.Background(), "key", limit); !res.Allowed {
+		t.Fatalf("Should allow a request once the bucket is refilled")
+	}
+}
+
+func Test_ClientIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		trusted    []string
+		remoteAddr string
+		headers    map[string]string
+		exp        string
+	}{
+		{"remote", "", nil, "203.0.113.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.1"},
+		{"forwarded-for", ratelimit.HeaderForwardedFor, []string{"10.0.0.0/8"}, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "198.51.100.1"},
+		{"forwarded-for-chain", ratelimit.HeaderForwardedFor, []string{"10.0.0.0/8"}, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "192.0.2.7, 198.51.100.1, 10.0.0.2"}, "198.51.100.1"},
+		{"forwarded-for-untrusted", ratelimit.HeaderForwardedFor, []string{"10.0.0.0/8"}, "203.0.113.1:1234", map[string]string{"X-Forwarded-For": "198.51.100.1"}, "203.0.113.1"},
+		{"forwarded-for-invalid", ratelimit.HeaderForwardedFor, []string{"10.0.0.1"}, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "unknown"}, "10.0.0.1"},
+		{"forwarded-for-all-trusted", ratelimit.HeaderForwardedFor, []string{"10.0.0.0/8"}, "10.0.0.1:1234", map[string]string{"X-Forwarded-For": "10.0.0.3, 10.0.0.2"}, "10.0.0.3"},
+		{"real-ip", ratelimit.HeaderRealIP, []string{"10.0.0.0/8"}, "10.0.0.1:1234", map[string]string{"X-Real-IP": "198.51.100.1"}, "198.51.100.1"},
+		{"real-ip-untrusted", ratelimit.HeaderRealIP, []string{"10.0.0.0/8"}, "203.0.113.1:1234", map[string]string{"X-Real-IP": "198.51.100.1"}, "203.0.113.1"},
+		{"real-ip-missing", ratelimit.HeaderRealIP, []string{"10.0.0.0/8"}, "10.0.0.1:1234", nil, "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		clientIP, err := ratelimit.NewClientIP(tt.header, tt.trusted)
+		if err != nil {
+			t.Fatalf("%s: Should be able to construct the client ip: %s", tt.name, err)
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for key, value := range tt.headers {
+			r.Header.Set(key, value)
+		}
+
+		if got := clientIP.Resolve(r); got != tt.exp {
+			t.Errorf("%s: Should resolve %s, got %s", tt.name, tt.exp, got)
+		}
+	}
+}
+
+func Test_NewClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		trusted []string
+	}{
+		{"unknown-header", "Forwarded", []string{"10.0.0.0/8"}},
+		{"no-trusted-proxy", ratelimit.HeaderForwardedFor, nil},
+		{"invalid-proxy", ratelimit.HeaderRealIP, []string{"proxy.local"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := ratelimit.NewClientIP(tt.header, tt.trusted); err == nil {
+			t.Errorf("%s: Should get an error", tt.name)
+		}
+	}
+}
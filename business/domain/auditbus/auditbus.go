@@ -63,6 +63,13 @@ func (b *Business) Create(ctx context.Context, na NewAudit) (Audit, error) {
 		return Audit{}, fmt.Errorf("marshal object: %w", err)
 	}
 
+	// Audit records are kept for a long time and are readable through the
+	// API so sensitive values must not be stored.
+	jsonData, err = logger.RedactJSON(jsonData)
+	if err != nil {
+		return Audit{}, fmt.Errorf("redact object: %w", err)
+	}
+
 	audit := Audit{
 		ID:        uuid.New(),
 		ObjID:     na.ObjID,
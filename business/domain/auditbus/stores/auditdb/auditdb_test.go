@@ -0,0 +1,21 @@
+package auditdb_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditdb"
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditstoretest"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	auditstoretest.Run(t, func(t *testing.T) auditstoretest.Harness {
+		db := dbtest.New(t, t.Name())
+
+		return auditstoretest.Harness{
+			Storer: auditdb.NewStore(db.Log, db.DB),
+		}
+	})
+}
@@ -0,0 +1,84 @@
+// Package auditmem contains audit related CRUD functionality backed by an
+// in-memory database.
+package auditmem
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Table is the name of the table that holds the audit records.
+const Table = "audit"
+
+// Store manages the set of APIs for audit in-memory access.
+type Store struct {
+	log    *logger.Logger
+	audits *memdb.Table[auditbus.Audit]
+}
+
+// NewStore constructs the API for data access.
+func NewStore(log *logger.Logger, db *memdb.DB) *Store {
+	return &Store{
+		log:    log,
+		audits: memdb.GetTable[auditbus.Audit](db, Table),
+	}
+}
+
+// Create inserts a new audit record into the database.
+func (s *Store) Create(ctx context.Context, a auditbus.Audit) error {
+	if err := s.audits.Insert(nil, a.ID, toMemAudit(a), nil); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing audit records from the database.
+func (s *Store) Query(ctx context.Context, filter auditbus.QueryFilter, orderBy order.By, page page.Page) ([]auditbus.Audit, error) {
+	audits := s.audits.Select(applyFilter(filter))
+
+	if err := memdb.Sort(audits, orderBy, orderByFields, auditID); err != nil {
+		return nil, err
+	}
+
+	return toBusAudits(memdb.Page(audits, page)), nil
+}
+
+// Count returns the total number of audit records in the DB.
+func (s *Store) Count(ctx context.Context, filter auditbus.QueryFilter) (int, error) {
+	return len(s.audits.Select(applyFilter(filter))), nil
+}
+
+// =============================================================================
+
+// toMemAudit copies the audit so the caller can't change the stored row and
+// normalizes the fields the way the database would.
+func toMemAudit(bus auditbus.Audit) auditbus.Audit {
+	a := bus
+	a.Data = slices.Clone(bus.Data)
+	a.Timestamp = memdb.Time(bus.Timestamp)
+
+	return a
+}
+
+func toBusAudits(audits []auditbus.Audit) []auditbus.Audit {
+	bus := make([]auditbus.Audit, len(audits))
+	for i, a := range audits {
+		bus[i] = a
+		bus[i].Data = slices.Clone(a.Data)
+	}
+
+	return bus
+}
+
+func auditID(a auditbus.Audit) uuid.UUID {
+	return a.ID
+}
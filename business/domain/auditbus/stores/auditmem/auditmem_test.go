@@ -0,0 +1,24 @@
+package auditmem_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditmem"
+	"github.com/ardanlabs/service/business/domain/auditbus/stores/auditstoretest"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	auditstoretest.Run(t, func(t *testing.T) auditstoretest.Harness {
+		return auditstoretest.Harness{
+			Storer: auditmem.NewStore(log, memdb.New()),
+		}
+	})
+}
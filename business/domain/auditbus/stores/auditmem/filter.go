@@ -0,0 +1,41 @@
+package auditmem
+
+import (
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+)
+
+func applyFilter(filter auditbus.QueryFilter) func(a auditbus.Audit) bool {
+	return func(a auditbus.Audit) bool {
+		if filter.ObjID != nil && a.ObjID != *filter.ObjID {
+			return false
+		}
+
+		if filter.ObjDomain != nil && a.ObjDomain.String() != filter.ObjDomain.String() {
+			return false
+		}
+
+		if filter.ObjName != nil && !strings.Contains(a.ObjName.String(), filter.ObjName.String()) {
+			return false
+		}
+
+		if filter.ActorID != nil && a.ActorID != *filter.ActorID {
+			return false
+		}
+
+		if filter.Action != nil && a.Action != *filter.Action {
+			return false
+		}
+
+		if filter.Since != nil && a.Timestamp.Before(*filter.Since) {
+			return false
+		}
+
+		if filter.Until != nil && a.Timestamp.After(*filter.Until) {
+			return false
+		}
+
+		return true
+	}
+}
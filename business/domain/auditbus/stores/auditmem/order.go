@@ -0,0 +1,26 @@
+package auditmem
+
+import (
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+)
+
+var orderByFields = map[string]memdb.CompareFunc[auditbus.Audit]{
+	auditbus.OrderByObjID: func(a auditbus.Audit, b auditbus.Audit) int {
+		return memdb.CompareID(a.ObjID, b.ObjID)
+	},
+	auditbus.OrderByObjDomain: func(a auditbus.Audit, b auditbus.Audit) int {
+		return strings.Compare(a.ObjDomain.String(), b.ObjDomain.String())
+	},
+	auditbus.OrderByObjName: func(a auditbus.Audit, b auditbus.Audit) int {
+		return strings.Compare(a.ObjName.String(), b.ObjName.String())
+	},
+	auditbus.OrderByActorID: func(a auditbus.Audit, b auditbus.Audit) int {
+		return memdb.CompareID(a.ActorID, b.ActorID)
+	},
+	auditbus.OrderByAction: func(a auditbus.Audit, b auditbus.Audit) int {
+		return strings.Compare(a.Action, b.Action)
+	},
+}
@@ -0,0 +1,239 @@
+// Package auditstoretest provides a conformance suite for implementations
+// of auditbus.Storer. Every store runs the same suite so they are known to
+// behave like auditdb:
+//
+//	func Test_Conformance(t *testing.T) {
+//	    auditstoretest.Run(t, func(t *testing.T) auditstoretest.Harness {
+//	        db := ... // an empty, migrated database owned by t
+//	        return auditstoretest.Harness{
+//	            Storer: NewStore(log, db),
+//	        }
+//	    })
+//	}
+//
+// Each case gets a new harness so the cases can't see each other's data.
+package auditstoretest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/domain"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+// Harness is the set of values the suite needs from a store. The store
+// must start out empty. Audits are only ever added, so unlike the other
+// domains there is no transaction or user support to provide.
+type Harness struct {
+	Storer auditbus.Storer
+}
+
+// Run executes the conformance suite against the harnesses returned by
+// newHarness, which is called once per case.
+func Run(t *testing.T, newHarness func(t *testing.T) Harness) {
+	table := []struct {
+		name string
+		test func(t *testing.T, s *suite)
+	}{
+		{"duplicate", duplicate},
+		{"filter", filter},
+		{"order", orderBy},
+		{"page", paging},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSuite(t, newHarness(t))
+			tt.test(t, s)
+		})
+	}
+}
+
+// =============================================================================
+
+// suite holds the data every case starts with. The audits are recorded an
+// hour apart against different domains, and the first two share an actor
+// so the filters can be checked for more than one match.
+type suite struct {
+	Harness
+	ctx    context.Context
+	now    time.Time
+	actor  uuid.UUID
+	audits []auditbus.Audit
+}
+
+func newSuite(t *testing.T, h Harness) *suite {
+	s := suite{
+		Harness: h,
+		ctx:     context.Background(),
+		now:     time.Now().Truncate(time.Second),
+		actor:   uuid.New(),
+	}
+
+	s.audits = []auditbus.Audit{
+		newAudit(s.actor, domain.User, "Alice Audit", "created", s.now.Add(-2*time.Hour)),
+		newAudit(s.actor, domain.Product, "Bob Audit", "updated", s.now.Add(-time.Hour)),
+		newAudit(uuid.New(), domain.Home, "Carol Audit", "deleted", s.now),
+	}
+
+	for _, a := range s.audits {
+		if err := s.Storer.Create(s.ctx, a); err != nil {
+			t.Fatalf("Should be able to create audit %s: %s", a.Action, err)
+		}
+	}
+
+	return &s
+}
+
+// jsonEqual compares the data of audits as documents, not bytes, since
+// stores like auditdb keep it in a JSONB column that reformats it.
+var jsonEqual = cmp.Transformer("json", func(data json.RawMessage) any {
+	var v any
+	json.Unmarshal(data, &v)
+	return v
+})
+
+// =============================================================================
+
+func duplicate(t *testing.T, s *suite) {
+	if err := s.Storer.Create(s.ctx, s.audits[0]); !errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+		t.Errorf("Should get ErrDBDuplicatedEntry, got %v", err)
+	}
+
+	if n := count(t, s, auditbus.QueryFilter{}); n != len(s.audits) {
+		t.Errorf("Should not add the duplicate audit, got count %d", n)
+	}
+}
+
+func filter(t *testing.T, s *suite) {
+	since := s.now.Add(-90 * time.Minute)
+	userDomain := domain.User
+	objName := name.MustParse("Audit")
+	action := "updated"
+	created, updated, deleted := s.audits[0], s.audits[1], s.audits[2]
+
+	table := []struct {
+		name   string
+		filter auditbus.QueryFilter
+		exp    []auditbus.Audit
+	}{
+		{"none", auditbus.QueryFilter{}, []auditbus.Audit{created, deleted, updated}},
+		{"obj-id", auditbus.QueryFilter{ObjID: &deleted.ObjID}, []auditbus.Audit{deleted}},
+		{"obj-domain", auditbus.QueryFilter{ObjDomain: &userDomain}, []auditbus.Audit{created}},
+		{"obj-name", auditbus.QueryFilter{ObjName: &objName}, []auditbus.Audit{created, deleted, updated}},
+		{"actor", auditbus.QueryFilter{ActorID: &s.actor}, []auditbus.Audit{created, updated}},
+		{"action", auditbus.QueryFilter{Action: &action}, []auditbus.Audit{updated}},
+		{"since", auditbus.QueryFilter{Since: &since}, []auditbus.Audit{deleted, updated}},
+		{"until", auditbus.QueryFilter{Until: &since}, []auditbus.Audit{created}},
+		{"actor-since", auditbus.QueryFilter{ActorID: &s.actor, Since: &since}, []auditbus.Audit{updated}},
+		{"no-match", auditbus.QueryFilter{Action: &action, Until: &since}, []auditbus.Audit{}},
+	}
+
+	orderBy := order.NewBy(auditbus.OrderByAction, order.ASC)
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, tt.filter, orderBy, page.MustParse("1", "10"))
+		if err != nil {
+			t.Fatalf("%s: Should be able to query: %s", tt.name, err)
+		}
+
+		if diff := cmp.Diff(got, tt.exp, jsonEqual); diff != "" {
+			t.Errorf("%s: Should get the expected audits:\n%s", tt.name, diff)
+		}
+
+		if n := count(t, s, tt.filter); n != len(tt.exp) {
+			t.Errorf("%s: Should get count %d, got %d", tt.name, len(tt.exp), n)
+		}
+	}
+}
+
+func orderBy(t *testing.T, s *suite) {
+	created, updated, deleted := s.audits[0], s.audits[1], s.audits[2]
+
+	table := []struct {
+		field string
+		dir   string
+		exp   []auditbus.Audit
+	}{
+		{auditbus.OrderByObjName, order.DESC, []auditbus.Audit{deleted, updated, created}},
+		{auditbus.OrderByAction, order.ASC, []auditbus.Audit{created, deleted, updated}},
+		{auditbus.OrderByObjDomain, order.ASC, []auditbus.Audit{deleted, updated, created}},
+	}
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, auditbus.QueryFilter{}, order.NewBy(tt.field, tt.dir), page.MustParse("1", "10"))
+		if err != nil {
+			t.Fatalf("%s %s: Should be able to query: %s", tt.field, tt.dir, err)
+		}
+
+		if diff := cmp.Diff(got, tt.exp, jsonEqual); diff != "" {
+			t.Errorf("%s %s: Should get the audits in order:\n%s", tt.field, tt.dir, diff)
+		}
+	}
+
+	if _, err := s.Storer.Query(s.ctx, auditbus.QueryFilter{}, order.NewBy("unknown", order.ASC), page.MustParse("1", "10")); err == nil {
+		t.Errorf("Should get an error for an unknown order field")
+	}
+}
+
+func paging(t *testing.T, s *suite) {
+	created, updated, deleted := s.audits[0], s.audits[1], s.audits[2]
+	orderBy := order.NewBy(auditbus.OrderByObjName, order.DESC)
+
+	table := []struct {
+		page page.Page
+		exp  []auditbus.Audit
+	}{
+		{page.MustParse("1", "2"), []auditbus.Audit{deleted, updated}},
+		{page.MustParse("2", "2"), []auditbus.Audit{created}},
+		{page.MustParse("3", "2"), []auditbus.Audit{}},
+	}
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, auditbus.QueryFilter{}, orderBy, tt.page)
+		if err != nil {
+			t.Fatalf("%s: Should be able to query: %s", tt.page, err)
+		}
+
+		if diff := cmp.Diff(got, tt.exp, jsonEqual); diff != "" {
+			t.Errorf("%s: Should get the page of audits:\n%s", tt.page, diff)
+		}
+	}
+}
+
+// =============================================================================
+
+func count(t *testing.T, s *suite, filter auditbus.QueryFilter) int {
+	t.Helper()
+
+	n, err := s.Storer.Count(s.ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	return n
+}
+
+func newAudit(actorID uuid.UUID, d domain.Domain, n string, action string, ts time.Time) auditbus.Audit {
+	return auditbus.Audit{
+		ID:        uuid.New(),
+		ObjID:     uuid.New(),
+		ObjDomain: d,
+		ObjName:   name.MustParse(n),
+		ActorID:   actorID,
+		Action:    action,
+		Data:      json.RawMessage(`{"name":"` + n + `"}`),
+		Message:   action + " " + n,
+		Timestamp: ts,
+	}
+}
@@ -0,0 +1,25 @@
+package homedb_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/service/business/domain/homebus/stores/homestoretest"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	homestoretest.Run(t, func(t *testing.T) homestoretest.Harness {
+		db := dbtest.New(t, t.Name())
+
+		return homestoretest.Harness{
+			Storer:   homedb.NewStore(db.Log, db.DB),
+			Beginner: sqldb.NewBeginner(db.DB),
+			Users:    userdb.NewStore(db.Log, db.DB),
+		}
+	})
+}
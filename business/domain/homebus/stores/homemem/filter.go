@@ -0,0 +1,31 @@
+package homemem
+
+import (
+	"github.com/ardanlabs/service/business/domain/homebus"
+)
+
+func applyFilter(filter homebus.QueryFilter) func(hme homebus.Home) bool {
+	return func(hme homebus.Home) bool {
+		if filter.ID != nil && hme.ID != *filter.ID {
+			return false
+		}
+
+		if filter.UserID != nil && hme.UserID != *filter.UserID {
+			return false
+		}
+
+		if filter.Type != nil && hme.Type.String() != filter.Type.String() {
+			return false
+		}
+
+		if filter.StartCreatedDate != nil && hme.DateCreated.Before(*filter.StartCreatedDate) {
+			return false
+		}
+
+		if filter.EndCreatedDate != nil && hme.DateCreated.After(*filter.EndCreatedDate) {
+			return false
+		}
+
+		return true
+	}
+}
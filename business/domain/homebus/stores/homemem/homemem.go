@@ -0,0 +1,140 @@
+// Package homemem contains home related CRUD functionality backed by an
+// in-memory database.
+package homemem
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Table is the name of the table that holds the homes.
+const Table = "homes"
+
+// Store manages the set of APIs for home in-memory access.
+type Store struct {
+	log   *logger.Logger
+	homes *memdb.Table[homebus.Home]
+	tx    *memdb.Tx
+}
+
+// NewStore constructs the api for data access. Homes reference the users
+// table so a home can only be created for an existing user and is deleted
+// with its user.
+func NewStore(log *logger.Logger, db *memdb.DB) *Store {
+	homes := memdb.GetTable[homebus.Home](db, Table)
+	memdb.ForeignKey(homes, usermem.Table, func(hme homebus.Home) uuid.UUID {
+		return hme.UserID
+	})
+
+	return &Store{
+		log:   log,
+		homes: homes,
+	}
+}
+
+// NewWithTx constructs a new Store value that records its writes in the
+// specified transaction so they can be rolled back.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (homebus.Storer, error) {
+	mtx, err := memdb.GetTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log:   s.log,
+		homes: s.homes,
+		tx:    mtx,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new home into the database.
+func (s *Store) Create(ctx context.Context, hme homebus.Home) error {
+	if err := s.homes.Insert(s.tx, hme.ID, toMemHome(hme), nil); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a home document in the database.
+func (s *Store) Update(ctx context.Context, hme homebus.Home) error {
+	set := func(row homebus.Home) homebus.Home {
+		upd := toMemHome(hme)
+		row.Address = upd.Address
+		row.Type = upd.Type
+		row.DateUpdated = upd.DateUpdated
+		return row
+	}
+
+	if err := s.homes.Update(s.tx, hme.ID, set, nil); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a home from the database.
+func (s *Store) Delete(ctx context.Context, hme homebus.Home) error {
+	if err := s.homes.Delete(s.tx, hme.ID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing homes from the database.
+func (s *Store) Query(ctx context.Context, filter homebus.QueryFilter, orderBy order.By, page page.Page) ([]homebus.Home, error) {
+	hmes := s.homes.Select(applyFilter(filter))
+
+	if err := memdb.Sort(hmes, orderBy, orderByFields, homeID); err != nil {
+		return nil, err
+	}
+
+	return memdb.Page(hmes, page), nil
+}
+
+// Count returns the total number of homes in the DB.
+func (s *Store) Count(ctx context.Context, filter homebus.QueryFilter) (int, error) {
+	return len(s.homes.Select(applyFilter(filter))), nil
+}
+
+// QueryByID gets the specified home from the database.
+func (s *Store) QueryByID(ctx context.Context, homeID uuid.UUID) (homebus.Home, error) {
+	hme, exists := s.homes.Get(homeID)
+	if !exists {
+		return homebus.Home{}, fmt.Errorf("db: %w", homebus.ErrNotFound)
+	}
+
+	return hme, nil
+}
+
+// QueryByUserID gets the specified home from the database by user id.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]homebus.Home, error) {
+	hmes := s.homes.Select(func(hme homebus.Home) bool {
+		return hme.UserID == userID
+	})
+
+	return hmes, nil
+}
+
+// =============================================================================
+
+// toMemHome normalizes the fields the way the database would.
+func toMemHome(bus homebus.Home) homebus.Home {
+	hme := bus
+	hme.DateCreated = memdb.Time(bus.DateCreated)
+	hme.DateUpdated = memdb.Time(bus.DateUpdated)
+
+	return hme
+}
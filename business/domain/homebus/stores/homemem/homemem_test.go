@@ -0,0 +1,29 @@
+package homemem_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/homebus/stores/homemem"
+	"github.com/ardanlabs/service/business/domain/homebus/stores/homestoretest"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	homestoretest.Run(t, func(t *testing.T) homestoretest.Harness {
+		db := memdb.New()
+
+		return homestoretest.Harness{
+			Storer:   homemem.NewStore(log, db),
+			Beginner: db,
+			Users:    usermem.NewStore(log, db),
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package homemem
+
+import (
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/google/uuid"
+)
+
+var orderByFields = map[string]memdb.CompareFunc[homebus.Home]{
+	homebus.OrderByID: func(a homebus.Home, b homebus.Home) int {
+		return memdb.CompareID(a.ID, b.ID)
+	},
+	homebus.OrderByType: func(a homebus.Home, b homebus.Home) int {
+		return strings.Compare(a.Type.String(), b.Type.String())
+	},
+	homebus.OrderByUserID: func(a homebus.Home, b homebus.Home) int {
+		return memdb.CompareID(a.UserID, b.UserID)
+	},
+}
+
+func homeID(hme homebus.Home) uuid.UUID {
+	return hme.ID
+}
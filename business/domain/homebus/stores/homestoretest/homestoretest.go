@@ -0,0 +1,405 @@
+// Package homestoretest provides a conformance suite for implementations
+// of homebus.Storer. Every store runs the same suite so they are known to
+// behave like homedb:
+//
+//	func Test_Conformance(t *testing.T) {
+//	    homestoretest.Run(t, func(t *testing.T) homestoretest.Harness {
+//	        db := ... // an empty, migrated database owned by t
+//	        return homestoretest.Harness{
+//	            Storer:   NewStore(log, db),
+//	            Beginner: sqldb.NewBeginner(db),
+//	            Users:    ...,
+//	        }
+//	    })
+//	}
+//
+// Each case gets a new harness so the cases can't see each other's data.
+package homestoretest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userstoretest"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/home"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+// Harness is the set of values the suite needs from a store. The store
+// must start out empty and the users are added to the same database since
+// homes reference their user.
+type Harness struct {
+	Storer   homebus.Storer
+	Beginner sqldb.Beginner
+	Users    userstoretest.Users
+}
+
+// Run executes the conformance suite against the harnesses returned by
+// newHarness, which is called once per case.
+func Run(t *testing.T, newHarness func(t *testing.T) Harness) {
+	table := []struct {
+		name string
+		test func(t *testing.T, s *suite)
+	}{
+		{"create", create},
+		{"not-found", notFound},
+		{"filter", filter},
+		{"order", orderBy},
+		{"page", paging},
+		{"update", update},
+		{"delete", deleteHome},
+		{"delete-user", deleteUser},
+		{"rollback", rollback},
+		{"commit", commit},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSuite(t, newHarness(t))
+			tt.test(t, s)
+		})
+	}
+}
+
+// =============================================================================
+
+// suite holds the data every case starts with. The homes are created an
+// hour apart in distinct cities, and two of them belong to the first user
+// and two are single family homes so the filters can be checked for more
+// than one match.
+type suite struct {
+	Harness
+	ctx   context.Context
+	now   time.Time
+	users []userbus.User
+	homes []homebus.Home
+}
+
+func newSuite(t *testing.T, h Harness) *suite {
+	s := suite{
+		Harness: h,
+		ctx:     context.Background(),
+		now:     time.Now().Truncate(time.Second),
+	}
+
+	s.users = []userbus.User{
+		userstoretest.NewUser("Alice Owner", "alice@storetest.com", s.now),
+		userstoretest.NewUser("Bob Owner", "bob@storetest.com", s.now),
+	}
+
+	for _, usr := range s.users {
+		if err := s.Users.Create(s.ctx, usr); err != nil {
+			t.Fatalf("Should be able to create user %s: %s", usr.Email.Address, err)
+		}
+	}
+
+	s.homes = []homebus.Home{
+		newHome(s.users[0].ID, home.Single, "Miami", s.now.Add(-2*time.Hour)),
+		newHome(s.users[0].ID, home.Condo, "Boston", s.now.Add(-time.Hour)),
+		newHome(s.users[1].ID, home.Single, "Denver", s.now),
+	}
+
+	for _, hme := range s.homes {
+		if err := s.Storer.Create(s.ctx, hme); err != nil {
+			t.Fatalf("Should be able to create home %s: %s", hme.Address.City, err)
+		}
+	}
+
+	return &s
+}
+
+// =============================================================================
+
+func create(t *testing.T, s *suite) {
+	for _, exp := range s.homes {
+		got, err := s.Storer.QueryByID(s.ctx, exp.ID)
+		if err != nil {
+			t.Fatalf("Should be able to query by id: %s", err)
+		}
+
+		if diff := cmp.Diff(got, exp); diff != "" {
+			t.Errorf("Should get back the same home:\n%s", diff)
+		}
+	}
+
+	got, err := s.Storer.QueryByUserID(s.ctx, s.users[0].ID)
+	if err != nil {
+		t.Fatalf("Should be able to query by user id: %s", err)
+	}
+
+	if diff := cmp.Diff(cities(got), []string{"Boston", "Miami"}); diff != "" {
+		t.Errorf("Should get the homes of the user:\n%s", diff)
+	}
+}
+
+func notFound(t *testing.T, s *suite) {
+	if _, err := s.Storer.QueryByID(s.ctx, uuid.New()); !errors.Is(err, homebus.ErrNotFound) {
+		t.Errorf("Should get ErrNotFound, got %v", err)
+	}
+
+	got, err := s.Storer.QueryByUserID(s.ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("Should be able to query by an unknown user id: %s", err)
+	}
+
+	if len(got) != 0 {
+		t.Errorf("Should get no homes for an unknown user, got %d", len(got))
+	}
+}
+
+func filter(t *testing.T, s *suite) {
+	start := s.now.Add(-90 * time.Minute)
+	single := home.Single
+	house, flat, cabin := s.homes[0], s.homes[1], s.homes[2]
+
+	table := []struct {
+		name   string
+		filter homebus.QueryFilter
+		exp    []homebus.Home
+	}{
+		{"none", homebus.QueryFilter{}, []homebus.Home{cabin, flat, house}},
+		{"id", homebus.QueryFilter{ID: &flat.ID}, []homebus.Home{flat}},
+		{"user", homebus.QueryFilter{UserID: &s.users[0].ID}, []homebus.Home{flat, house}},
+		{"type", homebus.QueryFilter{Type: &single}, []homebus.Home{cabin, house}},
+		{"start", homebus.QueryFilter{StartCreatedDate: &start}, []homebus.Home{cabin, flat}},
+		{"end", homebus.QueryFilter{EndCreatedDate: &start}, []homebus.Home{house}},
+		{"user-type", homebus.QueryFilter{UserID: &s.users[0].ID, Type: &single}, []homebus.Home{house}},
+		{"no-match", homebus.QueryFilter{UserID: &s.users[1].ID, EndCreatedDate: &start}, []homebus.Home{}},
+	}
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, tt.filter, homebus.DefaultOrderBy, page.MustParse("1", "10"))
+		if err != nil {
+			t.Fatalf("%s: Should be able to query: %s", tt.name, err)
+		}
+
+		if diff := cmp.Diff(cities(got), cities(tt.exp)); diff != "" {
+			t.Errorf("%s: Should get the expected homes:\n%s", tt.name, diff)
+		}
+
+		if n := count(t, s, tt.filter); n != len(tt.exp) {
+			t.Errorf("%s: Should get count %d, got %d", tt.name, len(tt.exp), n)
+		}
+	}
+}
+
+func orderBy(t *testing.T, s *suite) {
+	got, err := s.Storer.Query(s.ctx, homebus.QueryFilter{}, order.NewBy(homebus.OrderByType, order.DESC), page.MustParse("1", "10"))
+	if err != nil {
+		t.Fatalf("Should be able to query: %s", err)
+	}
+
+	if len(got) != 3 || got[0].Type != home.Single || got[1].Type != home.Single || got[2].Type != home.Condo {
+		t.Errorf("Should get the single family homes first, got %v", cities(got))
+	}
+
+	if _, err := s.Storer.Query(s.ctx, homebus.QueryFilter{}, order.NewBy("unknown", order.ASC), page.MustParse("1", "10")); err == nil {
+		t.Errorf("Should get an error for an unknown order field")
+	}
+}
+
+func paging(t *testing.T, s *suite) {
+	orderBy := order.NewBy(homebus.OrderByType, order.DESC)
+
+	table := []struct {
+		page page.Page
+		exp  []string
+	}{
+		{page.MustParse("1", "2"), []string{"Denver", "Miami"}},
+		{page.MustParse("2", "2"), []string{"Boston"}},
+		{page.MustParse("3", "2"), []string{}},
+	}
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, homebus.QueryFilter{}, orderBy, tt.page)
+		if err != nil {
+			t.Fatalf("%s: Should be able to query: %s", tt.page, err)
+		}
+
+		if diff := cmp.Diff(cities(got), tt.exp); diff != "" {
+			t.Errorf("%s: Should get the page of homes:\n%s", tt.page, diff)
+		}
+	}
+}
+
+func update(t *testing.T, s *suite) {
+	cabin := s.homes[2]
+
+	upd := cabin
+	upd.Type = home.Condo
+	upd.Address.City = "Aspen"
+	upd.DateUpdated = s.now.Add(time.Hour)
+
+	// The owner and date created are not something that can be updated.
+	upd.UserID = s.users[0].ID
+	upd.DateCreated = s.now.Add(time.Hour)
+
+	if err := s.Storer.Update(s.ctx, upd); err != nil {
+		t.Fatalf("Should be able to update: %s", err)
+	}
+
+	got, err := s.Storer.QueryByID(s.ctx, cabin.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query by id: %s", err)
+	}
+
+	upd.UserID = cabin.UserID
+	upd.DateCreated = cabin.DateCreated
+	if diff := cmp.Diff(got, upd); diff != "" {
+		t.Errorf("Should get back the updated home:\n%s", diff)
+	}
+}
+
+func deleteHome(t *testing.T, s *suite) {
+	if err := s.Storer.Delete(s.ctx, s.homes[0]); err != nil {
+		t.Fatalf("Should be able to delete: %s", err)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, s.homes[0].ID); !errors.Is(err, homebus.ErrNotFound) {
+		t.Errorf("Should not find the deleted home, got %v", err)
+	}
+
+	if n := count(t, s, homebus.QueryFilter{}); n != 2 {
+		t.Errorf("Should only delete the one home, got %d left", n)
+	}
+}
+
+func deleteUser(t *testing.T, s *suite) {
+	if err := s.Users.Delete(s.ctx, s.users[1]); err != nil {
+		t.Fatalf("Should be able to delete the user: %s", err)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, s.homes[2].ID); !errors.Is(err, homebus.ErrNotFound) {
+		t.Errorf("Should delete the homes of a deleted user, got %v", err)
+	}
+
+	if n := count(t, s, homebus.QueryFilter{}); n != 2 {
+		t.Errorf("Should keep the homes of the other user, got %d", n)
+	}
+}
+
+func rollback(t *testing.T, s *suite) {
+	lodge := newHome(s.users[1].ID, home.Single, "Vail", s.now)
+
+	tx, txStorer := begin(t, s)
+
+	if err := txStorer.Create(s.ctx, lodge); err != nil {
+		t.Fatalf("Should be able to create in the transaction: %s", err)
+	}
+
+	if err := txStorer.Delete(s.ctx, s.homes[0]); err != nil {
+		t.Fatalf("Should be able to delete in the transaction: %s", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Should be able to rollback: %s", err)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, lodge.ID); !errors.Is(err, homebus.ErrNotFound) {
+		t.Errorf("Should not find the home created in a rolled back transaction, got %v", err)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, s.homes[0].ID); err != nil {
+		t.Errorf("Should find the home deleted in a rolled back transaction: %s", err)
+	}
+}
+
+func commit(t *testing.T, s *suite) {
+	lodge := newHome(s.users[1].ID, home.Single, "Vail", s.now)
+
+	tx, txStorer := begin(t, s)
+
+	if err := txStorer.Create(s.ctx, lodge); err != nil {
+		t.Fatalf("Should be able to create in the transaction: %s", err)
+	}
+
+	if err := txStorer.Delete(s.ctx, s.homes[0]); err != nil {
+		t.Fatalf("Should be able to delete in the transaction: %s", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Should be able to commit: %s", err)
+	}
+
+	got, err := s.Storer.QueryByID(s.ctx, lodge.ID)
+	if err != nil {
+		t.Fatalf("Should find the home created in a committed transaction: %s", err)
+	}
+
+	if diff := cmp.Diff(got, lodge); diff != "" {
+		t.Errorf("Should get back the committed home:\n%s", diff)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, s.homes[0].ID); !errors.Is(err, homebus.ErrNotFound) {
+		t.Errorf("Should not find the home deleted in a committed transaction, got %v", err)
+	}
+}
+
+// =============================================================================
+
+func begin(t *testing.T, s *suite) (sqldb.CommitRollbacker, homebus.Storer) {
+	t.Helper()
+
+	tx, err := s.Beginner.Begin()
+	if err != nil {
+		t.Fatalf("Should be able to begin: %s", err)
+	}
+
+	txStorer, err := s.Storer.NewWithTx(tx)
+	if err != nil {
+		tx.Rollback()
+		t.Fatalf("Should be able to use the transaction: %s", err)
+	}
+
+	return tx, txStorer
+}
+
+func count(t *testing.T, s *suite, filter homebus.QueryFilter) int {
+	t.Helper()
+
+	n, err := s.Storer.Count(s.ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	return n
+}
+
+func newHome(userID uuid.UUID, typ home.Home, city string, created time.Time) homebus.Home {
+	return homebus.Home{
+		ID:     uuid.New(),
+		UserID: userID,
+		Type:   typ,
+		Address: homebus.Address{
+			Address1: "1 Main St",
+			ZipCode:  "12345",
+			City:     city,
+			State:    "ST",
+			Country:  "US",
+		},
+		DateCreated: created,
+		DateUpdated: created,
+	}
+}
+
+// cities returns the set of cities sorted so homes of the same type can be
+// compared without depending on the order of the random ids.
+func cities(hmes []homebus.Home) []string {
+	cs := make([]string, len(hmes))
+	for i, hme := range hmes {
+		cs[i] = hme.Address.City
+	}
+
+	slices.Sort(cs)
+
+	return cs
+}
@@ -0,0 +1,145 @@
+// Package jobotel provides an extension for jobbus that adds
+// otel tracking.
+package jobotel
+
+import (
+	"context"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/google/uuid"
+)
+
+// Extension provides a wrapper for otel functionality around the jobbus.
+type Extension struct {
+	bus jobbus.ExtBusiness
+}
+
+// NewExtension constructs a new extension that wraps the jobbus with otel.
+func NewExtension() jobbus.Extension {
+	return func(bus jobbus.ExtBusiness) jobbus.ExtBusiness {
+		return &Extension{
+			bus: bus,
+		}
+	}
+}
+
+// Enqueue adds a new job to the queue.
+func (ext *Extension) Enqueue(ctx context.Context, nj jobbus.NewJob) (jobbus.Job, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.enqueue")
+	defer span.End()
+
+	job, err := ext.bus.Enqueue(ctx, nj)
+	if err != nil {
+		return jobbus.Job{}, err
+	}
+
+	return job, nil
+}
+
+// Claim locks the jobs that are due to run.
+func (ext *Extension) Claim(ctx context.Context, types []string, limit int, lease time.Duration) ([]jobbus.Job, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.claim")
+	defer span.End()
+
+	jobs, err := ext.bus.Claim(ctx, types, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Complete marks a running job as succeeded.
+func (ext *Extension) Complete(ctx context.Context, job jobbus.Job) (jobbus.Job, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.complete")
+	defer span.End()
+
+	job, err := ext.bus.Complete(ctx, job)
+	if err != nil {
+		return jobbus.Job{}, err
+	}
+
+	return job, nil
+}
+
+// Fail records the error from a running job.
+func (ext *Extension) Fail(ctx context.Context, job jobbus.Job, jobErr error, retryAt time.Time) (jobbus.Job, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.fail")
+	defer span.End()
+
+	job, err := ext.bus.Fail(ctx, job, jobErr, retryAt)
+	if err != nil {
+		return jobbus.Job{}, err
+	}
+
+	return job, nil
+}
+
+// Retry queues a dead or cancelled job to run again.
+func (ext *Extension) Retry(ctx context.Context, job jobbus.Job) (jobbus.Job, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.retry")
+	defer span.End()
+
+	job, err := ext.bus.Retry(ctx, job)
+	if err != nil {
+		return jobbus.Job{}, err
+	}
+
+	return job, nil
+}
+
+// Cancel stops a queued job from running.
+func (ext *Extension) Cancel(ctx context.Context, job jobbus.Job) (jobbus.Job, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.cancel")
+	defer span.End()
+
+	job, err := ext.bus.Cancel(ctx, job)
+	if err != nil {
+		return jobbus.Job{}, err
+	}
+
+	return job, nil
+}
+
+// Query retrieves a list of existing jobs.
+func (ext *Extension) Query(ctx context.Context, filter jobbus.QueryFilter, orderBy order.By, page page.Page) ([]jobbus.Job, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.query")
+	defer span.End()
+
+	jobs, err := ext.bus.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return jobs, nil
+}
+
+// Count returns the total number of jobs.
+func (ext *Extension) Count(ctx context.Context, filter jobbus.QueryFilter) (int, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.count")
+	defer span.End()
+
+	count, err := ext.bus.Count(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// QueryByID finds the job by the specified ID.
+func (ext *Extension) QueryByID(ctx context.Context, jobID uuid.UUID) (jobbus.Job, error) {
+	ctx, span := otel.AddSpan(ctx, "business.jobbus.querybyid")
+	defer span.End()
+
+	job, err := ext.bus.QueryByID(ctx, jobID)
+	if err != nil {
+		return jobbus.Job{}, err
+	}
+
+	return job, nil
+}
@@ -0,0 +1,18 @@
+package jobbus
+
+import (
+	"time"
+
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/google/uuid"
+)
+
+// QueryFilter holds the available fields a query can be filtered on.
+// We are using pointer semantics because the With API mutates the value.
+type QueryFilter struct {
+	ID     *uuid.UUID
+	Type   *string
+	Status *jobstatus.Status
+	Since  *time.Time
+	Until  *time.Time
+}
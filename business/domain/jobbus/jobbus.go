@@ -0,0 +1,260 @@
+// Package jobbus provides business access to the durable background job
+// queue.
+package jobbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrNotFound       = errors.New("job not found")
+	ErrStatusChanged  = errors.New("job status changed")
+	ErrNotRetryable   = errors.New("job is not dead or cancelled")
+	ErrNotCancellable = errors.New("job is not queued")
+	ErrMissingType    = errors.New("job type is required")
+)
+
+// DefaultMaxAttempts is the number of times a job is attempted before it is
+// dead-lettered when the job doesn't specify a value.
+const DefaultMaxAttempts = 5
+
+// maxErrorLen limits the size of the last error kept for a job.
+const maxErrorLen = 4096
+
+// Storer interface declares the behavior this package needs to persist and
+// retrieve data.
+type Storer interface {
+	Create(ctx context.Context, job Job) error
+	Update(ctx context.Context, job Job, from jobstatus.Status) error
+	Claim(ctx context.Context, types []string, limit int, now time.Time, lockedUntil time.Time) ([]Job, error)
+	ExpireLeases(ctx context.Context, now time.Time) error
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Job, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, jobID uuid.UUID) (Job, error)
+}
+
+// ExtBusiness interface provides support for extensions that wrap extra functionality
+// around the core business logic.
+type ExtBusiness interface {
+	Enqueue(ctx context.Context, nj NewJob) (Job, error)
+	Claim(ctx context.Context, types []string, limit int, lease time.Duration) ([]Job, error)
+	Complete(ctx context.Context, job Job) (Job, error)
+	Fail(ctx context.Context, job Job, jobErr error, retryAt time.Time) (Job, error)
+	Retry(ctx context.Context, job Job) (Job, error)
+	Cancel(ctx context.Context, job Job) (Job, error)
+	Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Job, error)
+	Count(ctx context.Context, filter QueryFilter) (int, error)
+	QueryByID(ctx context.Context, jobID uuid.UUID) (Job, error)
+}
+
+// Extension is a function that wraps a new layer of business logic
+// around the existing business logic.
+type Extension func(ExtBusiness) ExtBusiness
+
+// Business manages the set of APIs for job access.
+type Business struct {
+	log    *logger.Logger
+	storer Storer
+}
+
+// NewBusiness constructs a job business API for use.
+func NewBusiness(log *logger.Logger, storer Storer, extensions ...Extension) ExtBusiness {
+	b := ExtBusiness(&Business{
+		log:    log,
+		storer: storer,
+	})
+
+	for i := len(extensions) - 1; i >= 0; i-- {
+		ext := extensions[i]
+		if ext != nil {
+			b = ext(b)
+		}
+	}
+
+	return b
+}
+
+// Enqueue adds a new job to the queue.
+func (b *Business) Enqueue(ctx context.Context, nj NewJob) (Job, error) {
+	if nj.Type == "" {
+		return Job{}, ErrMissingType
+	}
+
+	payload, err := json.Marshal(nj.Payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("marshal payload: %w", err)
+	}
+
+	now := time.Now()
+
+	scheduledAt := nj.ScheduledAt
+	if scheduledAt.IsZero() {
+		scheduledAt = now
+	}
+
+	maxAttempts := nj.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	job := Job{
+		ID:          uuid.New(),
+		Type:        nj.Type,
+		Payload:     payload,
+		Status:      jobstatus.Queued,
+		MaxAttempts: maxAttempts,
+		ScheduledAt: scheduledAt,
+		DateCreated: now,
+		DateUpdated: now,
+	}
+
+	if err := b.storer.Create(ctx, job); err != nil {
+		return Job{}, fmt.Errorf("create: %w", err)
+	}
+
+	return job, nil
+}
+
+// Claim locks up to limit jobs of the specified types that are due to run
+// and marks them as running for the duration of the lease. Jobs whose lease
+// expired without being completed are claimed again, or dead-lettered when
+// they have no attempts left.
+func (b *Business) Claim(ctx context.Context, types []string, limit int, lease time.Duration) ([]Job, error) {
+	if len(types) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+
+	if err := b.storer.ExpireLeases(ctx, now); err != nil {
+		return nil, fmt.Errorf("expire leases: %w", err)
+	}
+
+	jobs, err := b.storer.Claim(ctx, types, limit, now, now.Add(lease))
+	if err != nil {
+		return nil, fmt.Errorf("claim: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Complete marks a running job as succeeded.
+func (b *Business) Complete(ctx context.Context, job Job) (Job, error) {
+	from := job.Status
+
+	job.Status = jobstatus.Succeeded
+	job.LockedUntil = time.Time{}
+	job.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, job, from); err != nil {
+		return Job{}, fmt.Errorf("update: %w", err)
+	}
+
+	return job, nil
+}
+
+// Fail records the error from a running job. The job is queued again to run
+// at retryAt, or dead-lettered when it has no attempts left.
+func (b *Business) Fail(ctx context.Context, job Job, jobErr error, retryAt time.Time) (Job, error) {
+	from := job.Status
+
+	job.LastError = jobErr.Error()
+	if len(job.LastError) > maxErrorLen {
+		job.LastError = job.LastError[:maxErrorLen]
+	}
+
+	switch {
+	case job.Attempts >= job.MaxAttempts:
+		job.Status = jobstatus.Dead
+
+	default:
+		job.Status = jobstatus.Queued
+		job.ScheduledAt = retryAt
+	}
+
+	job.LockedUntil = time.Time{}
+	job.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, job, from); err != nil {
+		return Job{}, fmt.Errorf("update: %w", err)
+	}
+
+	return job, nil
+}
+
+// Retry queues a dead or cancelled job to run again as soon as possible with
+// a fresh set of attempts.
+func (b *Business) Retry(ctx context.Context, job Job) (Job, error) {
+	if job.Status != jobstatus.Dead && job.Status != jobstatus.Cancelled {
+		return Job{}, ErrNotRetryable
+	}
+
+	from := job.Status
+	now := time.Now()
+
+	job.Status = jobstatus.Queued
+	job.Attempts = 0
+	job.ScheduledAt = now
+	job.DateUpdated = now
+
+	if err := b.storer.Update(ctx, job, from); err != nil {
+		return Job{}, fmt.Errorf("update: %w", err)
+	}
+
+	return job, nil
+}
+
+// Cancel stops a queued job from running. Jobs that are running can't be
+// cancelled.
+func (b *Business) Cancel(ctx context.Context, job Job) (Job, error) {
+	if job.Status != jobstatus.Queued {
+		return Job{}, ErrNotCancellable
+	}
+
+	from := job.Status
+
+	job.Status = jobstatus.Cancelled
+	job.DateUpdated = time.Now()
+
+	if err := b.storer.Update(ctx, job, from); err != nil {
+		return Job{}, fmt.Errorf("update: %w", err)
+	}
+
+	return job, nil
+}
+
+// Query retrieves a list of existing jobs.
+func (b *Business) Query(ctx context.Context, filter QueryFilter, orderBy order.By, page page.Page) ([]Job, error) {
+	jobs, err := b.storer.Query(ctx, filter, orderBy, page)
+	if err != nil {
+		return nil, fmt.Errorf("query: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// Count returns the total number of jobs.
+func (b *Business) Count(ctx context.Context, filter QueryFilter) (int, error) {
+	return b.storer.Count(ctx, filter)
+}
+
+// QueryByID finds the job by the specified ID.
+func (b *Business) QueryByID(ctx context.Context, jobID uuid.UUID) (Job, error) {
+	job, err := b.storer.QueryByID(ctx, jobID)
+	if err != nil {
+		return Job{}, fmt.Errorf("query: jobID[%s]: %w", jobID, err)
+	}
+
+	return job, nil
+}
@@ -0,0 +1,267 @@
+package jobbus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/unittest"
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/google/go-cmp/cmp"
+)
+
+const testType = "test.job"
+
+func Test_Job(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Job")
+
+	sd, err := insertSeedData(db.BusDomain)
+	if err != nil {
+		t.Fatalf("Seeding error: %s", err)
+	}
+
+	// -------------------------------------------------------------------------
+
+	unittest.Run(t, query(db.BusDomain, sd), "query")
+	unittest.Run(t, cancel(db.BusDomain, sd), "cancel")
+	unittest.Run(t, lifecycle(db.BusDomain, sd), "lifecycle")
+}
+
+// =============================================================================
+
+func insertSeedData(busDomain dbtest.BusDomain) (unittest.SeedData, error) {
+	ctx := context.Background()
+
+	jobs, err := jobbus.TestSeedJobs(ctx, 3, testType, busDomain.Job)
+	if err != nil {
+		return unittest.SeedData{}, fmt.Errorf("seeding jobs : %w", err)
+	}
+
+	sd := unittest.SeedData{
+		Jobs: jobs,
+	}
+
+	return sd, nil
+}
+
+// =============================================================================
+
+// state reduces a job to the fields that change as it moves through the
+// queue so the timestamps don't need to be compared.
+type state struct {
+	ID       string
+	Status   string
+	Attempts int
+}
+
+func toState(job jobbus.Job) state {
+	return state{
+		ID:       job.ID.String(),
+		Status:   job.Status.String(),
+		Attempts: job.Attempts,
+	}
+}
+
+func toStates(jobs []jobbus.Job) []state {
+	states := make([]state, len(jobs))
+	for i, job := range jobs {
+		states[i] = toState(job)
+	}
+
+	return states
+}
+
+func cmpStates(got any, exp any) string {
+	switch got := got.(type) {
+	case jobbus.Job:
+		return cmp.Diff(toState(got), exp)
+
+	case []jobbus.Job:
+		return cmp.Diff(toStates(got), exp)
+	}
+
+	return fmt.Sprintf("error occurred: %v", got)
+}
+
+// =============================================================================
+
+func query(busDomain dbtest.BusDomain, sd unittest.SeedData) []unittest.Table {
+	table := []unittest.Table{
+		{
+			Name:    "all",
+			ExpResp: toStates(sd.Jobs),
+			ExcFunc: func(ctx context.Context) any {
+				filter := jobbus.QueryFilter{
+					Type:   dbtest.StringPointer(testType),
+					Status: &jobstatus.Queued,
+				}
+
+				orderBy := order.NewBy(jobbus.OrderByScheduledAt, order.ASC)
+
+				resp, err := busDomain.Job.Query(ctx, filter, orderBy, page.MustParse("1", "10"))
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpStates,
+		},
+		{
+			Name:    "byid",
+			ExpResp: toState(sd.Jobs[0]),
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Job.QueryByID(ctx, sd.Jobs[0].ID)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpStates,
+		},
+	}
+
+	return table
+}
+
+func cancel(busDomain dbtest.BusDomain, sd unittest.SeedData) []unittest.Table {
+	exp := toState(sd.Jobs[2])
+	exp.Status = jobstatus.Cancelled.String()
+
+	table := []unittest.Table{
+		{
+			Name:    "queued",
+			ExpResp: exp,
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Job.Cancel(ctx, sd.Jobs[2])
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpStates,
+		},
+		{
+			Name:    "stale",
+			ExpResp: true,
+			ExcFunc: func(ctx context.Context) any {
+				_, err := busDomain.Job.Cancel(ctx, sd.Jobs[2])
+				return errors.Is(err, jobbus.ErrStatusChanged)
+			},
+			CmpFunc: func(got any, exp any) string {
+				return cmp.Diff(got, exp)
+			},
+		},
+	}
+
+	return table
+}
+
+func lifecycle(busDomain dbtest.BusDomain, sd unittest.SeedData) []unittest.Table {
+	types := []string{testType}
+
+	claim := func(ctx context.Context) any {
+		resp, err := busDomain.Job.Claim(ctx, types, 1, time.Minute)
+		if err != nil {
+			return err
+		}
+
+		return resp
+	}
+
+	fail := func(ctx context.Context) any {
+		job, err := busDomain.Job.QueryByID(ctx, sd.Jobs[0].ID)
+		if err != nil {
+			return err
+		}
+
+		// Keep the original schedule so the job is the next one claimed.
+		resp, err := busDomain.Job.Fail(ctx, job, errors.New("failed"), sd.Jobs[0].ScheduledAt)
+		if err != nil {
+			return err
+		}
+
+		return resp
+	}
+
+	table := []unittest.Table{
+		{
+			Name:    "claim",
+			ExpResp: []state{{ID: sd.Jobs[0].ID.String(), Status: jobstatus.Running.String(), Attempts: 1}},
+			ExcFunc: claim,
+			CmpFunc: cmpStates,
+		},
+		{
+			Name:    "fail-retry",
+			ExpResp: state{ID: sd.Jobs[0].ID.String(), Status: jobstatus.Queued.String(), Attempts: 1},
+			ExcFunc: fail,
+			CmpFunc: cmpStates,
+		},
+		{
+			Name:    "claim-again",
+			ExpResp: []state{{ID: sd.Jobs[0].ID.String(), Status: jobstatus.Running.String(), Attempts: 2}},
+			ExcFunc: claim,
+			CmpFunc: cmpStates,
+		},
+		{
+			Name:    "fail-dead",
+			ExpResp: state{ID: sd.Jobs[0].ID.String(), Status: jobstatus.Dead.String(), Attempts: 2},
+			ExcFunc: fail,
+			CmpFunc: cmpStates,
+		},
+		{
+			Name:    "retry",
+			ExpResp: state{ID: sd.Jobs[0].ID.String(), Status: jobstatus.Queued.String(), Attempts: 0},
+			ExcFunc: func(ctx context.Context) any {
+				job, err := busDomain.Job.QueryByID(ctx, sd.Jobs[0].ID)
+				if err != nil {
+					return err
+				}
+
+				resp, err := busDomain.Job.Retry(ctx, job)
+				if err != nil {
+					return err
+				}
+
+				return resp
+			},
+			CmpFunc: cmpStates,
+		},
+		{
+			Name:    "complete",
+			ExpResp: state{ID: sd.Jobs[1].ID.String(), Status: jobstatus.Succeeded.String(), Attempts: 1},
+			ExcFunc: func(ctx context.Context) any {
+				resp, err := busDomain.Job.Claim(ctx, types, 2, time.Minute)
+				if err != nil {
+					return err
+				}
+
+				for _, job := range resp {
+					if job.ID == sd.Jobs[1].ID {
+						job, err := busDomain.Job.Complete(ctx, job)
+						if err != nil {
+							return err
+						}
+
+						return job
+					}
+				}
+
+				return errors.New("job not claimed")
+			},
+			CmpFunc: cmpStates,
+		},
+	}
+
+	return table
+}
@@ -0,0 +1,34 @@
+package jobbus
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/google/uuid"
+)
+
+// Job represents information about an individual background job.
+type Job struct {
+	ID          uuid.UUID
+	Type        string
+	Payload     json.RawMessage
+	Status      jobstatus.Status
+	Attempts    int
+	MaxAttempts int
+	LastError   string
+	ScheduledAt time.Time
+	LockedUntil time.Time
+	DateCreated time.Time
+	DateUpdated time.Time
+}
+
+// NewJob is what we require from clients when adding a Job. A zero
+// ScheduledAt runs the job as soon as possible and a zero MaxAttempts uses
+// DefaultMaxAttempts.
+type NewJob struct {
+	Type        string
+	Payload     any
+	ScheduledAt time.Time
+	MaxAttempts int
+}
@@ -0,0 +1,16 @@
+package jobbus
+
+import "github.com/ardanlabs/service/business/sdk/order"
+
+// DefaultOrderBy represents the default way we sort.
+var DefaultOrderBy = order.NewBy(OrderByScheduledAt, order.ASC)
+
+// Set of fields that the results can be ordered by.
+const (
+	OrderByID          = "a"
+	OrderByType        = "b"
+	OrderByStatus      = "c"
+	OrderByAttempts    = "d"
+	OrderByScheduledAt = "e"
+	OrderByDateCreated = "f"
+)
@@ -0,0 +1,199 @@
+package jobbus
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/worker"
+)
+
+// Handler executes a job of a registered type. Returning an error fails the
+// attempt and the job is retried with backoff until it runs out of attempts.
+// The context is cancelled when the lease on the job expires.
+type Handler func(ctx context.Context, job Job) error
+
+// RunnerConfig represents the settings for running jobs. Zero values use the
+// defaults.
+type RunnerConfig struct {
+	MaxRunning   int
+	PollInterval time.Duration
+	Lease        time.Duration
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+}
+
+// Runner claims jobs from the queue and executes them using a worker.
+type Runner struct {
+	log      *logger.Logger
+	bus      ExtBusiness
+	cfg      RunnerConfig
+	worker   *worker.Worker
+	handlers map[string]Handler
+	types    []string
+	shutdown chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewRunner constructs a runner for the jobs in the queue. Handlers must be
+// registered before the runner is started.
+func NewRunner(log *logger.Logger, bus ExtBusiness, cfg RunnerConfig) (*Runner, error) {
+	if cfg.MaxRunning <= 0 {
+		cfg.MaxRunning = 4
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 5 * time.Minute
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = 5 * time.Second
+	}
+	if cfg.MaxBackoff < cfg.MinBackoff {
+		cfg.MaxBackoff = max(10*time.Minute, cfg.MinBackoff)
+	}
+
+	w, err := worker.New(cfg.MaxRunning)
+	if err != nil {
+		return nil, fmt.Errorf("worker: %w", err)
+	}
+
+	r := Runner{
+		log:      log,
+		bus:      bus,
+		cfg:      cfg,
+		worker:   w,
+		handlers: make(map[string]Handler),
+		shutdown: make(chan struct{}),
+	}
+
+	return &r, nil
+}
+
+// Register binds the handler to the job type. Only jobs of registered types
+// are claimed by the runner.
+func (r *Runner) Register(jobType string, handler Handler) {
+	if _, exists := r.handlers[jobType]; !exists {
+		r.types = append(r.types, jobType)
+	}
+
+	r.handlers[jobType] = handler
+}
+
+// Start launches a goroutine that polls the queue for jobs until Shutdown is
+// called.
+func (r *Runner) Start() {
+	r.wg.Add(1)
+
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.cfg.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			r.poll()
+
+			select {
+			case <-r.shutdown:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Shutdown stops claiming jobs and waits for the running jobs to complete.
+// Running jobs are cancelled and will be retried.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	close(r.shutdown)
+	r.wg.Wait()
+
+	return r.worker.Shutdown(ctx)
+}
+
+func (r *Runner) poll() {
+	free := r.cfg.MaxRunning - r.worker.Running()
+	if free <= 0 || len(r.types) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.PollInterval+5*time.Second)
+	defer cancel()
+
+	jobs, err := r.bus.Claim(ctx, r.types, free, r.cfg.Lease)
+	if err != nil {
+		r.log.Error(ctx, "jobs", "status", "claim failed", "ERROR", err)
+		return
+	}
+
+	for _, job := range jobs {
+		if err := r.start(job); err != nil {
+			r.finish(job, fmt.Errorf("start: %w", err))
+		}
+	}
+}
+
+func (r *Runner) start(job Job) error {
+
+	// The worker uses the deadline of this context for the job.
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.Lease)
+	defer cancel()
+
+	f := func(ctx context.Context) {
+		r.finish(job, r.execute(ctx, job))
+	}
+
+	if _, err := r.worker.Start(ctx, f); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Runner) execute(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v\n%s", rec, debug.Stack())
+		}
+	}()
+
+	return r.handlers[job.Type](ctx, job)
+}
+
+// finish records the result of the job. The context of the job may be done
+// by now so a new one is used.
+func (r *Runner) finish(job Job, jobErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if jobErr == nil {
+		if _, err := r.bus.Complete(ctx, job); err != nil {
+			r.log.Error(ctx, "jobs", "status", "complete failed", "job_id", job.ID, "type", job.Type, "ERROR", err)
+		}
+		return
+	}
+
+	failed, err := r.bus.Fail(ctx, job, jobErr, time.Now().Add(r.backoff(job.Attempts)))
+	if err != nil {
+		r.log.Error(ctx, "jobs", "status", "record failure failed", "job_id", job.ID, "type", job.Type, "ERROR", err)
+		return
+	}
+
+	r.log.Warn(ctx, "jobs", "status", "attempt failed", "job_id", job.ID, "type", job.Type, "attempts", failed.Attempts, "job_status", failed.Status, "ERROR", jobErr)
+}
+
+// backoff doubles the delay for each attempt starting at the minimum and
+// capped at the maximum.
+func (r *Runner) backoff(attempts int) time.Duration {
+	d := r.cfg.MinBackoff
+	for i := 1; i < attempts && d < r.cfg.MaxBackoff; i++ {
+		d *= 2
+	}
+
+	return min(d, r.cfg.MaxBackoff)
+}
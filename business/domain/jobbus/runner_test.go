@@ -0,0 +1,130 @@
+package jobbus_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+func Test_Runner(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(os.Stdout, logger.LevelError, "TEST", func(context.Context) string { return "" })
+
+	bus := newFakeBus(jobbus.Job{
+		ID:          uuid.New(),
+		Type:        testType,
+		Status:      jobstatus.Queued,
+		MaxAttempts: 3,
+	})
+
+	runner, err := jobbus.NewRunner(log, bus, jobbus.RunnerConfig{
+		MaxRunning:   1,
+		PollInterval: 10 * time.Millisecond,
+		MinBackoff:   time.Millisecond,
+		MaxBackoff:   time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the runner: %s", err)
+	}
+
+	var calls int
+	runner.Register(testType, func(ctx context.Context, job jobbus.Job) error {
+		calls++
+		switch calls {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			panic("panicked")
+		}
+		return nil
+	})
+
+	runner.Start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for bus.job().Status != jobstatus.Succeeded && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := runner.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to shutdown the runner: %s", err)
+	}
+
+	job := bus.job()
+	if job.Status != jobstatus.Succeeded {
+		t.Fatalf("Should get the job succeeded: got %s, last error %q", job.Status, job.LastError)
+	}
+
+	if job.Attempts != 3 {
+		t.Errorf("Should get 3 attempts: got %d", job.Attempts)
+	}
+
+	if calls != 3 {
+		t.Errorf("Should get 3 calls to the handler: got %d", calls)
+	}
+}
+
+// =============================================================================
+
+// fakeBus keeps a single job in memory so the runner can be tested without
+// a database.
+type fakeBus struct {
+	jobbus.ExtBusiness
+
+	mu sync.Mutex
+	j  jobbus.Job
+}
+
+func newFakeBus(job jobbus.Job) *fakeBus {
+	return &fakeBus{j: job}
+}
+
+func (b *fakeBus) job() jobbus.Job {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.j
+}
+
+func (b *fakeBus) Claim(ctx context.Context, types []string, limit int, lease time.Duration) ([]jobbus.Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.j.Status != jobstatus.Queued || time.Now().Before(b.j.ScheduledAt) {
+		return nil, nil
+	}
+
+	b.j.Status = jobstatus.Running
+	b.j.Attempts++
+
+	return []jobbus.Job{b.j}, nil
+}
+
+func (b *fakeBus) Complete(ctx context.Context, job jobbus.Job) (jobbus.Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.j.Status = jobstatus.Succeeded
+
+	return b.j, nil
+}
+
+func (b *fakeBus) Fail(ctx context.Context, job jobbus.Job, jobErr error, retryAt time.Time) (jobbus.Job, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.j.Status = jobstatus.Queued
+	b.j.LastError = jobErr.Error()
+	b.j.ScheduledAt = retryAt
+
+	return b.j, nil
+}
@@ -0,0 +1,42 @@
+package jobdb
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/jobbus"
+)
+
+func applyFilter(filter jobbus.QueryFilter, data map[string]any, buf *bytes.Buffer) {
+	var wc []string
+
+	if filter.ID != nil {
+		data["id"] = filter.ID
+		wc = append(wc, "id = :id")
+	}
+
+	if filter.Type != nil {
+		data["type"] = *filter.Type
+		wc = append(wc, "type = :type")
+	}
+
+	if filter.Status != nil {
+		data["status"] = filter.Status.String()
+		wc = append(wc, "status = :status")
+	}
+
+	if filter.Since != nil {
+		data["since"] = filter.Since.UTC()
+		wc = append(wc, "scheduled_at >= :since")
+	}
+
+	if filter.Until != nil {
+		data["until"] = filter.Until.UTC()
+		wc = append(wc, "scheduled_at <= :until")
+	}
+
+	if len(wc) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(wc, " AND "))
+	}
+}
@@ -0,0 +1,250 @@
+// Package jobdb contains job related CRUD functionality.
+package jobdb
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// Store manages the set of APIs for job database access.
+type Store struct {
+	log *logger.Logger
+	db  sqlx.ExtContext
+}
+
+// NewStore constructs the API for data access.
+func NewStore(log *logger.Logger, db *sqlx.DB) *Store {
+	return &Store{
+		log: log,
+		db:  db,
+	}
+}
+
+// Create inserts a new job into the database.
+func (s *Store) Create(ctx context.Context, j jobbus.Job) error {
+	const q = `
+	INSERT INTO jobs
+		(id, type, payload, status, attempts, max_attempts, last_error, scheduled_at, locked_until, date_created, date_updated)
+	VALUES
+		(:id, :type, :payload, :status, :attempts, :max_attempts, :last_error, :scheduled_at, :locked_until, :date_created, :date_updated)`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, toDBJob(j)); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a job in the database as long as the job still has the
+// from status. This keeps a change based on a stale read, such as cancelling
+// a job that was claimed in the meantime, from being applied.
+func (s *Store) Update(ctx context.Context, j jobbus.Job, from jobstatus.Status) error {
+	dbJob := toDBJob(j)
+
+	data := map[string]any{
+		"id":           dbJob.ID,
+		"status":       dbJob.Status,
+		"attempts":     dbJob.Attempts,
+		"last_error":   dbJob.LastError,
+		"scheduled_at": dbJob.ScheduledAt,
+		"locked_until": dbJob.LockedUntil,
+		"date_updated": dbJob.DateUpdated,
+		"from_status":  from.String(),
+	}
+
+	const q = `
+	UPDATE
+		jobs
+	SET
+		"status"       = :status,
+		"attempts"     = :attempts,
+		"last_error"   = :last_error,
+		"scheduled_at" = :scheduled_at,
+		"locked_until" = :locked_until,
+		"date_updated" = :date_updated
+	WHERE
+		id = :id AND status = :from_status
+	RETURNING
+		id`
+
+	var dest struct {
+		ID uuid.UUID `db:"id"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dest); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return fmt.Errorf("db: %w", jobbus.ErrStatusChanged)
+		}
+		return fmt.Errorf("db: %w", err)
+	}
+
+	return nil
+}
+
+// Claim marks up to limit jobs of the specified types as running until the
+// lockedUntil time and returns them. Jobs that are due and jobs whose lease
+// has expired are claimed in the order they were scheduled. Rows locked by
+// another claim are skipped so several instances can share the queue.
+func (s *Store) Claim(ctx context.Context, types []string, limit int, now time.Time, lockedUntil time.Time) ([]jobbus.Job, error) {
+	data := map[string]any{
+		"types":        types,
+		"limit":        limit,
+		"now":          now.UTC(),
+		"locked_until": lockedUntil.UTC(),
+		"queued":       jobstatus.Queued.String(),
+		"running":      jobstatus.Running.String(),
+	}
+
+	const q = `
+	UPDATE
+		jobs
+	SET
+		"status"       = :running,
+		"attempts"     = attempts + 1,
+		"locked_until" = :locked_until,
+		"date_updated" = :now
+	WHERE
+		id IN (
+			SELECT
+				id
+			FROM
+				jobs
+			WHERE
+				type IN (:types) AND
+				((status = :queued AND scheduled_at <= :now) OR (status = :running AND locked_until < :now))
+			ORDER BY
+				scheduled_at
+			LIMIT :limit
+			FOR UPDATE SKIP LOCKED
+		)
+	RETURNING
+		id, type, payload, status, attempts, max_attempts, last_error, scheduled_at, locked_until, date_created, date_updated`
+
+	var dbJobs []job
+	if err := sqldb.NamedQuerySliceUsingIn(ctx, s.log, s.db, q, data, &dbJobs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusJobs(dbJobs)
+}
+
+// ExpireLeases dead-letters the running jobs whose lease has expired and
+// have no attempts left.
+func (s *Store) ExpireLeases(ctx context.Context, now time.Time) error {
+	data := map[string]any{
+		"now":        now.UTC(),
+		"running":    jobstatus.Running.String(),
+		"dead":       jobstatus.Dead.String(),
+		"last_error": "lease expired",
+	}
+
+	const q = `
+	UPDATE
+		jobs
+	SET
+		"status"       = :dead,
+		"last_error"   = :last_error,
+		"locked_until" = NULL,
+		"date_updated" = :now
+	WHERE
+		status = :running AND locked_until < :now AND attempts >= max_attempts`
+
+	if err := sqldb.NamedExecContext(ctx, s.log, s.db, q, data); err != nil {
+		return fmt.Errorf("namedexeccontext: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing jobs from the database.
+func (s *Store) Query(ctx context.Context, filter jobbus.QueryFilter, orderBy order.By, page page.Page) ([]jobbus.Job, error) {
+	data := map[string]any{
+		"offset":        (page.Number() - 1) * page.RowsPerPage(),
+		"rows_per_page": page.RowsPerPage(),
+	}
+
+	const q = `
+	SELECT
+		id, type, payload, status, attempts, max_attempts, last_error, scheduled_at, locked_until, date_created, date_updated
+	FROM
+		jobs`
+
+	buf := bytes.NewBufferString(q)
+	applyFilter(filter, data, buf)
+
+	orderByClause, err := orderByClause(orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.WriteString(orderByClause)
+	buf.WriteString(" OFFSET :offset ROWS FETCH NEXT :rows_per_page ROWS ONLY")
+
+	var dbJobs []job
+	if err := sqldb.NamedQuerySlice(ctx, s.log, s.db, buf.String(), data, &dbJobs); err != nil {
+		return nil, fmt.Errorf("namedqueryslice: %w", err)
+	}
+
+	return toBusJobs(dbJobs)
+}
+
+// Count returns the total number of jobs in the DB.
+func (s *Store) Count(ctx context.Context, filter jobbus.QueryFilter) (int, error) {
+	data := map[string]any{}
+
+	const q = `
+	SELECT
+		count(1)
+	FROM
+		jobs`
+
+	buf := bytes.NewBufferString(q)
+	applyFilter(filter, data, buf)
+
+	var count struct {
+		Count int `db:"count"`
+	}
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, buf.String(), data, &count); err != nil {
+		return 0, fmt.Errorf("db: %w", err)
+	}
+
+	return count.Count, nil
+}
+
+// QueryByID gets the specified job from the database.
+func (s *Store) QueryByID(ctx context.Context, jobID uuid.UUID) (jobbus.Job, error) {
+	data := struct {
+		ID string `db:"id"`
+	}{
+		ID: jobID.String(),
+	}
+
+	const q = `
+	SELECT
+		id, type, payload, status, attempts, max_attempts, last_error, scheduled_at, locked_until, date_created, date_updated
+	FROM
+		jobs
+	WHERE
+		id = :id`
+
+	var dbJob job
+	if err := sqldb.NamedQueryStruct(ctx, s.log, s.db, q, data, &dbJob); err != nil {
+		if errors.Is(err, sqldb.ErrDBNotFound) {
+			return jobbus.Job{}, fmt.Errorf("db: %w", jobbus.ErrNotFound)
+		}
+		return jobbus.Job{}, fmt.Errorf("db: %w", err)
+	}
+
+	return toBusJob(dbJob)
+}
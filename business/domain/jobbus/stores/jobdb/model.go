@@ -0,0 +1,94 @@
+package jobdb
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/types/jobstatus"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx/types"
+)
+
+type job struct {
+	ID          uuid.UUID          `db:"id"`
+	Type        string             `db:"type"`
+	Payload     types.NullJSONText `db:"payload"`
+	Status      string             `db:"status"`
+	Attempts    int                `db:"attempts"`
+	MaxAttempts int                `db:"max_attempts"`
+	LastError   sql.NullString     `db:"last_error"`
+	ScheduledAt time.Time          `db:"scheduled_at"`
+	LockedUntil sql.NullTime       `db:"locked_until"`
+	DateCreated time.Time          `db:"date_created"`
+	DateUpdated time.Time          `db:"date_updated"`
+}
+
+func toDBJob(bus jobbus.Job) job {
+	db := job{
+		ID:          bus.ID,
+		Type:        bus.Type,
+		Payload:     types.NullJSONText{JSONText: []byte(bus.Payload), Valid: true},
+		Status:      bus.Status.String(),
+		Attempts:    bus.Attempts,
+		MaxAttempts: bus.MaxAttempts,
+		LastError: sql.NullString{
+			String: bus.LastError,
+			Valid:  bus.LastError != "",
+		},
+		ScheduledAt: bus.ScheduledAt.UTC(),
+		LockedUntil: sql.NullTime{
+			Time:  bus.LockedUntil.UTC(),
+			Valid: !bus.LockedUntil.IsZero(),
+		},
+		DateCreated: bus.DateCreated.UTC(),
+		DateUpdated: bus.DateUpdated.UTC(),
+	}
+
+	return db
+}
+
+func toBusJob(db job) (jobbus.Job, error) {
+	status, err := jobstatus.Parse(db.Status)
+	if err != nil {
+		return jobbus.Job{}, fmt.Errorf("parse status: %w", err)
+	}
+
+	var lockedUntil time.Time
+	if db.LockedUntil.Valid {
+		lockedUntil = db.LockedUntil.Time.In(time.Local)
+	}
+
+	bus := jobbus.Job{
+		ID:          db.ID,
+		Type:        db.Type,
+		Payload:     json.RawMessage(db.Payload.JSONText),
+		Status:      status,
+		Attempts:    db.Attempts,
+		MaxAttempts: db.MaxAttempts,
+		LastError:   db.LastError.String,
+		ScheduledAt: db.ScheduledAt.In(time.Local),
+		LockedUntil: lockedUntil,
+		DateCreated: db.DateCreated.In(time.Local),
+		DateUpdated: db.DateUpdated.In(time.Local),
+	}
+
+	return bus, nil
+}
+
+func toBusJobs(dbs []job) ([]jobbus.Job, error) {
+	jobs := make([]jobbus.Job, len(dbs))
+
+	for i, db := range dbs {
+		j, err := toBusJob(db)
+		if err != nil {
+			return nil, err
+		}
+
+		jobs[i] = j
+	}
+
+	return jobs, nil
+}
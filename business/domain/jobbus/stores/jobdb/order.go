@@ -0,0 +1,26 @@
+package jobdb
+
+import (
+	"fmt"
+
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+)
+
+var orderByFields = map[string]string{
+	jobbus.OrderByID:          "id",
+	jobbus.OrderByType:        "type",
+	jobbus.OrderByStatus:      "status",
+	jobbus.OrderByAttempts:    "attempts",
+	jobbus.OrderByScheduledAt: "scheduled_at",
+	jobbus.OrderByDateCreated: "date_created",
+}
+
+func orderByClause(orderBy order.By) (string, error) {
+	by, exists := orderByFields[orderBy.Field]
+	if !exists {
+		return "", fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	return " ORDER BY " + by + " " + orderBy.Direction, nil
+}
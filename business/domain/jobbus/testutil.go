@@ -0,0 +1,44 @@
+package jobbus
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+)
+
+// TestNewJobs is a helper method for testing.
+func TestNewJobs(n int, jobType string) []NewJob {
+	newJobs := make([]NewJob, n)
+
+	idx := rand.Intn(10000)
+	for i := range n {
+		idx++
+
+		nj := NewJob{
+			Type:        jobType,
+			Payload:     struct{ Name string }{Name: fmt.Sprintf("Name%d", idx)},
+			MaxAttempts: 2,
+		}
+
+		newJobs[i] = nj
+	}
+
+	return newJobs
+}
+
+// TestSeedJobs is a helper method for testing.
+func TestSeedJobs(ctx context.Context, n int, jobType string, api ExtBusiness) ([]Job, error) {
+	newJobs := TestNewJobs(n, jobType)
+
+	jobs := make([]Job, len(newJobs))
+	for i, nj := range newJobs {
+		job, err := api.Enqueue(ctx, nj)
+		if err != nil {
+			return nil, fmt.Errorf("seeding job: idx: %d : %w", i, err)
+		}
+
+		jobs[i] = job
+	}
+
+	return jobs, nil
+}
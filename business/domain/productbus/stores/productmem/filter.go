@@ -0,0 +1,29 @@
+package productmem
+
+import (
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+)
+
+func applyFilter(filter productbus.QueryFilter) func(prd productbus.Product) bool {
+	return func(prd productbus.Product) bool {
+		if filter.ID != nil && prd.ID != *filter.ID {
+			return false
+		}
+
+		if filter.Name != nil && !strings.Contains(prd.Name.String(), filter.Name.String()) {
+			return false
+		}
+
+		if filter.Cost != nil && prd.Cost.Value() != *filter.Cost {
+			return false
+		}
+
+		if filter.Quantity != nil && prd.Quantity.Value() != *filter.Quantity {
+			return false
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,32 @@
+package productmem
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/google/uuid"
+)
+
+var orderByFields = map[string]memdb.CompareFunc[productbus.Product]{
+	productbus.OrderByProductID: func(a productbus.Product, b productbus.Product) int {
+		return memdb.CompareID(a.ID, b.ID)
+	},
+	productbus.OrderByUserID: func(a productbus.Product, b productbus.Product) int {
+		return memdb.CompareID(a.UserID, b.UserID)
+	},
+	productbus.OrderByName: func(a productbus.Product, b productbus.Product) int {
+		return strings.Compare(a.Name.String(), b.Name.String())
+	},
+	productbus.OrderByCost: func(a productbus.Product, b productbus.Product) int {
+		return cmp.Compare(a.Cost.Value(), b.Cost.Value())
+	},
+	productbus.OrderByQuantity: func(a productbus.Product, b productbus.Product) int {
+		return cmp.Compare(a.Quantity.Value(), b.Quantity.Value())
+	},
+}
+
+func productID(prd productbus.Product) uuid.UUID {
+	return prd.ID
+}
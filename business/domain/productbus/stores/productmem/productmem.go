@@ -0,0 +1,148 @@
+// Package productmem contains product related CRUD functionality backed by
+// an in-memory database.
+package productmem
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Table is the name of the table that holds the products.
+const Table = "products"
+
+// Store manages the set of APIs for product in-memory access.
+type Store struct {
+	log      *logger.Logger
+	products *memdb.Table[productbus.Product]
+	tx       *memdb.Tx
+}
+
+// NewStore constructs the api for data access. Products reference the users
+// table so a product can only be created for an existing user and is
+// deleted with its user.
+func NewStore(log *logger.Logger, db *memdb.DB) *Store {
+	products := memdb.GetTable[productbus.Product](db, Table)
+	memdb.ForeignKey(products, usermem.Table, func(prd productbus.Product) uuid.UUID {
+		return prd.UserID
+	})
+
+	return &Store{
+		log:      log,
+		products: products,
+	}
+}
+
+// NewWithTx constructs a new Store value that records its writes in the
+// specified transaction so they can be rolled back.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (productbus.Storer, error) {
+	mtx, err := memdb.GetTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log:      s.log,
+		products: s.products,
+		tx:       mtx,
+	}
+
+	return &store, nil
+}
+
+// Create adds a Product to the database.
+func (s *Store) Create(ctx context.Context, prd productbus.Product) error {
+	if err := s.products.Insert(s.tx, prd.ID, toMemProduct(prd), nil); err != nil {
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a product document in the database.
+func (s *Store) Update(ctx context.Context, prd productbus.Product) error {
+	set := func(row productbus.Product) productbus.Product {
+		upd := toMemProduct(prd)
+		row.Name = upd.Name
+		row.Cost = upd.Cost
+		row.Quantity = upd.Quantity
+		row.DateUpdated = upd.DateUpdated
+		return row
+	}
+
+	if err := s.products.Update(s.tx, prd.ID, set, nil); err != nil {
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the product identified by a given ID.
+func (s *Store) Delete(ctx context.Context, prd productbus.Product) error {
+	if err := s.products.Delete(s.tx, prd.ID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query gets all Products from the database.
+func (s *Store) Query(ctx context.Context, filter productbus.QueryFilter, orderBy order.By, page page.Page) ([]productbus.Product, error) {
+	prds := s.products.Select(applyFilter(filter))
+
+	if err := memdb.Sort(prds, orderBy, orderByFields, productID); err != nil {
+		return nil, err
+	}
+
+	return memdb.Page(prds, page), nil
+}
+
+// Count returns the total number of products in the DB.
+func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int, error) {
+	return len(s.products.Select(applyFilter(filter))), nil
+}
+
+// QueryByID finds the product identified by a given ID.
+func (s *Store) QueryByID(ctx context.Context, productID uuid.UUID) (productbus.Product, error) {
+	prd, exists := s.products.Get(productID)
+	if !exists {
+		return productbus.Product{}, fmt.Errorf("db: %w", productbus.ErrNotFound)
+	}
+
+	return prd, nil
+}
+
+// QueryByUserID finds the product identified by a given User ID.
+func (s *Store) QueryByUserID(ctx context.Context, userID uuid.UUID) ([]productbus.Product, error) {
+	prds := s.products.Select(func(prd productbus.Product) bool {
+		return prd.UserID == userID
+	})
+
+	return prds, nil
+}
+
+// =============================================================================
+
+// toMemProduct normalizes the fields the way the database would. The cost
+// column is a NUMERIC(10, 2).
+func toMemProduct(bus productbus.Product) productbus.Product {
+	prd := bus
+	prd.DateCreated = memdb.Time(bus.DateCreated)
+	prd.DateUpdated = memdb.Time(bus.DateUpdated)
+
+	if cost, err := money.Parse(math.Round(bus.Cost.Value()*100) / 100); err == nil {
+		prd.Cost = cost
+	}
+
+	return prd
+}
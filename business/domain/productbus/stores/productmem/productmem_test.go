@@ -0,0 +1,29 @@
+package productmem_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productmem"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productstoretest"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	productstoretest.Run(t, func(t *testing.T) productstoretest.Harness {
+		db := memdb.New()
+
+		return productstoretest.Harness{
+			Storer:   productmem.NewStore(log, db),
+			Beginner: db,
+			Users:    usermem.NewStore(log, db),
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package productpg_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productpg"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productstoretest"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	productstoretest.Run(t, func(t *testing.T) productstoretest.Harness {
+		db := dbtest.New(t, t.Name())
+
+		return productstoretest.Harness{
+			Storer:   productpg.NewStore(db.Log, db.DB),
+			Beginner: sqldb.NewBeginner(db.DB),
+			Users:    userdb.NewStore(db.Log, db.DB),
+		}
+	})
+}
@@ -160,7 +160,7 @@ func (s *Store) Count(ctx context.Context, filter productbus.QueryFilter) (int,
 
 	const q = `
 	SELECT
-		count(1)
+		count(1) AS count
 	FROM
 		products`
 
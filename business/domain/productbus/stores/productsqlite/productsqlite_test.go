@@ -0,0 +1,96 @@
+//go:build cgo
+
+package productsqlite_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productsqlite"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productstoretest"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/migrate"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dialect"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// The suite uses the same driver as the admin tooling. It needs cgo, so
+// the makefile runs this package with CGO_ENABLED=1 even when the rest of
+// the tests are built without it.
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	productstoretest.Run(t, func(t *testing.T) productstoretest.Harness {
+		db := sqlx.MustOpen("sqlite3", ":memory:")
+		t.Cleanup(func() { db.Close() })
+
+		// Every connection gets its own in-memory database, so there can
+		// only be one.
+		db.SetMaxOpenConns(1)
+
+		ctx := context.Background()
+
+		if _, err := db.ExecContext(ctx, "PRAGMA foreign_keys = ON"); err != nil {
+			t.Fatalf("Should be able to turn on foreign keys: %s", err)
+		}
+
+		m, err := migrate.New(db, dialect.SQLite{})
+		if err != nil {
+			t.Fatalf("Should be able to construct the migrator: %s", err)
+		}
+
+		if _, err := m.Up(ctx); err != nil {
+			t.Fatalf("Should be able to migrate: %s", err)
+		}
+
+		return productstoretest.Harness{
+			Storer:   productsqlite.NewStore(log, db),
+			Beginner: sqldb.NewBeginner(db),
+			Users:    users{db: db},
+		}
+	})
+}
+
+// users adds, changes and removes the users the products belong to. There
+// is no SQLite user store so the rows are written directly.
+type users struct {
+	db *sqlx.DB
+}
+
+func (u users) Create(ctx context.Context, usr userbus.User) error {
+	const q = `
+	INSERT INTO users
+		(user_id, name, email, roles, password_hash, department, enabled, date_created, date_updated)
+	VALUES
+		(?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	roles := strings.Join(role.ParseToString(usr.Roles), ",")
+
+	_, err := u.db.ExecContext(ctx, q, usr.ID.String(), usr.Name.String(), usr.Email.Address, roles,
+		string(usr.PasswordHash), nil, usr.Enabled, usr.DateCreated.UTC(), usr.DateUpdated.UTC())
+
+	return err
+}
+
+func (u users) Update(ctx context.Context, usr userbus.User) error {
+	const q = `UPDATE users SET name = ?, email = ?, enabled = ?, date_updated = ? WHERE user_id = ?`
+
+	_, err := u.db.ExecContext(ctx, q, usr.Name.String(), usr.Email.Address, usr.Enabled, usr.DateUpdated.UTC(), usr.ID.String())
+
+	return err
+}
+
+func (u users) Delete(ctx context.Context, usr userbus.User) error {
+	_, err := u.db.ExecContext(ctx, `DELETE FROM users WHERE user_id = ?`, usr.ID.String())
+
+	return err
+}
@@ -0,0 +1,553 @@
+// Package productstoretest provides a conformance suite for implementations
+// of productbus.Storer. Every engine package runs the same suite so a new
+// engine can prove it behaves like productpg with one call:
+//
+//	func Test_Conformance(t *testing.T) {
+//	    productstoretest.Run(t, func(t *testing.T) productstoretest.Harness {
+//	        db := ... // an empty, migrated database owned by t
+//	        return productstoretest.Harness{
+//	            Storer:   NewStore(log, db),
+//	            Beginner: sqldb.NewBeginner(db),
+//	            Users:    ...,
+//	        }
+//	    })
+//	}
+//
+// Each case gets a new harness so the cases can't see each other's data.
+package productstoretest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userstoretest"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+// Harness is the set of values the suite needs from an engine. The store
+// must start out empty and the users are added to the same database since
+// products reference their user.
+type Harness struct {
+	Storer   productbus.Storer
+	Beginner sqldb.Beginner
+	Users    userstoretest.Users
+}
+
+// Run executes the conformance suite against the harnesses returned by
+// newHarness, which is called once per case.
+func Run(t *testing.T, newHarness func(t *testing.T) Harness) {
+	table := []struct {
+		name string
+		test func(t *testing.T, s *suite)
+	}{
+		{"create", create},
+		{"duplicate", duplicate},
+		{"not-found", notFound},
+		{"filter", filter},
+		{"order", orderBy},
+		{"page", paging},
+		{"update", update},
+		{"delete", deleteProduct},
+		{"delete-user", deleteUser},
+		{"rollback", rollback},
+		{"commit", commit},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSuite(t, newHarness(t))
+			tt.test(t, s)
+		})
+	}
+}
+
+// =============================================================================
+
+// suite holds the data every case starts with. The names, costs and user
+// ids are distinct and two products share a quantity so the filters can be
+// checked for more than one match.
+type suite struct {
+	Harness
+	ctx      context.Context
+	now      time.Time
+	users    []userbus.User
+	products []productbus.Product
+}
+
+func newSuite(t *testing.T, h Harness) *suite {
+	s := suite{
+		Harness: h,
+		ctx:     context.Background(),
+		now:     time.Now().Truncate(time.Second),
+	}
+
+	s.users = []userbus.User{
+		userstoretest.NewUser("Alice Owner", "alice@storetest.com", s.now),
+		userstoretest.NewUser("Bob Owner", "bob@storetest.com", s.now),
+	}
+
+	for _, usr := range s.users {
+		if err := s.Users.Create(s.ctx, usr); err != nil {
+			t.Fatalf("Should be able to create user %s: %s", usr.Email.Address, err)
+		}
+	}
+
+	s.products = []productbus.Product{
+		newProduct(s.users[0].ID, "Apple", 1.25, 10, s.now),
+		newProduct(s.users[0].ID, "Banana", 0.5, 20, s.now),
+		newProduct(s.users[1].ID, "Cherry", 3.75, 10, s.now),
+		newProduct(s.users[1].ID, "Date", 2, 5, s.now),
+		newProduct(s.users[0].ID, "Elderberry", 9.99, 1, s.now),
+	}
+
+	for _, prd := range s.products {
+		if err := s.Storer.Create(s.ctx, prd); err != nil {
+			t.Fatalf("Should be able to create product %s: %s", prd.Name, err)
+		}
+	}
+
+	return &s
+}
+
+// query returns every product matching the filter in the order requested.
+func (s *suite) query(t *testing.T, filter productbus.QueryFilter, orderBy order.By) []productbus.Product {
+	t.Helper()
+
+	prds, err := s.Storer.Query(s.ctx, filter, orderBy, page.MustParse("1", "100"))
+	if err != nil {
+		t.Fatalf("Should be able to query: %s", err)
+	}
+
+	return prds
+}
+
+// =============================================================================
+
+func create(t *testing.T, s *suite) {
+	for _, exp := range s.products {
+		got, err := s.Storer.QueryByID(s.ctx, exp.ID)
+		if err != nil {
+			t.Fatalf("Should be able to query by id: %s", err)
+		}
+
+		if diff := cmp.Diff(got, exp); diff != "" {
+			t.Errorf("Should get back the same product:\n%s", diff)
+		}
+	}
+
+	got, err := s.Storer.QueryByUserID(s.ctx, s.users[1].ID)
+	if err != nil {
+		t.Fatalf("Should be able to query by user id: %s", err)
+	}
+
+	exp := []productbus.Product{s.products[2], s.products[3]}
+	if diff := cmp.Diff(byName(got), exp); diff != "" {
+		t.Errorf("Should get the products of the user:\n%s", diff)
+	}
+}
+
+func duplicate(t *testing.T, s *suite) {
+	dup := s.products[0]
+	dup.Name = name.MustParse("Duplicate")
+
+	if err := s.Storer.Create(s.ctx, dup); err == nil {
+		t.Errorf("Should not be able to create a product with an existing id")
+	}
+
+	got, err := s.Storer.QueryByID(s.ctx, dup.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query by id: %s", err)
+	}
+
+	if diff := cmp.Diff(got, s.products[0]); diff != "" {
+		t.Errorf("Should not change the existing product:\n%s", diff)
+	}
+}
+
+func notFound(t *testing.T, s *suite) {
+	if _, err := s.Storer.QueryByID(s.ctx, uuid.New()); !errors.Is(err, productbus.ErrNotFound) {
+		t.Errorf("Should get ErrNotFound, got %v", err)
+	}
+
+	got, err := s.Storer.QueryByUserID(s.ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("Should be able to query by an unknown user id: %s", err)
+	}
+
+	if len(got) != 0 {
+		t.Errorf("Should get no products for an unknown user, got %d", len(got))
+	}
+
+	// Like the SQL statements, changing a product that doesn't exist is
+	// not an error.
+	missing := newProduct(s.users[0].ID, "Missing", 1, 1, s.now)
+
+	if err := s.Storer.Update(s.ctx, missing); err != nil {
+		t.Errorf("Should be able to update a missing product: %s", err)
+	}
+
+	if err := s.Storer.Delete(s.ctx, missing); err != nil {
+		t.Errorf("Should be able to delete a missing product: %s", err)
+	}
+
+	if n := count(t, s, productbus.QueryFilter{}); n != len(s.products) {
+		t.Errorf("Should still have %d products, got %d", len(s.products), n)
+	}
+}
+
+func filter(t *testing.T, s *suite) {
+	apple, banana, cherry, date, elderberry := s.products[0], s.products[1], s.products[2], s.products[3], s.products[4]
+
+	nan := name.MustParse("nan")
+	erry := name.MustParse("err")
+	none := name.MustParse("Kiwi")
+	cost := 3.75
+	noCost := 1.5
+	qty := 10
+
+	table := []struct {
+		name   string
+		filter productbus.QueryFilter
+		exp    []productbus.Product
+	}{
+		{"none", productbus.QueryFilter{}, []productbus.Product{apple, banana, cherry, date, elderberry}},
+		{"id", productbus.QueryFilter{ID: &date.ID}, []productbus.Product{date}},
+		{"name-contains", productbus.QueryFilter{Name: &nan}, []productbus.Product{banana}},
+		{"name-many", productbus.QueryFilter{Name: &erry}, []productbus.Product{cherry, elderberry}},
+		{"name-none", productbus.QueryFilter{Name: &none}, []productbus.Product{}},
+		{"cost", productbus.QueryFilter{Cost: &cost}, []productbus.Product{cherry}},
+		{"cost-none", productbus.QueryFilter{Cost: &noCost}, []productbus.Product{}},
+		{"quantity", productbus.QueryFilter{Quantity: &qty}, []productbus.Product{apple, cherry}},
+		{"id-name", productbus.QueryFilter{ID: &cherry.ID, Name: &erry}, []productbus.Product{cherry}},
+		{"id-name-mismatch", productbus.QueryFilter{ID: &apple.ID, Name: &erry}, []productbus.Product{}},
+		{"name-quantity", productbus.QueryFilter{Name: &erry, Quantity: &qty}, []productbus.Product{cherry}},
+		{"cost-quantity", productbus.QueryFilter{Cost: &cost, Quantity: &qty}, []productbus.Product{cherry}},
+		{"all", productbus.QueryFilter{ID: &cherry.ID, Name: &erry, Cost: &cost, Quantity: &qty}, []productbus.Product{cherry}},
+	}
+
+	for _, tt := range table {
+		got := s.query(t, tt.filter, order.NewBy(productbus.OrderByName, order.ASC))
+
+		if diff := cmp.Diff(got, tt.exp); diff != "" {
+			t.Errorf("%s: Should get the expected products:\n%s", tt.name, diff)
+		}
+
+		if n := count(t, s, tt.filter); n != len(tt.exp) {
+			t.Errorf("%s: Should get count %d, got %d", tt.name, len(tt.exp), n)
+		}
+	}
+}
+
+func orderBy(t *testing.T, s *suite) {
+	fields := map[string]func(a productbus.Product, b productbus.Product) int{
+		productbus.OrderByProductID: func(a productbus.Product, b productbus.Product) int {
+			return strings.Compare(a.ID.String(), b.ID.String())
+		},
+		productbus.OrderByUserID: func(a productbus.Product, b productbus.Product) int {
+			return strings.Compare(a.UserID.String(), b.UserID.String())
+		},
+		productbus.OrderByName: func(a productbus.Product, b productbus.Product) int {
+			return strings.Compare(a.Name.String(), b.Name.String())
+		},
+		productbus.OrderByCost: func(a productbus.Product, b productbus.Product) int {
+			return compare(a.Cost.Value(), b.Cost.Value())
+		},
+		productbus.OrderByQuantity: func(a productbus.Product, b productbus.Product) int {
+			return compare(a.Quantity.Value(), b.Quantity.Value())
+		},
+	}
+
+	for field, cmpFn := range fields {
+		for _, direction := range []string{order.ASC, order.DESC} {
+			got := s.query(t, productbus.QueryFilter{}, order.NewBy(field, direction))
+
+			if len(got) != len(s.products) {
+				t.Fatalf("%s %s: Should get %d products, got %d", field, direction, len(s.products), len(got))
+			}
+
+			sorted := slices.IsSortedFunc(got, func(a productbus.Product, b productbus.Product) int {
+				if direction == order.DESC {
+					return cmpFn(b, a)
+				}
+				return cmpFn(a, b)
+			})
+
+			if !sorted {
+				t.Errorf("%s %s: Should get the products in order, got %v", field, direction, names(got))
+			}
+		}
+	}
+
+	if _, err := s.Storer.Query(s.ctx, productbus.QueryFilter{}, order.NewBy("unknown", order.ASC), page.MustParse("1", "10")); err == nil {
+		t.Errorf("Should get an error for an unknown order field")
+	}
+}
+
+func paging(t *testing.T, s *suite) {
+	all := s.query(t, productbus.QueryFilter{}, productbus.DefaultOrderBy)
+
+	table := []struct {
+		page page.Page
+		exp  []productbus.Product
+	}{
+		{page.MustParse("1", "2"), all[0:2]},
+		{page.MustParse("2", "2"), all[2:4]},
+		{page.MustParse("3", "2"), all[4:5]},
+		{page.MustParse("4", "2"), []productbus.Product{}},
+		{page.MustParse("1", "5"), all},
+		{page.MustParse("2", "5"), []productbus.Product{}},
+		{page.MustParse("5", "1"), all[4:5]},
+		{page.MustParse("1", "100"), all},
+		{page.MustParse("100", "100"), []productbus.Product{}},
+	}
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, productbus.QueryFilter{}, productbus.DefaultOrderBy, tt.page)
+		if err != nil {
+			t.Fatalf("%s: Should be able to query: %s", tt.page, err)
+		}
+
+		if diff := cmp.Diff(got, tt.exp); diff != "" {
+			t.Errorf("%s: Should get the page of products:\n%s", tt.page, diff)
+		}
+	}
+
+	// The count is not affected by paging.
+	if n := count(t, s, productbus.QueryFilter{}); n != len(all) {
+		t.Errorf("Should get count %d, got %d", len(all), n)
+	}
+}
+
+func update(t *testing.T, s *suite) {
+	orig := s.products[2]
+
+	upd := orig
+	upd.Name = name.MustParse("Black Cherry")
+	upd.Cost = money.MustParse(4.5)
+	upd.Quantity = quantity.MustParse(7)
+	upd.DateUpdated = s.now.Add(time.Hour)
+
+	// The owner and date created are not something that can be updated.
+	upd.UserID = s.users[0].ID
+	upd.DateCreated = s.now.Add(time.Hour)
+
+	if err := s.Storer.Update(s.ctx, upd); err != nil {
+		t.Fatalf("Should be able to update: %s", err)
+	}
+
+	got, err := s.Storer.QueryByID(s.ctx, orig.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query by id: %s", err)
+	}
+
+	upd.UserID = orig.UserID
+	upd.DateCreated = orig.DateCreated
+	if diff := cmp.Diff(got, upd); diff != "" {
+		t.Errorf("Should get back the updated product:\n%s", diff)
+	}
+
+	// The queries see the new values.
+	newName := name.MustParse("Black")
+	got2 := s.query(t, productbus.QueryFilter{Name: &newName}, productbus.DefaultOrderBy)
+	if diff := cmp.Diff(got2, []productbus.Product{upd}); diff != "" {
+		t.Errorf("Should find the product by its new name:\n%s", diff)
+	}
+
+	others := s.query(t, productbus.QueryFilter{}, order.NewBy(productbus.OrderByName, order.ASC))
+	exp := []productbus.Product{s.products[0], s.products[1], upd, s.products[3], s.products[4]}
+	if diff := cmp.Diff(others, exp); diff != "" {
+		t.Errorf("Should not change the other products:\n%s", diff)
+	}
+}
+
+func deleteProduct(t *testing.T, s *suite) {
+	if err := s.Storer.Delete(s.ctx, s.products[0]); err != nil {
+		t.Fatalf("Should be able to delete: %s", err)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, s.products[0].ID); !errors.Is(err, productbus.ErrNotFound) {
+		t.Errorf("Should not find the deleted product, got %v", err)
+	}
+
+	got := s.query(t, productbus.QueryFilter{}, order.NewBy(productbus.OrderByName, order.ASC))
+	if diff := cmp.Diff(got, s.products[1:]); diff != "" {
+		t.Errorf("Should keep the other products:\n%s", diff)
+	}
+
+	// A product can be created again with the id of a deleted product.
+	if err := s.Storer.Create(s.ctx, s.products[0]); err != nil {
+		t.Errorf("Should be able to create the deleted product again: %s", err)
+	}
+}
+
+func deleteUser(t *testing.T, s *suite) {
+	if err := s.Users.Delete(s.ctx, s.users[1]); err != nil {
+		t.Fatalf("Should be able to delete the user: %s", err)
+	}
+
+	got, err := s.Storer.QueryByUserID(s.ctx, s.users[1].ID)
+	if err != nil {
+		t.Fatalf("Should be able to query by user id: %s", err)
+	}
+
+	if len(got) != 0 {
+		t.Errorf("Should delete the products of a deleted user, got %v", names(got))
+	}
+
+	if n := count(t, s, productbus.QueryFilter{}); n != 3 {
+		t.Errorf("Should keep the products of the other user, got %d", n)
+	}
+}
+
+func rollback(t *testing.T, s *suite) {
+	created := newProduct(s.users[0].ID, "Fig", 3, 3, s.now)
+
+	updated := s.products[1]
+	updated.Name = name.MustParse("Plantain")
+
+	tx, txStorer := begin(t, s)
+
+	if err := txStorer.Create(s.ctx, created); err != nil {
+		t.Fatalf("Should be able to create in the transaction: %s", err)
+	}
+
+	if err := txStorer.Update(s.ctx, updated); err != nil {
+		t.Fatalf("Should be able to update in the transaction: %s", err)
+	}
+
+	if err := txStorer.Delete(s.ctx, s.products[0]); err != nil {
+		t.Fatalf("Should be able to delete in the transaction: %s", err)
+	}
+
+	if _, err := txStorer.QueryByID(s.ctx, created.ID); err != nil {
+		t.Errorf("Should see the create inside the transaction: %s", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Should be able to rollback: %s", err)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, created.ID); !errors.Is(err, productbus.ErrNotFound) {
+		t.Errorf("Should not find the product created in a rolled back transaction, got %v", err)
+	}
+
+	got := s.query(t, productbus.QueryFilter{}, order.NewBy(productbus.OrderByName, order.ASC))
+	if diff := cmp.Diff(got, s.products); diff != "" {
+		t.Errorf("Should have the products from before the transaction:\n%s", diff)
+	}
+}
+
+func commit(t *testing.T, s *suite) {
+	created := newProduct(s.users[0].ID, "Fig", 3, 3, s.now)
+
+	tx, txStorer := begin(t, s)
+
+	if err := txStorer.Create(s.ctx, created); err != nil {
+		t.Fatalf("Should be able to create in the transaction: %s", err)
+	}
+
+	if err := txStorer.Delete(s.ctx, s.products[0]); err != nil {
+		t.Fatalf("Should be able to delete in the transaction: %s", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Should be able to commit: %s", err)
+	}
+
+	got, err := s.Storer.QueryByID(s.ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Should find the product created in a committed transaction: %s", err)
+	}
+
+	if diff := cmp.Diff(got, created); diff != "" {
+		t.Errorf("Should get back the committed product:\n%s", diff)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, s.products[0].ID); !errors.Is(err, productbus.ErrNotFound) {
+		t.Errorf("Should not find the product deleted in a committed transaction, got %v", err)
+	}
+}
+
+// =============================================================================
+
+func begin(t *testing.T, s *suite) (sqldb.CommitRollbacker, productbus.Storer) {
+	t.Helper()
+
+	tx, err := s.Beginner.Begin()
+	if err != nil {
+		t.Fatalf("Should be able to begin: %s", err)
+	}
+
+	txStorer, err := s.Storer.NewWithTx(tx)
+	if err != nil {
+		tx.Rollback()
+		t.Fatalf("Should be able to use the transaction: %s", err)
+	}
+
+	return tx, txStorer
+}
+
+func count(t *testing.T, s *suite, filter productbus.QueryFilter) int {
+	t.Helper()
+
+	n, err := s.Storer.Count(s.ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	return n
+}
+
+func newProduct(userID uuid.UUID, n string, cost float64, qty int, created time.Time) productbus.Product {
+	return productbus.Product{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        name.MustParse(n),
+		Cost:        money.MustParse(cost),
+		Quantity:    quantity.MustParse(qty),
+		DateCreated: created,
+		DateUpdated: created,
+	}
+}
+
+func byName(prds []productbus.Product) []productbus.Product {
+	prds = slices.Clone(prds)
+	slices.SortFunc(prds, func(a productbus.Product, b productbus.Product) int {
+		return strings.Compare(a.Name.String(), b.Name.String())
+	})
+
+	return prds
+}
+
+func names(prds []productbus.Product) []string {
+	ns := make([]string, len(prds))
+	for i, prd := range prds {
+		ns[i] = prd.Name.String()
+	}
+
+	return ns
+}
+
+func compare[T int | float64](a T, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
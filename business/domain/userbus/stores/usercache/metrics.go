@@ -0,0 +1,35 @@
+package usercache
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var lookups metric.Int64Counter
+
+var (
+	hit  = metric.WithAttributes(attribute.String("result", "hit"))
+	miss = metric.WithAttributes(attribute.String("result", "miss"))
+)
+
+func init() {
+	meter := otel.Meter("github.com/ardanlabs/service/business/domain/userbus/stores/usercache")
+
+	var err error
+	lookups, err = meter.Int64Counter("usercache.lookups", metric.WithDescription("Number of user cache lookups by result."))
+	if err != nil {
+		panic(err)
+	}
+}
+
+func recordLookup(ctx context.Context, found bool) {
+	if found {
+		lookups.Add(ctx, 1, hit)
+		return
+	}
+
+	lookups.Add(ctx, 1, miss)
+}
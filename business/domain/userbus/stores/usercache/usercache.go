@@ -71,7 +71,14 @@ func (s *Store) Update(ctx context.Context, usr userbus.User) error {
 		return err
 	}
 
-	s.writeOrInvalidate(usr)
+	// Not every column is updated so the row in the database can differ
+	// from usr, and the email may have changed. Drop the entries under both
+	// the old and new values and let the next read repopulate them.
+	if prev, exists := s.cache.Get(usr.ID.String()); exists {
+		s.deleteCache(prev)
+	}
+
+	s.deleteCache(usr)
 
 	return nil
 }
@@ -100,7 +107,7 @@ func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, err
 // QueryByID gets the specified user from the database.
 func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
 	if !s.inTran {
-		if cachedUsr, ok := s.readCache(userID.String()); ok {
+		if cachedUsr, ok := s.readCache(ctx, userID.String()); ok {
 			return cachedUsr, nil
 		}
 	}
@@ -118,7 +125,7 @@ func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User,
 // QueryByEmail gets the specified user from the database by email.
 func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
 	if !s.inTran {
-		if cachedUsr, ok := s.readCache(email.Address); ok {
+		if cachedUsr, ok := s.readCache(ctx, email.Address); ok {
 			return cachedUsr, nil
 		}
 	}
@@ -133,9 +140,12 @@ func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.U
 	return usr, nil
 }
 
-// readCache performs a safe search in the cache for the specified key.
-func (s *Store) readCache(key string) (userbus.User, bool) {
+// readCache performs a safe search in the cache for the specified key and
+// records whether the key was found.
+func (s *Store) readCache(ctx context.Context, key string) (userbus.User, bool) {
 	usr, exists := s.cache.Get(key)
+	recordLookup(ctx, exists)
+
 	if !exists {
 		return userbus.User{}, false
 	}
@@ -0,0 +1,48 @@
+package usercache_test
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usercache"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userstoretest"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+// The cache must not change what the store it wraps returns, so it runs
+// the same suite as the stores themselves.
+
+func Test_ConformanceMemory(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	userstoretest.Run(t, func(t *testing.T) userstoretest.Harness {
+		db := memdb.New()
+
+		return userstoretest.Harness{
+			Storer:   usercache.NewStore(log, usermem.NewStore(log, db), time.Minute),
+			Beginner: db,
+		}
+	})
+}
+
+func Test_ConformancePostgres(t *testing.T) {
+	t.Parallel()
+
+	userstoretest.Run(t, func(t *testing.T) userstoretest.Harness {
+		db := dbtest.New(t, t.Name())
+
+		return userstoretest.Harness{
+			Storer:   usercache.NewStore(db.Log, userdb.NewStore(db.Log, db.DB), time.Minute),
+			Beginner: sqldb.NewBeginner(db.DB),
+		}
+	})
+}
@@ -0,0 +1,23 @@
+package userdb_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userstoretest"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	userstoretest.Run(t, func(t *testing.T) userstoretest.Harness {
+		db := dbtest.New(t, t.Name())
+
+		return userstoretest.Harness{
+			Storer:   userdb.NewStore(db.Log, db.DB),
+			Beginner: sqldb.NewBeginner(db.DB),
+		}
+	})
+}
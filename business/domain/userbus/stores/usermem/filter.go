@@ -0,0 +1,33 @@
+package usermem
+
+import (
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+)
+
+func applyFilter(filter userbus.QueryFilter) func(usr userbus.User) bool {
+	return func(usr userbus.User) bool {
+		if filter.ID != nil && usr.ID != *filter.ID {
+			return false
+		}
+
+		if filter.Name != nil && !strings.Contains(usr.Name.String(), filter.Name.String()) {
+			return false
+		}
+
+		if filter.Email != nil && usr.Email.Address != filter.Email.Address {
+			return false
+		}
+
+		if filter.StartCreatedDate != nil && usr.DateCreated.Before(*filter.StartCreatedDate) {
+			return false
+		}
+
+		if filter.EndCreatedDate != nil && usr.DateCreated.After(*filter.EndCreatedDate) {
+			return false
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,37 @@
+package usermem
+
+import (
+	"slices"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+)
+
+// toMemUser copies the user so the caller can't change the stored row and
+// normalizes the fields the way the database would.
+func toMemUser(bus userbus.User) userbus.User {
+	usr := bus
+	usr.Roles = slices.Clone(bus.Roles)
+	usr.PasswordHash = slices.Clone(bus.PasswordHash)
+	usr.DateCreated = memdb.Time(bus.DateCreated)
+	usr.DateUpdated = memdb.Time(bus.DateUpdated)
+
+	return usr
+}
+
+func toBusUser(usr userbus.User) userbus.User {
+	bus := usr
+	bus.Roles = slices.Clone(usr.Roles)
+	bus.PasswordHash = slices.Clone(usr.PasswordHash)
+
+	return bus
+}
+
+func toBusUsers(usrs []userbus.User) []userbus.User {
+	bus := make([]userbus.User, len(usrs))
+	for i, usr := range usrs {
+		bus[i] = toBusUser(usr)
+	}
+
+	return bus
+}
@@ -0,0 +1,33 @@
+package usermem
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/uuid"
+)
+
+var orderByFields = map[string]memdb.CompareFunc[userbus.User]{
+	userbus.OrderByID: func(a userbus.User, b userbus.User) int {
+		return memdb.CompareID(a.ID, b.ID)
+	},
+	userbus.OrderByName: func(a userbus.User, b userbus.User) int {
+		return strings.Compare(a.Name.String(), b.Name.String())
+	},
+	userbus.OrderByEmail: func(a userbus.User, b userbus.User) int {
+		return strings.Compare(a.Email.Address, b.Email.Address)
+	},
+	userbus.OrderByRoles: func(a userbus.User, b userbus.User) int {
+		return slices.Compare(role.ParseToString(a.Roles), role.ParseToString(b.Roles))
+	},
+	userbus.OrderByEnabled: func(a userbus.User, b userbus.User) int {
+		return memdb.CompareBool(a.Enabled, b.Enabled)
+	},
+}
+
+func userID(usr userbus.User) uuid.UUID {
+	return usr.ID
+}
@@ -0,0 +1,144 @@
+// Package usermem contains user related CRUD functionality backed by an
+// in-memory database.
+package usermem
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Table is the name of the table that holds the users.
+const Table = "users"
+
+// Store manages the set of APIs for user in-memory access.
+type Store struct {
+	log   *logger.Logger
+	users *memdb.Table[userbus.User]
+	tx    *memdb.Tx
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *memdb.DB) *Store {
+	return &Store{
+		log:   log,
+		users: memdb.GetTable[userbus.User](db, Table),
+	}
+}
+
+// NewWithTx constructs a new Store value that records its writes in the
+// specified transaction so they can be rolled back.
+func (s *Store) NewWithTx(tx sqldb.CommitRollbacker) (userbus.Storer, error) {
+	mtx, err := memdb.GetTx(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	store := Store{
+		log:   s.log,
+		users: s.users,
+		tx:    mtx,
+	}
+
+	return &store, nil
+}
+
+// Create inserts a new user into the database.
+func (s *Store) Create(ctx context.Context, usr userbus.User) error {
+	if err := s.users.Insert(s.tx, usr.ID, toMemUser(usr), sameEmail(usr)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return fmt.Errorf("insert: %w", userbus.ErrUniqueEmail)
+		}
+		return fmt.Errorf("insert: %w", err)
+	}
+
+	return nil
+}
+
+// Update replaces a user document in the database.
+func (s *Store) Update(ctx context.Context, usr userbus.User) error {
+	set := func(row userbus.User) userbus.User {
+		upd := toMemUser(usr)
+		row.Name = upd.Name
+		row.Email = upd.Email
+		row.Roles = upd.Roles
+		row.PasswordHash = upd.PasswordHash
+		row.Department = upd.Department
+		row.Enabled = upd.Enabled
+		row.DateUpdated = upd.DateUpdated
+		return row
+	}
+
+	if err := s.users.Update(s.tx, usr.ID, set, sameEmail(usr)); err != nil {
+		if errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+			return userbus.ErrUniqueEmail
+		}
+		return fmt.Errorf("update: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes a user from the database.
+func (s *Store) Delete(ctx context.Context, usr userbus.User) error {
+	if err := s.users.Delete(s.tx, usr.ID); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+
+	return nil
+}
+
+// Query retrieves a list of existing users from the database.
+func (s *Store) Query(ctx context.Context, filter userbus.QueryFilter, orderBy order.By, page page.Page) ([]userbus.User, error) {
+	usrs := s.users.Select(applyFilter(filter))
+
+	if err := memdb.Sort(usrs, orderBy, orderByFields, userID); err != nil {
+		return nil, err
+	}
+
+	return toBusUsers(memdb.Page(usrs, page)), nil
+}
+
+// Count returns the total number of users in the DB.
+func (s *Store) Count(ctx context.Context, filter userbus.QueryFilter) (int, error) {
+	return len(s.users.Select(applyFilter(filter))), nil
+}
+
+// QueryByID gets the specified user from the database.
+func (s *Store) QueryByID(ctx context.Context, userID uuid.UUID) (userbus.User, error) {
+	usr, exists := s.users.Get(userID)
+	if !exists {
+		return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+	}
+
+	return toBusUser(usr), nil
+}
+
+// QueryByEmail gets the specified user from the database by email.
+func (s *Store) QueryByEmail(ctx context.Context, email mail.Address) (userbus.User, error) {
+	usrs := s.users.Select(func(usr userbus.User) bool {
+		return usr.Email.Address == email.Address
+	})
+
+	if len(usrs) == 0 {
+		return userbus.User{}, fmt.Errorf("db: %w", userbus.ErrNotFound)
+	}
+
+	return toBusUser(usrs[0]), nil
+}
+
+// sameEmail enforces the unique constraint on the email column.
+func sameEmail(usr userbus.User) func(userbus.User) bool {
+	return func(existing userbus.User) bool {
+		return existing.Email.Address == usr.Email.Address
+	}
+}
@@ -0,0 +1,27 @@
+package usermem_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userstoretest"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	userstoretest.Run(t, func(t *testing.T) userstoretest.Harness {
+		db := memdb.New()
+
+		return userstoretest.Harness{
+			Storer:   usermem.NewStore(log, db),
+			Beginner: db,
+		}
+	})
+}
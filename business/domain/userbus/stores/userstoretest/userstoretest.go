@@ -0,0 +1,591 @@
+// Package userstoretest provides a conformance suite for implementations of
+// userbus.Storer. Every store, including the usercache decorator, runs the
+// same suite so they are known to behave like userdb:
+//
+//	func Test_Conformance(t *testing.T) {
+//	    userstoretest.Run(t, func(t *testing.T) userstoretest.Harness {
+//	        db := ... // an empty, migrated database owned by t
+//	        return userstoretest.Harness{
+//	            Storer:   NewStore(log, db),
+//	            Beginner: sqldb.NewBeginner(db),
+//	        }
+//	    })
+//	}
+//
+// Each case gets a new harness so the cases can't see each other's data.
+// The cases look users up before changing them so a caching store is
+// checked for stale entries as well.
+//
+// The suites of the domains whose records belong to a user create their
+// owners with NewUser through a store that satisfies Users.
+package userstoretest
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+// Users adds, changes and removes the users that the records of other
+// domains belong to. A userbus.Storer for the same database satisfies the
+// interface.
+type Users interface {
+	Create(ctx context.Context, usr userbus.User) error
+	Update(ctx context.Context, usr userbus.User) error
+	Delete(ctx context.Context, usr userbus.User) error
+}
+
+// NewUser returns an enabled user with the user role, created at the time.
+func NewUser(n string, email string, created time.Time) userbus.User {
+	return userbus.User{
+		ID:           uuid.New(),
+		Name:         name.MustParse(n),
+		Email:        mail.Address{Address: email},
+		Roles:        []role.Role{role.User},
+		PasswordHash: []byte("hash"),
+		Enabled:      true,
+		DateCreated:  created,
+		DateUpdated:  created,
+	}
+}
+
+// Harness is the set of values the suite needs from a store. The store
+// must start out empty.
+type Harness struct {
+	Storer   userbus.Storer
+	Beginner sqldb.Beginner
+}
+
+// Run executes the conformance suite against the harnesses returned by
+// newHarness, which is called once per case.
+func Run(t *testing.T, newHarness func(t *testing.T) Harness) {
+	table := []struct {
+		name string
+		test func(t *testing.T, s *suite)
+	}{
+		{"create", create},
+		{"duplicate", duplicate},
+		{"not-found", notFound},
+		{"filter", filter},
+		{"order", orderBy},
+		{"page", paging},
+		{"update", update},
+		{"update-email", updateEmail},
+		{"delete", deleteUser},
+		{"rollback", rollback},
+		{"commit", commit},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSuite(t, newHarness(t))
+			tt.test(t, s)
+		})
+	}
+}
+
+// =============================================================================
+
+// suite holds the data every case starts with. The users were created an
+// hour apart and share roles, name parts and enabled flags so the filters
+// and orderings can be checked for more than one match.
+type suite struct {
+	Harness
+	ctx   context.Context
+	now   time.Time
+	users []userbus.User
+}
+
+func newSuite(t *testing.T, h Harness) *suite {
+	s := suite{
+		Harness: h,
+		ctx:     context.Background(),
+		now:     time.Now().Truncate(time.Second),
+	}
+
+	s.users = []userbus.User{
+		newUser("Ada Admin", "ada@storetest.com", []role.Role{role.Admin}, true, s.now.Add(-3*time.Hour)),
+		newUser("Bea User", "bea@storetest.com", []role.Role{role.User}, true, s.now.Add(-2*time.Hour)),
+		newUser("Cal User", "cal@storetest.com", []role.Role{role.User}, false, s.now.Add(-time.Hour)),
+		newUser("Dee Admin", "dee@storetest.com", []role.Role{role.Admin, role.User}, true, s.now),
+	}
+
+	s.users[0].Department = name.MustParseNull("Engineering")
+
+	for _, usr := range s.users {
+		if err := s.Storer.Create(s.ctx, usr); err != nil {
+			t.Fatalf("Should be able to create user %s: %s", usr.Email.Address, err)
+		}
+	}
+
+	return &s
+}
+
+// query returns every user matching the filter in the order requested.
+func (s *suite) query(t *testing.T, filter userbus.QueryFilter, orderBy order.By) []userbus.User {
+	t.Helper()
+
+	usrs, err := s.Storer.Query(s.ctx, filter, orderBy, page.MustParse("1", "100"))
+	if err != nil {
+		t.Fatalf("Should be able to query: %s", err)
+	}
+
+	return usrs
+}
+
+// lookup checks the user can be found by both id and email. It also warms
+// the cache of a caching store.
+func (s *suite) lookup(t *testing.T, exp userbus.User) {
+	t.Helper()
+
+	got, err := s.Storer.QueryByID(s.ctx, exp.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query by id: %s", err)
+	}
+
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Errorf("Should get back the user by id:\n%s", diff)
+	}
+
+	got, err = s.Storer.QueryByEmail(s.ctx, exp.Email)
+	if err != nil {
+		t.Fatalf("Should be able to query by email: %s", err)
+	}
+
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Errorf("Should get back the user by email:\n%s", diff)
+	}
+}
+
+// =============================================================================
+
+func create(t *testing.T, s *suite) {
+	for _, exp := range s.users {
+		s.lookup(t, exp)
+	}
+}
+
+func duplicate(t *testing.T, s *suite) {
+	dup := newUser("Eve User", s.users[1].Email.Address, []role.Role{role.User}, true, s.now)
+
+	if err := s.Storer.Create(s.ctx, dup); !errors.Is(err, userbus.ErrUniqueEmail) {
+		t.Errorf("Should get ErrUniqueEmail, got %v", err)
+	}
+
+	sameID := s.users[1]
+	sameID.Email = mail.Address{Address: "eve@storetest.com"}
+
+	if err := s.Storer.Create(s.ctx, sameID); err == nil {
+		t.Errorf("Should not be able to create a user with an existing id")
+	}
+
+	s.lookup(t, s.users[1])
+
+	if _, err := s.Storer.QueryByEmail(s.ctx, sameID.Email); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should not find the user that failed to be created, got %v", err)
+	}
+}
+
+func notFound(t *testing.T, s *suite) {
+	if _, err := s.Storer.QueryByID(s.ctx, uuid.New()); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should get ErrNotFound by id, got %v", err)
+	}
+
+	if _, err := s.Storer.QueryByEmail(s.ctx, mail.Address{Address: "nobody@storetest.com"}); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should get ErrNotFound by email, got %v", err)
+	}
+
+	// Like the SQL statements, changing a user that doesn't exist is not
+	// an error.
+	missing := newUser("Missing User", "missing@storetest.com", []role.Role{role.User}, true, s.now)
+
+	if err := s.Storer.Update(s.ctx, missing); err != nil {
+		t.Errorf("Should be able to update a missing user: %s", err)
+	}
+
+	if err := s.Storer.Delete(s.ctx, missing); err != nil {
+		t.Errorf("Should be able to delete a missing user: %s", err)
+	}
+
+	if n := count(t, s, userbus.QueryFilter{}); n != len(s.users) {
+		t.Errorf("Should still have %d users, got %d", len(s.users), n)
+	}
+}
+
+func filter(t *testing.T, s *suite) {
+	ada, bea, cal, dee := s.users[0], s.users[1], s.users[2], s.users[3]
+
+	admin := name.MustParse("Admin")
+	user := name.MustParse("User")
+	none := name.MustParse("Nobody")
+	start := s.now.Add(-90 * time.Minute)
+	end := s.now.Add(-150 * time.Minute)
+	unknown := mail.Address{Address: "nobody@storetest.com"}
+
+	table := []struct {
+		name   string
+		filter userbus.QueryFilter
+		exp    []userbus.User
+	}{
+		{"none", userbus.QueryFilter{}, []userbus.User{ada, bea, cal, dee}},
+		{"id", userbus.QueryFilter{ID: &cal.ID}, []userbus.User{cal}},
+		{"name", userbus.QueryFilter{Name: &admin}, []userbus.User{ada, dee}},
+		{"name-none", userbus.QueryFilter{Name: &none}, []userbus.User{}},
+		{"email", userbus.QueryFilter{Email: &bea.Email}, []userbus.User{bea}},
+		{"email-none", userbus.QueryFilter{Email: &unknown}, []userbus.User{}},
+		{"start", userbus.QueryFilter{StartCreatedDate: &start}, []userbus.User{cal, dee}},
+		{"start-exact", userbus.QueryFilter{StartCreatedDate: &dee.DateCreated}, []userbus.User{dee}},
+		{"end", userbus.QueryFilter{EndCreatedDate: &end}, []userbus.User{ada}},
+		{"end-exact", userbus.QueryFilter{EndCreatedDate: &ada.DateCreated}, []userbus.User{ada}},
+		{"range", userbus.QueryFilter{StartCreatedDate: &bea.DateCreated, EndCreatedDate: &cal.DateCreated}, []userbus.User{bea, cal}},
+		{"id-name", userbus.QueryFilter{ID: &bea.ID, Name: &user}, []userbus.User{bea}},
+		{"id-name-mismatch", userbus.QueryFilter{ID: &bea.ID, Name: &admin}, []userbus.User{}},
+		{"name-start", userbus.QueryFilter{Name: &user, StartCreatedDate: &start}, []userbus.User{cal}},
+		{"email-name-mismatch", userbus.QueryFilter{Email: &ada.Email, Name: &user}, []userbus.User{}},
+	}
+
+	for _, tt := range table {
+		got := s.query(t, tt.filter, order.NewBy(userbus.OrderByName, order.ASC))
+
+		if diff := cmp.Diff(got, tt.exp); diff != "" {
+			t.Errorf("%s: Should get the expected users:\n%s", tt.name, diff)
+		}
+
+		if n := count(t, s, tt.filter); n != len(tt.exp) {
+			t.Errorf("%s: Should get count %d, got %d", tt.name, len(tt.exp), n)
+		}
+	}
+}
+
+func orderBy(t *testing.T, s *suite) {
+	fields := map[string]func(a userbus.User, b userbus.User) int{
+		userbus.OrderByID: func(a userbus.User, b userbus.User) int {
+			return strings.Compare(a.ID.String(), b.ID.String())
+		},
+		userbus.OrderByName: func(a userbus.User, b userbus.User) int {
+			return strings.Compare(a.Name.String(), b.Name.String())
+		},
+		userbus.OrderByEmail: func(a userbus.User, b userbus.User) int {
+			return strings.Compare(a.Email.Address, b.Email.Address)
+		},
+		userbus.OrderByRoles: func(a userbus.User, b userbus.User) int {
+			return slices.Compare(role.ParseToString(a.Roles), role.ParseToString(b.Roles))
+		},
+		userbus.OrderByEnabled: func(a userbus.User, b userbus.User) int {
+			switch {
+			case a.Enabled == b.Enabled:
+				return 0
+			case a.Enabled:
+				return 1
+			}
+			return -1
+		},
+	}
+
+	for field, cmpFn := range fields {
+		for _, direction := range []string{order.ASC, order.DESC} {
+			got := s.query(t, userbus.QueryFilter{}, order.NewBy(field, direction))
+
+			if len(got) != len(s.users) {
+				t.Fatalf("%s %s: Should get %d users, got %d", field, direction, len(s.users), len(got))
+			}
+
+			sorted := slices.IsSortedFunc(got, func(a userbus.User, b userbus.User) int {
+				if direction == order.DESC {
+					return cmpFn(b, a)
+				}
+				return cmpFn(a, b)
+			})
+
+			if !sorted {
+				t.Errorf("%s %s: Should get the users in order, got %v", field, direction, emails(got))
+			}
+		}
+	}
+
+	if _, err := s.Storer.Query(s.ctx, userbus.QueryFilter{}, order.NewBy("unknown", order.ASC), page.MustParse("1", "10")); err == nil {
+		t.Errorf("Should get an error for an unknown order field")
+	}
+}
+
+func paging(t *testing.T, s *suite) {
+	all := s.query(t, userbus.QueryFilter{}, userbus.DefaultOrderBy)
+
+	table := []struct {
+		page page.Page
+		exp  []userbus.User
+	}{
+		{page.MustParse("1", "3"), all[0:3]},
+		{page.MustParse("2", "3"), all[3:4]},
+		{page.MustParse("3", "3"), []userbus.User{}},
+		{page.MustParse("1", "4"), all},
+		{page.MustParse("2", "4"), []userbus.User{}},
+		{page.MustParse("4", "1"), all[3:4]},
+		{page.MustParse("1", "100"), all},
+		{page.MustParse("100", "100"), []userbus.User{}},
+	}
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, userbus.QueryFilter{}, userbus.DefaultOrderBy, tt.page)
+		if err != nil {
+			t.Fatalf("%s: Should be able to query: %s", tt.page, err)
+		}
+
+		if diff := cmp.Diff(got, tt.exp); diff != "" {
+			t.Errorf("%s: Should get the page of users:\n%s", tt.page, diff)
+		}
+	}
+
+	// The count is not affected by paging.
+	if n := count(t, s, userbus.QueryFilter{}); n != len(all) {
+		t.Errorf("Should get count %d, got %d", len(all), n)
+	}
+}
+
+func update(t *testing.T, s *suite) {
+	orig := s.users[1]
+	s.lookup(t, orig)
+
+	upd := orig
+	upd.Name = name.MustParse("Beatrice User")
+	upd.Roles = []role.Role{role.Admin}
+	upd.PasswordHash = []byte("new hash")
+	upd.Department = name.MustParseNull("Sales")
+	upd.Enabled = false
+	upd.DateUpdated = s.now.Add(time.Hour)
+
+	// The date created is not something that can be updated.
+	upd.DateCreated = s.now.Add(time.Hour)
+
+	if err := s.Storer.Update(s.ctx, upd); err != nil {
+		t.Fatalf("Should be able to update: %s", err)
+	}
+
+	upd.DateCreated = orig.DateCreated
+	s.lookup(t, upd)
+
+	got := s.query(t, userbus.QueryFilter{}, order.NewBy(userbus.OrderByEmail, order.ASC))
+	exp := []userbus.User{s.users[0], upd, s.users[2], s.users[3]}
+	if diff := cmp.Diff(got, exp); diff != "" {
+		t.Errorf("Should not change the other users:\n%s", diff)
+	}
+
+	// Taking the email of another user is rejected and leaves the user as
+	// it was.
+	taken := upd
+	taken.Email = s.users[0].Email
+
+	if err := s.Storer.Update(s.ctx, taken); !errors.Is(err, userbus.ErrUniqueEmail) {
+		t.Errorf("Should get ErrUniqueEmail, got %v", err)
+	}
+
+	s.lookup(t, upd)
+	s.lookup(t, s.users[0])
+}
+
+func updateEmail(t *testing.T, s *suite) {
+	orig := s.users[2]
+	s.lookup(t, orig)
+
+	upd := orig
+	upd.Email = mail.Address{Address: "calvin@storetest.com"}
+
+	if err := s.Storer.Update(s.ctx, upd); err != nil {
+		t.Fatalf("Should be able to update: %s", err)
+	}
+
+	s.lookup(t, upd)
+
+	if _, err := s.Storer.QueryByEmail(s.ctx, orig.Email); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should not find the user by the old email, got %v", err)
+	}
+
+	// The old email is free to be used by a new user.
+	reuse := newUser("Cal Other", orig.Email.Address, []role.Role{role.User}, true, s.now)
+
+	if err := s.Storer.Create(s.ctx, reuse); err != nil {
+		t.Fatalf("Should be able to create a user with the old email: %s", err)
+	}
+
+	s.lookup(t, reuse)
+}
+
+func deleteUser(t *testing.T, s *suite) {
+	usr := s.users[0]
+	s.lookup(t, usr)
+
+	if err := s.Storer.Delete(s.ctx, usr); err != nil {
+		t.Fatalf("Should be able to delete: %s", err)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, usr.ID); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should not find the deleted user by id, got %v", err)
+	}
+
+	if _, err := s.Storer.QueryByEmail(s.ctx, usr.Email); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should not find the deleted user by email, got %v", err)
+	}
+
+	got := s.query(t, userbus.QueryFilter{}, order.NewBy(userbus.OrderByEmail, order.ASC))
+	if diff := cmp.Diff(got, s.users[1:]); diff != "" {
+		t.Errorf("Should keep the other users:\n%s", diff)
+	}
+
+	// A user can be created again with the id and email of a deleted user.
+	if err := s.Storer.Create(s.ctx, usr); err != nil {
+		t.Fatalf("Should be able to create the deleted user again: %s", err)
+	}
+
+	s.lookup(t, usr)
+}
+
+func rollback(t *testing.T, s *suite) {
+	for _, usr := range s.users {
+		s.lookup(t, usr)
+	}
+
+	created := newUser("Eve User", "eve@storetest.com", []role.Role{role.User}, true, s.now)
+
+	updated := s.users[1]
+	updated.Name = name.MustParse("Beatrice User")
+	updated.Email = mail.Address{Address: "beatrice@storetest.com"}
+
+	tx, txStorer := begin(t, s)
+
+	if err := txStorer.Create(s.ctx, created); err != nil {
+		t.Fatalf("Should be able to create in the transaction: %s", err)
+	}
+
+	if err := txStorer.Update(s.ctx, updated); err != nil {
+		t.Fatalf("Should be able to update in the transaction: %s", err)
+	}
+
+	if err := txStorer.Delete(s.ctx, s.users[0]); err != nil {
+		t.Fatalf("Should be able to delete in the transaction: %s", err)
+	}
+
+	got, err := txStorer.QueryByID(s.ctx, updated.ID)
+	if err != nil {
+		t.Fatalf("Should be able to query by id in the transaction: %s", err)
+	}
+
+	if diff := cmp.Diff(got, updated); diff != "" {
+		t.Errorf("Should see the update inside the transaction:\n%s", diff)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Should be able to rollback: %s", err)
+	}
+
+	if _, err := s.Storer.QueryByID(s.ctx, created.ID); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should not find the user created in a rolled back transaction, got %v", err)
+	}
+
+	if _, err := s.Storer.QueryByEmail(s.ctx, updated.Email); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should not find the email set in a rolled back transaction, got %v", err)
+	}
+
+	for _, usr := range s.users {
+		s.lookup(t, usr)
+	}
+
+	if n := count(t, s, userbus.QueryFilter{}); n != len(s.users) {
+		t.Errorf("Should have %d users, got %d", len(s.users), n)
+	}
+}
+
+func commit(t *testing.T, s *suite) {
+	for _, usr := range s.users {
+		s.lookup(t, usr)
+	}
+
+	created := newUser("Eve User", "eve@storetest.com", []role.Role{role.User}, true, s.now)
+
+	updated := s.users[1]
+	updated.Name = name.MustParse("Beatrice User")
+
+	tx, txStorer := begin(t, s)
+
+	if err := txStorer.Create(s.ctx, created); err != nil {
+		t.Fatalf("Should be able to create in the transaction: %s", err)
+	}
+
+	if err := txStorer.Update(s.ctx, updated); err != nil {
+		t.Fatalf("Should be able to update in the transaction: %s", err)
+	}
+
+	if err := txStorer.Delete(s.ctx, s.users[0]); err != nil {
+		t.Fatalf("Should be able to delete in the transaction: %s", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Should be able to commit: %s", err)
+	}
+
+	s.lookup(t, created)
+	s.lookup(t, updated)
+
+	if _, err := s.Storer.QueryByID(s.ctx, s.users[0].ID); !errors.Is(err, userbus.ErrNotFound) {
+		t.Errorf("Should not find the user deleted in a committed transaction, got %v", err)
+	}
+}
+
+// =============================================================================
+
+func begin(t *testing.T, s *suite) (sqldb.CommitRollbacker, userbus.Storer) {
+	t.Helper()
+
+	tx, err := s.Beginner.Begin()
+	if err != nil {
+		t.Fatalf("Should be able to begin: %s", err)
+	}
+
+	txStorer, err := s.Storer.NewWithTx(tx)
+	if err != nil {
+		tx.Rollback()
+		t.Fatalf("Should be able to use the transaction: %s", err)
+	}
+
+	return tx, txStorer
+}
+
+func count(t *testing.T, s *suite, filter userbus.QueryFilter) int {
+	t.Helper()
+
+	n, err := s.Storer.Count(s.ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	return n
+}
+
+func newUser(n string, email string, roles []role.Role, enabled bool, created time.Time) userbus.User {
+	usr := NewUser(n, email, created)
+	usr.Roles = roles
+	usr.Enabled = enabled
+
+	return usr
+}
+
+func emails(usrs []userbus.User) []string {
+	es := make([]string, len(usrs))
+	for i, usr := range usrs {
+		es[i] = usr.Email.Address
+	}
+
+	return es
+}
@@ -32,7 +32,7 @@ func (s *Store) applyFilter(filter vproductbus.QueryFilter, data map[string]any,
 	}
 
 	if filter.UserName != nil {
-		data["user_name"] = fmt.Sprintf("%%%s%%", filter.Name)
+		data["user_name"] = fmt.Sprintf("%%%s%%", filter.UserName)
 		wc = append(wc, "user_name LIKE :user_name")
 	}
 
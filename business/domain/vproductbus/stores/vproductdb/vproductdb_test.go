@@ -0,0 +1,25 @@
+package vproductdb_test
+
+import (
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productpg"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userdb"
+	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductdb"
+	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductstoretest"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	vproductstoretest.Run(t, func(t *testing.T) vproductstoretest.Harness {
+		db := dbtest.New(t, t.Name())
+
+		return vproductstoretest.Harness{
+			Storer:   vproductdb.NewStore(db.Log, db.DB),
+			Products: productpg.NewStore(db.Log, db.DB),
+			Users:    userdb.NewStore(db.Log, db.DB),
+		}
+	})
+}
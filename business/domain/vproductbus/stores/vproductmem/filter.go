@@ -0,0 +1,33 @@
+package vproductmem
+
+import (
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/vproductbus"
+)
+
+func applyFilter(filter vproductbus.QueryFilter) func(prd vproductbus.Product) bool {
+	return func(prd vproductbus.Product) bool {
+		if filter.ID != nil && prd.ID != *filter.ID {
+			return false
+		}
+
+		if filter.Name != nil && !strings.Contains(prd.Name.String(), filter.Name.String()) {
+			return false
+		}
+
+		if filter.Cost != nil && prd.Cost.Value() != *filter.Cost {
+			return false
+		}
+
+		if filter.Quantity != nil && prd.Quantity.Value() != *filter.Quantity {
+			return false
+		}
+
+		if filter.UserName != nil && !strings.Contains(prd.UserName.String(), filter.UserName.String()) {
+			return false
+		}
+
+		return true
+	}
+}
@@ -0,0 +1,30 @@
+package vproductmem
+
+import (
+	"cmp"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/vproductbus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+)
+
+var orderByFields = map[string]memdb.CompareFunc[vproductbus.Product]{
+	vproductbus.OrderByProductID: func(a vproductbus.Product, b vproductbus.Product) int {
+		return memdb.CompareID(a.ID, b.ID)
+	},
+	vproductbus.OrderByUserID: func(a vproductbus.Product, b vproductbus.Product) int {
+		return memdb.CompareID(a.UserID, b.UserID)
+	},
+	vproductbus.OrderByName: func(a vproductbus.Product, b vproductbus.Product) int {
+		return strings.Compare(a.Name.String(), b.Name.String())
+	},
+	vproductbus.OrderByCost: func(a vproductbus.Product, b vproductbus.Product) int {
+		return cmp.Compare(a.Cost.Value(), b.Cost.Value())
+	},
+	vproductbus.OrderByQuantity: func(a vproductbus.Product, b vproductbus.Product) int {
+		return cmp.Compare(a.Quantity.Value(), b.Quantity.Value())
+	},
+	vproductbus.OrderByUserName: func(a vproductbus.Product, b vproductbus.Product) int {
+		return strings.Compare(a.UserName.String(), b.UserName.String())
+	},
+}
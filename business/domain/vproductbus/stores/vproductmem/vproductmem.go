@@ -0,0 +1,86 @@
+// Package vproductmem provides access to the product view backed by an
+// in-memory database.
+package vproductmem
+
+import (
+	"context"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productmem"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/domain/vproductbus"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/google/uuid"
+)
+
+// Store manages the set of APIs for product view in-memory access. Like the
+// view_products view it joins the products and users tables, so it reads
+// the rows written by the productmem and usermem stores.
+type Store struct {
+	log      *logger.Logger
+	products *memdb.Table[productbus.Product]
+	users    *memdb.Table[userbus.User]
+}
+
+// NewStore constructs the api for data access.
+func NewStore(log *logger.Logger, db *memdb.DB) *Store {
+	return &Store{
+		log:      log,
+		products: memdb.GetTable[productbus.Product](db, productmem.Table),
+		users:    memdb.GetTable[userbus.User](db, usermem.Table),
+	}
+}
+
+// Query retrieves a list of existing products from the database.
+func (s *Store) Query(ctx context.Context, filter vproductbus.QueryFilter, orderBy order.By, page page.Page) ([]vproductbus.Product, error) {
+	prds := s.view(filter)
+
+	if err := memdb.Sort(prds, orderBy, orderByFields, productID); err != nil {
+		return nil, err
+	}
+
+	return memdb.Page(prds, page), nil
+}
+
+// Count returns the total number of products in the DB.
+func (s *Store) Count(ctx context.Context, filter vproductbus.QueryFilter) (int, error) {
+	return len(s.view(filter)), nil
+}
+
+// view joins every product with its user and applies the filter.
+func (s *Store) view(filter vproductbus.QueryFilter) []vproductbus.Product {
+	match := applyFilter(filter)
+
+	var prds []vproductbus.Product
+	for _, prd := range s.products.Select(nil) {
+		usr, exists := s.users.Get(prd.UserID)
+		if !exists {
+			continue
+		}
+
+		vprd := vproductbus.Product{
+			ID:          prd.ID,
+			UserID:      prd.UserID,
+			Name:        prd.Name,
+			Cost:        prd.Cost,
+			Quantity:    prd.Quantity,
+			DateCreated: prd.DateCreated,
+			DateUpdated: prd.DateUpdated,
+			UserName:    usr.Name,
+		}
+
+		if match(vprd) {
+			prds = append(prds, vprd)
+		}
+	}
+
+	return prds
+}
+
+func productID(prd vproductbus.Product) uuid.UUID {
+	return prd.ID
+}
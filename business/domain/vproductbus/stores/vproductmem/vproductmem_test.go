@@ -0,0 +1,30 @@
+package vproductmem_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/productbus/stores/productmem"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/usermem"
+	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductmem"
+	"github.com/ardanlabs/service/business/domain/vproductbus/stores/vproductstoretest"
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Conformance(t *testing.T) {
+	t.Parallel()
+
+	log := logger.New(io.Discard, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	vproductstoretest.Run(t, func(t *testing.T) vproductstoretest.Harness {
+		db := memdb.New()
+
+		return vproductstoretest.Harness{
+			Storer:   vproductmem.NewStore(log, db),
+			Products: productmem.NewStore(log, db),
+			Users:    usermem.NewStore(log, db),
+		}
+	})
+}
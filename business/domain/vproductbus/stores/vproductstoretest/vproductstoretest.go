@@ -0,0 +1,289 @@
+// Package vproductstoretest provides a conformance suite for implementations
+// of vproductbus.Storer. Every store runs the same suite so they are known
+// to behave like vproductdb:
+//
+//	func Test_Conformance(t *testing.T) {
+//	    vproductstoretest.Run(t, func(t *testing.T) vproductstoretest.Harness {
+//	        db := ... // an empty, migrated database owned by t
+//	        return vproductstoretest.Harness{
+//	            Storer:   NewStore(log, db),
+//	            Products: ...,
+//	            Users:    ...,
+//	        }
+//	    })
+//	}
+//
+// Each case gets a new harness so the cases can't see each other's data.
+package vproductstoretest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/domain/userbus/stores/userstoretest"
+	"github.com/ardanlabs/service/business/domain/vproductbus"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+// Products is the behavior the suite needs to add the products the view
+// is built from.
+type Products interface {
+	Create(ctx context.Context, prd productbus.Product) error
+}
+
+// Harness is the set of values the suite needs from a store. The store
+// must start out empty and the products and users are added to the same
+// database since the view joins them.
+type Harness struct {
+	Storer   vproductbus.Storer
+	Products Products
+	Users    userstoretest.Users
+}
+
+// Run executes the conformance suite against the harnesses returned by
+// newHarness, which is called once per case.
+func Run(t *testing.T, newHarness func(t *testing.T) Harness) {
+	table := []struct {
+		name string
+		test func(t *testing.T, s *suite)
+	}{
+		{"filter", filter},
+		{"order", orderBy},
+		{"page", paging},
+		{"update-user", updateUser},
+		{"delete-user", deleteUser},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSuite(t, newHarness(t))
+			tt.test(t, s)
+		})
+	}
+}
+
+// =============================================================================
+
+// suite holds the data every case starts with. The first user owns two of
+// the products and two of them share a quantity so the filters can be
+// checked for more than one match.
+type suite struct {
+	Harness
+	ctx      context.Context
+	now      time.Time
+	users    []userbus.User
+	products []vproductbus.Product
+}
+
+func newSuite(t *testing.T, h Harness) *suite {
+	s := suite{
+		Harness: h,
+		ctx:     context.Background(),
+		now:     time.Now().Truncate(time.Second),
+	}
+
+	s.users = []userbus.User{
+		userstoretest.NewUser("Alice Owner", "alice@storetest.com", s.now),
+		userstoretest.NewUser("Bob Owner", "bob@storetest.com", s.now),
+	}
+
+	for _, usr := range s.users {
+		if err := s.Users.Create(s.ctx, usr); err != nil {
+			t.Fatalf("Should be able to create user %s: %s", usr.Email.Address, err)
+		}
+	}
+
+	s.products = []vproductbus.Product{
+		newProduct(s.users[0], "Apple", 1.25, 10, s.now),
+		newProduct(s.users[0], "Banana", 0.5, 20, s.now),
+		newProduct(s.users[1], "Cherry", 3.75, 10, s.now),
+	}
+
+	for _, prd := range s.products {
+		if err := s.Products.Create(s.ctx, toProduct(prd)); err != nil {
+			t.Fatalf("Should be able to create product %s: %s", prd.Name, err)
+		}
+	}
+
+	return &s
+}
+
+// =============================================================================
+
+func filter(t *testing.T, s *suite) {
+	part := name.MustParse("nan")
+	alice := name.MustParse("Alice")
+	cost := 3.75
+	qty := 10
+	apple, banana, cherry := s.products[0], s.products[1], s.products[2]
+
+	table := []struct {
+		name   string
+		filter vproductbus.QueryFilter
+		exp    []vproductbus.Product
+	}{
+		{"none", vproductbus.QueryFilter{}, []vproductbus.Product{apple, banana, cherry}},
+		{"id", vproductbus.QueryFilter{ID: &banana.ID}, []vproductbus.Product{banana}},
+		{"name", vproductbus.QueryFilter{Name: &part}, []vproductbus.Product{banana}},
+		{"cost", vproductbus.QueryFilter{Cost: &cost}, []vproductbus.Product{cherry}},
+		{"quantity", vproductbus.QueryFilter{Quantity: &qty}, []vproductbus.Product{apple, cherry}},
+		{"user-name", vproductbus.QueryFilter{UserName: &alice}, []vproductbus.Product{apple, banana}},
+		{"user-name-quantity", vproductbus.QueryFilter{UserName: &alice, Quantity: &qty}, []vproductbus.Product{apple}},
+		{"no-match", vproductbus.QueryFilter{UserName: &alice, Cost: &cost}, []vproductbus.Product{}},
+	}
+
+	orderBy := order.NewBy(vproductbus.OrderByName, order.ASC)
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, tt.filter, orderBy, page.MustParse("1", "10"))
+		if err != nil {
+			t.Fatalf("%s: Should be able to query: %s", tt.name, err)
+		}
+
+		if diff := cmp.Diff(got, tt.exp); diff != "" {
+			t.Errorf("%s: Should get the expected products:\n%s", tt.name, diff)
+		}
+
+		if n := count(t, s, tt.filter); n != len(tt.exp) {
+			t.Errorf("%s: Should get count %d, got %d", tt.name, len(tt.exp), n)
+		}
+	}
+}
+
+func orderBy(t *testing.T, s *suite) {
+	apple, banana, cherry := s.products[0], s.products[1], s.products[2]
+
+	table := []struct {
+		field string
+		dir   string
+		exp   []vproductbus.Product
+	}{
+		{vproductbus.OrderByName, order.DESC, []vproductbus.Product{cherry, banana, apple}},
+		{vproductbus.OrderByCost, order.ASC, []vproductbus.Product{banana, apple, cherry}},
+	}
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, vproductbus.QueryFilter{}, order.NewBy(tt.field, tt.dir), page.MustParse("1", "10"))
+		if err != nil {
+			t.Fatalf("%s %s: Should be able to query: %s", tt.field, tt.dir, err)
+		}
+
+		if diff := cmp.Diff(got, tt.exp); diff != "" {
+			t.Errorf("%s %s: Should get the products in order:\n%s", tt.field, tt.dir, diff)
+		}
+	}
+
+	got, err := s.Storer.Query(s.ctx, vproductbus.QueryFilter{}, order.NewBy(vproductbus.OrderByUserName, order.DESC), page.MustParse("1", "1"))
+	if err != nil {
+		t.Fatalf("Should be able to query: %s", err)
+	}
+
+	if diff := cmp.Diff(got, []vproductbus.Product{cherry}); diff != "" {
+		t.Errorf("Should get the product of the last user first:\n%s", diff)
+	}
+
+	if _, err := s.Storer.Query(s.ctx, vproductbus.QueryFilter{}, order.NewBy("unknown", order.ASC), page.MustParse("1", "10")); err == nil {
+		t.Errorf("Should get an error for an unknown order field")
+	}
+}
+
+func paging(t *testing.T, s *suite) {
+	apple, banana, cherry := s.products[0], s.products[1], s.products[2]
+	orderBy := order.NewBy(vproductbus.OrderByCost, order.ASC)
+
+	table := []struct {
+		page page.Page
+		exp  []vproductbus.Product
+	}{
+		{page.MustParse("1", "2"), []vproductbus.Product{banana, apple}},
+		{page.MustParse("2", "2"), []vproductbus.Product{cherry}},
+		{page.MustParse("3", "2"), []vproductbus.Product{}},
+	}
+
+	for _, tt := range table {
+		got, err := s.Storer.Query(s.ctx, vproductbus.QueryFilter{}, orderBy, tt.page)
+		if err != nil {
+			t.Fatalf("%s: Should be able to query: %s", tt.page, err)
+		}
+
+		if diff := cmp.Diff(got, tt.exp); diff != "" {
+			t.Errorf("%s: Should get the page of products:\n%s", tt.page, diff)
+		}
+	}
+}
+
+func updateUser(t *testing.T, s *suite) {
+	cherry := s.products[2]
+
+	upd := s.users[1]
+	upd.Name = name.MustParse("Robert Owner")
+	if err := s.Users.Update(s.ctx, upd); err != nil {
+		t.Fatalf("Should be able to update the user: %s", err)
+	}
+
+	got, err := s.Storer.Query(s.ctx, vproductbus.QueryFilter{ID: &cherry.ID}, vproductbus.DefaultOrderBy, page.MustParse("1", "10"))
+	if err != nil {
+		t.Fatalf("Should be able to query: %s", err)
+	}
+
+	if len(got) != 1 || got[0].UserName != upd.Name {
+		t.Errorf("Should see the new user name, got %v", got)
+	}
+}
+
+func deleteUser(t *testing.T, s *suite) {
+	if err := s.Users.Delete(s.ctx, s.users[1]); err != nil {
+		t.Fatalf("Should be able to delete the user: %s", err)
+	}
+
+	if n := count(t, s, vproductbus.QueryFilter{}); n != 2 {
+		t.Errorf("Should not see the products of a deleted user, got %d", n)
+	}
+}
+
+// =============================================================================
+
+func count(t *testing.T, s *suite, filter vproductbus.QueryFilter) int {
+	t.Helper()
+
+	n, err := s.Storer.Count(s.ctx, filter)
+	if err != nil {
+		t.Fatalf("Should be able to count: %s", err)
+	}
+
+	return n
+}
+
+func newProduct(usr userbus.User, n string, cost float64, qty int, created time.Time) vproductbus.Product {
+	return vproductbus.Product{
+		ID:          uuid.New(),
+		UserID:      usr.ID,
+		Name:        name.MustParse(n),
+		Cost:        money.MustParse(cost),
+		Quantity:    quantity.MustParse(qty),
+		DateCreated: created,
+		DateUpdated: created,
+		UserName:    usr.Name,
+	}
+}
+
+func toProduct(prd vproductbus.Product) productbus.Product {
+	return productbus.Product{
+		ID:          prd.ID,
+		UserID:      prd.UserID,
+		Name:        prd.Name,
+		Cost:        prd.Cost,
+		Quantity:    prd.Quantity,
+		DateCreated: prd.DateCreated,
+		DateUpdated: prd.DateUpdated,
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/ardanlabs/service/business/domain/homebus"
 	"github.com/ardanlabs/service/business/domain/homebus/extensions/homeotel"
 	"github.com/ardanlabs/service/business/domain/homebus/stores/homedb"
+	"github.com/ardanlabs/service/business/domain/jobbus"
+	"github.com/ardanlabs/service/business/domain/jobbus/extensions/jobotel"
+	"github.com/ardanlabs/service/business/domain/jobbus/stores/jobdb"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/productbus/extensions/productotel"
 	"github.com/ardanlabs/service/business/domain/productbus/stores/productpg"
@@ -30,6 +33,7 @@ type BusDomain struct {
 	Delegate *delegate.Delegate
 	Audit    auditbus.ExtBusiness
 	Home     homebus.ExtBusiness
+	Job      jobbus.ExtBusiness
 	Product  productbus.ExtBusiness
 	User     userbus.ExtBusiness
 	VProduct vproductbus.ExtBusiness
@@ -59,10 +63,15 @@ func newBusDomains(log *logger.Logger, db *sqlx.DB) BusDomain {
 	vproductStorage := vproductdb.NewStore(log, db)
 	vproductBus := vproductbus.NewBusiness(vproductStorage, vproductOtelExt)
 
+	jobOtelExt := jobotel.NewExtension()
+	jobStorage := jobdb.NewStore(log, db)
+	jobBus := jobbus.NewBusiness(log, jobStorage, jobOtelExt)
+
 	return BusDomain{
 		Delegate: delegate,
 		Audit:    auditBus,
 		Home:     homeBus,
+		Job:      jobBus,
 		Product:  productBus,
 		User:     userBus,
 		VProduct: vproductBus,
@@ -1,15 +1,37 @@
 // Package dbtest contains supporting code for running tests that hit the DB.
+//
+// The database is migrated once into a template database and every test gets
+// its own copy of the template, so tests can run in parallel within and
+// across packages without running the migrations again. The template name
+// includes a fingerprint of the migrations so a change to the schema builds a
+// new template. Templates for older migrations are left in place since other
+// test runs may still use them; make test-clean removes them.
+//
+// By default the postgres server runs in a docker container that is shared
+// by every test. Set DBTEST_HOST to the host:port of a running postgres to
+// use it instead, with DBTEST_USER and DBTEST_PASSWORD when they are not
+// postgres. Set DBTEST_KEEP to keep the test databases for debugging.
 package dbtest
 
 import (
 	"bytes"
 	"context"
-	"math/rand"
+	"crypto/md5"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ardanlabs/service/business/sdk/migrate"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dialect"
 	"github.com/ardanlabs/service/foundation/docker"
 	"github.com/ardanlabs/service/foundation/logger"
 	"github.com/ardanlabs/service/foundation/otel"
@@ -24,74 +46,53 @@ type Database struct {
 }
 
 // New creates a new test database inside the database that was started
-// to handle testing. The database is migrated to the current version and
-// a connection pool is provided with business domain packages.
+// to handle testing. The database is a copy of a template that is migrated
+// to the current version and a connection pool is provided with business
+// domain packages. The database is dropped when the test completes.
 func New(t *testing.T, testName string) *Database {
-	image := "postgres:18.4"
-	name := "servicetest"
-	port := "5432"
-	dockerArgs := []string{"-e", "POSTGRES_PASSWORD=postgres"}
-	appArgs := []string{"-c", "log_statement=all"}
-
-	c, err := docker.StartContainer(image, name, port, dockerArgs, appArgs)
+	srv, err := startServer()
 	if err != nil {
 		t.Fatalf("Starting database: %v", err)
 	}
 
-	t.Logf("Name    : %s\n", c.Name)
-	t.Logf("HostPort: %s\n", c.HostPort)
+	t.Logf("Host    : %s\n", srv.host)
 
-	dbM, err := sqldb.Open(sqldb.Config{
-		User:       "postgres",
-		Password:   "postgres",
-		Host:       c.HostPort,
-		Name:       "postgres",
-		DisableTLS: true,
-	})
+	if srv.container.Name != "" {
+		docker.DumpLogsOnFailure(t, srv.container)
+	}
+
+	dbM, err := sqldb.Open(srv.config("postgres"))
 	if err != nil {
 		t.Fatalf("Opening database connection: %v", err)
 	}
+	defer dbM.Close()
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := sqldb.StatusCheck(ctx, dbM); err != nil {
 		t.Fatalf("status check database: %v", err)
 	}
 
+	tmpl, err := srv.template(ctx, dbM)
+	if err != nil {
+		t.Fatalf("Building template database: %s", err)
+	}
+
 	// -------------------------------------------------------------------------
 
-	const letterBytes = "abcdefghijklmnopqrstuvwxyz"
-	b := make([]byte, 4)
-	for i := range b {
-		b[i] = letterBytes[rand.Intn(len(letterBytes))]
-	}
-	dbName := string(b)
+	dbName := databaseName(testName)
 
-	t.Logf("Create Database: %s\n", dbName)
-	if _, err := dbM.ExecContext(context.Background(), "CREATE DATABASE "+dbName); err != nil {
+	t.Logf("Create Database: %s from %s\n", dbName, tmpl)
+	if _, err := dbM.ExecContext(ctx, fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s", dbName, tmpl)); err != nil {
 		t.Fatalf("creating database %s: %v", dbName, err)
 	}
 
-	// -------------------------------------------------------------------------
-
-	db, err := sqldb.Open(sqldb.Config{
-		User:       "postgres",
-		Password:   "postgres",
-		Host:       c.HostPort,
-		Name:       dbName,
-		DisableTLS: true,
-	})
+	db, err := sqldb.Open(srv.config(dbName))
 	if err != nil {
 		t.Fatalf("Opening database connection: %v", err)
 	}
 
-	t.Logf("Migrate Database: %s\n", dbName)
-	if err := migrate.Migrate(ctx, db); err != nil {
-		t.Logf("Logs for %s\n%s:", c.Name, docker.DumpContainerLogs(c.Name))
-		t.Fatalf("Migrating error: %s", err)
-	}
-
 	// -------------------------------------------------------------------------
 
 	var buf bytes.Buffer
@@ -102,13 +103,14 @@ func New(t *testing.T, testName string) *Database {
 	t.Cleanup(func() {
 		t.Helper()
 
-		t.Logf("Drop Database: %s\n", dbName)
-		if _, err := dbM.ExecContext(context.Background(), "DROP DATABASE "+dbName); err != nil {
-			t.Fatalf("dropping database %s: %v", dbName, err)
-		}
-
 		db.Close()
-		dbM.Close()
+
+		if os.Getenv("DBTEST_KEEP") == "" {
+			t.Logf("Drop Database: %s\n", dbName)
+			if err := srv.drop(dbName); err != nil {
+				t.Errorf("dropping database %s: %v", dbName, err)
+			}
+		}
 
 		t.Logf("******************** LOGS (%s) ********************\n\n", testName)
 		t.Log(buf.String())
@@ -121,3 +123,220 @@ func New(t *testing.T, testName string) *Database {
 		BusDomain: newBusDomains(log, db),
 	}
 }
+
+// =============================================================================
+
+// server represents the postgres server the test databases are created in.
+type server struct {
+	host      string
+	user      string
+	password  string
+	container docker.Container
+
+	mu        sync.Mutex
+	templates map[string]bool
+}
+
+var (
+	srvOnce sync.Once
+	srv     *server
+	srvErr  error
+)
+
+// startServer returns the server provided by the environment or starts the
+// shared container. The server is resolved once per test binary.
+func startServer() (*server, error) {
+	srvOnce.Do(func() {
+		s := server{
+			host:      os.Getenv("DBTEST_HOST"),
+			user:      envOr("DBTEST_USER", "postgres"),
+			password:  envOr("DBTEST_PASSWORD", "postgres"),
+			templates: make(map[string]bool),
+		}
+
+		if s.host == "" {
+			cfg := docker.Config{
+				Image:      "postgres:18.4",
+				Name:       "servicetest",
+				Port:       "5432",
+				DockerArgs: []string{"-e", "POSTGRES_PASSWORD=postgres"},
+				AppArgs:    []string{"-c", "log_statement=all"},
+
+				// The image starts a server to run the init scripts before
+				// the real one, so the ready line is logged twice.
+				Wait: docker.ForAll(
+					docker.ForLog("database system is ready to accept connections", 2),
+					docker.ForPort(),
+				),
+			}
+
+			c, err := docker.Start(context.Background(), cfg)
+			if err != nil {
+				srvErr = err
+				return
+			}
+
+			s.host = c.HostPort
+			s.container = c
+		}
+
+		srv = &s
+	})
+
+	return srv, srvErr
+}
+
+func (s *server) config(name string) sqldb.Config {
+	return sqldb.Config{
+		User:       s.user,
+		Password:   s.password,
+		Host:       s.host,
+		Name:       name,
+		DisableTLS: true,
+	}
+}
+
+// template returns the name of the template database for the current
+// migrations, building it if it doesn't exist. An advisory lock serializes
+// the build between the test binaries of different packages.
+func (s *server) template(ctx context.Context, dbM *sqlx.DB) (string, error) {
+	fingerprint, err := migrationsFingerprint(dbM)
+	if err != nil {
+		return "", err
+	}
+
+	name := "dbtest_template_" + fingerprint
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.templates[name] {
+		return name, nil
+	}
+
+	conn, err := dbM.Connx(ctx)
+	if err != nil {
+		return "", fmt.Errorf("conn: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(hashtext('dbtest_template'))"); err != nil {
+		return "", fmt.Errorf("lock: %w", err)
+	}
+
+	defer func() {
+		conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock(hashtext('dbtest_template'))")
+	}()
+
+	// A database is only marked as a template once it is migrated, so a
+	// database left behind by a build that failed is built again. Templates
+	// for other versions of the migrations may be in use by other test runs
+	// against the same server, so they are left for make test-clean.
+
+	var isTemplate bool
+
+	const q = `SELECT datistemplate FROM pg_database WHERE datname = $1`
+	switch err := sqlx.GetContext(ctx, conn, &isTemplate, q, name); {
+	case errors.Is(err, sql.ErrNoRows):
+		// The template hasn't been built yet.
+
+	case err != nil:
+		return "", fmt.Errorf("query template: %w", err)
+
+	case isTemplate:
+		s.templates[name] = true
+		return name, nil
+
+	default:
+		if _, err := conn.ExecContext(ctx, "DROP DATABASE "+name); err != nil {
+			return "", fmt.Errorf("drop unfinished template %s: %w", name, err)
+		}
+	}
+
+	if _, err := conn.ExecContext(ctx, "CREATE DATABASE "+name); err != nil {
+		return "", fmt.Errorf("create template: %w", err)
+	}
+
+	if err := s.migrate(ctx, name); err != nil {
+		return "", err
+	}
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("ALTER DATABASE %s WITH IS_TEMPLATE true", name)); err != nil {
+		return "", fmt.Errorf("mark template: %w", err)
+	}
+
+	s.templates[name] = true
+
+	return name, nil
+}
+
+// migrate runs the migrations against the template. The connection is closed
+// before returning since postgres can't copy a database that is in use.
+func (s *server) migrate(ctx context.Context, name string) error {
+	db, err := sqldb.Open(s.config(name))
+	if err != nil {
+		return fmt.Errorf("open template: %w", err)
+	}
+	defer db.Close()
+
+	if err := migrate.Migrate(ctx, db); err != nil {
+		return fmt.Errorf("migrate template: %w", err)
+	}
+
+	return nil
+}
+
+func (s *server) drop(name string) error {
+	dbM, err := sqldb.Open(s.config("postgres"))
+	if err != nil {
+		return err
+	}
+	defer dbM.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err = dbM.ExecContext(ctx, fmt.Sprintf("DROP DATABASE IF EXISTS %s WITH (FORCE)", name))
+	return err
+}
+
+// =============================================================================
+
+// migrationsFingerprint identifies the current set of migrations.
+func migrationsFingerprint(db *sqlx.DB) (string, error) {
+	m, err := migrate.New(db, dialect.Postgres{})
+	if err != nil {
+		return "", fmt.Errorf("migrations: %w", err)
+	}
+
+	h := md5.New()
+	for _, mig := range m.Migrations() {
+		fmt.Fprintf(h, "%s:%s\n", mig, mig.Checksum())
+	}
+
+	return hex.EncodeToString(h.Sum(nil))[:12], nil
+}
+
+var invalidChars = regexp.MustCompile("[^a-z0-9_]+")
+
+// databaseName builds a name from the test name that is readable in the
+// server logs and unique across parallel tests.
+func databaseName(testName string) string {
+	name := invalidChars.ReplaceAllString(strings.ToLower(testName), "_")
+	if len(name) > 40 {
+		name = name[:40]
+	}
+
+	b := make([]byte, 4)
+	rand.Read(b)
+
+	return fmt.Sprintf("test_%s_%s", strings.Trim(name, "_"), hex.EncodeToString(b))
+}
+
+func envOr(key string, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return def
+}
@@ -0,0 +1,27 @@
+package dbtest
+
+import (
+	"regexp"
+	"testing"
+)
+
+func Test_DatabaseName(t *testing.T) {
+	valid := regexp.MustCompile("^test_[a-z0-9_]{1,40}_[0-9a-f]{8}$")
+
+	names := []string{
+		"Test_User",
+		"Test_Product/create-with spaces",
+		"Test_AVeryLongTestNameThatKeepsGoingPastTheLimitOfTheDatabaseName",
+	}
+
+	for _, name := range names {
+		got := databaseName(name)
+		if !valid.MatchString(got) {
+			t.Errorf("Should get a valid database name for %q : got %q", name, got)
+		}
+	}
+
+	if databaseName("Test_User") == databaseName("Test_User") {
+		t.Errorf("Should get unique names for the same test")
+	}
+}
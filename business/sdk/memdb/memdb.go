@@ -0,0 +1,335 @@
+// Package memdb provides an in-memory database for the domain stores so
+// business logic can be exercised without running postgres.
+//
+// Rows are kept per table keyed by their uuid and the stores own the query
+// semantics, the same way the SQL stores own their SQL. Writes made inside a
+// transaction are applied immediately and undone on rollback. There is no
+// isolation between transactions, which is good enough for tests and local
+// development but not for concurrent writers that depend on it.
+package memdb
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+// Set of error variables for CRUD operations.
+var (
+	ErrForeignKey = errors.New("foreign key violation")
+	ErrTxDone     = errors.New("transaction has already been committed or rolled back")
+)
+
+// DB represents a set of named tables and the relationships between them.
+type DB struct {
+	mu       sync.Mutex
+	tables   map[string]table
+	cascades map[string]map[string]func(tx *Tx, id uuid.UUID) error
+}
+
+// New constructs an empty database.
+func New() *DB {
+	return &DB{
+		tables:   make(map[string]table),
+		cascades: make(map[string]map[string]func(tx *Tx, id uuid.UUID) error),
+	}
+}
+
+// Begin implements the sqldb.Beginner interface and returns a transaction
+// the in-memory stores know how to use.
+func (db *DB) Begin() (sqldb.CommitRollbacker, error) {
+	return &Tx{}, nil
+}
+
+func (db *DB) exists(name string, id uuid.UUID) bool {
+	db.mu.Lock()
+	t, exists := db.tables[name]
+	db.mu.Unlock()
+
+	return exists && t.exists(id)
+}
+
+func (db *DB) onDelete(name string) []func(tx *Tx, id uuid.UUID) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	fns := make([]func(tx *Tx, id uuid.UUID) error, 0, len(db.cascades[name]))
+	for _, fn := range db.cascades[name] {
+		fns = append(fns, fn)
+	}
+
+	return fns
+}
+
+// ForeignKey declares that the column of the child table references the id
+// of a row in the parent table, like a FOREIGN KEY with ON DELETE CASCADE.
+// Inserts and updates with an id that is not in the parent table fail with
+// ErrForeignKey and deleting a parent row deletes the child rows that
+// reference it. Declaring the same key again replaces it.
+func ForeignKey[T any](child *Table[T], parent string, column func(row T) uuid.UUID) {
+	db := child.db
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if db.cascades[parent] == nil {
+		db.cascades[parent] = make(map[string]func(tx *Tx, id uuid.UUID) error)
+	}
+
+	db.cascades[parent][child.name] = func(tx *Tx, id uuid.UUID) error {
+		return child.DeleteWhere(tx, func(row T) bool {
+			return column(row) == id
+		})
+	}
+
+	child.mu.Lock()
+	defer child.mu.Unlock()
+
+	child.fks[parent] = column
+}
+
+// =============================================================================
+
+type table interface {
+	exists(id uuid.UUID) bool
+}
+
+// Table represents a set of rows keyed by their id.
+type Table[T any] struct {
+	db   *DB
+	name string
+	mu   sync.RWMutex
+	rows map[uuid.UUID]T
+	fks  map[string]func(row T) uuid.UUID
+}
+
+// GetTable returns the named table, creating it on first use. It panics if
+// the table already exists with a different row type since that is a
+// programming error.
+func GetTable[T any](db *DB, name string) *Table[T] {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if v, exists := db.tables[name]; exists {
+		t, ok := v.(*Table[T])
+		if !ok {
+			panic(fmt.Sprintf("memdb: table %q is of type %T", name, v))
+		}
+		return t
+	}
+
+	t := Table[T]{
+		db:   db,
+		name: name,
+		rows: make(map[uuid.UUID]T),
+		fks:  make(map[string]func(row T) uuid.UUID),
+	}
+
+	db.tables[name] = &t
+
+	return &t
+}
+
+// Get returns the row for the specified id.
+func (t *Table[T]) Get(id uuid.UUID) (T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	row, exists := t.rows[id]
+	return row, exists
+}
+
+func (t *Table[T]) exists(id uuid.UUID) bool {
+	_, exists := t.Get(id)
+	return exists
+}
+
+// Select returns the rows that match in no particular order. A nil match
+// returns every row.
+func (t *Table[T]) Select(match func(row T) bool) []T {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows := make([]T, 0, len(t.rows))
+	for _, row := range t.rows {
+		if match == nil || match(row) {
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// Insert adds a new row. It returns sqldb.ErrDBDuplicatedEntry if the id
+// exists or conflict reports true for any existing row. A nil conflict
+// only checks the id.
+func (t *Table[T]) Insert(tx *Tx, id uuid.UUID, row T, conflict func(existing T) bool) error {
+	if err := tx.active(); err != nil {
+		return err
+	}
+
+	if err := t.checkForeignKeys(row); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, exists := t.rows[id]; exists {
+		return sqldb.ErrDBDuplicatedEntry
+	}
+
+	if conflict != nil {
+		for _, existing := range t.rows {
+			if conflict(existing) {
+				return sqldb.ErrDBDuplicatedEntry
+			}
+		}
+	}
+
+	t.rows[id] = row
+
+	tx.record(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		delete(t.rows, id)
+	})
+
+	return nil
+}
+
+// Update changes an existing row to the value returned by set, which is
+// given the current row so a store can change only the columns its SQL
+// counterpart updates. Like a SQL UPDATE, updating a row that does not
+// exist is not an error. It returns sqldb.ErrDBDuplicatedEntry if conflict
+// reports true for any other row.
+func (t *Table[T]) Update(tx *Tx, id uuid.UUID, set func(row T) T, conflict func(existing T) bool) error {
+	if err := tx.active(); err != nil {
+		return err
+	}
+
+	current, exists := t.Get(id)
+	if !exists {
+		return nil
+	}
+
+	row := set(current)
+
+	if err := t.checkForeignKeys(row); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, exists := t.rows[id]
+	if !exists {
+		return nil
+	}
+
+	if conflict != nil {
+		for existingID, existing := range t.rows {
+			if existingID != id && conflict(existing) {
+				return sqldb.ErrDBDuplicatedEntry
+			}
+		}
+	}
+
+	t.rows[id] = row
+
+	tx.record(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.rows[id] = prev
+	})
+
+	return nil
+}
+
+// Delete removes the row for the specified id and the rows of other tables
+// that reference it through a ForeignKey. Like a SQL DELETE, deleting a row
+// that does not exist is not an error.
+func (t *Table[T]) Delete(tx *Tx, id uuid.UUID) error {
+	if err := tx.active(); err != nil {
+		return err
+	}
+
+	if !t.delete(tx, id) {
+		return nil
+	}
+
+	for _, fn := range t.db.onDelete(t.name) {
+		if err := fn(tx, id); err != nil {
+			return fmt.Errorf("cascade %s: %w", t.name, err)
+		}
+	}
+
+	return nil
+}
+
+// DeleteWhere removes every row that matches.
+func (t *Table[T]) DeleteWhere(tx *Tx, match func(row T) bool) error {
+	if err := tx.active(); err != nil {
+		return err
+	}
+
+	t.mu.RLock()
+	var ids []uuid.UUID
+	for id, row := range t.rows {
+		if match(row) {
+			ids = append(ids, id)
+		}
+	}
+	t.mu.RUnlock()
+
+	for _, id := range ids {
+		if err := t.Delete(tx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *Table[T]) delete(tx *Tx, id uuid.UUID) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev, exists := t.rows[id]
+	if !exists {
+		return false
+	}
+
+	delete(t.rows, id)
+
+	tx.record(func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		t.rows[id] = prev
+	})
+
+	return true
+}
+
+func (t *Table[T]) checkForeignKeys(row T) error {
+	t.mu.RLock()
+	fks := make(map[string]func(row T) uuid.UUID, len(t.fks))
+	for parent, column := range t.fks {
+		fks[parent] = column
+	}
+	t.mu.RUnlock()
+
+	for parent, column := range fks {
+		if !t.db.exists(parent, column(row)) {
+			return fmt.Errorf("%s references %s: %w", t.name, parent, ErrForeignKey)
+		}
+	}
+
+	return nil
+}
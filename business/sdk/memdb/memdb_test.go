@@ -0,0 +1,154 @@
+package memdb_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/business/sdk/memdb"
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/google/uuid"
+)
+
+type parent struct {
+	ID   uuid.UUID
+	Name string
+}
+
+type child struct {
+	ID       uuid.UUID
+	ParentID uuid.UUID
+}
+
+func Test_Rollback(t *testing.T) {
+	db := memdb.New()
+	parents := memdb.GetTable[parent](db, "parents")
+
+	p1 := parent{ID: uuid.New(), Name: "one"}
+	if err := parents.Insert(nil, p1.ID, p1, nil); err != nil {
+		t.Fatalf("Should be able to insert: %s", err)
+	}
+
+	crTx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Should be able to begin: %s", err)
+	}
+
+	tx, err := memdb.GetTx(crTx)
+	if err != nil {
+		t.Fatalf("Should be able to get the tx: %s", err)
+	}
+
+	p2 := parent{ID: uuid.New(), Name: "two"}
+	if err := parents.Insert(tx, p2.ID, p2, nil); err != nil {
+		t.Fatalf("Should be able to insert: %s", err)
+	}
+
+	rename := func(p parent) parent {
+		p.Name = "renamed"
+		return p
+	}
+	if err := parents.Update(tx, p1.ID, rename, nil); err != nil {
+		t.Fatalf("Should be able to update: %s", err)
+	}
+
+	if err := parents.Delete(tx, p1.ID); err != nil {
+		t.Fatalf("Should be able to delete: %s", err)
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("Should be able to rollback: %s", err)
+	}
+
+	if _, exists := parents.Get(p2.ID); exists {
+		t.Errorf("Should not find the row inserted in the transaction")
+	}
+
+	got, exists := parents.Get(p1.ID)
+	if !exists || got.Name != "one" {
+		t.Errorf("Should find the original row, got %v %v", got, exists)
+	}
+
+	if err := parents.Insert(tx, p2.ID, p2, nil); !errors.Is(err, memdb.ErrTxDone) {
+		t.Errorf("Should get ErrTxDone after rollback, got %v", err)
+	}
+
+	if err := tx.Commit(); !errors.Is(err, memdb.ErrTxDone) {
+		t.Errorf("Should get ErrTxDone on commit after rollback, got %v", err)
+	}
+}
+
+func Test_Constraints(t *testing.T) {
+	db := memdb.New()
+	parents := memdb.GetTable[parent](db, "parents")
+	children := memdb.GetTable[child](db, "children")
+	memdb.ForeignKey(children, "parents", func(c child) uuid.UUID { return c.ParentID })
+
+	p := parent{ID: uuid.New(), Name: "one"}
+	if err := parents.Insert(nil, p.ID, p, nil); err != nil {
+		t.Fatalf("Should be able to insert: %s", err)
+	}
+
+	sameName := func(existing parent) bool { return existing.Name == p.Name }
+	dup := parent{ID: uuid.New(), Name: "one"}
+	if err := parents.Insert(nil, dup.ID, dup, sameName); !errors.Is(err, sqldb.ErrDBDuplicatedEntry) {
+		t.Errorf("Should get ErrDBDuplicatedEntry, got %v", err)
+	}
+
+	orphan := child{ID: uuid.New(), ParentID: uuid.New()}
+	if err := children.Insert(nil, orphan.ID, orphan, nil); !errors.Is(err, memdb.ErrForeignKey) {
+		t.Errorf("Should get ErrForeignKey, got %v", err)
+	}
+
+	c := child{ID: uuid.New(), ParentID: p.ID}
+	if err := children.Insert(nil, c.ID, c, nil); err != nil {
+		t.Fatalf("Should be able to insert: %s", err)
+	}
+
+	if err := parents.Delete(nil, p.ID); err != nil {
+		t.Fatalf("Should be able to delete: %s", err)
+	}
+
+	if _, exists := children.Get(c.ID); exists {
+		t.Errorf("Should delete the child rows with the parent")
+	}
+}
+
+func Test_SortPage(t *testing.T) {
+	rows := []parent{
+		{ID: uuid.New(), Name: "b"},
+		{ID: uuid.New(), Name: "c"},
+		{ID: uuid.New(), Name: "a"},
+	}
+
+	fields := map[string]memdb.CompareFunc[parent]{
+		"name": func(a parent, b parent) int {
+			return strings.Compare(a.Name, b.Name)
+		},
+	}
+	id := func(p parent) uuid.UUID { return p.ID }
+
+	if err := memdb.Sort(rows, order.NewBy("name", order.DESC), fields, id); err != nil {
+		t.Fatalf("Should be able to sort: %s", err)
+	}
+
+	got := memdb.Page(rows, page.MustParse("1", "2"))
+	if len(got) != 2 || got[0].Name != "c" || got[1].Name != "b" {
+		t.Errorf("Should get the first page in order, got %v", got)
+	}
+
+	got = memdb.Page(rows, page.MustParse("2", "2"))
+	if len(got) != 1 || got[0].Name != "a" {
+		t.Errorf("Should get the last page, got %v", got)
+	}
+
+	if got := memdb.Page(rows, page.MustParse("3", "2")); len(got) != 0 {
+		t.Errorf("Should get an empty page past the end, got %v", got)
+	}
+
+	if err := memdb.Sort(rows, order.NewBy("unknown", order.ASC), fields, id); err == nil {
+		t.Errorf("Should get an error for an unknown field")
+	}
+}
@@ -0,0 +1,77 @@
+package memdb
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/order"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/google/uuid"
+)
+
+// CompareFunc compares two rows on a single field the way the database
+// would order them in ascending order.
+type CompareFunc[T any] func(a T, b T) int
+
+// Sort orders the rows on the field specified by orderBy using the compare
+// function registered for that field. Rows that compare equal are ordered by
+// their id so results are stable across calls. It returns an error if the
+// field is not in fields, the same as the SQL stores.
+func Sort[T any](rows []T, orderBy order.By, fields map[string]CompareFunc[T], id func(row T) uuid.UUID) error {
+	cmp, exists := fields[orderBy.Field]
+	if !exists {
+		return fmt.Errorf("field %q does not exist", orderBy.Field)
+	}
+
+	slices.SortFunc(rows, func(a T, b T) int {
+		c := cmp(a, b)
+		if orderBy.Direction == order.DESC {
+			c = -c
+		}
+
+		if c == 0 {
+			return CompareID(id(a), id(b))
+		}
+
+		return c
+	})
+
+	return nil
+}
+
+// Page returns the slice of rows for the requested page.
+func Page[T any](rows []T, pg page.Page) []T {
+	offset := (pg.Number() - 1) * pg.RowsPerPage()
+	if offset >= len(rows) {
+		return []T{}
+	}
+
+	end := min(offset+pg.RowsPerPage(), len(rows))
+
+	return rows[offset:end]
+}
+
+// CompareID compares two ids the way postgres orders a UUID column.
+func CompareID(a uuid.UUID, b uuid.UUID) int {
+	return bytes.Compare(a[:], b[:])
+}
+
+// CompareBool compares two booleans with false ordered before true.
+func CompareBool(a bool, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// Time returns the time the way it comes back from a TIMESTAMP column, in
+// UTC rounded to the microsecond and then converted to local time.
+func Time(t time.Time) time.Time {
+	return t.UTC().Round(time.Microsecond).In(time.Local)
+}
@@ -0,0 +1,91 @@
+package memdb
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+)
+
+// Tx represents an in-memory transaction. It keeps the set of functions
+// that undo the writes made inside the transaction.
+type Tx struct {
+	mu   sync.Mutex
+	undo []func()
+	done bool
+}
+
+// Commit implements the sqldb.CommitRollbacker interface and keeps the
+// writes made inside the transaction.
+func (tx *Tx) Commit() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTxDone
+	}
+
+	tx.done = true
+	tx.undo = nil
+
+	return nil
+}
+
+// Rollback implements the sqldb.CommitRollbacker interface and undoes the
+// writes made inside the transaction in reverse order.
+func (tx *Tx) Rollback() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTxDone
+	}
+
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+
+	tx.done = true
+	tx.undo = nil
+
+	return nil
+}
+
+// active reports an error if the transaction is finished. A nil transaction
+// means the write is not part of a transaction.
+func (tx *Tx) active() error {
+	if tx == nil {
+		return nil
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return ErrTxDone
+	}
+
+	return nil
+}
+
+func (tx *Tx) record(undo func()) {
+	if tx == nil {
+		return
+	}
+
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	tx.undo = append(tx.undo, undo)
+}
+
+// GetTx is a helper function that extracts the in-memory transaction from
+// the domain transactor interface for transactional use.
+func GetTx(tx sqldb.CommitRollbacker) (*Tx, error) {
+	mtx, ok := tx.(*Tx)
+	if !ok {
+		return nil, fmt.Errorf("Transactor(%T) not of a type *memdb.Tx", tx)
+	}
+
+	return mtx, nil
+}
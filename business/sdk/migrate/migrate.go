@@ -4,44 +4,55 @@ package migrate
 import (
 	"context"
 	"database/sql"
-	_ "embed"
+	"embed"
 	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/ardanlabs/darwin/v3"
-	"github.com/ardanlabs/darwin/v3/dialects/postgres"
-	"github.com/ardanlabs/darwin/v3/drivers/generic"
 	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/sdk/sqldb/dialect"
 	"github.com/jmoiron/sqlx"
 )
 
 var (
-	//go:embed sql/migrate.sql
-	migrateDoc string
+	//go:embed sql/postgres sql/sqlite
+	migrationsFS embed.FS
 
-	//go:embed sql/seed.sql
-	seedDoc string
+	//go:embed sql/seed
+	seedFS embed.FS
 )
 
-// Migrate attempts to bring the database up to date with the migrations
-// defined in this package.
+// ErrUnknownSeedSet is returned when there is no seed set with the name.
+var ErrUnknownSeedSet = errors.New("unknown seed set")
+
+// Migrate attempts to bring the Postgres database up to date with the
+// migrations defined in this package.
 func Migrate(ctx context.Context, db *sqlx.DB) error {
 	if err := sqldb.StatusCheck(ctx, db); err != nil {
 		return fmt.Errorf("status check database: %w", err)
 	}
 
-	driver, err := generic.New(db.DB, postgres.Dialect{})
+	m, err := New(db, dialect.Postgres{})
 	if err != nil {
-		return fmt.Errorf("construct darwin driver: %w", err)
+		return err
 	}
 
-	d := darwin.New(driver, darwin.ParseMigrations(migrateDoc))
-	return d.Migrate()
+	if _, err := m.Up(ctx); err != nil {
+		return err
+	}
+
+	return nil
 }
 
-// Seed runs the seed document defined in this package against db. The queries
-// are run in a transaction and rolled back if any fail.
-func Seed(ctx context.Context, db *sqlx.DB) (err error) {
+// Seed runs the named seed set defined in this package against db. The
+// queries are run in a transaction and rolled back if any fail. The sets use
+// upserts so they can be run more than once.
+func Seed(ctx context.Context, db *sqlx.DB, set string) (err error) {
+	seedDoc, err := seedFS.ReadFile("sql/seed/" + set + ".sql")
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrUnknownSeedSet, set)
+	}
+
 	if err := sqldb.StatusCheck(ctx, db); err != nil {
 		return fmt.Errorf("status check database: %w", err)
 	}
@@ -62,7 +73,7 @@ func Seed(ctx context.Context, db *sqlx.DB) (err error) {
 		}
 	}()
 
-	if _, err := tx.Exec(seedDoc); err != nil {
+	if _, err := tx.ExecContext(ctx, string(seedDoc)); err != nil {
 		return fmt.Errorf("exec: %w", err)
 	}
 
@@ -72,3 +83,15 @@ func Seed(ctx context.Context, db *sqlx.DB) (err error) {
 
 	return nil
 }
+
+// SeedSets returns the names of the seed sets defined in this package.
+func SeedSets() []string {
+	entries, _ := seedFS.ReadDir("sql/seed")
+
+	sets := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		sets = append(sets, strings.TrimSuffix(entry.Name(), ".sql"))
+	}
+
+	return sets
+}
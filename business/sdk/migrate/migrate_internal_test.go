@@ -0,0 +1,215 @@
+package migrate
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Checksums(t *testing.T) {
+	migs := parse(t, "postgres")
+
+	// These are the checksums darwin recorded for the versions that were
+	// released. An applied migration must never change or every database
+	// that was migrated reports drift.
+	exp := map[float64]string{
+		1.01: "26d6d21ffce7e68676ff7aa27f99e493",
+		1.02: "5b07c48a6a205c0f14359e3d6a7ee6b3",
+		1.03: "0c4aa0e19e49b4bcf70898e3796924a8",
+		1.04: "f050d15047f279e8c026b154106354e1",
+		1.05: "57eb17ce406cb38a8b5e574ec4587d76",
+		1.06: "de30ae86acd56e0ade0a2591f0167bf0",
+		1.07: "046a59d74327fa4c717f357aa32acf53",
+	}
+
+	for _, mig := range migs {
+		sum, exists := exp[mig.Version]
+		if !exists {
+			continue
+		}
+
+		if mig.Checksum() != sum {
+			t.Errorf("Should keep the checksum for version %s : got %s, exp %s", mig, mig.Checksum(), sum)
+		}
+	}
+}
+
+func Test_Dialects(t *testing.T) {
+	pg := parse(t, "postgres")
+	lite := parse(t, "sqlite")
+
+	versions := func(migs []Migration) []float64 {
+		var v []float64
+		for _, mig := range migs {
+			v = append(v, mig.Version)
+
+			if mig.Down == "" {
+				t.Errorf("Should have a down script for version %s", mig)
+			}
+		}
+		return v
+	}
+
+	if diff := cmp.Diff(versions(pg), versions(lite)); diff != "" {
+		t.Errorf("Should have the same versions for every dialect :\n%s", diff)
+	}
+}
+
+func Test_ParseMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"1.02_add_column.up.sql": {Data: []byte("ALTER TABLE a ADD COLUMN b TEXT;\n")},
+		"1.01_create_a.up.sql":   {Data: []byte("-- Description: Create table a\nCREATE TABLE a (id INT);\n")},
+		"1.01_create_a.down.sql": {Data: []byte("DROP TABLE a;\n")},
+		"README.md":              {Data: []byte("migrations")},
+	}
+
+	migs, err := ParseMigrations(fsys)
+	if err != nil {
+		t.Fatalf("Should be able to parse the migrations : %s", err)
+	}
+
+	exp := []Migration{
+		{Version: 1.01, Description: "Create table a", Up: "CREATE TABLE a (id INT);", Down: "DROP TABLE a;"},
+		{Version: 1.02, Description: "add column", Up: "ALTER TABLE a ADD COLUMN b TEXT;"},
+	}
+
+	if diff := cmp.Diff(exp, migs); diff != "" {
+		t.Errorf("Should get the migrations in order :\n%s", diff)
+	}
+
+	invalid := []fstest.MapFS{
+		{"one_create.up.sql": {Data: []byte("SELECT 1")}},
+		{"1.01_create.sql": {Data: []byte("SELECT 1")}},
+		{"1.01_create.down.sql": {Data: []byte("SELECT 1")}},
+		{"1.01_a.up.sql": {Data: []byte("SELECT 1")}, "1.01_b.up.sql": {Data: []byte("SELECT 2")}},
+	}
+
+	for _, fsys := range invalid {
+		if _, err := ParseMigrations(fsys); err == nil {
+			t.Errorf("Should not be able to parse %v", fsys)
+		}
+	}
+}
+
+func Test_Plan(t *testing.T) {
+	migs := []Migration{
+		{Version: 1.01, Up: "up 1", Down: "down 1"},
+		{Version: 1.02, Up: "up 2", Down: "down 2"},
+		{Version: 1.03, Up: "up 3"},
+		{Version: 1.04, Up: "up 4", Down: "down 4"},
+	}
+
+	applied := func(versions ...float64) []record {
+		var records []record
+		for _, mig := range migs {
+			for _, v := range versions {
+				if mig.Version == v {
+					records = append(records, record{Version: v, Checksum: mig.Checksum()})
+				}
+			}
+		}
+		return records
+	}
+
+	type step struct {
+		Version   float64
+		Direction Direction
+	}
+
+	tests := []struct {
+		name    string
+		records []record
+		target  float64
+		exp     []step
+		err     error
+	}{
+		{
+			name:   "up from empty",
+			target: 1.04,
+			exp:    []step{{1.01, DirectionUp}, {1.02, DirectionUp}, {1.03, DirectionUp}, {1.04, DirectionUp}},
+		},
+		{
+			name:    "up to version",
+			records: applied(1.01),
+			target:  1.03,
+			exp:     []step{{1.02, DirectionUp}, {1.03, DirectionUp}},
+		},
+		{
+			name:    "up at version",
+			records: applied(1.01, 1.02, 1.03, 1.04),
+			target:  1.04,
+		},
+		{
+			name:    "down to version",
+			records: applied(1.01, 1.02, 1.03, 1.04),
+			target:  1.03,
+			exp:     []step{{1.04, DirectionDown}},
+		},
+		{
+			name:    "down without script",
+			records: applied(1.01, 1.02, 1.03),
+			target:  1.02,
+			err:     ErrNoDownScript,
+		},
+		{
+			name:    "gap is applied",
+			records: applied(1.01, 1.03),
+			target:  1.04,
+			exp:     []step{{1.02, DirectionUp}, {1.04, DirectionUp}},
+		},
+		{
+			name:    "drift",
+			records: []record{{Version: 1.01, Checksum: "changed"}},
+			target:  1.04,
+			err:     ErrChecksumDrift,
+		},
+		{
+			name:    "missing",
+			records: append(applied(1.01), record{Version: 1.5}),
+			target:  1.04,
+			err:     ErrMissingMigration,
+		},
+		{
+			name:   "unknown version",
+			target: 2,
+			err:    ErrUnknownVersion,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps, err := plan(migs, tt.records, tt.target)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Should get error %v : got %v", tt.err, err)
+			}
+
+			var got []step
+			for _, s := range steps {
+				got = append(got, step{s.Migration.Version, s.Direction})
+			}
+
+			if diff := cmp.Diff(tt.exp, got); diff != "" {
+				t.Errorf("Should get the planned steps :\n%s", diff)
+			}
+		})
+	}
+}
+
+func parse(t *testing.T, dir string) []Migration {
+	t.Helper()
+
+	fsys, err := fs.Sub(migrationsFS, "sql/"+dir)
+	if err != nil {
+		t.Fatalf("Should be able to open the %s migrations : %s", dir, err)
+	}
+
+	migs, err := ParseMigrations(fsys)
+	if err != nil {
+		t.Fatalf("Should be able to parse the %s migrations : %s", dir, err)
+	}
+
+	return migs
+}
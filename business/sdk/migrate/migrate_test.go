@@ -0,0 +1,41 @@
+package migrate_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/migrate"
+)
+
+func Test_SeedSets(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_SeedSets")
+
+	ctx := context.Background()
+
+	// Every set can be run more than once and on top of the others.
+	for _, set := range migrate.SeedSets() {
+		for range 2 {
+			if err := migrate.Seed(ctx, db.DB, set); err != nil {
+				t.Fatalf("Should be able to seed %s : %s", set, err)
+			}
+		}
+	}
+
+	n, err := db.BusDomain.User.Count(ctx, userbus.QueryFilter{})
+	if err != nil {
+		t.Fatalf("Should be able to count the users : %s", err)
+	}
+
+	if n != 4 {
+		t.Errorf("Should get the 4 users of the demo set : got %d", n)
+	}
+
+	if err := migrate.Seed(ctx, db.DB, "unknown"); !errors.Is(err, migrate.ErrUnknownSeedSet) {
+		t.Errorf("Should get ErrUnknownSeedSet : got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package migrate
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Migration represents a single version of the schema with the script to
+// apply it and an optional script to roll it back.
+type Migration struct {
+	Version     float64
+	Description string
+	Up          string
+	Down        string
+}
+
+// Checksum calculates the md5 of the up script. The value matches the one
+// darwin recorded so databases migrated before this package owned the
+// migrations table are still valid.
+func (m Migration) Checksum() string {
+	return fmt.Sprintf("%x", md5.Sum([]byte(m.Up)))
+}
+
+// String returns the version in the form used by the file names.
+func (m Migration) String() string {
+	return formatVersion(m.Version)
+}
+
+// ParseMigrations reads the migration files in the root of the file system.
+// The files are named <version>_<name>.up.sql and <version>_<name>.down.sql,
+// for example 1.01_create_table_users.up.sql. An up script can start with a
+// "-- Description: text" line, otherwise the description is taken from the
+// name. The migrations are returned ordered by version.
+func ParseMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("readdir: %w", err)
+	}
+
+	byVersion := make(map[float64]*Migration)
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".sql" {
+			continue
+		}
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("file %s: expecting a .up.sql or .down.sql extension", name)
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		verStr, title, _ := strings.Cut(base, "_")
+
+		version, err := strconv.ParseFloat(verStr, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("file %s: invalid version %q", name, verStr)
+		}
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("readfile: %w", err)
+		}
+
+		mig, exists := byVersion[version]
+		if !exists {
+			mig = &Migration{Version: version}
+			byVersion[version] = mig
+		}
+
+		switch direction {
+		case "up":
+			if mig.Up != "" {
+				return nil, fmt.Errorf("file %s: duplicate up script for version %s", name, verStr)
+			}
+
+			mig.Description, mig.Up = parseScript(string(data))
+			if mig.Description == "" {
+				mig.Description = strings.ReplaceAll(title, "_", " ")
+			}
+
+		case "down":
+			if mig.Down != "" {
+				return nil, fmt.Errorf("file %s: duplicate down script for version %s", name, verStr)
+			}
+
+			_, mig.Down = parseScript(string(data))
+		}
+	}
+
+	migs := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("version %s: missing up script", mig)
+		}
+
+		migs = append(migs, *mig)
+	}
+
+	slices.SortFunc(migs, func(a, b Migration) int {
+		switch {
+		case a.Version < b.Version:
+			return -1
+		case a.Version > b.Version:
+			return 1
+		}
+		return 0
+	})
+
+	return migs, nil
+}
+
+// parseScript splits the optional description line from the script. The
+// script is trimmed the same way darwin did so the checksums match.
+func parseScript(doc string) (string, string) {
+	var description string
+
+	first, rest, _ := strings.Cut(doc, "\n")
+	lower := strings.ToLower(first)
+	if strings.HasPrefix(lower, "--des") || strings.HasPrefix(lower, "-- des") {
+		if _, desc, found := strings.Cut(first, ":"); found {
+			description = strings.TrimSpace(desc)
+			doc = rest
+		}
+	}
+
+	return description, strings.TrimSpace(doc)
+}
+
+func formatVersion(version float64) string {
+	return strconv.FormatFloat(version, 'f', -1, 64)
+}
+
+// ParseVersion parses a version provided on the command line.
+func ParseVersion(s string) (float64, error) {
+	version, err := strconv.ParseFloat(s, 64)
+	if err != nil || version < 0 {
+		return 0, fmt.Errorf("invalid version %q", s)
+	}
+
+	return version, nil
+}
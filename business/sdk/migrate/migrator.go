@@ -0,0 +1,416 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/ardanlabs/service/business/sdk/sqldb/dialect"
+	"github.com/jmoiron/sqlx"
+)
+
+// Set of error variables for migrations.
+var (
+	ErrChecksumDrift    = errors.New("applied migration was changed")
+	ErrMissingMigration = errors.New("applied migration is missing")
+	ErrNoDownScript     = errors.New("migration has no down script")
+	ErrUnknownVersion   = errors.New("unknown version")
+	ErrUnknownDialect   = errors.New("no migrations for the dialect")
+)
+
+// State represents the state of a migration in the database.
+type State string
+
+// Set of states for a migration.
+const (
+	StateApplied State = "applied"
+	StatePending State = "pending"
+	StateDrift   State = "drift"
+	StateMissing State = "missing"
+)
+
+// Status represents the state of a single version.
+type Status struct {
+	Version     float64
+	Description string
+	State       State
+	AppliedAt   time.Time
+	HasDown     bool
+}
+
+// Direction represents which script of a migration a step runs.
+type Direction string
+
+// Set of directions for a step.
+const (
+	DirectionUp   Direction = "up"
+	DirectionDown Direction = "down"
+)
+
+// Step represents a migration to run in one direction.
+type Step struct {
+	Migration Migration
+	Direction Direction
+}
+
+// Script returns the script the step runs.
+func (s Step) Script() string {
+	if s.Direction == DirectionDown {
+		return s.Migration.Down
+	}
+
+	return s.Migration.Up
+}
+
+// =============================================================================
+
+// The migrations table keeps the name and layout darwin created so databases
+// migrated before this package owned the table keep working.
+var createTable = map[string]string{
+	dialect.Postgres{}.Name(): `
+	CREATE TABLE IF NOT EXISTS darwin_migrations (
+		id             SERIAL                  NOT NULL,
+		version        REAL                    NOT NULL,
+		description    CHARACTER VARYING (255) NOT NULL,
+		checksum       CHARACTER VARYING (32)  NOT NULL,
+		applied_at     INTEGER                 NOT NULL,
+		execution_time REAL                    NOT NULL,
+		UNIQUE         (version),
+		PRIMARY KEY    (id)
+	)`,
+
+	dialect.SQLite{}.Name(): `
+	CREATE TABLE IF NOT EXISTS darwin_migrations (
+		id             INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+		version        REAL    NOT NULL UNIQUE,
+		description    TEXT    NOT NULL,
+		checksum       TEXT    NOT NULL,
+		applied_at     INTEGER NOT NULL,
+		execution_time REAL    NOT NULL
+	)`,
+}
+
+// record represents a row in the migrations table.
+type record struct {
+	Version       float64 `db:"version"`
+	Description   string  `db:"description"`
+	Checksum      string  `db:"checksum"`
+	AppliedAt     int64   `db:"applied_at"`
+	ExecutionTime float64 `db:"execution_time"`
+}
+
+// Migrator applies and rolls back the migrations for a dialect.
+type Migrator struct {
+	db          *sqlx.DB
+	createTable string
+	migrations  []Migration
+}
+
+// New constructs a migrator for the database using the migrations for the
+// dialect embedded in this package.
+func New(db *sqlx.DB, d dialect.Dialect) (*Migrator, error) {
+	createTable, exists := createTable[d.Name()]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, d.Name())
+	}
+
+	fsys, err := fs.Sub(migrationsFS, "sql/"+d.Name())
+	if err != nil {
+		return nil, fmt.Errorf("sub: %w", err)
+	}
+
+	migs, err := ParseMigrations(fsys)
+	if err != nil {
+		return nil, fmt.Errorf("parse migrations: %w", err)
+	}
+
+	m := Migrator{
+		db:          db,
+		createTable: createTable,
+		migrations:  migs,
+	}
+
+	return &m, nil
+}
+
+// Migrations returns the migrations ordered by version.
+func (m *Migrator) Migrations() []Migration {
+	return slices.Clone(m.migrations)
+}
+
+// Status returns the state of every version known to the package or
+// recorded in the database.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return status(m.migrations, records), nil
+}
+
+// PlanUp returns the steps that bring the database to the latest version.
+func (m *Migrator) PlanUp(ctx context.Context) ([]Step, error) {
+	if len(m.migrations) == 0 {
+		return nil, nil
+	}
+
+	return m.PlanTo(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// PlanDown returns the step that rolls back the latest applied version.
+func (m *Migrator) PlanDown(ctx context.Context) ([]Step, error) {
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var target float64
+	if len(records) > 1 {
+		target = records[len(records)-2].Version
+	}
+
+	return plan(m.migrations, records, target)
+}
+
+// PlanTo returns the steps that bring the database to the version, applying
+// or rolling back migrations as needed. A version of 0 rolls back every
+// migration.
+func (m *Migrator) PlanTo(ctx context.Context, version float64) ([]Step, error) {
+	records, err := m.records(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return plan(m.migrations, records, version)
+}
+
+// Up brings the database to the latest version.
+func (m *Migrator) Up(ctx context.Context) ([]Step, error) {
+	steps, err := m.PlanUp(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return steps, m.apply(ctx, steps)
+}
+
+// Down rolls back the latest applied version.
+func (m *Migrator) Down(ctx context.Context) ([]Step, error) {
+	steps, err := m.PlanDown(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return steps, m.apply(ctx, steps)
+}
+
+// To brings the database to the version.
+func (m *Migrator) To(ctx context.Context, version float64) ([]Step, error) {
+	steps, err := m.PlanTo(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	return steps, m.apply(ctx, steps)
+}
+
+// =============================================================================
+
+func (m *Migrator) records(ctx context.Context) ([]record, error) {
+	if _, err := m.db.ExecContext(ctx, m.createTable); err != nil {
+		return nil, fmt.Errorf("create migrations table: %w", err)
+	}
+
+	const q = `
+	SELECT
+		version, description, checksum, applied_at, execution_time
+	FROM
+		darwin_migrations
+	ORDER BY
+		version`
+
+	var records []record
+	if err := m.db.SelectContext(ctx, &records, q); err != nil {
+		return nil, fmt.Errorf("select migrations: %w", err)
+	}
+
+	// Postgres stores the version as a 32 bit float so it's rounded back to
+	// the value the migration was recorded with.
+	for i := range records {
+		records[i].Version = math.Round(records[i].Version*1e5) / 1e5
+	}
+
+	return records, nil
+}
+
+// apply runs each step in its own transaction with the change to the
+// migrations table so a failed step leaves the database at the last good
+// version.
+func (m *Migrator) apply(ctx context.Context, steps []Step) error {
+	for _, step := range steps {
+		if err := m.applyStep(ctx, step); err != nil {
+			return fmt.Errorf("%s %s: %w", step.Direction, step.Migration, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *Migrator) applyStep(ctx context.Context, step Step) (err error) {
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+
+	defer func() {
+		if errTx := tx.Rollback(); errTx != nil && !errors.Is(errTx, sql.ErrTxDone) {
+			err = errors.Join(err, fmt.Errorf("rollback: %w", errTx))
+		}
+	}()
+
+	start := time.Now()
+
+	if _, err := tx.ExecContext(ctx, step.Script()); err != nil {
+		return fmt.Errorf("exec: %w", err)
+	}
+
+	mig := step.Migration
+
+	switch step.Direction {
+	case DirectionUp:
+		const q = `
+		INSERT INTO darwin_migrations
+			(version, description, checksum, applied_at, execution_time)
+		VALUES
+			(?, ?, ?, ?, ?)`
+
+		if _, err := tx.ExecContext(ctx, m.db.Rebind(q), mig.Version, mig.Description, mig.Checksum(), start.Unix(), time.Since(start)); err != nil {
+			return fmt.Errorf("insert migration: %w", err)
+		}
+
+	case DirectionDown:
+		const q = `
+		DELETE FROM
+			darwin_migrations
+		WHERE
+			version = CAST(? AS REAL)`
+
+		if _, err := tx.ExecContext(ctx, m.db.Rebind(q), mig.Version); err != nil {
+			return fmt.Errorf("delete migration: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+func status(migs []Migration, records []record) []Status {
+	applied := make(map[float64]record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+
+	statuses := make([]Status, 0, len(migs))
+
+	for _, mig := range migs {
+		s := Status{
+			Version:     mig.Version,
+			Description: mig.Description,
+			State:       StatePending,
+			HasDown:     mig.Down != "",
+		}
+
+		if rec, exists := applied[mig.Version]; exists {
+			s.State = StateApplied
+			s.AppliedAt = time.Unix(rec.AppliedAt, 0)
+
+			if rec.Checksum != mig.Checksum() {
+				s.State = StateDrift
+			}
+
+			delete(applied, mig.Version)
+		}
+
+		statuses = append(statuses, s)
+	}
+
+	for _, rec := range applied {
+		statuses = append(statuses, Status{
+			Version:     rec.Version,
+			Description: rec.Description,
+			State:       StateMissing,
+			AppliedAt:   time.Unix(rec.AppliedAt, 0),
+		})
+	}
+
+	slices.SortFunc(statuses, func(a, b Status) int {
+		switch {
+		case a.Version < b.Version:
+			return -1
+		case a.Version > b.Version:
+			return 1
+		}
+		return 0
+	})
+
+	return statuses
+}
+
+// plan validates the applied migrations and returns the steps to reach the
+// target version. Rollbacks of the versions above the target run first,
+// newest to oldest, then the pending versions up to the target run oldest
+// to newest.
+func plan(migs []Migration, records []record, target float64) ([]Step, error) {
+	for _, s := range status(migs, records) {
+		switch s.State {
+		case StateDrift:
+			return nil, fmt.Errorf("%w: version %s", ErrChecksumDrift, formatVersion(s.Version))
+		case StateMissing:
+			return nil, fmt.Errorf("%w: version %s", ErrMissingMigration, formatVersion(s.Version))
+		}
+	}
+
+	if target != 0 && !slices.ContainsFunc(migs, func(mig Migration) bool { return mig.Version == target }) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownVersion, formatVersion(target))
+	}
+
+	applied := make(map[float64]bool, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = true
+	}
+
+	var steps []Step
+
+	for _, mig := range slices.Backward(migs) {
+		if mig.Version <= target || !applied[mig.Version] {
+			continue
+		}
+
+		if mig.Down == "" {
+			return nil, fmt.Errorf("%w: version %s", ErrNoDownScript, mig)
+		}
+
+		steps = append(steps, Step{Migration: mig, Direction: DirectionDown})
+	}
+
+	for _, mig := range migs {
+		if mig.Version > target || applied[mig.Version] {
+			continue
+		}
+
+		steps = append(steps, Step{Migration: mig, Direction: DirectionUp})
+	}
+
+	return steps, nil
+}
@@ -0,0 +1,17 @@
+-- Description: Create table homes
+CREATE TABLE homes (
+    home_id       UUID       NOT NULL,
+    type          TEXT       NOT NULL,
+    user_id       UUID       NOT NULL,
+    address_1     TEXT       NOT NULL,
+    address_2     TEXT       NULL,
+    zip_code      TEXT       NOT NULL,
+    city          TEXT       NOT NULL,
+    state         TEXT       NOT NULL,
+    country       TEXT       NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+    date_updated  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (home_id),
+    FOREIGN KEY (user_id) REFERENCES users(user_id) ON DELETE CASCADE
+);
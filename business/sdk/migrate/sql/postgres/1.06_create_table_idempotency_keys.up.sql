@@ -0,0 +1,14 @@
+-- Description: Create table idempotency_keys
+CREATE TABLE idempotency_keys (
+    subject_id    UUID       NOT NULL,
+    idem_key      TEXT       NOT NULL,
+    fingerprint   TEXT       NOT NULL,
+    completed     BOOLEAN    NOT NULL,
+    status_code   INT        NOT NULL,
+    content_type  TEXT       NOT NULL,
+    body          BYTEA      NULL,
+    expires_at    TIMESTAMP  NOT NULL,
+    date_created  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (subject_id, idem_key)
+);
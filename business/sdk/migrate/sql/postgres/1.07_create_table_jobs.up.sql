@@ -0,0 +1,18 @@
+-- Description: Create table jobs
+CREATE TABLE jobs (
+    id            UUID       NOT NULL,
+    type          TEXT       NOT NULL,
+    payload       JSONB      NOT NULL,
+    status        TEXT       NOT NULL,
+    attempts      INT        NOT NULL,
+    max_attempts  INT        NOT NULL,
+    last_error    TEXT       NULL,
+    scheduled_at  TIMESTAMP  NOT NULL,
+    locked_until  TIMESTAMP  NULL,
+    date_created  TIMESTAMP  NOT NULL,
+    date_updated  TIMESTAMP  NOT NULL,
+
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX jobs_status_scheduled_at_idx ON jobs (status, scheduled_at);
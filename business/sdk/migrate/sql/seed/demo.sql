@@ -0,0 +1,43 @@
+-- The dev users plus a small catalog of products and homes for demos. The
+-- upserts make the set safe to run again and restore the rows if they were
+-- changed.
+INSERT INTO users (user_id, name, email, roles, password_hash, department, enabled, date_created, date_updated) VALUES
+	('5cf37266-3473-4006-984f-9325122678b7', 'Admin Gopher', 'admin@example.com', '{ADMIN}', '$2a$10$1ggfMVZV6Js0ybvJufLRUOWHS5f6KneuP0XwwHpJ8L8ipdry9f2/a', NULL, true, '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
+	('45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'User Gopher', 'user@example.com', '{USER}', '$2a$10$9/XASPKBbJKVfCAZKDH.UuhsuALDr5vVm6VrYA9VFR8rccK86C1hW', NULL, true, '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
+	('8a1c2f4e-3b6d-4e0a-9c7f-1d2e3f4a5b6c', 'Ava Martinez', 'ava@example.com', '{USER}', '$2a$10$9/XASPKBbJKVfCAZKDH.UuhsuALDr5vVm6VrYA9VFR8rccK86C1hW', 'Sales', true, '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
+	('2b7d9e1f-6c4a-4f3b-8e2d-7a9c1b3d5e7f', 'Noah Williams', 'noah@example.com', '{USER}', '$2a$10$9/XASPKBbJKVfCAZKDH.UuhsuALDr5vVm6VrYA9VFR8rccK86C1hW', 'Support', true, '2019-03-24 00:00:00', '2019-03-24 00:00:00')
+ON CONFLICT (user_id) DO UPDATE SET
+	name          = EXCLUDED.name,
+	email         = EXCLUDED.email,
+	roles         = EXCLUDED.roles,
+	password_hash = EXCLUDED.password_hash,
+	department    = EXCLUDED.department,
+	enabled       = EXCLUDED.enabled,
+	date_updated  = EXCLUDED.date_updated;
+
+INSERT INTO products (product_id, user_id, name, cost, quantity, date_created, date_updated) VALUES
+	('a2b0639f-2cc6-44b8-b97b-15d69dbb511e', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'Comic Books', 50.00, 42, '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
+	('72f8b983-3eb4-48db-9ed0-e45cc6bd716b', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'McDonalds Toys', 75.00, 120, '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
+	('3f6e1a2b-9c8d-4e7f-a1b2-c3d4e5f6a7b8', '8a1c2f4e-3b6d-4e0a-9c7f-1d2e3f4a5b6c', 'Vintage Lamp', 35.50, 3, '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
+	('4a7f2b3c-0d9e-4f8a-b2c3-d4e5f6a7b8c9', '8a1c2f4e-3b6d-4e0a-9c7f-1d2e3f4a5b6c', 'Road Bike', 220.00, 1, '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
+	('5b8a3c4d-1e0f-4a9b-c3d4-e5f6a7b8c9d0', '2b7d9e1f-6c4a-4f3b-8e2d-7a9c1b3d5e7f', 'Board Games', 18.25, 12, '2019-03-24 00:00:00', '2019-03-24 00:00:00')
+ON CONFLICT (product_id) DO UPDATE SET
+	user_id      = EXCLUDED.user_id,
+	name         = EXCLUDED.name,
+	cost         = EXCLUDED.cost,
+	quantity     = EXCLUDED.quantity,
+	date_updated = EXCLUDED.date_updated;
+
+INSERT INTO homes (home_id, type, user_id, address_1, address_2, zip_code, city, state, country, date_created, date_updated) VALUES
+	('6c9b4d5e-2f1a-4b0c-d4e5-f6a7b8c9d0e1', 'SINGLE FAMILY', '45b5fbd3-755f-4379-8f07-a58d4a30fa2f', '123 Mockingbird Lane', '', '35803', 'Huntsville', 'AL', 'US', '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
+	('7d0c5e6f-3a2b-4c1d-e5f6-a7b8c9d0e1f2', 'CONDO', '8a1c2f4e-3b6d-4e0a-9c7f-1d2e3f4a5b6c', '500 Ocean Drive', 'Unit 12', '33139', 'Miami Beach', 'FL', 'US', '2019-03-24 00:00:00', '2019-03-24 00:00:00')
+ON CONFLICT (home_id) DO UPDATE SET
+	type         = EXCLUDED.type,
+	user_id      = EXCLUDED.user_id,
+	address_1    = EXCLUDED.address_1,
+	address_2    = EXCLUDED.address_2,
+	zip_code     = EXCLUDED.zip_code,
+	city         = EXCLUDED.city,
+	state        = EXCLUDED.state,
+	country      = EXCLUDED.country,
+	date_updated = EXCLUDED.date_updated;
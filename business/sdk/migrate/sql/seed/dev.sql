@@ -1,4 +1,13 @@
+-- The users needed to work with the service locally. The upserts make the
+-- set safe to run again and restore the rows if they were changed.
 INSERT INTO users (user_id, name, email, roles, password_hash, department, enabled, date_created, date_updated) VALUES
 	('5cf37266-3473-4006-984f-9325122678b7', 'Admin Gopher', 'admin@example.com', '{ADMIN}', '$2a$10$1ggfMVZV6Js0ybvJufLRUOWHS5f6KneuP0XwwHpJ8L8ipdry9f2/a', NULL, true, '2019-03-24 00:00:00', '2019-03-24 00:00:00'),
 	('45b5fbd3-755f-4379-8f07-a58d4a30fa2f', 'User Gopher', 'user@example.com', '{USER}', '$2a$10$9/XASPKBbJKVfCAZKDH.UuhsuALDr5vVm6VrYA9VFR8rccK86C1hW', NULL, true, '2019-03-24 00:00:00', '2019-03-24 00:00:00')
-ON CONFLICT DO NOTHING;
+ON CONFLICT (user_id) DO UPDATE SET
+	name          = EXCLUDED.name,
+	email         = EXCLUDED.email,
+	roles         = EXCLUDED.roles,
+	password_hash = EXCLUDED.password_hash,
+	department    = EXCLUDED.department,
+	enabled       = EXCLUDED.enabled,
+	date_updated  = EXCLUDED.date_updated;
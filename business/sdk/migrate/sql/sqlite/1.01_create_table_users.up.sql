@@ -0,0 +1,14 @@
+-- Description: Create table users
+CREATE TABLE users (
+	user_id       TEXT        NOT NULL,
+	name          TEXT        NOT NULL,
+	email         TEXT UNIQUE NOT NULL,
+	roles         TEXT        NOT NULL,
+	password_hash TEXT        NOT NULL,
+	department    TEXT        NULL,
+	enabled       BOOLEAN     NOT NULL,
+	date_created  TIMESTAMP   NOT NULL,
+	date_updated  TIMESTAMP   NOT NULL,
+
+	PRIMARY KEY (user_id)
+);
@@ -0,0 +1 @@
+DROP VIEW view_products;
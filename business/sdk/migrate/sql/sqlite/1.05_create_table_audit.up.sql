@@ -0,0 +1,14 @@
+-- Description: Create table audit
+CREATE TABLE audit (
+	id          TEXT      NOT NULL,
+	obj_id      TEXT      NOT NULL,
+	obj_domain  TEXT      NOT NULL,
+	obj_name    TEXT      NOT NULL,
+	actor_id    TEXT      NOT NULL,
+	action      TEXT      NOT NULL,
+	data        TEXT      NULL,
+	message     TEXT      NULL,
+	timestamp   TIMESTAMP NOT NULL,
+
+	PRIMARY KEY (id)
+);
@@ -0,0 +1,2 @@
+-- Description: Add the in-flight lease to idempotency_keys
+ALTER TABLE idempotency_keys ADD COLUMN locked_until TIMESTAMP NOT NULL DEFAULT '1970-01-01 00:00:00';
@@ -0,0 +1,53 @@
+package seed
+
+var firstNames = []string{
+	"Ava", "Liam", "Olivia", "Noah", "Emma", "Oliver", "Sophia", "Elijah",
+	"Isabella", "James", "Mia", "Lucas", "Amelia", "Mason", "Harper", "Ethan",
+	"Evelyn", "Logan", "Abigail", "Aiden", "Emily", "Jackson", "Ella", "Mateo",
+	"Aria", "Daniel", "Chloe", "Henry", "Layla", "Samuel",
+}
+
+var lastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller",
+	"Davis", "Rodriguez", "Martinez", "Hernandez", "Lopez", "Gonzalez",
+	"Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+	"Lee", "Perez", "Thompson", "White", "Harris", "Clark", "Lewis", "Walker",
+}
+
+var departments = []string{
+	"Sales", "Support", "Marketing", "Engineering", "Finance", "Operations",
+}
+
+var adjectives = []string{
+	"Vintage", "Handmade", "Classic", "Compact", "Deluxe", "Rustic", "Modern",
+	"Antique", "Portable", "Sturdy", "Retro", "Wooden",
+}
+
+var products = []string{
+	"Lamp", "Bike", "Guitar", "Bookshelf", "Kettle", "Board Game", "Camera",
+	"Desk Chair", "Turntable", "Toolbox", "Tent", "Skateboard", "Mirror",
+	"Rug", "Blender",
+}
+
+var streets = []string{
+	"Main Street", "Oak Avenue", "Maple Drive", "Cedar Lane", "Pine Street",
+	"Elm Street", "Lakeview Drive", "Hillcrest Road", "Sunset Boulevard",
+	"River Road", "Park Avenue", "Mockingbird Lane",
+}
+
+var cities = []struct {
+	name      string
+	state     string
+	zipPrefix string
+}{
+	{"Huntsville", "AL", "358"},
+	{"Miami", "FL", "331"},
+	{"Austin", "TX", "787"},
+	{"Denver", "CO", "802"},
+	{"Portland", "OR", "972"},
+	{"Chicago", "IL", "606"},
+	{"Boston", "MA", "021"},
+	{"Seattle", "WA", "981"},
+	{"Phoenix", "AZ", "850"},
+	{"Atlanta", "GA", "303"},
+}
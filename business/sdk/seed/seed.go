@@ -0,0 +1,227 @@
+// Package seed generates realistic users, products and homes through the
+// business layer so passwords are hashed and the extensions, like auditing,
+// run the same as they do for requests to the service.
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/mail"
+	"strings"
+
+	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/home"
+	"github.com/ardanlabs/service/business/types/money"
+	"github.com/ardanlabs/service/business/types/name"
+	"github.com/ardanlabs/service/business/types/password"
+	"github.com/ardanlabs/service/business/types/quantity"
+	"github.com/ardanlabs/service/business/types/role"
+	"github.com/google/uuid"
+)
+
+// Buses represents the business apis the generator writes through and the
+// beginner for the transaction each user is created in.
+type Buses struct {
+	Beginner sqldb.Beginner
+	User     userbus.ExtBusiness
+	Product  productbus.ExtBusiness
+	Home     homebus.ExtBusiness
+}
+
+// Config represents the amount of data to generate. The same random seed
+// generates the same users, so running the generator again only creates the
+// users that don't exist yet. The actor is recorded as the one creating the
+// users.
+type Config struct {
+	Users           int
+	ProductsPerUser int
+	HomesPerUser    int
+	Password        string
+	RandomSeed      int64
+	ActorID         uuid.UUID
+}
+
+// Result represents what the generator created.
+type Result struct {
+	Users    int
+	Products int
+	Homes    int
+	Skipped  int
+}
+
+// Generate creates the users with their products and homes. Each user is
+// created in one transaction with its products and homes, so a user whose
+// email already exists is skipped knowing they were created as well.
+func Generate(ctx context.Context, buses Buses, cfg Config) (Result, error) {
+	if cfg.Password == "" {
+		cfg.Password = "gophers"
+	}
+
+	pass, err := password.Parse(cfg.Password)
+	if err != nil {
+		return Result{}, fmt.Errorf("parse password: %w", err)
+	}
+
+	rnd := rand.New(rand.NewSource(cfg.RandomSeed))
+
+	var result Result
+
+	for i := range cfg.Users {
+		nu := newUser(rnd, i, pass)
+
+		// The products and homes are drawn for every user, even the ones
+		// that are skipped, so the data for a user doesn't depend on what
+		// already exists.
+		nps := newProducts(rnd, cfg.ProductsPerUser)
+		nhs := newHomes(rnd, cfg.HomesPerUser)
+
+		_, err := buses.User.QueryByEmail(ctx, nu.Email)
+		switch {
+		case err == nil:
+			result.Skipped++
+			continue
+
+		case !errors.Is(err, userbus.ErrNotFound):
+			return result, fmt.Errorf("query user: %s: %w", nu.Email.Address, err)
+		}
+
+		if err := generateUser(ctx, buses, cfg.ActorID, nu, nps, nhs); err != nil {
+			return result, err
+		}
+
+		result.Users++
+		result.Products += len(nps)
+		result.Homes += len(nhs)
+	}
+
+	return result, nil
+}
+
+// =============================================================================
+
+// generateUser creates the user with its products and homes in a transaction.
+func generateUser(ctx context.Context, buses Buses, actorID uuid.UUID, nu userbus.NewUser, nps []productbus.NewProduct, nhs []homebus.NewHome) error {
+	tx, err := buses.Beginner.Begin()
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	userBus, err := buses.User.NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("user with tx: %w", err)
+	}
+
+	productBus, err := buses.Product.NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("product with tx: %w", err)
+	}
+
+	homeBus, err := buses.Home.NewWithTx(tx)
+	if err != nil {
+		return fmt.Errorf("home with tx: %w", err)
+	}
+
+	usr, err := userBus.Create(ctx, actorID, nu)
+	if err != nil {
+		return fmt.Errorf("create user: %s: %w", nu.Email.Address, err)
+	}
+
+	for _, np := range nps {
+		np.UserID = usr.ID
+
+		if _, err := productBus.Create(ctx, np); err != nil {
+			return fmt.Errorf("create product: %s: %w", np.Name, err)
+		}
+	}
+
+	for _, nh := range nhs {
+		nh.UserID = usr.ID
+
+		if _, err := homeBus.Create(ctx, nh); err != nil {
+			return fmt.Errorf("create home: %s: %w", nh.Address.Address1, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %s: %w", nu.Email.Address, err)
+	}
+
+	return nil
+}
+
+func newUser(rnd *rand.Rand, idx int, pass password.Password) userbus.NewUser {
+	first := pick(rnd, firstNames)
+	last := pick(rnd, lastNames)
+
+	roles := []role.Role{role.User}
+	if rnd.Intn(20) == 0 {
+		roles = []role.Role{role.Admin}
+	}
+
+	// The index keeps the email unique when the same name is drawn twice.
+	email := fmt.Sprintf("%s.%s.%d@example.com", strings.ToLower(first), strings.ToLower(last), idx)
+
+	return userbus.NewUser{
+		Name:       name.MustParse(first + " " + last),
+		Email:      mail.Address{Address: email},
+		Roles:      roles,
+		Department: name.MustParseNull(pick(rnd, departments)),
+		Password:   pass,
+	}
+}
+
+func newProducts(rnd *rand.Rand, n int) []productbus.NewProduct {
+	nps := make([]productbus.NewProduct, n)
+
+	for i := range nps {
+		cost := math.Round((rnd.Float64()*250+1)*100) / 100
+
+		nps[i] = productbus.NewProduct{
+			Name:     name.MustParse(pick(rnd, adjectives) + " " + pick(rnd, products)),
+			Cost:     money.MustParse(cost),
+			Quantity: quantity.MustParse(rnd.Intn(100) + 1),
+		}
+	}
+
+	return nps
+}
+
+func newHomes(rnd *rand.Rand, n int) []homebus.NewHome {
+	nhs := make([]homebus.NewHome, n)
+
+	for i := range nhs {
+		typ := home.Single
+		var address2 string
+		if rnd.Intn(3) == 0 {
+			typ = home.Condo
+			address2 = fmt.Sprintf("Unit %d", rnd.Intn(400)+1)
+		}
+
+		c := cities[rnd.Intn(len(cities))]
+
+		nhs[i] = homebus.NewHome{
+			Type: typ,
+			Address: homebus.Address{
+				Address1: fmt.Sprintf("%d %s", rnd.Intn(9900)+100, pick(rnd, streets)),
+				Address2: address2,
+				ZipCode:  fmt.Sprintf("%s%02d", c.zipPrefix, rnd.Intn(100)),
+				City:     c.name,
+				State:    c.state,
+				Country:  "US",
+			},
+		}
+	}
+
+	return nhs
+}
+
+func pick(rnd *rand.Rand, values []string) string {
+	return values[rnd.Intn(len(values))]
+}
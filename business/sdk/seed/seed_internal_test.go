@@ -0,0 +1,31 @@
+package seed
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/ardanlabs/service/business/types/password"
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_Values(t *testing.T) {
+	pass := password.MustParse("gophers")
+
+	gen := func() []any {
+		rnd := rand.New(rand.NewSource(7))
+
+		var values []any
+		for i := range 500 {
+			values = append(values, newUser(rnd, i, pass), newProducts(rnd, 3), newHomes(rnd, 2))
+		}
+
+		return values
+	}
+
+	// The constructors panic if a generated value doesn't pass validation.
+	first := gen()
+
+	if diff := cmp.Diff(first, gen()); diff != "" {
+		t.Errorf("Should generate the same data for the same seed :\n%s", diff)
+	}
+}
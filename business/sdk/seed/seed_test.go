@@ -0,0 +1,99 @@
+package seed_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ardanlabs/service/business/domain/auditbus"
+	"github.com/ardanlabs/service/business/domain/productbus"
+	"github.com/ardanlabs/service/business/domain/userbus"
+	"github.com/ardanlabs/service/business/sdk/dbtest"
+	"github.com/ardanlabs/service/business/sdk/page"
+	"github.com/ardanlabs/service/business/sdk/seed"
+	"github.com/ardanlabs/service/business/sdk/sqldb"
+	"github.com/ardanlabs/service/business/types/domain"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+)
+
+func Test_Generate(t *testing.T) {
+	t.Parallel()
+
+	db := dbtest.New(t, "Test_Generate")
+
+	ctx := context.Background()
+
+	buses := seed.Buses{
+		Beginner: sqldb.NewBeginner(db.DB),
+		User:     db.BusDomain.User,
+		Product:  db.BusDomain.Product,
+		Home:     db.BusDomain.Home,
+	}
+
+	actorID := uuid.New()
+
+	cfg := seed.Config{
+		Users:           5,
+		ProductsPerUser: 2,
+		HomesPerUser:    1,
+		RandomSeed:      42,
+		ActorID:         actorID,
+	}
+
+	result, err := seed.Generate(ctx, buses, cfg)
+	if err != nil {
+		t.Fatalf("Should be able to generate the data : %s", err)
+	}
+
+	exp := seed.Result{Users: 5, Products: 10, Homes: 5}
+	if diff := cmp.Diff(exp, result); diff != "" {
+		t.Errorf("Should create the data :\n%s", diff)
+	}
+
+	n, err := db.BusDomain.Product.Count(ctx, productbus.QueryFilter{})
+	if err != nil {
+		t.Fatalf("Should be able to count the products : %s", err)
+	}
+
+	if n != 10 {
+		t.Errorf("Should get 10 products : got %d", n)
+	}
+
+	// The users are created through the business layer so the audit
+	// extension runs and the password is hashed.
+
+	dmn := domain.User
+	action := "created"
+	n, err = db.BusDomain.Audit.Count(ctx, auditbus.QueryFilter{ObjDomain: &dmn, Action: &action, ActorID: &actorID})
+	if err != nil {
+		t.Fatalf("Should be able to count the audits : %s", err)
+	}
+
+	if n != 5 {
+		t.Errorf("Should get 5 user audits by the actor : got %d", n)
+	}
+
+	usrs, err := db.BusDomain.User.Query(ctx, userbus.QueryFilter{}, userbus.DefaultOrderBy, page.MustParse("1", "10"))
+	if err != nil || len(usrs) == 0 {
+		t.Fatalf("Should be able to query the users : %v", err)
+	}
+
+	if _, err := db.BusDomain.User.Authenticate(ctx, usrs[0].Email, "gophers"); err != nil {
+		t.Errorf("Should be able to authenticate a generated user : %s", err)
+	}
+
+	// Running the generator again with the same seed skips the users that
+	// exist and creates the new ones.
+
+	cfg.Users = 7
+
+	result, err = seed.Generate(ctx, buses, cfg)
+	if err != nil {
+		t.Fatalf("Should be able to generate the data again : %s", err)
+	}
+
+	exp = seed.Result{Users: 2, Products: 4, Homes: 2, Skipped: 5}
+	if diff := cmp.Diff(exp, result); diff != "" {
+		t.Errorf("Should only create the new users :\n%s", diff)
+	}
+}
@@ -0,0 +1,83 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/ardanlabs/service/foundation/scheduler"
+	"github.com/jmoiron/sqlx"
+)
+
+// AdvisoryLocker implements the scheduler Locker interface with Postgres
+// session level advisory locks so only one instance of a service runs a
+// leader task. The lock is tied to a dedicated connection and is released
+// by the database if the instance goes away.
+type AdvisoryLocker struct {
+	db *sqlx.DB
+}
+
+// NewAdvisoryLocker constructs a locker that uses the database.
+func NewAdvisoryLocker(db *sqlx.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{
+		db: db,
+	}
+}
+
+// Acquire tries to take the advisory lock for the name. It returns
+// scheduler.ErrLocked when another session holds the lock.
+func (l *AdvisoryLocker) Acquire(ctx context.Context, name string) (scheduler.Lease, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("conn: %w", err)
+	}
+
+	const q = `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, q, name).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("try lock: %s: %w", name, err)
+	}
+
+	if !locked {
+		conn.Close()
+		return nil, scheduler.ErrLocked
+	}
+
+	lease := advisoryLease{
+		conn: conn,
+		name: name,
+	}
+
+	return &lease, nil
+}
+
+// advisoryLease holds the connection the advisory lock was taken on.
+type advisoryLease struct {
+	conn *sql.Conn
+	name string
+}
+
+// Check confirms the connection holding the lock is still alive. If the
+// connection was lost the database released the lock.
+func (l *advisoryLease) Check(ctx context.Context) error {
+	if err := l.conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping: %s: %w", l.name, err)
+	}
+
+	return nil
+}
+
+// Release gives up the lock and returns the connection to the pool.
+func (l *advisoryLease) Release(ctx context.Context) error {
+	defer l.conn.Close()
+
+	const q = `SELECT pg_advisory_unlock(hashtextextended($1, 0))`
+
+	if _, err := l.conn.ExecContext(ctx, q, l.name); err != nil {
+		return fmt.Errorf("unlock: %s: %w", l.name, err)
+	}
+
+	return nil
+}
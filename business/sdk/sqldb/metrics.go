@@ -0,0 +1,78 @@
+package sqldb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// RegisterMetrics registers the connection pool statistics of the database
+// as metrics that are observed when the metrics are collected. The pool name
+// is added to the metrics so multiple databases can be told apart. The
+// returned function removes the registration.
+func RegisterMetrics(db *sqlx.DB, pool string) (func(), error) {
+	meter := otel.Meter("github.com/ardanlabs/service/business/sdk/sqldb")
+
+	open, err := meter.Int64ObservableGauge("db.client.connections.open", metric.WithDescription("Number of established connections."))
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+
+	inUse, err := meter.Int64ObservableGauge("db.client.connections.in_use", metric.WithDescription("Number of connections currently in use."))
+	if err != nil {
+		return nil, fmt.Errorf("in use: %w", err)
+	}
+
+	idle, err := meter.Int64ObservableGauge("db.client.connections.idle", metric.WithDescription("Number of idle connections."))
+	if err != nil {
+		return nil, fmt.Errorf("idle: %w", err)
+	}
+
+	maxOpen, err := meter.Int64ObservableGauge("db.client.connections.max", metric.WithDescription("Maximum number of open connections."))
+	if err != nil {
+		return nil, fmt.Errorf("max: %w", err)
+	}
+
+	waitCount, err := meter.Int64ObservableCounter("db.client.connections.waits", metric.WithDescription("Number of connections waited for."))
+	if err != nil {
+		return nil, fmt.Errorf("wait count: %w", err)
+	}
+
+	waitDuration, err := meter.Int64ObservableCounter("db.client.connections.wait_time",
+		metric.WithDescription("Time spent waiting for a connection."),
+		metric.WithUnit("ms"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("wait duration: %w", err)
+	}
+
+	attrs := metric.WithAttributes(attribute.String("pool.name", pool))
+
+	f := func(ctx context.Context, o metric.Observer) error {
+		stats := db.Stats()
+
+		o.ObserveInt64(open, int64(stats.OpenConnections), attrs)
+		o.ObserveInt64(inUse, int64(stats.InUse), attrs)
+		o.ObserveInt64(idle, int64(stats.Idle), attrs)
+		o.ObserveInt64(maxOpen, int64(stats.MaxOpenConnections), attrs)
+		o.ObserveInt64(waitCount, stats.WaitCount, attrs)
+		o.ObserveInt64(waitDuration, stats.WaitDuration.Milliseconds(), attrs)
+
+		return nil
+	}
+
+	reg, err := meter.RegisterCallback(f, open, inUse, idle, maxOpen, waitCount, waitDuration)
+	if err != nil {
+		return nil, fmt.Errorf("register callback: %w", err)
+	}
+
+	unregister := func() {
+		reg.Unregister()
+	}
+
+	return unregister, nil
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
 	"time"
 
@@ -302,14 +303,32 @@ func namedQueryStruct(ctx context.Context, log *logger.Logger, db sqlx.ExtContex
 	return nil
 }
 
+// namedParam matches the named parameters in a query the same way sqlx does,
+// skipping "::" type casts.
+var namedParam = regexp.MustCompile(`::|:([A-Za-z0-9_.]+)`)
+
 // queryString provides a pretty print version of the query and parameters.
+// The values of sensitive parameters, like password hashes and emails, are
+// redacted since the result is logged and added to spans.
 func queryString(query string, args any) string {
+	names := paramNames(query)
+
 	query, params, err := sqlx.Named(query, args)
 	if err != nil {
 		return err.Error()
 	}
 
-	for _, param := range params {
+	for i, param := range params {
+		var name string
+		if i < len(names) {
+			name = names[i]
+		}
+
+		if logger.IsSensitiveKey(name) || logger.IsSensitiveValue(param) {
+			query = strings.Replace(query, "?", fmt.Sprintf("'%s'", logger.Redacted), 1)
+			continue
+		}
+
 		var value string
 		switch v := param.(type) {
 		case string:
@@ -327,3 +346,16 @@ func queryString(query string, args any) string {
 
 	return strings.Trim(query, " ")
 }
+
+// paramNames returns the names of the parameters in the order they are bound.
+func paramNames(query string) []string {
+	var names []string
+
+	for _, match := range namedParam.FindAllStringSubmatch(query, -1) {
+		if match[1] != "" {
+			names = append(names, match[1])
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,37 @@
+package sqldb
+
+import (
+	"strings"
+	"testing"
+)
+
+func Test_QueryStringRedacts(t *testing.T) {
+	const hash = "$2a$10$hashedpassword"
+	const email = "bill@example.com"
+
+	data := struct {
+		Name         string `db:"name"`
+		Email        string `db:"email"`
+		PasswordHash []byte `db:"password_hash"`
+	}{
+		Name:         "bill",
+		Email:        email,
+		PasswordHash: []byte(hash),
+	}
+
+	const q = `
+	INSERT INTO users
+		(date_created, name, email, password_hash)
+	VALUES
+		(NOW()::timestamp, :name, :email, :password_hash)`
+
+	got := queryString(q, data)
+
+	if strings.Contains(got, hash) || strings.Contains(got, email) {
+		t.Errorf("Should redact sensitive parameters: %s", got)
+	}
+
+	if !strings.Contains(got, "'bill'") {
+		t.Errorf("Should keep the other parameters after a type cast: %s", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/ardanlabs/service/business/domain/auditbus"
 	"github.com/ardanlabs/service/business/domain/homebus"
+	"github.com/ardanlabs/service/business/domain/jobbus"
 	"github.com/ardanlabs/service/business/domain/productbus"
 	"github.com/ardanlabs/service/business/domain/userbus"
 )
@@ -21,6 +22,7 @@ type User struct {
 type SeedData struct {
 	Users  []User
 	Admins []User
+	Jobs   []jobbus.Job
 }
 
 // Table represent fields needed for running an unit test.
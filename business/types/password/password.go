@@ -4,6 +4,8 @@ package password
 import (
 	"fmt"
 	"regexp"
+
+	"github.com/ardanlabs/service/foundation/logger"
 )
 
 // Password represents a password in the system.
@@ -21,9 +23,16 @@ func (n Password) Equal(n2 Password) bool {
 	return n.value == n2.value
 }
 
-// MarshalText provides support for logging and any marshal needs.
+// Sensitive marks the password as a value that must never be logged.
+func (n Password) Sensitive() bool {
+	return true
+}
+
+// MarshalText provides support for logging and any marshal needs. The value
+// is redacted so a password can't leak into logs or stored documents like
+// audit records. Use String to access the value.
 func (n Password) MarshalText() ([]byte, error) {
-	return []byte(n.value), nil
+	return []byte(logger.Redacted), nil
 }
 
 // =============================================================================
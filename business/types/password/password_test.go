@@ -0,0 +1,48 @@
+package password_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/business/types/password"
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_PasswordNeverLeaks(t *testing.T) {
+	const value = "gophers#123"
+
+	pass := password.MustParse(value)
+
+	nu := struct {
+		Name     string
+		Password password.Password
+	}{
+		Name:     "bill",
+		Password: pass,
+	}
+
+	data, err := json.Marshal(nu)
+	if err != nil {
+		t.Fatalf("Should be able to marshal the value: %s", err)
+	}
+
+	if strings.Contains(string(data), value) {
+		t.Errorf("Should not marshal the password: %s", data)
+	}
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+
+	log.Info(context.Background(), "password", "user", nu, "pass", pass, "ptr", &pass)
+
+	if strings.Contains(buf.String(), value) {
+		t.Errorf("Should not log the password: %s", buf.String())
+	}
+
+	if pass.String() != value {
+		t.Errorf("Should provide the value through String: got %s", pass.String())
+	}
+}
@@ -0,0 +1,91 @@
+package docker_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/docker"
+)
+
+func Test_WaitForPort(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Should be able to listen : %s", err)
+	}
+	defer l.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := docker.ForPort().Wait(ctx, docker.Container{HostPort: l.Addr().String()}); err != nil {
+		t.Errorf("Should be able to connect to the port : %s", err)
+	}
+
+	addr := l.Addr().String()
+	l.Close()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	if err := docker.ForPort().Wait(ctx, docker.Container{HostPort: addr}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Should time out on a closed port : got %v", err)
+	}
+}
+
+func Test_WaitForHTTP(t *testing.T) {
+	var calls atomic.Int64
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ready" || calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}))
+	defer srv.Close()
+
+	c := docker.Container{HostPort: srv.Listener.Addr().String()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := docker.ForHTTP("/ready").Wait(ctx, c); err != nil {
+		t.Errorf("Should wait for the endpoint to be ready : %s", err)
+	}
+
+	if calls.Load() != 3 {
+		t.Errorf("Should poll until the endpoint is ready : got %d calls", calls.Load())
+	}
+}
+
+func Test_WaitForAll(t *testing.T) {
+	var order []string
+
+	step := func(name string, failures int) docker.WaitStrategy {
+		var calls int
+		return docker.WaitFunc(func(ctx context.Context, c docker.Container) error {
+			calls++
+			if calls <= failures {
+				return errors.New("not ready")
+			}
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := docker.ForAll(step("first", 1), step("second", 0)).Wait(ctx, docker.Container{}); err != nil {
+		t.Fatalf("Should wait for every strategy : %s", err)
+	}
+
+	if len(order) != 2 || order[0] != "first" || order[1] != "second" {
+		t.Errorf("Should wait for the strategies in order : got %v", order)
+	}
+}
@@ -0,0 +1,152 @@
+package docker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Config represents the settings to start a container. When the name is not
+// provided it is derived from the image, port, network and arguments, so
+// tests that ask for the same container share it. The wait strategy is used
+// for a started and a reused container and defaults to the port being open.
+type Config struct {
+	Image      string
+	Name       string
+	Port       string
+	Network    string
+	DockerArgs []string
+	AppArgs    []string
+	Wait       WaitStrategy
+	Timeout    time.Duration
+}
+
+// Start starts the container or reuses the one already running for the
+// configuration, then waits for it to be ready.
+func Start(ctx context.Context, cfg Config) (Container, error) {
+	if cfg.Name == "" {
+		cfg.Name = reuseName(cfg)
+	}
+
+	if cfg.Wait == nil {
+		cfg.Wait = ForPort()
+	}
+
+	if cfg.Timeout == 0 {
+		cfg.Timeout = time.Minute
+	}
+
+	dockerArgs := cfg.DockerArgs
+	if cfg.Network != "" {
+		dockerArgs = append([]string{"--network", cfg.Network}, dockerArgs...)
+	}
+
+	c, err := StartContainer(cfg.Image, cfg.Name, cfg.Port, dockerArgs, cfg.AppArgs)
+	if err != nil {
+		return Container{}, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cfg.Timeout)
+	defer cancel()
+
+	if err := cfg.Wait.Wait(ctx, c); err != nil {
+		return Container{}, fmt.Errorf("waiting for container %s: %w\n%s", c.Name, err, logTail(c.Name, 20))
+	}
+
+	return c, nil
+}
+
+// DumpLogsOnFailure writes the logs the container wrote while the test ran
+// to the test output when the test fails. A container shared by tests that
+// run in parallel can include lines written for the other tests.
+func DumpLogsOnFailure(t testing.TB, c Container) {
+	t.Helper()
+
+	start := time.Now()
+
+	t.Cleanup(func() {
+		if t.Failed() {
+			t.Logf("******************** CONTAINER LOGS (%s) ********************\n%s", c.Name, logsSince(c.Name, start))
+		}
+	})
+}
+
+// =============================================================================
+
+// CreateNetwork creates a bridge network so containers started on it can
+// reach each other by name. An existing network with the name is reused.
+func CreateNetwork(name string) error {
+	if exec.Command("docker", "network", "inspect", name).Run() == nil {
+		return nil
+	}
+
+	out, err := exec.Command("docker", "network", "create", name).CombinedOutput()
+	if err != nil {
+		// Another test process could have created it in the meantime.
+		if exec.Command("docker", "network", "inspect", name).Run() == nil {
+			return nil
+		}
+
+		return fmt.Errorf("could not create network %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// RemoveNetwork removes the network. The containers on it need to be stopped
+// first.
+func RemoveNetwork(name string) error {
+	out, err := exec.Command("docker", "network", "rm", name).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("could not remove network %s: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+// =============================================================================
+
+// reuseName derives a container name from everything that makes containers
+// interchangeable.
+func reuseName(cfg Config) string {
+	h := sha256.New()
+	for _, v := range slices.Concat([]string{cfg.Image, cfg.Port, cfg.Network}, cfg.DockerArgs, cfg.AppArgs) {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+
+	image := cfg.Image
+	if i := strings.LastIndex(image, "/"); i != -1 {
+		image = image[i+1:]
+	}
+	image, _, _ = strings.Cut(image, ":")
+
+	return fmt.Sprintf("test-%s-%s", image, hex.EncodeToString(h.Sum(nil))[:12])
+}
+
+// logsSince returns the logs the container wrote since the time.
+func logsSince(name string, since time.Time) []byte {
+	out, err := exec.Command("docker", "logs", "--since", since.Format(time.RFC3339Nano), name).CombinedOutput()
+	if err != nil {
+		return nil
+	}
+
+	return out
+}
+
+// logTail returns the last lines of the container logs to keep failure
+// messages readable.
+func logTail(name string, lines int) string {
+	out := strings.Split(strings.TrimSpace(string(DumpContainerLogs(name))), "\n")
+	if len(out) > lines {
+		out = out[len(out)-lines:]
+	}
+
+	return strings.Join(out, "\n")
+}
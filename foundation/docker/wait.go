@@ -0,0 +1,139 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os/exec"
+	"regexp"
+	"time"
+)
+
+// pollInterval is the time between the checks of a wait strategy.
+const pollInterval = 250 * time.Millisecond
+
+// WaitStrategy decides when a started container is ready to be used. Wait
+// blocks until the container is ready or the context is done.
+type WaitStrategy interface {
+	Wait(ctx context.Context, c Container) error
+}
+
+// WaitFunc is a function that implements the WaitStrategy interface. The
+// function is called until it returns nil or the context is done, so it can
+// be a single check.
+type WaitFunc func(ctx context.Context, c Container) error
+
+// Wait implements the WaitStrategy interface.
+func (f WaitFunc) Wait(ctx context.Context, c Container) error {
+	return poll(ctx, func() error {
+		return f(ctx, c)
+	})
+}
+
+// ForPort waits until a TCP connection can be made to the host port.
+func ForPort() WaitStrategy {
+	f := func(ctx context.Context, c Container) error {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", c.HostPort)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+
+	return WaitFunc(f)
+}
+
+// ForLog waits until the container logs match the pattern the number of
+// times provided. Some images, like postgres, log the same line when a setup
+// server starts and when the real one does.
+func ForLog(pattern string, occurrences int) WaitStrategy {
+	re := regexp.MustCompile(pattern)
+
+	f := func(ctx context.Context, c Container) error {
+		out, err := exec.CommandContext(ctx, "docker", "logs", c.Name).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("logs: %w", err)
+		}
+
+		if n := len(re.FindAll(out, -1)); n < occurrences {
+			return fmt.Errorf("log %q found %d of %d times", pattern, n, occurrences)
+		}
+
+		return nil
+	}
+
+	return WaitFunc(f)
+}
+
+// ForHTTP waits until a GET for the path on the host port returns a 200.
+func ForHTTP(path string) WaitStrategy {
+	client := http.Client{Timeout: time.Second}
+
+	f := func(ctx context.Context, c Container) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+c.HostPort+path, nil)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status[%d]", resp.StatusCode)
+		}
+
+		return nil
+	}
+
+	return WaitFunc(f)
+}
+
+// ForAll waits for each of the strategies in order.
+func ForAll(strategies ...WaitStrategy) WaitStrategy {
+	f := func(ctx context.Context, c Container) error {
+		for _, ws := range strategies {
+			if err := ws.Wait(ctx, c); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	return waitAll(f)
+}
+
+// waitAll runs the strategies once instead of polling them again since each
+// strategy polls on its own.
+type waitAll func(ctx context.Context, c Container) error
+
+func (f waitAll) Wait(ctx context.Context, c Container) error {
+	return f(ctx, c)
+}
+
+// =============================================================================
+
+func poll(ctx context.Context, check func() error) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := check()
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Join(ctx.Err(), err)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+// encryptedExt is the extension of the PEM files encrypted with a master key.
+const encryptedExt = ".pem.enc"
+
+// ErrMasterKeyMissing is returned when an encrypted file is found and no
+// master key was provided.
+var ErrMasterKeyMissing = errors.New("master key missing")
+
+// Encrypt encrypts the data with AES-256-GCM using a key derived from the
+// master key. The nonce is written in front of the sealed data.
+func Encrypt(masterKey []byte, data []byte) ([]byte, error) {
+	gcm, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+
+	return gcm.Seal(nonce, nonce, data, nil), nil
+}
+
+// Decrypt decrypts data produced by Encrypt with the same master key.
+func Decrypt(masterKey []byte, data []byte) ([]byte, error) {
+	gcm, err := newGCM(masterKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("data is too short")
+	}
+
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+
+	plain, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+
+	return plain, nil
+}
+
+func newGCM(masterKey []byte) (cipher.AEAD, error) {
+	if len(masterKey) == 0 {
+		return nil, ErrMasterKeyMissing
+	}
+
+	key := sha256.Sum256(masterKey)
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("gcm: %w", err)
+	}
+
+	return gcm, nil
+}
@@ -1,9 +1,12 @@
 // Package keystore implements the auth.KeyLookup interface. This implements
-// an in-memory keystore for JWT support.
+// an in-memory keystore for JWT support. Keys can be loaded directly or synced
+// from a set of sources such as the environment, encrypted files and a Vault
+// KV secrets engine.
 package keystore
 
 import (
 	"bytes"
+	"context"
 	"crypto/rsa"
 	"crypto/x509"
 	"encoding/json"
@@ -12,9 +15,12 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"maps"
 	"path"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 )
 
 // ErrKeyNotFound is returned when a key identified by a kid is not found.
@@ -31,8 +37,10 @@ type key struct {
 // KeyStore represents an in memory store implementation of the
 // KeyLookup interface for use with the auth package.
 type KeyStore struct {
-	store map[string]key
-	mu    sync.RWMutex
+	store    map[string]key
+	mu       sync.RWMutex
+	onChange []func(Change)
+	notifyMu sync.Mutex
 }
 
 // New constructs an empty KeyStore ready for use.
@@ -49,27 +57,24 @@ func (ks *KeyStore) LoadByJSON(document string) (int, error) {
 		return 0, nil
 	}
 
-	var d struct {
-		Key string `json:"key"`
-		PEM string `json:"pem"`
-	}
-	if err := json.Unmarshal([]byte(document), &d); err != nil {
-		return len(ks.store), fmt.Errorf("unable to marshal document: %w", err)
+	kid, privatePEM, err := parseDocument(document)
+	if err != nil {
+		return 0, err
 	}
 
-	publicPEM, err := toPublicPEM(d.PEM)
+	publicPEM, err := toPublicPEM(privatePEM)
 	if err != nil {
 		return 0, fmt.Errorf("converting private PEM to public: %w", err)
 	}
 
 	key := key{
-		privatePEM: d.PEM,
+		privatePEM: privatePEM,
 		publicPEM:  publicPEM,
 	}
 
 	ks.mu.Lock()
 	defer ks.mu.Unlock()
-	ks.store[d.Key] = key
+	ks.store[kid] = key
 
 	return len(ks.store), nil
 }
@@ -80,58 +85,93 @@ func (ks *KeyStore) LoadByJSON(document string) (int, error) {
 // Example: ks.LoadRSAKeys(os.DirFS("/zarf/keys/"))
 // Example: /zarf/keys/54bb2165-71e1-41a6-af3e-7da4a0e1e2c1.pem
 func (ks *KeyStore) LoadByFileSystem(fsys fs.FS) (int, error) {
-	fn := func(fileName string, dirEntry fs.DirEntry, err error) error {
-		if err != nil {
-			return fmt.Errorf("walkdir failure: %w", err)
-		}
+	pems, err := readFS(fsys, nil)
+	if err != nil {
+		return 0, err
+	}
 
-		if dirEntry.IsDir() {
-			return nil
-		}
+	keys, err := toKeys(pems)
+	if err != nil {
+		return 0, err
+	}
 
-		if path.Ext(fileName) != ".pem" {
-			return nil
-		}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	maps.Copy(ks.store, keys)
 
-		file, err := fsys.Open(fileName)
-		if err != nil {
-			return fmt.Errorf("opening key file: %w", err)
-		}
-		defer file.Close()
+	return len(ks.store), nil
+}
 
-		// limit PEM file size to 1 megabyte. This should be reasonable for
-		// almost any PEM file and prevents shenanigans like linking the file
-		// to /dev/random or something like that.
-		pem, err := io.ReadAll(io.LimitReader(file, maxPEMFileSize))
-		if err != nil {
-			return fmt.Errorf("reading auth private key: %w", err)
-		}
+// Sync replaces the keys in the store with the keys provided by the sources.
+// When a kid is provided by more than one source, the last source wins. If
+// any source fails, the store is left untouched so a temporary outage of a
+// backend doesn't remove keys that are in use. The handlers registered with
+// OnChange are called when the set of keys changed.
+func (ks *KeyStore) Sync(ctx context.Context, sources ...Source) (int, error) {
+	pems := make(map[string]string)
 
-		privatePEM := string(pem)
-		publicPEM, err := toPublicPEM(privatePEM)
+	for _, src := range sources {
+		srcPEMs, err := src.Keys(ctx)
 		if err != nil {
-			return fmt.Errorf("converting private PEM to public: %w", err)
+			return 0, fmt.Errorf("source %s: %w", src, err)
 		}
 
-		key := key{
-			privatePEM: privatePEM,
-			publicPEM:  publicPEM,
-		}
+		maps.Copy(pems, srcPEMs)
+	}
 
-		ks.mu.Lock()
-		defer ks.mu.Unlock()
-		ks.store[strings.TrimSuffix(dirEntry.Name(), ".pem")] = key
+	keys, err := toKeys(pems)
+	if err != nil {
+		return 0, err
+	}
 
-		return nil
+	ks.mu.Lock()
+	change := diff(ks.store, keys)
+	ks.store = keys
+	ks.mu.Unlock()
+
+	if !change.Empty() {
+		ks.notify(change)
 	}
 
-	if err := fs.WalkDir(fsys, ".", fn); err != nil {
-		return 0, fmt.Errorf("walking directory: %w", err)
+	return len(keys), nil
+}
+
+// Refresh syncs the store with the sources on the specified interval until
+// the context is cancelled. Errors are reported to the error handler and the
+// current keys are kept.
+func (ks *KeyStore) Refresh(ctx context.Context, interval time.Duration, onError func(err error), sources ...Source) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if _, err := ks.Sync(ctx, sources...); err != nil && ctx.Err() == nil {
+				onError(err)
+			}
+		}
 	}
+}
 
-	ks.mu.RLock()
-	defer ks.mu.RUnlock()
-	return len(ks.store), nil
+// OnChange registers a handler that is called with the set of kids that
+// changed each time a sync changes the keys in the store.
+func (ks *KeyStore) OnChange(fn func(Change)) {
+	ks.notifyMu.Lock()
+	defer ks.notifyMu.Unlock()
+
+	ks.onChange = append(ks.onChange, fn)
+}
+
+func (ks *KeyStore) notify(change Change) {
+	ks.notifyMu.Lock()
+	defer ks.notifyMu.Unlock()
+
+	for _, fn := range ks.onChange {
+		fn(change)
+	}
 }
 
 // PrivateKey searches the key store for a given kid and returns the private key.
@@ -160,6 +200,148 @@ func (ks *KeyStore) PublicKey(kid string) (string, error) {
 	return key.publicPEM, nil
 }
 
+// =============================================================================
+
+// Change represents the kids that were added, updated or removed by a sync.
+type Change struct {
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+// Empty reports whether the sync didn't change any keys.
+func (c Change) Empty() bool {
+	return len(c.Added) == 0 && len(c.Updated) == 0 && len(c.Removed) == 0
+}
+
+func diff(current map[string]key, next map[string]key) Change {
+	var change Change
+
+	for kid, nextKey := range next {
+		curKey, exists := current[kid]
+		switch {
+		case !exists:
+			change.Added = append(change.Added, kid)
+		case curKey.privatePEM != nextKey.privatePEM:
+			change.Updated = append(change.Updated, kid)
+		}
+	}
+
+	for kid := range current {
+		if _, exists := next[kid]; !exists {
+			change.Removed = append(change.Removed, kid)
+		}
+	}
+
+	slices.Sort(change.Added)
+	slices.Sort(change.Updated)
+	slices.Sort(change.Removed)
+
+	return change
+}
+
+// =============================================================================
+
+func toKeys(pems map[string]string) (map[string]key, error) {
+	keys := make(map[string]key, len(pems))
+
+	for kid, privatePEM := range pems {
+		publicPEM, err := toPublicPEM(privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("converting private PEM to public: kid[%s]: %w", kid, err)
+		}
+
+		keys[kid] = key{
+			privatePEM: privatePEM,
+			publicPEM:  publicPEM,
+		}
+	}
+
+	return keys, nil
+}
+
+// parseDocument parses a JSON document with two fields, key and pem (private
+// key).
+func parseDocument(document string) (string, string, error) {
+	var d struct {
+		Key string `json:"key"`
+		PEM string `json:"pem"`
+	}
+	if err := json.Unmarshal([]byte(document), &d); err != nil {
+		return "", "", fmt.Errorf("unable to marshal document: %w", err)
+	}
+
+	if d.Key == "" {
+		return "", "", errors.New("document is missing the key")
+	}
+
+	return d.Key, d.PEM, nil
+}
+
+// readFS reads the PEM files rooted inside of a directory. The name of each
+// file without the extension is used as the key id. Files with the .pem.enc
+// extension are decrypted with the master key.
+func readFS(fsys fs.FS, masterKey []byte) (map[string]string, error) {
+	pems := make(map[string]string)
+
+	fn := func(fileName string, dirEntry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("walkdir failure: %w", err)
+		}
+
+		if dirEntry.IsDir() {
+			return nil
+		}
+
+		name := dirEntry.Name()
+
+		var encrypted bool
+		switch {
+		case strings.HasSuffix(name, encryptedExt):
+			encrypted = true
+		case path.Ext(name) != ".pem":
+			return nil
+		}
+
+		file, err := fsys.Open(fileName)
+		if err != nil {
+			return fmt.Errorf("opening key file: %w", err)
+		}
+		defer file.Close()
+
+		// limit PEM file size to 1 megabyte. This should be reasonable for
+		// almost any PEM file and prevents shenanigans like linking the file
+		// to /dev/random or something like that.
+		data, err := io.ReadAll(io.LimitReader(file, maxPEMFileSize))
+		if err != nil {
+			return fmt.Errorf("reading auth private key: %w", err)
+		}
+
+		if encrypted {
+			if len(masterKey) == 0 {
+				return fmt.Errorf("decrypting %s: %w", fileName, ErrMasterKeyMissing)
+			}
+
+			if data, err = Decrypt(masterKey, data); err != nil {
+				return fmt.Errorf("decrypting %s: %w", fileName, err)
+			}
+
+			pems[strings.TrimSuffix(name, encryptedExt)] = string(data)
+			return nil
+		}
+
+		pems[strings.TrimSuffix(name, ".pem")] = string(data)
+
+		return nil
+	}
+
+	if err := fs.WalkDir(fsys, ".", fn); err != nil {
+		return nil, fmt.Errorf("walking directory: %w", err)
+	}
+
+	return pems, nil
+}
+
 func toPublicPEM(privatePEM string) (string, error) {
 	block, _ := pem.Decode([]byte(privatePEM))
 	if block == nil {
@@ -0,0 +1,260 @@
+package keystore_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/ardanlabs/service/foundation/keystore"
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	kid1 = "54bb2165-71e1-41a6-af3e-7da4a0e1e2c1"
+	kid2 = "7c1b3e1a-0a3f-4a51-9d5e-2a8a8d1a3b61"
+	kid3 = "f2a5e6d0-1b7c-4f3e-8a2d-9c4b5e6f7a80"
+)
+
+func Test_EnvSource(t *testing.T) {
+	pem1 := genPEM(t)
+
+	doc, _ := json.Marshal(map[string]string{"key": kid1, "pem": pem1})
+	t.Setenv("KEYSTORE_TEST_1", string(doc))
+
+	keys, err := keystore.NewEnvSource("KEYSTORE_TEST_").Keys(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to read the keys : %s", err)
+	}
+
+	if diff := cmp.Diff(map[string]string{kid1: pem1}, keys); diff != "" {
+		t.Errorf("Should get the keys from the environment :\n%s", diff)
+	}
+
+	t.Setenv("KEYSTORE_TEST_2", "not json")
+
+	if _, err := keystore.NewEnvSource("KEYSTORE_TEST_").Keys(context.Background()); err == nil {
+		t.Errorf("Should not be able to read an invalid document")
+	}
+}
+
+func Test_FileSource(t *testing.T) {
+	pem1 := genPEM(t)
+	pem2 := genPEM(t)
+
+	masterKey := []byte("master key")
+
+	sealed, err := keystore.Encrypt(masterKey, []byte(pem2))
+	if err != nil {
+		t.Fatalf("Should be able to encrypt the key : %s", err)
+	}
+
+	fsys := fstest.MapFS{
+		kid1 + ".pem":     {Data: []byte(pem1)},
+		kid2 + ".pem.enc": {Data: sealed},
+		"README.md":       {Data: []byte("keys")},
+	}
+
+	keys, err := keystore.NewFileSource(fsys, masterKey).Keys(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to read the keys : %s", err)
+	}
+
+	if diff := cmp.Diff(map[string]string{kid1: pem1, kid2: pem2}, keys); diff != "" {
+		t.Errorf("Should get the plain and encrypted keys :\n%s", diff)
+	}
+
+	if _, err := keystore.NewFileSource(fsys, nil).Keys(context.Background()); !errors.Is(err, keystore.ErrMasterKeyMissing) {
+		t.Errorf("Should get ErrMasterKeyMissing without a master key : got %v", err)
+	}
+
+	if _, err := keystore.NewFileSource(fsys, []byte("wrong")).Keys(context.Background()); err == nil {
+		t.Errorf("Should not be able to decrypt with the wrong master key")
+	}
+}
+
+func Test_VaultSource(t *testing.T) {
+	pem1 := genPEM(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/kv/data/auth/keys", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+
+		resp := map[string]any{
+			"data": map[string]any{
+				"data":     map[string]string{kid1: pem1},
+				"metadata": map[string]any{"version": 3},
+			},
+		}
+
+		json.NewEncoder(w).Encode(resp)
+	})
+
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	src, err := keystore.NewVaultSource(keystore.VaultConfig{
+		Address: srv.URL,
+		Token:   "token",
+		Mount:   "kv",
+		Path:    "auth/keys",
+	})
+	if err != nil {
+		t.Fatalf("Should be able to construct the source : %s", err)
+	}
+
+	keys, err := src.Keys(context.Background())
+	if err != nil {
+		t.Fatalf("Should be able to read the keys : %s", err)
+	}
+
+	if diff := cmp.Diff(map[string]string{kid1: pem1}, keys); diff != "" {
+		t.Errorf("Should get the keys from the secret :\n%s", diff)
+	}
+
+	src, _ = keystore.NewVaultSource(keystore.VaultConfig{
+		Address: srv.URL,
+		Token:   "bad",
+		Mount:   "kv",
+		Path:    "auth/keys",
+	})
+
+	if _, err := src.Keys(context.Background()); err == nil {
+		t.Errorf("Should not be able to read the keys with a bad token")
+	}
+}
+
+func Test_Sync(t *testing.T) {
+	pem1 := genPEM(t)
+	pem2 := genPEM(t)
+	pem3 := genPEM(t)
+
+	src := &fakeSource{keys: map[string]string{kid1: pem1, kid2: pem2}}
+
+	ks := keystore.New()
+
+	var changes []keystore.Change
+	ks.OnChange(func(change keystore.Change) {
+		changes = append(changes, change)
+	})
+
+	n, err := ks.Sync(context.Background(), src)
+	if err != nil {
+		t.Fatalf("Should be able to sync the keys : %s", err)
+	}
+
+	if n != 2 {
+		t.Errorf("Should get 2 keys : got %d", n)
+	}
+
+	if _, err := ks.PublicKey(kid1); err != nil {
+		t.Errorf("Should be able to get the public key : %s", err)
+	}
+
+	// Rotate one key, add one and remove one.
+	src.set(map[string]string{kid1: pem3, kid3: pem2})
+
+	if _, err := ks.Sync(context.Background(), src); err != nil {
+		t.Fatalf("Should be able to sync the keys : %s", err)
+	}
+
+	// A sync without changes doesn't notify.
+	if _, err := ks.Sync(context.Background(), src); err != nil {
+		t.Fatalf("Should be able to sync the keys : %s", err)
+	}
+
+	exp := []keystore.Change{
+		{Added: []string{kid1, kid2}},
+		{Added: []string{kid3}, Updated: []string{kid1}, Removed: []string{kid2}},
+	}
+
+	if diff := cmp.Diff(exp, changes); diff != "" {
+		t.Errorf("Should get the changes :\n%s", diff)
+	}
+
+	if _, err := ks.PrivateKey(kid2); !errors.Is(err, keystore.ErrKeyNotFound) {
+		t.Errorf("Should not find the removed key : got %v", err)
+	}
+
+	privatePEM, _ := ks.PrivateKey(kid1)
+	if privatePEM != pem3 {
+		t.Errorf("Should get the rotated key")
+	}
+
+	// A failing source leaves the keys untouched.
+	src.fail(errors.New("unavailable"))
+
+	if _, err := ks.Sync(context.Background(), src); err == nil {
+		t.Errorf("Should get the source error")
+	}
+
+	if _, err := ks.PrivateKey(kid3); err != nil {
+		t.Errorf("Should keep the keys when a source fails : %s", err)
+	}
+}
+
+// =============================================================================
+
+type fakeSource struct {
+	mu   sync.Mutex
+	keys map[string]string
+	err  error
+}
+
+func (s *fakeSource) set(keys map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+}
+
+func (s *fakeSource) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = err
+}
+
+func (s *fakeSource) Keys(ctx context.Context) (map[string]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.keys, s.err
+}
+
+func (s *fakeSource) String() string {
+	return "fake"
+}
+
+func genPEM(t *testing.T) string {
+	t.Helper()
+
+	pk, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Should be able to generate a key : %s", err)
+	}
+
+	block := pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(pk),
+	}
+
+	var buf bytes.Buffer
+	if err := pem.Encode(&buf, &block); err != nil {
+		t.Fatalf("Should be able to encode the key : %s", err)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,218 @@
+package keystore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Source provides a set of private PEM keys indexed by kid for the KeyStore
+// to sync with.
+type Source interface {
+	Keys(ctx context.Context) (map[string]string, error)
+	String() string
+}
+
+// =============================================================================
+
+// EnvSource provides the keys from the environment variables that start with
+// the prefix. The value of each variable is a JSON document with two fields,
+// key and pem (private key), since a kid is usually not a valid variable name.
+//
+//	SALES_KEY_1={"key":"54bb2165-71e1-41a6-af3e-7da4a0e1e2c1","pem":"-----BEGIN..."}
+type EnvSource struct {
+	prefix string
+}
+
+// NewEnvSource constructs a source for the variables that start with the
+// prefix.
+func NewEnvSource(prefix string) *EnvSource {
+	return &EnvSource{
+		prefix: prefix,
+	}
+}
+
+// Keys implements the Source interface.
+func (s *EnvSource) Keys(ctx context.Context) (map[string]string, error) {
+	pems := make(map[string]string)
+
+	for _, env := range os.Environ() {
+		name, value, _ := strings.Cut(env, "=")
+		if !strings.HasPrefix(name, s.prefix) || value == "" {
+			continue
+		}
+
+		kid, privatePEM, err := parseDocument(value)
+		if err != nil {
+			return nil, fmt.Errorf("variable %s: %w", name, err)
+		}
+
+		pems[kid] = privatePEM
+	}
+
+	return pems, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (s *EnvSource) String() string {
+	return "env:" + s.prefix
+}
+
+// =============================================================================
+
+// DocumentSource provides the key from a single JSON document with two fields,
+// key and pem (private key). An empty document provides no keys.
+type DocumentSource struct {
+	document string
+}
+
+// NewDocumentSource constructs a source for the JSON document.
+func NewDocumentSource(document string) *DocumentSource {
+	return &DocumentSource{
+		document: document,
+	}
+}
+
+// Keys implements the Source interface.
+func (s *DocumentSource) Keys(ctx context.Context) (map[string]string, error) {
+	if s.document == "" {
+		return nil, nil
+	}
+
+	kid, privatePEM, err := parseDocument(s.document)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{kid: privatePEM}, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (s *DocumentSource) String() string {
+	return "document"
+}
+
+// =============================================================================
+
+// FileSource provides the keys from the PEM files rooted inside of a
+// directory. The name of each file without the extension is used as the key
+// id. Files with the .pem.enc extension are decrypted with the master key.
+type FileSource struct {
+	fsys      fs.FS
+	masterKey []byte
+}
+
+// NewFileSource constructs a source for the file system. The master key can
+// be nil when there are no encrypted files.
+func NewFileSource(fsys fs.FS, masterKey []byte) *FileSource {
+	return &FileSource{
+		fsys:      fsys,
+		masterKey: masterKey,
+	}
+}
+
+// Keys implements the Source interface.
+func (s *FileSource) Keys(ctx context.Context) (map[string]string, error) {
+	return readFS(s.fsys, s.masterKey)
+}
+
+// String implements the fmt.Stringer interface.
+func (s *FileSource) String() string {
+	return "files"
+}
+
+// =============================================================================
+
+// VaultConfig represents the settings for reading keys from a Vault KV
+// version 2 secrets engine. Each field of the secret is a kid with the
+// private PEM as the value.
+type VaultConfig struct {
+	Address string
+	Token   string
+	Mount   string
+	Path    string
+	Client  *http.Client
+}
+
+// VaultSource provides the keys stored in a secret of a Vault KV version 2
+// secrets engine, or any server implementing the same API.
+type VaultSource struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewVaultSource constructs a source for the secret. The mount defaults to
+// secret and the client to one with a 10 second timeout.
+func NewVaultSource(cfg VaultConfig) (*VaultSource, error) {
+	if cfg.Address == "" || cfg.Path == "" {
+		return nil, errors.New("vault address and path are required")
+	}
+
+	if cfg.Mount == "" {
+		cfg.Mount = "secret"
+	}
+
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	u, err := url.JoinPath(cfg.Address, "v1", cfg.Mount, "data", cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("vault url: %w", err)
+	}
+
+	s := VaultSource{
+		url:    u,
+		token:  cfg.Token,
+		client: cfg.Client,
+	}
+
+	return &s, nil
+}
+
+// Keys implements the Source interface.
+func (s *VaultSource) Keys(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("new request: %w", err)
+	}
+
+	req.Header.Set("X-Vault-Token", s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("do: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("status[%d]: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPEMFileSize)).Decode(&secret); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	return secret.Data.Data, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (s *VaultSource) String() string {
+	return "vault"
+}
@@ -3,19 +3,22 @@ package logger
 import (
 	"context"
 	"log/slog"
+	"time"
 )
 
 // logHandler provides a wrapper around the slog handler to capture which
 // log level is being logged for event handling.
 type logHandler struct {
-	handler slog.Handler
-	events  Events
+	handler    slog.Handler
+	events     Events
+	dispatcher *dispatcher
 }
 
-func newLogHandler(handler slog.Handler, events Events) *logHandler {
+func newLogHandler(handler slog.Handler, events Events, dispatcher *dispatcher) *logHandler {
 	return &logHandler{
-		handler: handler,
-		events:  events,
+		handler:    handler,
+		events:     events,
+		dispatcher: dispatcher,
 	}
 }
 
@@ -28,14 +31,14 @@ func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
 // WithAttrs returns a new JSONHandler whose attributes consists
 // of h's attributes followed by attrs.
 func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	return &logHandler{handler: h.handler.WithAttrs(attrs), events: h.events}
+	return &logHandler{handler: h.handler.WithAttrs(attrs), events: h.events, dispatcher: h.dispatcher}
 }
 
 // WithGroup returns a new Handler with the given group appended to the receiver's
 // existing groups. The keys of all subsequent attributes, whether added by With
 // or in a Record, should be qualified by the sequence of group names.
 func (h *logHandler) WithGroup(name string) slog.Handler {
-	return &logHandler{handler: h.handler.WithGroup(name), events: h.events}
+	return &logHandler{handler: h.handler.WithGroup(name), events: h.events, dispatcher: h.dispatcher}
 }
 
 // Handle looks to see if an event function needs to be executed for a given
@@ -43,25 +46,37 @@ func (h *logHandler) WithGroup(name string) slog.Handler {
 func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
 	switch r.Level {
 	case slog.LevelDebug:
-		if h.events.Debug != nil {
-			h.events.Debug(ctx, toRecord(r))
-		}
+		h.dispatch(ctx, h.events.Debug, r)
 
 	case slog.LevelError:
-		if h.events.Error != nil {
-			h.events.Error(ctx, toRecord(r))
-		}
+		h.dispatch(ctx, h.events.Error, r)
 
 	case slog.LevelWarn:
-		if h.events.Warn != nil {
-			h.events.Warn(ctx, toRecord(r))
-		}
+		h.dispatch(ctx, h.events.Warn, r)
 
 	case slog.LevelInfo:
-		if h.events.Info != nil {
-			h.events.Info(ctx, toRecord(r))
-		}
+		h.dispatch(ctx, h.events.Info, r)
 	}
 
 	return h.handler.Handle(ctx, r)
 }
+
+// dispatch executes the event function unless the dispatcher suppresses the
+// record. The number of suppressed records is added to the attributes.
+func (h *logHandler) dispatch(ctx context.Context, fn EventFn, r slog.Record) {
+	if fn == nil {
+		return
+	}
+
+	allow, suppressed := h.dispatcher.allow(r, time.Now())
+	if !allow {
+		return
+	}
+
+	rec := toRecord(r)
+	if suppressed > 0 {
+		rec.Attributes["suppressed"] = suppressed
+	}
+
+	fn(ctx, rec)
+}
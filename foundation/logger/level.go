@@ -0,0 +1,289 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ParseLevel converts the name of a level like "DEBUG" or "info" into a level.
+func ParseLevel(name string) (Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("parse level %q: %w", name, err)
+	}
+
+	return Level(l), nil
+}
+
+// ParsePackageLevels converts a set of "package=level" values into a set of
+// level overrides keyed by package.
+func ParsePackageLevels(values []string) (map[string]Level, error) {
+	levels := make(map[string]Level, len(values))
+
+	for _, value := range values {
+		pkg, name, found := strings.Cut(value, "=")
+		if !found || strings.TrimSpace(pkg) == "" {
+			return nil, fmt.Errorf("parse package level %q: expected package=level", value)
+		}
+
+		level, err := ParseLevel(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+
+		levels[strings.TrimSpace(pkg)] = level
+	}
+
+	return levels, nil
+}
+
+// String returns the name of the level.
+func (l Level) String() string {
+	return slog.Level(l).String()
+}
+
+// LevelState represents the current level configuration of a logger.
+type LevelState struct {
+	Level    Level
+	Default  Level
+	RevertAt time.Time
+	Packages map[string]PackageLevel
+}
+
+// PackageLevel represents a level override for a package.
+type PackageLevel struct {
+	Level    Level
+	RevertAt time.Time
+}
+
+// =============================================================================
+
+// override is a package level override along with the timer that will
+// remove it.
+type override struct {
+	level    Level
+	revertAt time.Time
+	timer    *time.Timer
+}
+
+// levels maintains the level that can be changed at runtime. The minimum of
+// the level and the package overrides is given to the slog handler so records
+// for packages with a lower level reach the logger's own check.
+type levels struct {
+	min       slog.LevelVar
+	current   slog.LevelVar
+	overrides atomic.Pointer[map[string]Level]
+	packages  sync.Map
+
+	mu       sync.Mutex
+	def      Level
+	revertAt time.Time
+	timer    *time.Timer
+	pkgs     map[string]override
+}
+
+func newLevels(level Level) *levels {
+	lv := levels{
+		def:  level,
+		pkgs: make(map[string]override),
+	}
+
+	lv.min.Set(slog.Level(level))
+	lv.current.Set(slog.Level(level))
+
+	return &lv
+}
+
+// enabled reports whether a record at the specified level, written from the
+// function at the program counter, should be logged.
+func (lv *levels) enabled(level Level, pc uintptr) bool {
+	overrides := lv.overrides.Load()
+	if overrides == nil || len(*overrides) == 0 {
+		return slog.Level(level) >= lv.current.Level()
+	}
+
+	pkg := lv.packageOf(pc)
+
+	// The longest matching package is the most specific override.
+	var match string
+	for key := range *overrides {
+		if len(key) > len(match) && packageMatch(pkg, key) {
+			match = key
+		}
+	}
+
+	if match != "" {
+		return level >= (*overrides)[match]
+	}
+
+	return slog.Level(level) >= lv.current.Level()
+}
+
+// set changes the level. A level with a duration reverts to the default level
+// once the duration has passed, otherwise it becomes the new default.
+func (lv *levels) set(level Level, revertAfter time.Duration) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	if lv.timer != nil {
+		lv.timer.Stop()
+		lv.timer = nil
+	}
+
+	lv.revertAt = time.Time{}
+	lv.current.Set(slog.Level(level))
+
+	switch {
+	case revertAfter > 0:
+		lv.revertAt = time.Now().Add(revertAfter)
+
+		var timer *time.Timer
+		timer = time.AfterFunc(revertAfter, func() {
+			lv.mu.Lock()
+			defer lv.mu.Unlock()
+
+			// A newer change replaced this timer.
+			if lv.timer != timer {
+				return
+			}
+
+			lv.timer = nil
+			lv.revertAt = time.Time{}
+			lv.current.Set(slog.Level(lv.def))
+			lv.update()
+		})
+		lv.timer = timer
+
+	default:
+		lv.def = level
+	}
+
+	lv.update()
+}
+
+// setPackage overrides the level for a package. A level with a duration is
+// removed once the duration has passed.
+func (lv *levels) setPackage(pkg string, level Level, revertAfter time.Duration) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	if o, exists := lv.pkgs[pkg]; exists && o.timer != nil {
+		o.timer.Stop()
+	}
+
+	o := override{
+		level: level,
+	}
+
+	if revertAfter > 0 {
+		o.revertAt = time.Now().Add(revertAfter)
+
+		var timer *time.Timer
+		timer = time.AfterFunc(revertAfter, func() {
+			lv.mu.Lock()
+			defer lv.mu.Unlock()
+
+			// A newer change replaced this timer.
+			if current, exists := lv.pkgs[pkg]; !exists || current.timer != timer {
+				return
+			}
+
+			delete(lv.pkgs, pkg)
+			lv.update()
+		})
+		o.timer = timer
+	}
+
+	lv.pkgs[pkg] = o
+	lv.update()
+}
+
+// resetPackage removes the level override for a package.
+func (lv *levels) resetPackage(pkg string) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	if o, exists := lv.pkgs[pkg]; exists && o.timer != nil {
+		o.timer.Stop()
+	}
+
+	delete(lv.pkgs, pkg)
+	lv.update()
+}
+
+// state returns the current configuration.
+func (lv *levels) state() LevelState {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+
+	state := LevelState{
+		Level:    Level(lv.current.Level()),
+		Default:  lv.def,
+		RevertAt: lv.revertAt,
+		Packages: make(map[string]PackageLevel, len(lv.pkgs)),
+	}
+
+	for pkg, o := range lv.pkgs {
+		state.Packages[pkg] = PackageLevel{
+			Level:    o.level,
+			RevertAt: o.revertAt,
+		}
+	}
+
+	return state
+}
+
+// update publishes the overrides and recalculates the minimum level. The
+// mutex must be held by the caller.
+func (lv *levels) update() {
+	overrides := make(map[string]Level, len(lv.pkgs))
+	for pkg, o := range lv.pkgs {
+		overrides[pkg] = o.level
+	}
+
+	minLevel := Level(lv.current.Level())
+	for level := range maps.Values(overrides) {
+		minLevel = min(minLevel, level)
+	}
+
+	lv.overrides.Store(&overrides)
+	lv.min.Set(slog.Level(minLevel))
+}
+
+// packageOf returns the import path of the package for the function at the
+// program counter.
+func (lv *levels) packageOf(pc uintptr) string {
+	if pkg, exists := lv.packages.Load(pc); exists {
+		return pkg.(string)
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+
+	pkg := frame.Function
+	lastSlash := strings.LastIndex(pkg, "/")
+	if dot := strings.Index(pkg[lastSlash+1:], "."); dot != -1 {
+		pkg = pkg[:lastSlash+1+dot]
+	}
+
+	lv.packages.Store(pc, pkg)
+
+	return pkg
+}
+
+// packageMatch reports whether the package is identified by the key. A key
+// can be the full import path, the trailing part of the path, or a parent
+// package such as "productbus" which includes its stores.
+func packageMatch(pkg string, key string) bool {
+	key = strings.Trim(key, "/")
+
+	return pkg == key ||
+		strings.HasSuffix(pkg, "/"+key) ||
+		strings.HasPrefix(pkg, key+"/") ||
+		strings.Contains(pkg, "/"+key+"/")
+}
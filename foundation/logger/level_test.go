@@ -0,0 +1,100 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func newLogger(buf *bytes.Buffer) *logger.Logger {
+	return logger.New(buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+}
+
+func Test_SetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(&buf)
+	ctx := context.Background()
+
+	log.Debug(ctx, "hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("Should not log debug at the info level: %s", buf.String())
+	}
+
+	log.SetLevel(logger.LevelDebug, 50*time.Millisecond)
+
+	log.Debug(ctx, "shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Fatalf("Should log debug once the level is changed: %s", buf.String())
+	}
+
+	if state := log.LevelState(); state.Default != logger.LevelInfo || state.RevertAt.IsZero() {
+		t.Errorf("Should keep the default level while the change is temporary: %+v", state)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	buf.Reset()
+	log.Debug(ctx, "reverted")
+	if buf.Len() != 0 {
+		t.Fatalf("Should revert to the default level after the timeout: %s", buf.String())
+	}
+
+	if log.Level() != logger.LevelInfo {
+		t.Errorf("Should report the reverted level, got %s", log.Level())
+	}
+}
+
+func Test_SetPackageLevel(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(&buf)
+	ctx := context.Background()
+
+	log.SetPackageLevel("productbus", logger.LevelDebug, 0)
+
+	log.Debug(ctx, "other package")
+	if buf.Len() != 0 {
+		t.Fatalf("Should not log debug for packages without an override: %s", buf.String())
+	}
+
+	log.SetPackageLevel("logger_test", logger.LevelDebug, 0)
+
+	log.Debug(ctx, "this package")
+	if !strings.Contains(buf.String(), "this package") {
+		t.Fatalf("Should log debug for the package with an override: %s", buf.String())
+	}
+
+	buf.Reset()
+	log.SetPackageLevel("foundation/logger_test", logger.LevelError, 0)
+
+	log.Info(ctx, "quieted")
+	if buf.Len() != 0 {
+		t.Fatalf("Should use the most specific override: %s", buf.String())
+	}
+
+	log.ResetPackageLevel("foundation/logger_test")
+	log.ResetPackageLevel("logger_test")
+
+	log.Info(ctx, "restored")
+	if !strings.Contains(buf.String(), "restored") {
+		t.Fatalf("Should use the default level once the overrides are removed: %s", buf.String())
+	}
+}
+
+func Test_ParsePackageLevels(t *testing.T) {
+	levels, err := logger.ParsePackageLevels([]string{"productbus=debug", "sqldb = WARN"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the package levels: %s", err)
+	}
+
+	if levels["productbus"] != logger.LevelDebug || levels["sqldb"] != logger.LevelWarn {
+		t.Errorf("Should parse each package level, got %v", levels)
+	}
+
+	if _, err := logger.ParsePackageLevels([]string{"productbus"}); err == nil {
+		t.Errorf("Should fail to parse a value without a level")
+	}
+}
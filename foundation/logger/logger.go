@@ -9,6 +9,7 @@ import (
 	"log/slog"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"time"
 )
 
@@ -16,11 +17,26 @@ import (
 // the specified context.
 type TraceIDFn func(ctx context.Context) string
 
+// ContextFn represents a function that can return a value from the
+// specified context to be added to the log.
+type ContextFn func(ctx context.Context) string
+
+// contextValue binds a key to the function that provides its value.
+type contextValue struct {
+	key string
+	fn  ContextFn
+}
+
 // Logger represents a logger for logging information.
 type Logger struct {
-	discard   bool
-	handler   slog.Handler
-	traceIDFn TraceIDFn
+	discard    bool
+	handler    slog.Handler
+	traceIDFn  TraceIDFn
+	ctxValues  []contextValue
+	levels     *levels
+	sampler    *sampler
+	dispatcher *dispatcher
+	handlers   *handlers
 }
 
 // New constructs a new log for application use.
@@ -34,9 +50,9 @@ func NewWithEvents(w io.Writer, minLevel Level, serviceName string, traceIDFn Tr
 }
 
 // NewWithHandler returns a new log for application use with the underlying
-// handler.
+// handler. Sensitive attributes are redacted before reaching the handler.
 func NewWithHandler(h slog.Handler) *Logger {
-	return &Logger{handler: h}
+	return &Logger{handler: newRedactHandler(h)}
 }
 
 // NewStdLogger returns a standard library Logger that wraps the slog Logger.
@@ -44,6 +60,95 @@ func NewStdLogger(logger *Logger, level Level) *log.Logger {
 	return slog.NewLogLogger(logger.handler, slog.Level(level))
 }
 
+// WithContextValue returns a new logger that adds the value returned by the
+// function under the specified key to every log. Empty values are not logged.
+func (log *Logger) WithContextValue(key string, fn ContextFn) *Logger {
+	l := *log
+	l.ctxValues = append(slices.Clone(log.ctxValues), contextValue{key: key, fn: fn})
+
+	return &l
+}
+
+// Level returns the current minimum level being logged.
+func (log *Logger) Level() Level {
+	if log.levels == nil {
+		return LevelDebug
+	}
+
+	return Level(log.levels.current.Level())
+}
+
+// SetLevel changes the minimum level being logged. When revertAfter is
+// provided the level reverts to the previous default once it has passed,
+// otherwise the level becomes the new default.
+func (log *Logger) SetLevel(level Level, revertAfter time.Duration) {
+	if log.levels == nil {
+		return
+	}
+
+	log.levels.set(level, revertAfter)
+}
+
+// SetPackageLevel overrides the minimum level for logs written from the
+// specified package. The package can be the import path or a trailing part
+// of it such as "productbus", which includes the packages below it. When
+// revertAfter is provided the override is removed once it has passed.
+func (log *Logger) SetPackageLevel(pkg string, level Level, revertAfter time.Duration) {
+	if log.levels == nil {
+		return
+	}
+
+	log.levels.setPackage(pkg, level, revertAfter)
+}
+
+// ResetPackageLevel removes the level override for the specified package.
+func (log *Logger) ResetPackageLevel(pkg string) {
+	if log.levels == nil {
+		return
+	}
+
+	log.levels.resetPackage(pkg)
+}
+
+// LevelState returns the current level configuration.
+func (log *Logger) LevelState() LevelState {
+	if log.levels == nil {
+		return LevelState{Level: LevelDebug, Default: LevelDebug}
+	}
+
+	return log.levels.state()
+}
+
+// SetSampling changes how the records for the specified level are sampled.
+// A sampling without an interval turns sampling off for the level.
+func (log *Logger) SetSampling(level Level, sampling Sampling) {
+	if log.sampler == nil {
+		return
+	}
+
+	log.sampler.set(level, sampling)
+}
+
+// SetEventLimit changes how often the event function for the specified level
+// is executed. A limit without an interval or max removes the limit.
+func (log *Logger) SetEventLimit(level Level, limit EventLimit) {
+	if log.dispatcher == nil {
+		return
+	}
+
+	log.dispatcher.set(level, limit)
+}
+
+// AddHandler adds a handler that receives every record written to the log
+// after sampling, such as a handler exporting the records to a collector.
+func (log *Logger) AddHandler(h slog.Handler) {
+	if log.handlers == nil {
+		return
+	}
+
+	log.handlers.add(h)
+}
+
 // Debug logs at LevelDebug with the given context.
 func (log *Logger) Debug(ctx context.Context, msg string, args ...any) {
 	if log.discard {
@@ -126,11 +231,21 @@ func (log *Logger) write(ctx context.Context, level Level, caller int, msg strin
 	var pcs [1]uintptr
 	runtime.Callers(caller, pcs[:])
 
+	if log.levels != nil && !log.levels.enabled(level, pcs[0]) {
+		return
+	}
+
 	r := slog.NewRecord(time.Now(), slogLevel, msg, pcs[0])
 
 	if log.traceIDFn != nil {
 		args = append(args, "trace_id", log.traceIDFn(ctx))
 	}
+
+	for _, cv := range log.ctxValues {
+		if v := cv.fn(ctx); v != "" {
+			args = append(args, cv.key, v)
+		}
+	}
 	r.Add(args...)
 
 	log.handler.Handle(ctx, r)
@@ -151,15 +266,32 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 		return a
 	}
 
+	// The level can be changed at runtime.
+	levels := newLevels(minLevel)
+
 	// Construct the slog JSON handler for use.
-	handler := slog.Handler(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: slog.Level(minLevel), ReplaceAttr: f}))
+	handler := slog.Handler(slog.NewJSONHandler(w, &slog.HandlerOptions{AddSource: true, Level: &levels.min, ReplaceAttr: f}))
+
+	// Additional handlers can be added at runtime.
+	handlers := &handlers{}
+	handler = newTeeHandler(handler, handlers)
+
+	// Records are sampled before they are written, which doesn't affect the
+	// event functions.
+	sampler := newSampler()
+	handler = newSamplingHandler(handler, sampler)
 
 	// If events are to be processed, wrap the JSON handler around the custom
 	// log handler.
+	dispatcher := newDispatcher()
 	if events.Debug != nil || events.Info != nil || events.Warn != nil || events.Error != nil {
-		handler = newLogHandler(handler, events)
+		handler = newLogHandler(handler, events, dispatcher)
 	}
 
+	// Sensitive attributes are redacted before they reach the JSON handler
+	// or any of the event functions.
+	handler = newRedactHandler(handler)
+
 	// Attributes to add to every log.
 	attrs := []slog.Attr{
 		{Key: "service", Value: slog.StringValue(serviceName)},
@@ -169,8 +301,12 @@ func new(w io.Writer, minLevel Level, serviceName string, traceIDFn TraceIDFn, e
 	handler = handler.WithAttrs(attrs)
 
 	return &Logger{
-		discard:   w == io.Discard,
-		handler:   handler,
-		traceIDFn: traceIDFn,
+		discard:    w == io.Discard,
+		handler:    handler,
+		traceIDFn:  traceIDFn,
+		levels:     levels,
+		sampler:    sampler,
+		dispatcher: dispatcher,
+		handlers:   handlers,
 	}
 }
@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Redacted is written in place of a sensitive value.
+const Redacted = "[REDACTED]"
+
+// Sensitive is implemented by types whose values must never be written to a
+// log or any other sink such as a span or an audit record.
+type Sensitive interface {
+	Sensitive() bool
+}
+
+var sensitive = struct {
+	mu        sync.RWMutex
+	fragments []string
+	keys      []string
+}{
+	fragments: []string{"password", "passwd", "secret", "token", "authorization", "apikey", "cookie", "privatekey"},
+	keys:      []string{"email"},
+}
+
+// AddSensitiveKeys adds to the set of keys whose values are redacted. A key
+// matches regardless of case, underscores or dashes.
+func AddSensitiveKeys(keys ...string) {
+	sensitive.mu.Lock()
+	defer sensitive.mu.Unlock()
+
+	for _, key := range keys {
+		sensitive.keys = append(sensitive.keys, normalizeKey(key))
+	}
+}
+
+// IsSensitiveKey reports whether values with the specified key are redacted.
+// Keys containing words like password, secret or token are always sensitive.
+func IsSensitiveKey(key string) bool {
+	key = normalizeKey(key)
+
+	sensitive.mu.RLock()
+	defer sensitive.mu.RUnlock()
+
+	if slices.Contains(sensitive.keys, key) {
+		return true
+	}
+
+	for _, fragment := range sensitive.fragments {
+		if strings.Contains(key, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsSensitiveValue reports whether the value marks itself as sensitive.
+func IsSensitiveValue(v any) bool {
+	s, ok := v.(Sensitive)
+	return ok && s.Sensitive()
+}
+
+// Redact returns the value or the redacted marker if the key or the value
+// is sensitive.
+func Redact(key string, v any) any {
+	if IsSensitiveKey(key) || IsSensitiveValue(v) {
+		return Redacted
+	}
+
+	return v
+}
+
+// RedactJSON replaces the values of sensitive keys found at any level of the
+// JSON document.
+func RedactJSON(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(redactJSONValue(v))
+}
+
+func redactJSONValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			switch {
+			case value != nil && IsSensitiveKey(key):
+				v[key] = Redacted
+
+			default:
+				v[key] = redactJSONValue(value)
+			}
+		}
+		return v
+
+	case []any:
+		for i, value := range v {
+			v[i] = redactJSONValue(value)
+		}
+		return v
+	}
+
+	return v
+}
+
+var keyReplacer = strings.NewReplacer("_", "", "-", "", ".", "")
+
+func normalizeKey(key string) string {
+	return keyReplacer.Replace(strings.ToLower(key))
+}
+
+// =============================================================================
+
+// redactHandler replaces sensitive attributes before the record reaches the
+// handlers and event functions it wraps.
+type redactHandler struct {
+	handler slog.Handler
+}
+
+func newRedactHandler(handler slog.Handler) *redactHandler {
+	return &redactHandler{
+		handler: handler,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new handler with the redacted attributes.
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+
+	return &redactHandler{handler: h.handler.WithAttrs(redacted)}
+}
+
+// WithGroup returns a new handler with the given group appended.
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{handler: h.handler.WithGroup(name)}
+}
+
+// Handle redacts the attributes of the record and passes it along.
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	nr := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+
+	f := func(attr slog.Attr) bool {
+		nr.AddAttrs(redactAttr(attr))
+		return true
+	}
+	r.Attrs(f)
+
+	return h.handler.Handle(ctx, nr)
+}
+
+func redactAttr(attr slog.Attr) slog.Attr {
+	if IsSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	// Check the value before and after resolving a slog.LogValuer so a
+	// sensitive type can't hide behind one.
+	value := attr.Value
+	if value.Kind() == slog.KindAny && IsSensitiveValue(value.Any()) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	value = value.Resolve()
+
+	switch value.Kind() {
+	case slog.KindAny:
+		if IsSensitiveValue(value.Any()) {
+			return slog.String(attr.Key, Redacted)
+		}
+
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = redactAttr(ga)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	}
+
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,99 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+const secret = "gophers-secret"
+
+type secretValue struct {
+	value string
+}
+
+func (s secretValue) Sensitive() bool { return true }
+
+func (s secretValue) MarshalText() ([]byte, error) { return []byte(s.value), nil }
+
+func Test_Redact(t *testing.T) {
+	var buf bytes.Buffer
+	var events []string
+
+	evts := logger.Events{
+		Info: func(ctx context.Context, r logger.Record) {
+			events = append(events, fmt.Sprint(r.Attributes))
+		},
+	}
+
+	log := logger.NewWithEvents(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" }, evts)
+
+	tests := []struct {
+		name string
+		args []any
+	}{
+		{"key", []any{"password", secret}},
+		{"key-case", []any{"Password_Hash", []byte(secret)}},
+		{"key-auth", []any{"authorization", "Bearer " + secret}},
+		{"value", []any{"value", secretValue{secret}}},
+		{"group", []any{slog.Group("user", "name", "bill", "password", secret)}},
+		{"group-value", []any{slog.Group("user", "name", "bill", "pass", secretValue{secret})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			events = nil
+
+			log.Info(context.Background(), "redact", tt.args...)
+
+			if strings.Contains(buf.String(), secret) {
+				t.Errorf("Should not write the sensitive value to the log: %s", buf.String())
+			}
+
+			if !strings.Contains(buf.String(), logger.Redacted) {
+				t.Errorf("Should mark the value as redacted: %s", buf.String())
+			}
+
+			for _, event := range events {
+				if strings.Contains(event, secret) {
+					t.Errorf("Should not pass the sensitive value to events: %s", event)
+				}
+			}
+		})
+	}
+}
+
+func Test_RedactWithHandler(t *testing.T) {
+	var buf bytes.Buffer
+
+	log := logger.NewWithHandler(slog.NewJSONHandler(&buf, nil))
+	log.Info(context.Background(), "redact", "secret", secret, "value", secretValue{secret})
+
+	if strings.Contains(buf.String(), secret) {
+		t.Errorf("Should not write the sensitive value to a custom handler: %s", buf.String())
+	}
+}
+
+func Test_RedactJSON(t *testing.T) {
+	data := fmt.Appendf(nil, `{"Name":"bill","Password":%q,"Roles":[{"token":%q}],"Cost":10.5}`, secret, secret)
+
+	redacted, err := logger.RedactJSON(data)
+	if err != nil {
+		t.Fatalf("Should be able to redact the document: %s", err)
+	}
+
+	if strings.Contains(string(redacted), secret) {
+		t.Errorf("Should not keep the sensitive value in the document: %s", redacted)
+	}
+
+	exp := `{"Cost":10.5,"Name":"bill","Password":"[REDACTED]","Roles":[{"token":"[REDACTED]"}]}`
+	if string(redacted) != exp {
+		t.Errorf("Should only redact sensitive keys:\ngot: %s\nexp: %s", redacted, exp)
+	}
+}
@@ -0,0 +1,368 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sampling defines how the records for a level are sampled. Within every
+// interval the first records with the same message are logged, then only
+// one in every Thereafter. A Thereafter of zero drops the rest.
+type Sampling struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+// FingerprintKey is the attribute a caller can set to decide which records
+// are duplicates of each other for the event limits.
+const FingerprintKey = "fingerprint"
+
+// EventLimit defines how often the event function for a level is executed.
+// Within every interval a fingerprint is only dispatched once and no more
+// than Max records are dispatched. The fingerprint of a record is its
+// message plus the FingerprintKey attribute or, without one, its error. The
+// number of records that were suppressed for a fingerprint is added to its
+// next dispatched record.
+type EventLimit struct {
+	Interval time.Duration
+	Max      int
+}
+
+// ParseSampling converts a set of "level=first/thereafter/interval" values
+// like "INFO=100/10/1s" into a set of sampling settings keyed by level.
+func ParseSampling(values []string) (map[Level]Sampling, error) {
+	settings := make(map[Level]Sampling, len(values))
+
+	for _, value := range values {
+		level, fields, err := parseLevelFields(value, 3)
+		if err != nil {
+			return nil, fmt.Errorf("parse sampling %q: expected level=first/thereafter/interval: %w", value, err)
+		}
+
+		first, err1 := strconv.Atoi(fields[0])
+		thereafter, err2 := strconv.Atoi(fields[1])
+		interval, err3 := time.ParseDuration(fields[2])
+		if err1 != nil || err2 != nil || err3 != nil || first < 0 || thereafter < 0 || interval <= 0 {
+			return nil, fmt.Errorf("parse sampling %q: expected level=first/thereafter/interval", value)
+		}
+
+		settings[level] = Sampling{Interval: interval, First: first, Thereafter: thereafter}
+	}
+
+	return settings, nil
+}
+
+// ParseEventLimits converts a set of "level=max/interval" values like
+// "ERROR=10/1m" into a set of event limits keyed by level.
+func ParseEventLimits(values []string) (map[Level]EventLimit, error) {
+	limits := make(map[Level]EventLimit, len(values))
+
+	for _, value := range values {
+		level, fields, err := parseLevelFields(value, 2)
+		if err != nil {
+			return nil, fmt.Errorf("parse event limit %q: expected level=max/interval: %w", value, err)
+		}
+
+		max, err1 := strconv.Atoi(fields[0])
+		interval, err2 := time.ParseDuration(fields[1])
+		if err1 != nil || err2 != nil || max <= 0 || interval <= 0 {
+			return nil, fmt.Errorf("parse event limit %q: expected level=max/interval", value)
+		}
+
+		limits[level] = EventLimit{Interval: interval, Max: max}
+	}
+
+	return limits, nil
+}
+
+func parseLevelFields(value string, n int) (Level, []string, error) {
+	name, rest, found := strings.Cut(value, "=")
+	if !found {
+		return 0, nil, fmt.Errorf("missing level")
+	}
+
+	level, err := ParseLevel(strings.TrimSpace(name))
+	if err != nil {
+		return 0, nil, err
+	}
+
+	fields := strings.Split(rest, "/")
+	if len(fields) != n {
+		return 0, nil, fmt.Errorf("expected %d fields, got %d", n, len(fields))
+	}
+
+	for i := range fields {
+		fields[i] = strings.TrimSpace(fields[i])
+	}
+
+	return level, fields, nil
+}
+
+// =============================================================================
+
+// window counts the records seen for each message since it started.
+type window struct {
+	start  time.Time
+	counts map[string]int
+}
+
+// sampler decides which records are written based on the sampling settings
+// for their level. The settings can be changed at runtime.
+type sampler struct {
+	settings atomic.Pointer[map[slog.Level]Sampling]
+
+	mu      sync.Mutex
+	windows map[slog.Level]*window
+}
+
+func newSampler() *sampler {
+	return &sampler{
+		windows: make(map[slog.Level]*window),
+	}
+}
+
+func (s *sampler) set(level Level, sampling Sampling) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := make(map[slog.Level]Sampling)
+	if current := s.settings.Load(); current != nil {
+		settings = maps.Clone(*current)
+	}
+
+	switch {
+	case sampling.Interval <= 0:
+		delete(settings, slog.Level(level))
+
+	default:
+		settings[slog.Level(level)] = sampling
+	}
+
+	delete(s.windows, slog.Level(level))
+	s.settings.Store(&settings)
+}
+
+func (s *sampler) sample(level slog.Level, msg string, now time.Time) bool {
+	settings := s.settings.Load()
+	if settings == nil {
+		return true
+	}
+
+	sampling, exists := (*settings)[level]
+	if !exists {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.windows[level]
+	if w == nil || now.Sub(w.start) >= sampling.Interval {
+		w = &window{start: now, counts: make(map[string]int)}
+		s.windows[level] = w
+	}
+
+	w.counts[msg]++
+	n := w.counts[msg]
+
+	switch {
+	case n <= sampling.First:
+		return true
+
+	case sampling.Thereafter <= 0:
+		return false
+	}
+
+	return (n-sampling.First)%sampling.Thereafter == 0
+}
+
+// samplingHandler drops the records the sampler doesn't select before they
+// reach the handler it wraps.
+type samplingHandler struct {
+	handler slog.Handler
+	sampler *sampler
+}
+
+func newSamplingHandler(handler slog.Handler, sampler *sampler) *samplingHandler {
+	return &samplingHandler{
+		handler: handler,
+		sampler: sampler,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new handler with the given attributes appended.
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{handler: h.handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+// WithGroup returns a new handler with the given group appended.
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{handler: h.handler.WithGroup(name), sampler: h.sampler}
+}
+
+// Handle passes the record along if it is selected by the sampler.
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.sample(r.Level, r.Message, time.Now()) {
+		return nil
+	}
+
+	return h.handler.Handle(ctx, r)
+}
+
+// =============================================================================
+
+// maxSuppressed is the number of fingerprints the suppressed records are
+// counted for on each level. Records for other fingerprints are dropped
+// without being counted.
+const maxSuppressed = 1024
+
+// sweepIntervals is the number of intervals a fingerprint is kept after its
+// last suppressed record, so the counts for fingerprints that are never
+// dispatched again are freed.
+const sweepIntervals = 10
+
+// eventWindow tracks the records dispatched since it started.
+type eventWindow struct {
+	start      time.Time
+	sent       int
+	dispatched map[string]bool
+}
+
+// suppression counts the records suppressed for a fingerprint.
+type suppression struct {
+	count int
+	last  time.Time
+}
+
+// dispatcher deduplicates and limits the records given to the event
+// functions based on the limits for their level. The limits can be changed
+// at runtime.
+type dispatcher struct {
+	limits atomic.Pointer[map[slog.Level]EventLimit]
+
+	mu         sync.Mutex
+	windows    map[slog.Level]*eventWindow
+	suppressed map[slog.Level]map[string]suppression
+}
+
+func newDispatcher() *dispatcher {
+	return &dispatcher{
+		windows:    make(map[slog.Level]*eventWindow),
+		suppressed: make(map[slog.Level]map[string]suppression),
+	}
+}
+
+func (d *dispatcher) set(level Level, limit EventLimit) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	limits := make(map[slog.Level]EventLimit)
+	if current := d.limits.Load(); current != nil {
+		limits = maps.Clone(*current)
+	}
+
+	switch {
+	case limit.Interval <= 0 || limit.Max <= 0:
+		delete(limits, slog.Level(level))
+
+	default:
+		limits[slog.Level(level)] = limit
+	}
+
+	delete(d.windows, slog.Level(level))
+	delete(d.suppressed, slog.Level(level))
+	d.limits.Store(&limits)
+}
+
+// allow reports whether the record should be dispatched and how many records
+// with the same fingerprint were suppressed since it was last dispatched.
+func (d *dispatcher) allow(r slog.Record, now time.Time) (bool, int) {
+	limits := d.limits.Load()
+	if limits == nil {
+		return true, 0
+	}
+
+	limit, exists := (*limits)[r.Level]
+	if !exists {
+		return true, 0
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	suppressed := d.suppressed[r.Level]
+	if suppressed == nil {
+		suppressed = make(map[string]suppression)
+		d.suppressed[r.Level] = suppressed
+	}
+
+	w := d.windows[r.Level]
+	if w == nil || now.Sub(w.start) >= limit.Interval {
+		w = &eventWindow{start: now, dispatched: make(map[string]bool)}
+		d.windows[r.Level] = w
+
+		maps.DeleteFunc(suppressed, func(_ string, s suppression) bool {
+			return now.Sub(s.last) >= sweepIntervals*limit.Interval
+		})
+	}
+
+	fp := fingerprint(r)
+
+	if w.dispatched[fp] || w.sent >= limit.Max {
+		s, exists := suppressed[fp]
+		if exists || len(suppressed) < maxSuppressed {
+			suppressed[fp] = suppression{count: s.count + 1, last: now}
+		}
+		return false, 0
+	}
+
+	w.dispatched[fp] = true
+	w.sent++
+
+	n := suppressed[fp].count
+	delete(suppressed, fp)
+
+	return true, n
+}
+
+// fingerprint identifies the records that are duplicates of each other by
+// their message plus the fingerprint the caller provided or their error.
+func fingerprint(r slog.Record) string {
+	var fp, errMsg string
+
+	r.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == FingerprintKey {
+			fp = attr.Value.String()
+			return false
+		}
+
+		if err, ok := attr.Value.Any().(error); ok && errMsg == "" {
+			errMsg = err.Error()
+		}
+
+		return true
+	})
+
+	switch {
+	case fp != "":
+		return r.Message + "\x00" + fp
+
+	case errMsg != "":
+		return r.Message + "\x00" + errMsg
+	}
+
+	return r.Message
+}
@@ -0,0 +1,174 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+)
+
+func Test_Sampling(t *testing.T) {
+	var buf bytes.Buffer
+	log := newLogger(&buf)
+	ctx := context.Background()
+
+	log.SetSampling(logger.LevelInfo, logger.Sampling{Interval: time.Hour, First: 3, Thereafter: 5})
+
+	for range 20 {
+		log.Info(ctx, "request")
+	}
+	log.Info(ctx, "other")
+	log.Warn(ctx, "warning")
+
+	// The first 3, then the 8th, 13th and 18th.
+	if n := strings.Count(buf.String(), `"msg":"request"`); n != 6 {
+		t.Errorf("Should sample the repeated message: got %d, exp 6", n)
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"other"`) {
+		t.Errorf("Should count each message separately: %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `"msg":"warning"`) {
+		t.Errorf("Should not sample other levels: %s", buf.String())
+	}
+
+	log.SetSampling(logger.LevelInfo, logger.Sampling{})
+
+	buf.Reset()
+	for range 5 {
+		log.Info(ctx, "request")
+	}
+
+	if n := strings.Count(buf.String(), `"msg":"request"`); n != 5 {
+		t.Errorf("Should stop sampling once turned off: got %d, exp 5", n)
+	}
+}
+
+func Test_EventLimit(t *testing.T) {
+	var records []logger.Record
+
+	events := logger.Events{
+		Error: func(ctx context.Context, r logger.Record) {
+			records = append(records, r)
+		},
+	}
+
+	var buf bytes.Buffer
+	log := logger.NewWithEvents(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" }, events)
+	ctx := context.Background()
+
+	log.SetEventLimit(logger.LevelError, logger.EventLimit{Interval: 50 * time.Millisecond, Max: 2})
+
+	for range 5 {
+		log.Error(ctx, "database down")
+	}
+	log.Error(ctx, "disk full")
+	log.Error(ctx, "cache down")
+
+	if len(records) != 2 {
+		t.Fatalf("Should dispatch each message once up to the max: got %d, exp 2", len(records))
+	}
+
+	if n := strings.Count(buf.String(), `"level":"ERROR"`); n != 7 {
+		t.Errorf("Should still log every record: got %d, exp 7", n)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	log.Error(ctx, "database down")
+
+	if len(records) != 3 {
+		t.Fatalf("Should dispatch again in the next interval: got %d, exp 3", len(records))
+	}
+
+	if got := records[2].Attributes["suppressed"]; got != 4 {
+		t.Errorf("Should report the suppressed records: got %v, exp 4", got)
+	}
+}
+
+func Test_EventLimitFingerprint(t *testing.T) {
+	var records []logger.Record
+
+	events := logger.Events{
+		Error: func(ctx context.Context, r logger.Record) {
+			records = append(records, r)
+		},
+	}
+
+	log := logger.NewWithEvents(&bytes.Buffer{}, logger.LevelInfo, "TEST", func(context.Context) string { return "" }, events)
+	ctx := context.Background()
+
+	log.SetEventLimit(logger.LevelError, logger.EventLimit{Interval: time.Minute, Max: 10})
+
+	log.Error(ctx, "query failed", "ERROR", errors.New("connection refused"))
+	log.Error(ctx, "query failed", "ERROR", errors.New("connection refused"))
+	log.Error(ctx, "query failed", "ERROR", errors.New("deadlock detected"))
+
+	if len(records) != 2 {
+		t.Fatalf("Should dispatch each error for the same message: got %d, exp 2", len(records))
+	}
+
+	log.Error(ctx, "query failed", logger.FingerprintKey, "orders", "ERROR", errors.New("timeout 1"))
+	log.Error(ctx, "query failed", logger.FingerprintKey, "orders", "ERROR", errors.New("timeout 2"))
+	log.Error(ctx, "query failed", logger.FingerprintKey, "users", "ERROR", errors.New("timeout 3"))
+
+	if len(records) != 4 {
+		t.Fatalf("Should dedup on the fingerprint instead of the error: got %d, exp 4", len(records))
+	}
+}
+
+func Test_EventLimitSweep(t *testing.T) {
+	var records []logger.Record
+
+	events := logger.Events{
+		Error: func(ctx context.Context, r logger.Record) {
+			records = append(records, r)
+		},
+	}
+
+	log := logger.NewWithEvents(&bytes.Buffer{}, logger.LevelInfo, "TEST", func(context.Context) string { return "" }, events)
+	ctx := context.Background()
+
+	log.SetEventLimit(logger.LevelError, logger.EventLimit{Interval: 5 * time.Millisecond, Max: 1})
+
+	log.Error(ctx, "database down")
+	log.Error(ctx, "database down")
+
+	// The suppressed count is freed once the message hasn't been seen for
+	// more than ten intervals.
+	time.Sleep(60 * time.Millisecond)
+
+	log.Error(ctx, "disk full")
+	log.Error(ctx, "database down")
+	time.Sleep(10 * time.Millisecond)
+	log.Error(ctx, "database down")
+
+	if len(records) != 3 {
+		t.Fatalf("Should dispatch a record in each window: got %d, exp 3", len(records))
+	}
+
+	if got := records[2].Attributes["suppressed"]; got != 1 {
+		t.Errorf("Should only report the records suppressed since the sweep: got %v, exp 1", got)
+	}
+}
+
+func Test_ParseSampling(t *testing.T) {
+	settings, err := logger.ParseSampling([]string{"INFO=100/10/1s"})
+	if err != nil {
+		t.Fatalf("Should be able to parse the sampling: %s", err)
+	}
+
+	exp := logger.Sampling{Interval: time.Second, First: 100, Thereafter: 10}
+	if settings[logger.LevelInfo] != exp {
+		t.Errorf("Should parse the sampling: got %+v, exp %+v", settings[logger.LevelInfo], exp)
+	}
+
+	if _, err := logger.ParseEventLimits([]string{"ERROR=10"}); err == nil {
+		t.Errorf("Should not parse an event limit without an interval")
+	}
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// handlers holds the additional handlers that receive every record written
+// to the log. Handlers can be added after the logger is constructed.
+type handlers struct {
+	mu   sync.Mutex
+	list atomic.Pointer[[]slog.Handler]
+}
+
+func (hs *handlers) add(h slog.Handler) {
+	hs.mu.Lock()
+	defer hs.mu.Unlock()
+
+	var list []slog.Handler
+	if current := hs.list.Load(); current != nil {
+		list = slices.Clone(*current)
+	}
+	list = append(list, h)
+
+	hs.list.Store(&list)
+}
+
+// teeHandler passes each record to the handler it wraps and then to the
+// additional handlers. The attributes and groups given to the tee are applied
+// to the additional handlers when a record is handled since those handlers
+// can be added later.
+type teeHandler struct {
+	handler  slog.Handler
+	handlers *handlers
+	with     []func(slog.Handler) slog.Handler
+}
+
+func newTeeHandler(handler slog.Handler, handlers *handlers) *teeHandler {
+	return &teeHandler{
+		handler:  handler,
+		handlers: handlers,
+	}
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// WithAttrs returns a new handler with the given attributes appended.
+func (h *teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	f := func(handler slog.Handler) slog.Handler {
+		return handler.WithAttrs(attrs)
+	}
+
+	return &teeHandler{
+		handler:  h.handler.WithAttrs(attrs),
+		handlers: h.handlers,
+		with:     append(slices.Clip(h.with), f),
+	}
+}
+
+// WithGroup returns a new handler with the given group appended.
+func (h *teeHandler) WithGroup(name string) slog.Handler {
+	f := func(handler slog.Handler) slog.Handler {
+		return handler.WithGroup(name)
+	}
+
+	return &teeHandler{
+		handler:  h.handler.WithGroup(name),
+		handlers: h.handlers,
+		with:     append(slices.Clip(h.with), f),
+	}
+}
+
+// Handle passes the record to every handler.
+func (h *teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	err := h.handler.Handle(ctx, r)
+
+	list := h.handlers.list.Load()
+	if list == nil {
+		return err
+	}
+
+	errs := []error{err}
+	for _, handler := range *list {
+		for _, f := range h.with {
+			handler = f(handler)
+		}
+
+		if handler.Enabled(ctx, r.Level) {
+			errs = append(errs, handler.Handle(ctx, r.Clone()))
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,88 @@
+package otel
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// splitHost returns the host and port of an exporter's host, which may start
+// with the scheme, and whether the connection is made without TLS.
+func splitHost(cfg Config, host string) (string, bool) {
+	switch {
+	case strings.HasPrefix(host, "http://"):
+		return strings.TrimSuffix(strings.TrimPrefix(host, "http://"), "/"), true
+
+	case strings.HasPrefix(host, "https://"):
+		return strings.TrimSuffix(strings.TrimPrefix(host, "https://"), "/"), false
+	}
+
+	return host, cfg.Insecure
+}
+
+// tlsConfig constructs the TLS configuration for the CA and client
+// certificate files that are configured.
+func tlsConfig(cfg Config) (*tls.Config, error) {
+	tc := tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("parsing ca file %q: no certificates found", cfg.CAFile)
+		}
+		tc.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+
+	return &tc, nil
+}
+
+// resourceAttributes returns the attributes describing the service that are
+// added to every span, log and metric.
+func resourceAttributes(cfg Config) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.ServiceNameKey.String(cfg.ServiceName),
+	}
+
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersionKey.String(cfg.ServiceVersion))
+	}
+
+	if cfg.Environment != "" {
+		attrs = append(attrs, semconv.DeploymentEnvironmentNameKey.String(cfg.Environment))
+	}
+
+	if host, err := os.Hostname(); err == nil {
+		attrs = append(attrs, semconv.HostNameKey.String(host))
+	}
+
+	for k, v := range cfg.Attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+
+	return attrs
+}
+
+func newResource(cfg Config) *resource.Resource {
+	return resource.NewWithAttributes(semconv.SchemaURL, resourceAttributes(cfg)...)
+}
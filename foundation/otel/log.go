@@ -0,0 +1,210 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"google.golang.org/grpc/credentials"
+)
+
+const logScope = "github.com/ardanlabs/service/foundation/logger"
+
+// InitLogging constructs a slog handler that exports the records it receives
+// to the collector at the configured log host using OTLP over the configured
+// protocol. The trace and span
+// ids found in the context are added to each record so logs can be
+// correlated with traces. Without a log host the handler discards every
+// record.
+func InitLogging(cfg Config) (slog.Handler, func(ctx context.Context), error) {
+	if cfg.LogHost == "" {
+		return slog.DiscardHandler, func(ctx context.Context) {}, nil
+	}
+
+	exporter, err := newLogExporter(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating log exporter: %w", err)
+	}
+
+	lp := sdklog.NewLoggerProvider(
+		sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithResource(newResource(cfg)),
+	)
+
+	teardown := func(ctx context.Context) {
+		lp.Shutdown(ctx)
+	}
+
+	return &logHandler{logger: lp.Logger(logScope)}, teardown, nil
+}
+
+// newLogExporter constructs the exporter that sends the log records to the
+// log host using the configured protocol.
+func newLogExporter(cfg Config) (sdklog.Exporter, error) {
+	endpoint, insecure := splitHost(cfg, cfg.LogHost)
+
+	switch cfg.Protocol {
+	case "", ProtocolGRPC:
+		opts := []otlploggrpc.Option{
+			otlploggrpc.WithEndpoint(endpoint),
+			otlploggrpc.WithHeaders(cfg.Headers),
+		}
+
+		switch {
+		case insecure:
+			opts = append(opts, otlploggrpc.WithInsecure())
+
+		default:
+			tc, err := tlsConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tc)))
+		}
+
+		return otlploggrpc.New(context.Background(), opts...)
+
+	case ProtocolHTTP:
+		opts := []otlploghttp.Option{
+			otlploghttp.WithEndpoint(endpoint),
+			otlploghttp.WithHeaders(cfg.Headers),
+		}
+
+		switch {
+		case insecure:
+			opts = append(opts, otlploghttp.WithInsecure())
+
+		default:
+			tc, err := tlsConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlploghttp.WithTLSClientConfig(tc))
+		}
+
+		return otlploghttp.New(context.Background(), opts...)
+	}
+
+	return nil, fmt.Errorf("unknown protocol %q", cfg.Protocol)
+}
+
+// =============================================================================
+
+// logHandler converts slog records into OTel log records. The logger adds
+// the trace and span ids from the context when the record is emitted.
+type logHandler struct {
+	logger log.Logger
+	attrs  []log.KeyValue
+	prefix string
+}
+
+// Enabled reports whether the handler handles records at the given level.
+// The logger decides which levels are written so every level is handled.
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return true
+}
+
+// WithAttrs returns a new handler whose attributes consists of h's
+// attributes followed by attrs.
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	kvs := h.attrs[:len(h.attrs):len(h.attrs)]
+	for _, attr := range attrs {
+		kvs = appendKeyValues(kvs, h.prefix, attr)
+	}
+
+	return &logHandler{logger: h.logger, attrs: kvs, prefix: h.prefix}
+}
+
+// WithGroup returns a new handler that qualifies the keys of the attributes
+// that follow with the group name.
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	return &logHandler{logger: h.logger, attrs: h.attrs, prefix: h.prefix + name + "."}
+}
+
+// Handle converts the record and emits it to the logger provider.
+func (h *logHandler) Handle(ctx context.Context, r slog.Record) error {
+	kvs := make([]log.KeyValue, len(h.attrs), len(h.attrs)+r.NumAttrs())
+	copy(kvs, h.attrs)
+
+	f := func(attr slog.Attr) bool {
+		kvs = appendKeyValues(kvs, h.prefix, attr)
+		return true
+	}
+	r.Attrs(f)
+
+	var rec log.Record
+	rec.SetTimestamp(r.Time)
+	rec.SetObservedTimestamp(time.Now())
+	rec.SetSeverity(severity(r.Level))
+	rec.SetSeverityText(r.Level.String())
+	rec.SetBody(log.StringValue(r.Message))
+	rec.AddAttributes(kvs...)
+
+	h.logger.Emit(ctx, rec)
+
+	return nil
+}
+
+// severity maps a slog level to the OTel severity. The slog levels are
+// defined so that adding 9 gives the matching severity.
+func severity(level slog.Level) log.Severity {
+	return log.Severity(min(max(int(level)+9, 1), 24))
+}
+
+func appendKeyValues(kvs []log.KeyValue, prefix string, attr slog.Attr) []log.KeyValue {
+	value := attr.Value.Resolve()
+
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix = prefix + attr.Key + "."
+		}
+
+		for _, ga := range value.Group() {
+			kvs = appendKeyValues(kvs, prefix, ga)
+		}
+
+		return kvs
+	}
+
+	if attr.Key == "" {
+		return kvs
+	}
+
+	return append(kvs, log.KeyValue{Key: prefix + attr.Key, Value: toValue(value)})
+}
+
+func toValue(v slog.Value) log.Value {
+	switch v.Kind() {
+	case slog.KindString:
+		return log.StringValue(v.String())
+
+	case slog.KindInt64:
+		return log.Int64Value(v.Int64())
+
+	case slog.KindUint64:
+		return log.Int64Value(int64(v.Uint64()))
+
+	case slog.KindFloat64:
+		return log.Float64Value(v.Float64())
+
+	case slog.KindBool:
+		return log.BoolValue(v.Bool())
+
+	case slog.KindDuration:
+		return log.StringValue(v.Duration().String())
+
+	case slog.KindTime:
+		return log.StringValue(v.Time().Format(time.RFC3339Nano))
+	}
+
+	return log.StringValue(fmt.Sprint(v.Any()))
+}
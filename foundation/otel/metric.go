@@ -0,0 +1,121 @@
+package otel
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"google.golang.org/grpc/credentials"
+)
+
+// InitMetrics configures open telemetry metrics for the service. The
+// measurements are registered with the Prometheus registerer so they can be
+// scraped when there is one, and are exported to the collector at the
+// configured metric host using OTLP when there is one.
+func InitMetrics(cfg Config) (metric.MeterProvider, func(ctx context.Context), error) {
+	opts := []sdkmetric.Option{
+		sdkmetric.WithResource(newResource(cfg)),
+	}
+
+	if cfg.Registerer != nil {
+		exporter, err := otelprom.New(otelprom.WithRegisterer(cfg.Registerer), otelprom.WithoutScopeInfo())
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating prometheus exporter: %w", err)
+		}
+
+		opts = append(opts, sdkmetric.WithReader(exporter))
+	}
+
+	if cfg.MetricHost != "" {
+		reader, err := newMetricReader(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating metric exporter: %w", err)
+		}
+
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+
+	mp := sdkmetric.NewMeterProvider(opts...)
+
+	teardown := func(ctx context.Context) {
+		mp.Shutdown(ctx)
+	}
+
+	// We must set this provider as the global provider so the instruments
+	// created through the otel package are recorded.
+	otel.SetMeterProvider(mp)
+
+	return mp, teardown, nil
+}
+
+// newMetricReader constructs the reader that exports the metrics to the
+// metric host on the configured interval.
+func newMetricReader(cfg Config) (sdkmetric.Reader, error) {
+	interval := cfg.MetricInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	exporter, err := newMetricExporter(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(interval)), nil
+}
+
+// newMetricExporter constructs the exporter that sends the metrics to the
+// metric host using the configured protocol.
+func newMetricExporter(cfg Config) (sdkmetric.Exporter, error) {
+	endpoint, insecure := splitHost(cfg, cfg.MetricHost)
+
+	switch cfg.Protocol {
+	case "", ProtocolGRPC:
+		opts := []otlpmetricgrpc.Option{
+			otlpmetricgrpc.WithEndpoint(endpoint),
+			otlpmetricgrpc.WithHeaders(cfg.Headers),
+		}
+
+		switch {
+		case insecure:
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+
+		default:
+			tc, err := tlsConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlpmetricgrpc.WithTLSCredentials(credentials.NewTLS(tc)))
+		}
+
+		return otlpmetricgrpc.New(context.Background(), opts...)
+
+	case ProtocolHTTP:
+		opts := []otlpmetrichttp.Option{
+			otlpmetrichttp.WithEndpoint(endpoint),
+			otlpmetrichttp.WithHeaders(cfg.Headers),
+		}
+
+		switch {
+		case insecure:
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+
+		default:
+			tc, err := tlsConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlpmetrichttp.WithTLSClientConfig(tc))
+		}
+
+		return otlpmetrichttp.New(context.Background(), opts...)
+	}
+
+	return nil, fmt.Errorf("unknown protocol %q", cfg.Protocol)
+}
@@ -5,49 +5,77 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"go.opentelemetry.io/otel/trace"
 	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc/credentials"
 )
 
 const defaultTraceID = "00000000000000000000000000000000"
 
-// Config defines the information needed to init tracing.
+// Set of protocols that can be used to export spans, logs and metrics.
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http"
+)
+
+// Config defines the information needed to init tracing, logging and
+// metrics. Spans are exported to the host, and logs and metrics to the
+// optional log and metric hosts, using OTLP over the same protocol. The
+// protocol, TLS files and headers apply to every exporter. When a Registerer is provided the
+// metrics are also registered with it so they can be scraped by Prometheus.
 type Config struct {
 	ServiceName    string
+	ServiceVersion string
+	Environment    string
+	Attributes     map[string]string
 	Host           string
-	ExcludedRoutes map[string]struct{}
+	Protocol       string
+	Insecure       bool
+	CAFile         string
+	CertFile       string
+	KeyFile        string
+	Headers        map[string]string
+	ExcludedRoutes []string
+	Sampler        string
 	Probability    float64
+	SampleErrors   bool
+	LogHost        string
+	MetricHost     string
+	MetricInterval time.Duration
+	Registerer     prometheus.Registerer
 }
 
-// InitTracing configures open telemetry to be used with the service.
-func InitTracing(cfg Config) (trace.TracerProvider, func(ctx context.Context), error) {
+// ParseKeyValues converts a set of "key=value" values, such as the headers
+// or resource attributes, into a map.
+func ParseKeyValues(values []string) (map[string]string, error) {
+	kvs := make(map[string]string, len(values))
 
-	// WARNING: The current settings are using defaults which may not be
-	// compatible with your project. Please review the documentation for
-	// opentelemetry.
-
-	exporter, err := otlptrace.New(
-		context.Background(),
-		otlptracegrpc.NewClient(
-			otlptracegrpc.WithInsecure(), // This should be configurable
-			otlptracegrpc.WithEndpoint(cfg.Host),
-		),
-	)
-	if err != nil {
-		return nil, nil, fmt.Errorf("creating new exporter: %w", err)
+	for _, value := range values {
+		k, v, found := strings.Cut(value, "=")
+		if !found || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("parse %q: expected key=value", value)
+		}
+
+		kvs[strings.TrimSpace(k)] = strings.TrimSpace(v)
 	}
 
+	return kvs, nil
+}
+
+// InitTracing configures open telemetry to be used with the service.
+func InitTracing(cfg Config) (trace.TracerProvider, func(ctx context.Context), error) {
 	var traceProvider trace.TracerProvider
 	teardown := func(ctx context.Context) {}
 
@@ -56,18 +84,36 @@ func InitTracing(cfg Config) (trace.TracerProvider, func(ctx context.Context), e
 		traceProvider = noop.NewTracerProvider()
 
 	default:
+		client, err := newTraceClient(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating trace client: %w", err)
+		}
+
+		exporter, err := otlptrace.New(context.Background(), client)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating new exporter: %w", err)
+		}
+
+		sampler, err := newSampler(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating sampler: %w", err)
+		}
+
+		var processor sdktrace.SpanProcessor = sdktrace.NewBatchSpanProcessor(exporter,
+			sdktrace.WithMaxExportBatchSize(sdktrace.DefaultMaxExportBatchSize),
+			sdktrace.WithBatchTimeout(sdktrace.DefaultScheduleDelay*time.Millisecond),
+		)
+
+		// Spans that aren't sampled are only recorded when errors are
+		// sampled, in which case the ones ending with an error are exported.
+		if cfg.SampleErrors {
+			processor = errorProcessor{SpanProcessor: processor}
+		}
+
 		tp := sdktrace.NewTracerProvider(
-			sdktrace.WithSampler(sdktrace.ParentBased(newEndpointExcluder(cfg.ExcludedRoutes, cfg.Probability))),
-			sdktrace.WithBatcher(exporter,
-				sdktrace.WithMaxExportBatchSize(sdktrace.DefaultMaxExportBatchSize),
-				sdktrace.WithBatchTimeout(sdktrace.DefaultScheduleDelay*time.Millisecond),
-			),
-			sdktrace.WithResource(
-				resource.NewWithAttributes(
-					semconv.SchemaURL,
-					semconv.ServiceNameKey.String(cfg.ServiceName),
-				),
-			),
+			sdktrace.WithSampler(sampler),
+			sdktrace.WithSpanProcessor(processor),
+			sdktrace.WithResource(newResource(cfg)),
 		)
 
 		teardown = func(ctx context.Context) {
@@ -91,6 +137,54 @@ func InitTracing(cfg Config) (trace.TracerProvider, func(ctx context.Context), e
 	return traceProvider, teardown, nil
 }
 
+func newTraceClient(cfg Config) (otlptrace.Client, error) {
+	endpoint, insecure := splitHost(cfg, cfg.Host)
+
+	switch cfg.Protocol {
+	case "", ProtocolGRPC:
+		opts := []otlptracegrpc.Option{
+			otlptracegrpc.WithEndpoint(endpoint),
+			otlptracegrpc.WithHeaders(cfg.Headers),
+		}
+
+		switch {
+		case insecure:
+			opts = append(opts, otlptracegrpc.WithInsecure())
+
+		default:
+			tc, err := tlsConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tc)))
+		}
+
+		return otlptracegrpc.NewClient(opts...), nil
+
+	case ProtocolHTTP:
+		opts := []otlptracehttp.Option{
+			otlptracehttp.WithEndpoint(endpoint),
+			otlptracehttp.WithHeaders(cfg.Headers),
+		}
+
+		switch {
+		case insecure:
+			opts = append(opts, otlptracehttp.WithInsecure())
+
+		default:
+			tc, err := tlsConfig(cfg)
+			if err != nil {
+				return nil, err
+			}
+			opts = append(opts, otlptracehttp.WithTLSClientConfig(tc))
+		}
+
+		return otlptracehttp.NewClient(opts...), nil
+	}
+
+	return nil, fmt.Errorf("unknown protocol %q", cfg.Protocol)
+}
+
 // InjectTracing initializes the request for tracing by writing otel related
 // information into the response and saving the tracer and trace id in the
 // context for later use.
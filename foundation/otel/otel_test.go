@@ -0,0 +1,316 @@
+package otel_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/logger"
+	"github.com/ardanlabs/service/foundation/otel"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	gootel "go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	colmetricpb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// receiver is an in-process OTLP over HTTP receiver that captures the
+// requests it receives by path.
+type receiver struct {
+	mu       sync.Mutex
+	requests map[string][]map[string]any
+}
+
+func newReceiver(t *testing.T) (*receiver, string) {
+	rcv := receiver{
+		requests: make(map[string][]map[string]any),
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+
+		var doc map[string]any
+
+		// The requests are decoded into protobuf JSON so the tests can look
+		// for the values they expect.
+		var req proto.Message
+		switch r.URL.Path {
+		case "/v1/traces":
+			req = &coltracepb.ExportTraceServiceRequest{}
+		case "/v1/logs":
+			req = &collogspb.ExportLogsServiceRequest{}
+		case "/v1/metrics":
+			req = &colmetricpb.ExportMetricsServiceRequest{}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if err := proto.Unmarshal(data, req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		data, _ = protojson.Marshal(req)
+
+		if err := json.Unmarshal(data, &doc); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		doc["authorization"] = r.Header.Get("Authorization")
+
+		rcv.mu.Lock()
+		rcv.requests[r.URL.Path] = append(rcv.requests[r.URL.Path], doc)
+		rcv.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+
+	return &rcv, strings.TrimPrefix(srv.URL, "http://")
+}
+
+func (rcv *receiver) get(path string) string {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	data, _ := json.Marshal(rcv.requests[path])
+	return string(data)
+}
+
+func Test_Logging(t *testing.T) {
+	rcv, host := newReceiver(t)
+
+	handler, teardown, err := otel.InitLogging(otel.Config{ServiceName: "test", LogHost: host, Protocol: otel.ProtocolHTTP, Insecure: true})
+	if err != nil {
+		t.Fatalf("Should be able to init logging: %s", err)
+	}
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.LevelInfo, "TEST", func(context.Context) string { return "" })
+	log.AddHandler(handler)
+
+	tp := sdktrace.NewTracerProvider()
+	defer tp.Shutdown(context.Background())
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "test")
+	log.Info(ctx, "exported", "user", "bill", "password", "gophers")
+	span.End()
+
+	teardown(context.Background())
+
+	got := rcv.get("/v1/logs")
+
+	// Protobuf JSON encodes the ids as base64.
+	traceID := span.SpanContext().TraceID()
+	spanID := span.SpanContext().SpanID()
+
+	for _, exp := range []string{`"stringValue":"exported"`, base64.StdEncoding.EncodeToString(traceID[:]), base64.StdEncoding.EncodeToString(spanID[:]), `"service.name"`, `"severityText":"INFO"`} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("Should export %s: %s", exp, got)
+		}
+	}
+
+	if strings.Contains(got, "gophers") {
+		t.Errorf("Should export the redacted records: %s", got)
+	}
+
+	if !strings.Contains(buf.String(), "exported") {
+		t.Errorf("Should still write the log: %s", buf.String())
+	}
+}
+
+func Test_Metrics(t *testing.T) {
+	rcv, host := newReceiver(t)
+
+	_, teardown, err := otel.InitMetrics(otel.Config{ServiceName: "test", MetricHost: host, Protocol: otel.ProtocolHTTP, MetricInterval: time.Hour, Insecure: true})
+	if err != nil {
+		t.Fatalf("Should be able to init metrics: %s", err)
+	}
+
+	meter := gootel.Meter("test")
+
+	counter, err := meter.Int64Counter("requests")
+	if err != nil {
+		t.Fatalf("Should be able to create a counter: %s", err)
+	}
+
+	hist, err := meter.Float64Histogram("duration", metric.WithExplicitBucketBoundaries(0.1, 1))
+	if err != nil {
+		t.Fatalf("Should be able to create a histogram: %s", err)
+	}
+
+	ctx := context.Background()
+	route := metric.WithAttributes(attribute.String("http.route", "/v1/users"))
+
+	counter.Add(ctx, 2)
+	counter.Add(ctx, 3)
+	hist.Record(ctx, 0.05, route)
+	hist.Record(ctx, 0.5, route)
+	hist.Record(ctx, 5, route)
+
+	teardown(ctx)
+
+	got := rcv.get("/v1/metrics")
+
+	for _, exp := range []string{`"name":"requests"`, `"asInt":"5"`, `"isMonotonic":true`, `"name":"duration"`, `"bucketCounts":["1","1","1"]`, `"stringValue":"/v1/users"`} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("Should export %s: %s", exp, got)
+		}
+	}
+}
+
+func Test_Protocol(t *testing.T) {
+	cfg := otel.Config{
+		ServiceName: "test",
+		Host:        "localhost:4317",
+		LogHost:     "localhost:4317",
+		MetricHost:  "localhost:4317",
+		Protocol:    "udp",
+		Insecure:    true,
+	}
+
+	if _, _, err := otel.InitTracing(cfg); err == nil {
+		t.Errorf("Should not be able to init tracing with an unknown protocol")
+	}
+
+	if _, _, err := otel.InitLogging(cfg); err == nil {
+		t.Errorf("Should not be able to init logging with an unknown protocol")
+	}
+
+	if _, _, err := otel.InitMetrics(cfg); err == nil {
+		t.Errorf("Should not be able to init metrics with an unknown protocol")
+	}
+}
+
+func Test_Tracing(t *testing.T) {
+	rcv, host := newReceiver(t)
+
+	cfg := otel.Config{
+		ServiceName:    "test",
+		ServiceVersion: "1.0.0",
+		Environment:    "testing",
+		Host:           host,
+		Protocol:       otel.ProtocolHTTP,
+		Insecure:       true,
+		Headers:        map[string]string{"Authorization": "Bearer token"},
+		ExcludedRoutes: []string{"/v1/liveness", "/v1/users/{id}/roles", "/debug/{path...}"},
+		Sampler:        otel.SamplerParentBasedAlwaysOff,
+		SampleErrors:   true,
+	}
+
+	tp, teardown, err := otel.InitTracing(cfg)
+	if err != nil {
+		t.Fatalf("Should be able to init tracing: %s", err)
+	}
+
+	tracer := tp.Tracer("test")
+	ctx := context.Background()
+
+	_, span := tracer.Start(ctx, "dropped")
+	span.End()
+
+	ctx, span = tracer.Start(ctx, "failed")
+	_, child := tracer.Start(ctx, "child-failed")
+	child.SetStatus(codes.Error, "child")
+	child.End()
+	span.SetStatus(codes.Error, "failed")
+	span.End()
+
+	for _, path := range []string{"/v1/liveness", "/v1/users/123/roles", "/debug/pprof/heap"} {
+		_, span = tracer.Start(context.Background(), "excluded", trace.WithAttributes(attribute.String("url.path", path)))
+		span.SetStatus(codes.Error, "excluded")
+		span.End()
+	}
+
+	teardown(context.Background())
+
+	got := rcv.get("/v1/traces")
+
+	for _, exp := range []string{`"name":"failed"`, `"name":"child-failed"`, `"stringValue":"1.0.0"`, `"stringValue":"testing"`, `"authorization":"Bearer token"`} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("Should export %s: %s", exp, got)
+		}
+	}
+
+	for _, exp := range []string{`"name":"dropped"`, `"name":"excluded"`} {
+		if strings.Contains(got, exp) {
+			t.Errorf("Should not export %s: %s", exp, got)
+		}
+	}
+}
+
+func Test_Prometheus(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	mp, teardown, err := otel.InitMetrics(otel.Config{ServiceName: "test", Registerer: registry})
+	if err != nil {
+		t.Fatalf("Should be able to init metrics: %s", err)
+	}
+	defer teardown(context.Background())
+
+	meter := mp.Meter("prom")
+
+	counter, err := meter.Int64Counter("http.server.requests")
+	if err != nil {
+		t.Fatalf("Should be able to create a counter: %s", err)
+	}
+
+	hist, err := meter.Float64Histogram("http.server.request.duration", metric.WithUnit("s"), metric.WithExplicitBucketBoundaries(0.1, 1))
+	if err != nil {
+		t.Fatalf("Should be able to create a histogram: %s", err)
+	}
+
+	open := func(ctx context.Context, o metric.Int64Observer) error {
+		o.Observe(7, metric.WithAttributes(attribute.String("pool.name", "postgres")))
+		return nil
+	}
+
+	if _, err := meter.Int64ObservableGauge("db.client.connections.open", metric.WithDescription("Open connections."), metric.WithInt64Callback(open)); err != nil {
+		t.Fatalf("Should be able to create an observable gauge: %s", err)
+	}
+
+	ctx := context.Background()
+	route := metric.WithAttributes(attribute.String("http.route", "/v1/users/{user_id}"), attribute.Int("http.response.status_code", 200))
+
+	counter.Add(ctx, 3, route)
+	hist.Record(ctx, 0.05, route)
+	hist.Record(ctx, 0.5, route)
+
+	w := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	got := w.Body.String()
+
+	exps := []string{
+		"# TYPE http_server_requests_total counter",
+		`http_server_requests_total{http_response_status_code="200",http_route="/v1/users/{user_id}"} 3`,
+		"# TYPE http_server_request_duration_seconds histogram",
+		`http_server_request_duration_seconds_bucket{http_response_status_code="200",http_route="/v1/users/{user_id}",le="0.1"} 1`,
+		`http_server_request_duration_seconds_bucket{http_response_status_code="200",http_route="/v1/users/{user_id}",le="+Inf"} 2`,
+		`http_server_request_duration_seconds_count{http_response_status_code="200",http_route="/v1/users/{user_id}"} 2`,
+		"# HELP db_client_connections_open Open connections.",
+		`db_client_connections_open{pool_name="postgres"} 7`,
+	}
+
+	for _, exp := range exps {
+		if !strings.Contains(got, exp) {
+			t.Errorf("Should write %s:\n%s", exp, got)
+		}
+	}
+}
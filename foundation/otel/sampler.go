@@ -2,59 +2,203 @@ package otel
 
 import (
 	"fmt"
+	"strings"
 
+	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
-type endpointExcluder struct {
-	endpoints   map[string]struct{}
-	probability float64
+// Set of samplers that can be configured. The names match the values of the
+// OTEL_TRACES_SAMPLER environment variable.
+const (
+	SamplerAlwaysOn              = "always_on"
+	SamplerAlwaysOff             = "always_off"
+	SamplerTraceIDRatio          = "traceidratio"
+	SamplerParentBasedAlwaysOn   = "parentbased_always_on"
+	SamplerParentBasedAlwaysOff  = "parentbased_always_off"
+	SamplerParentBasedTraceRatio = "parentbased_traceidratio"
+)
+
+// newSampler constructs the sampler for the configuration. Spans for the
+// excluded routes are always dropped. When errors are sampled, spans that
+// aren't sampled are still recorded so they can be exported if they end with
+// an error.
+func newSampler(cfg Config) (trace.Sampler, error) {
+	name := cfg.Sampler
+	if name == "" {
+		name = SamplerParentBasedTraceRatio
+	}
+
+	var root trace.Sampler
+	switch strings.TrimPrefix(name, "parentbased_") {
+	case SamplerAlwaysOn:
+		root = trace.AlwaysSample()
+
+	case SamplerAlwaysOff:
+		root = trace.NeverSample()
+
+	case SamplerTraceIDRatio:
+		root = trace.TraceIDRatioBased(cfg.Probability)
+
+	default:
+		return nil, fmt.Errorf("unknown sampler %q", cfg.Sampler)
+	}
+
+	if cfg.SampleErrors {
+		root = recordOnly{sampler: root}
+	}
+
+	root = newRouteExcluder(cfg.ExcludedRoutes, root)
+
+	if !strings.HasPrefix(name, "parentbased_") {
+		return root, nil
+	}
+
+	notSampled := trace.NeverSample()
+	if cfg.SampleErrors {
+		notSampled = recordOnly{sampler: notSampled}
+	}
+
+	sampler := trace.ParentBased(root,
+		trace.WithRemoteParentNotSampled(notSampled),
+		trace.WithLocalParentNotSampled(notSampled),
+	)
+
+	return sampler, nil
+}
+
+// =============================================================================
+
+// routeExcluder drops the spans for requests matching one of the route
+// patterns and uses the sampler it wraps for the rest.
+type routeExcluder struct {
+	patterns [][]string
+	sampler  trace.Sampler
 }
 
-func newEndpointExcluder(endpoints map[string]struct{}, probability float64) endpointExcluder {
-	return endpointExcluder{
-		endpoints:   endpoints,
-		probability: probability,
+func newRouteExcluder(patterns []string, sampler trace.Sampler) routeExcluder {
+	re := routeExcluder{
+		sampler: sampler,
+	}
+
+	for _, pattern := range patterns {
+		re.patterns = append(re.patterns, segments(pattern))
 	}
+
+	return re
 }
 
-func endpoint(parameters trace.SamplingParameters) string {
-	var path, query string
+// ShouldSample implements the sampler interface. It prevents the spans for
+// the excluded routes from being added to the trace.
+func (re routeExcluder) ShouldSample(parameters trace.SamplingParameters) trace.SamplingResult {
+	if path := urlPath(parameters); path != "" {
+		for _, pattern := range re.patterns {
+			if matchRoute(pattern, segments(path)) {
+				return trace.SamplingResult{Decision: trace.Drop}
+			}
+		}
+	}
 
+	return re.sampler.ShouldSample(parameters)
+}
+
+// Description implements the sampler interface.
+func (re routeExcluder) Description() string {
+	return fmt.Sprintf("RouteExcluder{%s}", re.sampler.Description())
+}
+
+func urlPath(parameters trace.SamplingParameters) string {
 	for _, attr := range parameters.Attributes {
-		switch attr.Key {
-		case "url.path":
-			path = attr.Value.AsString()
-		case "url.query":
-			query = attr.Value.AsString()
+		if attr.Key == "url.path" {
+			return attr.Value.AsString()
 		}
 	}
 
-	switch {
-	case path == "":
-		return ""
+	return ""
+}
 
-	case query == "":
-		return path
+func segments(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
 
-	default:
-		return fmt.Sprintf("%s?%s", path, query)
+// matchRoute reports whether the path matches the route pattern. Patterns
+// use the same wildcards as http.ServeMux, {name} matches a single segment
+// and {name...} matches the remaining segments.
+func matchRoute(pattern []string, path []string) bool {
+	for i, seg := range pattern {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "...}") {
+			return true
+		}
+
+		if i >= len(path) {
+			return false
+		}
+
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			continue
+		}
+
+		if seg != path[i] {
+			return false
+		}
 	}
+
+	return len(pattern) == len(path)
 }
 
-// ShouldSample implements the sampler interface. It prevents the specified
-// endpoints from being added to the trace.
-func (ee endpointExcluder) ShouldSample(parameters trace.SamplingParameters) trace.SamplingResult {
-	if ep := endpoint(parameters); ep != "" {
-		if _, exists := ee.endpoints[ep]; exists {
-			return trace.SamplingResult{Decision: trace.Drop}
-		}
+// =============================================================================
+
+// recordOnly records the spans the sampler it wraps drops so they are
+// available to the errorProcessor.
+type recordOnly struct {
+	sampler trace.Sampler
+}
+
+// ShouldSample implements the sampler interface.
+func (ro recordOnly) ShouldSample(parameters trace.SamplingParameters) trace.SamplingResult {
+	result := ro.sampler.ShouldSample(parameters)
+	if result.Decision == trace.Drop {
+		result.Decision = trace.RecordOnly
 	}
 
-	return trace.TraceIDRatioBased(ee.probability).ShouldSample(parameters)
+	return result
 }
 
 // Description implements the sampler interface.
-func (endpointExcluder) Description() string {
-	return "customSampler"
+func (ro recordOnly) Description() string {
+	return fmt.Sprintf("RecordErrors{%s}", ro.sampler.Description())
+}
+
+// errorProcessor passes the spans that are sampled and the recorded spans
+// that ended with an error to the processor it wraps.
+type errorProcessor struct {
+	trace.SpanProcessor
+}
+
+// OnEnd marks a recorded span that ended with an error as sampled so it's
+// exported.
+func (ep errorProcessor) OnEnd(s trace.ReadOnlySpan) {
+	sc := s.SpanContext()
+
+	if !sc.IsSampled() {
+		if s.Status().Code != codes.Error {
+			return
+		}
+
+		s = sampledSpan{ReadOnlySpan: s, sc: sc.WithTraceFlags(sc.TraceFlags().WithSampled(true))}
+	}
+
+	ep.SpanProcessor.OnEnd(s)
+}
+
+// sampledSpan overrides the span context of a recorded span.
+type sampledSpan struct {
+	trace.ReadOnlySpan
+	sc oteltrace.SpanContext
+}
+
+// SpanContext returns the span context with the sampled flag set.
+func (s sampledSpan) SpanContext() oteltrace.SpanContext {
+	return s.sc
 }
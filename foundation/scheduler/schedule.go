@@ -0,0 +1,267 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule determines when a task runs next.
+type Schedule interface {
+	Next(t time.Time) time.Time
+	String() string
+}
+
+// Parse parses a schedule specification. It accepts a standard five field
+// cron expression (minute hour day-of-month month day-of-week), one of the
+// descriptors @yearly, @monthly, @weekly, @daily and @hourly, or @every
+// followed by a duration such as "@every 5m".
+func Parse(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+
+	if d, found := strings.CutPrefix(spec, "@every "); found {
+		dur, err := time.ParseDuration(strings.TrimSpace(d))
+		if err != nil {
+			return nil, fmt.Errorf("parse %q: %w", spec, err)
+		}
+
+		return Every(dur)
+	}
+
+	return ParseCron(spec)
+}
+
+// MustParse parses the specification and panics if it's invalid.
+func MustParse(spec string) Schedule {
+	s, err := Parse(spec)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
+// =============================================================================
+
+type interval struct {
+	d time.Duration
+}
+
+// Every returns a schedule that runs a task at a fixed interval from the
+// time the previous run was scheduled.
+func Every(d time.Duration) (Schedule, error) {
+	if d <= 0 {
+		return nil, fmt.Errorf("interval %s must be greater than 0", d)
+	}
+
+	return interval{d: d}, nil
+}
+
+func (i interval) Next(t time.Time) time.Time {
+	return t.Add(i.d)
+}
+
+func (i interval) String() string {
+	return "@every " + i.d.String()
+}
+
+// =============================================================================
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var dayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// bits represents the set of values that match a cron field.
+type bits uint64
+
+func (b bits) has(v int) bool {
+	return b&(1<<uint(v)) != 0
+}
+
+type cron struct {
+	spec    string
+	minute  bits
+	hour    bits
+	dom     bits
+	month   bits
+	dow     bits
+	anyDay  bool
+	anyWeek bool
+}
+
+// ParseCron parses a standard five field cron expression. Fields support
+// lists, ranges and steps such as "*/15", "1-5" and "0,30". Months and days
+// of the week can be written by name. As with cron, when both the day of the
+// month and the day of the week are restricted a day matching either runs.
+func ParseCron(spec string) (Schedule, error) {
+	expr := spec
+	if d, exists := descriptors[strings.ToLower(spec)]; exists {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("parse %q: expecting 5 fields, got %d", spec, len(fields))
+	}
+
+	c := cron{
+		spec:    spec,
+		anyDay:  fields[2] == "*" || fields[2] == "?",
+		anyWeek: fields[4] == "*" || fields[4] == "?",
+	}
+
+	var err error
+	if c.minute, err = parseField(fields[0], 0, 59, nil); err != nil {
+		return nil, fmt.Errorf("parse %q: minute: %w", spec, err)
+	}
+	if c.hour, err = parseField(fields[1], 0, 23, nil); err != nil {
+		return nil, fmt.Errorf("parse %q: hour: %w", spec, err)
+	}
+	if c.dom, err = parseField(fields[2], 1, 31, nil); err != nil {
+		return nil, fmt.Errorf("parse %q: day of month: %w", spec, err)
+	}
+	if c.month, err = parseField(fields[3], 1, 12, monthNames); err != nil {
+		return nil, fmt.Errorf("parse %q: month: %w", spec, err)
+	}
+	if c.dow, err = parseField(fields[4], 0, 7, dayNames); err != nil {
+		return nil, fmt.Errorf("parse %q: day of week: %w", spec, err)
+	}
+
+	// Sunday can be written as 0 or 7.
+	if c.dow.has(7) {
+		c.dow |= 1
+	}
+
+	return c, nil
+}
+
+func parseField(field string, low int, high int, names map[string]int) (bits, error) {
+	var b bits
+
+	for part := range strings.SplitSeq(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step %q", part)
+			}
+		}
+
+		var start, end int
+		switch {
+		case rng == "*" || rng == "?":
+			start, end = low, high
+
+		default:
+			first, last, isRange := strings.Cut(rng, "-")
+
+			var err error
+			if start, err = parseValue(first, names); err != nil {
+				return 0, err
+			}
+
+			end = start
+			switch {
+			case isRange:
+				if end, err = parseValue(last, names); err != nil {
+					return 0, err
+				}
+			case hasStep:
+				end = high
+			}
+		}
+
+		if start < low || end > high || start > end {
+			return 0, fmt.Errorf("value %q out of range %d-%d", part, low, high)
+		}
+
+		for v := start; v <= end; v += step {
+			b |= 1 << uint(v)
+		}
+	}
+
+	return b, nil
+}
+
+func parseValue(s string, names map[string]int) (int, error) {
+	if v, exists := names[strings.ToUpper(s)]; exists {
+		return v, nil
+	}
+
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	return v, nil
+}
+
+// Next returns the first time after t that matches the expression in the
+// location of t. A zero time is returned when nothing matches within five
+// years, such as for February 30.
+func (c cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		switch {
+		case !c.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+
+		case !c.matchDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+
+		case !c.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+
+		case !c.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (c cron) matchDay(t time.Time) bool {
+	dom := c.dom.has(t.Day())
+	dow := c.dow.has(int(t.Weekday()))
+
+	switch {
+	case c.anyDay && c.anyWeek:
+		return true
+	case c.anyDay:
+		return dow
+	case c.anyWeek:
+		return dom
+	}
+
+	return dom || dow
+}
+
+func (c cron) String() string {
+	return c.spec
+}
@@ -0,0 +1,456 @@
+// Package scheduler runs named tasks on cron expressions or fixed intervals
+// using a worker for the execution.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/worker"
+)
+
+// ErrLocked is returned by a Locker when another instance holds the lock.
+var ErrLocked = errors.New("locked by another instance")
+
+// TaskFn defines a function that performs the work for a task.
+type TaskFn func(ctx context.Context) error
+
+// Task represents a named unit of work that runs on a schedule. A task with
+// Leader set only runs on the instance that holds the lock for the task when
+// the scheduler has a Locker. The Timeout is the deadline for a single run
+// and defaults to one minute.
+type Task struct {
+	Name     string
+	Schedule Schedule
+	Timeout  time.Duration
+	Leader   bool
+	Fn       TaskFn
+}
+
+// Lease represents a lock held by this instance.
+type Lease interface {
+	Check(ctx context.Context) error
+	Release(ctx context.Context) error
+}
+
+// Locker provides leader election for tasks. Acquire returns ErrLocked when
+// another instance holds the lock for the name.
+type Locker interface {
+	Acquire(ctx context.Context, name string) (Lease, error)
+}
+
+// Status represents the state of a task.
+type Status struct {
+	Name         string    `json:"name"`
+	Schedule     string    `json:"schedule"`
+	Leader       bool      `json:"leader"`
+	IsLeader     bool      `json:"is_leader"`
+	Running      bool      `json:"running"`
+	Next         time.Time `json:"next"`
+	LastRun      time.Time `json:"last_run"`
+	LastDuration string    `json:"last_duration"`
+	LastError    string    `json:"last_error,omitempty"`
+	Runs         int       `json:"runs"`
+	Failures     int       `json:"failures"`
+	Skipped      int       `json:"skipped"`
+}
+
+// Options represent optional parameters.
+type Options struct {
+	locker   Locker
+	location *time.Location
+	onError  func(ctx context.Context, task string, err error)
+}
+
+// WithLocker provides the locker used for the tasks that need to be run by
+// a single instance.
+func WithLocker(locker Locker) func(opts *Options) {
+	return func(opts *Options) {
+		opts.locker = locker
+	}
+}
+
+// WithLocation sets the time zone the cron expressions are evaluated in. The
+// default is the local time zone.
+func WithLocation(loc *time.Location) func(opts *Options) {
+	return func(opts *Options) {
+		opts.location = loc
+	}
+}
+
+// WithErrorHandler provides a function that is called when a run fails or
+// the lock for a task can't be checked.
+func WithErrorHandler(fn func(ctx context.Context, task string, err error)) func(opts *Options) {
+	return func(opts *Options) {
+		opts.onError = fn
+	}
+}
+
+type task struct {
+	Task
+	status  Status
+	lease   Lease
+	workKey string
+}
+
+// Scheduler manages the set of tasks and runs them when they are due.
+type Scheduler struct {
+	worker   *worker.Worker
+	opts     Options
+	mu       sync.Mutex
+	tasks    map[string]*task
+	wake     chan struct{}
+	shutdown chan struct{}
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	runs     sync.WaitGroup
+}
+
+// New constructs a scheduler that runs the tasks using the worker.
+func New(w *worker.Worker, options ...func(opts *Options)) *Scheduler {
+	opts := Options{
+		location: time.Local,
+		onError:  func(ctx context.Context, task string, err error) {},
+	}
+
+	for _, option := range options {
+		option(&opts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s := Scheduler{
+		worker:   w,
+		opts:     opts,
+		tasks:    make(map[string]*task),
+		wake:     make(chan struct{}, 1),
+		shutdown: make(chan struct{}),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	return &s
+}
+
+// Add registers a task with the scheduler. Tasks can be added before or after
+// the scheduler is started.
+func (s *Scheduler) Add(t Task) error {
+	switch {
+	case t.Name == "":
+		return errors.New("task name is required")
+	case t.Schedule == nil:
+		return fmt.Errorf("task[%s]: schedule is required", t.Name)
+	case t.Fn == nil:
+		return fmt.Errorf("task[%s]: function is required", t.Name)
+	}
+
+	if t.Timeout <= 0 {
+		t.Timeout = time.Minute
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tasks[t.Name]; exists {
+		return fmt.Errorf("task[%s]: already added", t.Name)
+	}
+
+	s.tasks[t.Name] = &task{
+		Task: t,
+		status: Status{
+			Name:     t.Name,
+			Schedule: t.Schedule.String(),
+			Leader:   t.Leader,
+			Next:     t.Schedule.Next(s.now()),
+		},
+	}
+
+	s.signal()
+
+	return nil
+}
+
+// Start launches a goroutine that runs the tasks when they are due until
+// Shutdown is called.
+func (s *Scheduler) Start() {
+	s.wg.Add(1)
+
+	go func() {
+		defer s.wg.Done()
+
+		timer := time.NewTimer(s.untilNext())
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-s.shutdown:
+				return
+			case <-s.wake:
+			case <-timer.C:
+				s.runDue()
+			}
+
+			timer.Reset(s.untilNext())
+		}
+	}()
+}
+
+// Shutdown stops scheduling tasks, cancels the runs in progress and waits for
+// them to finish before releasing the locks held by this instance. Runs still
+// waiting for the worker are abandoned.
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	close(s.shutdown)
+	s.cancel()
+	s.wg.Wait()
+
+	func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		for _, t := range s.tasks {
+			if t.status.Running {
+				s.worker.Stop(t.workKey)
+			}
+		}
+	}()
+
+	ch := make(chan struct{})
+	go func() {
+		s.runs.Wait()
+		close(ch)
+	}()
+
+	select {
+	case <-ch:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, t := range s.tasks {
+		if t.lease != nil {
+			if err := t.lease.Release(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("task[%s]: release: %w", t.Name, err))
+			}
+			t.lease = nil
+			t.status.IsLeader = false
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Status returns the state of the tasks sorted by name.
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]Status, 0, len(s.tasks))
+	for _, t := range s.tasks {
+		statuses = append(statuses, t.status)
+	}
+
+	slices.SortFunc(statuses, func(a, b Status) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return statuses
+}
+
+// =============================================================================
+
+func (s *Scheduler) now() time.Time {
+	return time.Now().In(s.opts.location)
+}
+
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// untilNext returns the time until the next task is due. A task that will
+// never run again doesn't count.
+func (s *Scheduler) untilNext() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, t := range s.tasks {
+		if t.status.Next.IsZero() {
+			continue
+		}
+
+		if next.IsZero() || t.status.Next.Before(next) {
+			next = t.status.Next
+		}
+	}
+
+	if next.IsZero() {
+		return time.Hour
+	}
+
+	return max(time.Until(next), 0)
+}
+
+// runDue starts the tasks that are due, each on its own goroutine so a task
+// waiting for room in the worker doesn't hold up the others. A task whose
+// previous run is still going is skipped.
+func (s *Scheduler) runDue() {
+	now := s.now()
+
+	s.mu.Lock()
+	var due []*task
+	for _, t := range s.tasks {
+		if t.status.Next.IsZero() || t.status.Next.After(now) {
+			continue
+		}
+
+		// The next run is measured from the time this run was scheduled so
+		// intervals don't drift. Runs that were missed aren't caught up.
+		next := t.Schedule.Next(t.status.Next)
+		if !next.After(now) {
+			next = t.Schedule.Next(now)
+		}
+		t.status.Next = next
+
+		if t.status.Running {
+			t.status.Skipped++
+			continue
+		}
+
+		t.status.Running = true
+		due = append(due, t)
+	}
+	s.mu.Unlock()
+
+	for _, t := range due {
+		s.wg.Add(1)
+
+		go func() {
+			defer s.wg.Done()
+			s.run(t)
+		}()
+	}
+}
+
+func (s *Scheduler) run(t *task) {
+	ctx, cancel := context.WithTimeout(s.ctx, t.Timeout)
+	defer cancel()
+
+	if t.Leader && s.opts.locker != nil && !s.lead(ctx, t) {
+		s.mu.Lock()
+		t.status.Running = false
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.runs.Add(1)
+
+	f := func(ctx context.Context) (any, error) {
+		defer s.runs.Done()
+
+		start := time.Now()
+
+		var err error
+		defer func() {
+			if rec := recover(); rec != nil {
+				s.finish(ctx, t, start, fmt.Errorf("panic: %v", rec))
+				panic(rec)
+			}
+
+			s.finish(ctx, t, start, err)
+		}()
+
+		err = t.Fn(ctx)
+
+		return nil, err
+	}
+
+	workKey, err := s.worker.StartResult(ctx, f)
+	if err != nil {
+		s.runs.Done()
+
+		s.mu.Lock()
+		t.status.Running = false
+		t.status.Skipped++
+		s.mu.Unlock()
+
+		// Runs waiting for the worker are expected to fail on shutdown.
+		if s.ctx.Err() == nil {
+			s.opts.onError(ctx, t.Name, fmt.Errorf("start: %w", err))
+		}
+		return
+	}
+
+	s.mu.Lock()
+	t.workKey = workKey
+	s.mu.Unlock()
+}
+
+// lead reports whether this instance holds the lock for the task, acquiring
+// it when nobody does.
+func (s *Scheduler) lead(ctx context.Context, t *task) bool {
+	s.mu.Lock()
+	lease := t.lease
+	s.mu.Unlock()
+
+	if lease != nil {
+		if err := lease.Check(ctx); err == nil {
+			return true
+		}
+
+		// The lock is lost when the connection holding it is.
+		lease.Release(ctx)
+		lease = nil
+	}
+
+	lease, err := s.opts.locker.Acquire(ctx, t.Name)
+	if err != nil && !errors.Is(err, ErrLocked) {
+		s.opts.onError(ctx, t.Name, fmt.Errorf("acquire lock: %w", err))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease == nil {
+		t.status.Skipped++
+	}
+
+	t.lease = lease
+	t.status.IsLeader = lease != nil
+
+	return lease != nil
+}
+
+func (s *Scheduler) finish(ctx context.Context, t *task, start time.Time, err error) {
+	s.mu.Lock()
+
+	t.status.Running = false
+	t.status.Runs++
+	t.status.LastRun = start
+	t.status.LastDuration = time.Since(start).String()
+	t.status.LastError = ""
+
+	if err != nil {
+		t.status.Failures++
+		t.status.LastError = err.Error()
+	}
+
+	s.mu.Unlock()
+
+	if err != nil {
+		s.opts.onError(ctx, t.Name, err)
+	}
+}
@@ -0,0 +1,308 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/scheduler"
+	"github.com/ardanlabs/service/foundation/worker"
+)
+
+func Test_Cron(t *testing.T) {
+	from := time.Date(2026, time.January, 30, 10, 17, 42, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		exp  time.Time
+	}{
+		{"* * * * *", time.Date(2026, time.January, 30, 10, 18, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, time.January, 30, 10, 30, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2026, time.January, 31, 3, 0, 0, 0, time.UTC)},
+		{"30 9-17 * * MON-FRI", time.Date(2026, time.January, 30, 10, 30, 0, 0, time.UTC)},
+		{"0 0 * * SAT,SUN", time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 FEB *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * 0", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, time.January, 30, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			s, err := scheduler.Parse(tt.spec)
+			if err != nil {
+				t.Fatalf("Should be able to parse the spec : %s", err)
+			}
+
+			if got := s.Next(from); !got.Equal(tt.exp) {
+				t.Errorf("Should get the next time %s : got %s", tt.exp, got)
+			}
+		})
+	}
+}
+
+func Test_CronInvalid(t *testing.T) {
+	specs := []string{
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"@every 0s",
+		"@every soon",
+	}
+
+	for _, spec := range specs {
+		if _, err := scheduler.Parse(spec); err == nil {
+			t.Errorf("Should not be able to parse %q", spec)
+		}
+	}
+}
+
+func Test_Scheduler(t *testing.T) {
+	w, err := worker.New(2)
+	if err != nil {
+		t.Fatalf("Should be able to create a worker : %s", err)
+	}
+	defer w.Shutdown(context.Background())
+
+	locker := newFakeLocker()
+
+	var errs sync.Map
+	onError := func(ctx context.Context, task string, err error) {
+		errs.Store(task, err)
+	}
+
+	sched := scheduler.New(w, scheduler.WithLocker(locker), scheduler.WithErrorHandler(onError))
+
+	every, _ := scheduler.Every(10 * time.Millisecond)
+
+	var ran, failed, led atomic.Int64
+
+	tasks := []scheduler.Task{
+		{
+			Name:     "ran",
+			Schedule: every,
+			Fn: func(ctx context.Context) error {
+				ran.Add(1)
+				return nil
+			},
+		},
+		{
+			Name:     "failed",
+			Schedule: every,
+			Fn: func(ctx context.Context) error {
+				failed.Add(1)
+				return errors.New("failed")
+			},
+		},
+		{
+			Name:     "led",
+			Schedule: every,
+			Leader:   true,
+			Fn: func(ctx context.Context) error {
+				led.Add(1)
+				return nil
+			},
+		},
+		{
+			Name:     "follower",
+			Schedule: every,
+			Leader:   true,
+			Fn: func(ctx context.Context) error {
+				t.Error("Should not run a task locked by another instance")
+				return nil
+			},
+		},
+	}
+
+	locker.lock("follower")
+
+	for _, task := range tasks {
+		if err := sched.Add(task); err != nil {
+			t.Fatalf("Should be able to add task %s : %s", task.Name, err)
+		}
+	}
+
+	if err := sched.Add(tasks[0]); err == nil {
+		t.Errorf("Should not be able to add a task twice")
+	}
+
+	sched.Start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for (ran.Load() < 3 || failed.Load() < 3 || led.Load() < 3) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := sched.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Should be able to shutdown the scheduler : %s", err)
+	}
+
+	statuses := make(map[string]scheduler.Status)
+	for _, status := range sched.Status() {
+		statuses[status.Name] = status
+	}
+
+	if s := statuses["ran"]; s.Runs < 3 || s.Failures != 0 {
+		t.Errorf("Should get at least 3 runs without failures : got %+v", s)
+	}
+
+	if s := statuses["failed"]; s.Failures < 3 || s.LastError != "failed" {
+		t.Errorf("Should get at least 3 failures : got %+v", s)
+	}
+
+	if _, exists := errs.Load("failed"); !exists {
+		t.Errorf("Should get the failure reported to the error handler")
+	}
+
+	if s := statuses["led"]; s.Runs < 3 {
+		t.Errorf("Should get at least 3 runs for the leader : got %+v", s)
+	}
+
+	if s := statuses["follower"]; s.Runs != 0 || s.IsLeader || s.Skipped == 0 {
+		t.Errorf("Should get the follower skipped : got %+v", s)
+	}
+
+	if locker.held("led") {
+		t.Errorf("Should release the lock on shutdown")
+	}
+}
+
+func Test_SchedulerBusy(t *testing.T) {
+	w, err := worker.New(1)
+	if err != nil {
+		t.Fatalf("Should be able to create a worker : %s", err)
+	}
+	defer w.Shutdown(context.Background())
+
+	sched := scheduler.New(w)
+
+	every, _ := scheduler.Every(10 * time.Millisecond)
+
+	started := make(chan struct{})
+	var once sync.Once
+
+	tasks := []scheduler.Task{
+		{
+			Name:     "busy",
+			Schedule: every,
+			Timeout:  time.Hour,
+			Fn: func(ctx context.Context) error {
+				once.Do(func() { close(started) })
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		},
+		{
+			Name:     "waiting",
+			Schedule: every,
+			Timeout:  time.Hour,
+			Fn: func(ctx context.Context) error {
+				return nil
+			},
+		},
+	}
+
+	for _, task := range tasks {
+		if err := sched.Add(task); err != nil {
+			t.Fatalf("Should be able to add task %s : %s", task.Name, err)
+		}
+	}
+
+	first := sched.Status()[0].Next
+
+	sched.Start()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Should start the busy task")
+	}
+
+	// Let the scheduler keep ticking while the worker has no room.
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := sched.Shutdown(ctx); err != nil {
+		t.Fatalf("Should be able to shutdown the scheduler while runs are waiting for the worker : %s", err)
+	}
+
+	status := sched.Status()[0]
+
+	if status.Skipped == 0 {
+		t.Errorf("Should skip the busy task while it is running : got %+v", status)
+	}
+
+	if d := status.Next.Sub(first); d <= 0 || d%(10*time.Millisecond) != 0 {
+		t.Errorf("Should schedule the interval from the previous run without drift : got %s", d)
+	}
+}
+
+// =============================================================================
+
+type fakeLocker struct {
+	mu    sync.Mutex
+	locks map[string]bool
+}
+
+func newFakeLocker() *fakeLocker {
+	return &fakeLocker{locks: make(map[string]bool)}
+}
+
+func (l *fakeLocker) lock(name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.locks[name] = true
+}
+
+func (l *fakeLocker) held(name string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.locks[name]
+}
+
+func (l *fakeLocker) Acquire(ctx context.Context, name string) (scheduler.Lease, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.locks[name] {
+		return nil, scheduler.ErrLocked
+	}
+
+	l.locks[name] = true
+
+	return &fakeLease{locker: l, name: name}, nil
+}
+
+type fakeLease struct {
+	locker *fakeLocker
+	name   string
+}
+
+func (l *fakeLease) Check(ctx context.Context) error {
+	return nil
+}
+
+func (l *fakeLease) Release(ctx context.Context) error {
+	l.locker.mu.Lock()
+	defer l.locker.mu.Unlock()
+
+	delete(l.locker.locks, l.name)
+
+	return nil
+}
@@ -13,6 +13,7 @@ type ctxKey int
 const (
 	tracerKey ctxKey = iota + 1
 	writerKey
+	requestIDKey
 )
 
 func setTracer(ctx context.Context, tracer trace.Tracer) context.Context {
@@ -0,0 +1,238 @@
+package web
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS represents the policy for cross-origin resource sharing. Origins can
+// be an exact match, "*" for any origin, or contain a wildcard for subdomains
+// like "https://*.example.com".
+type CORS struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration
+}
+
+// The methods a browser can use without a preflight request and which are
+// allowed when no methods are configured.
+var defaultCORSMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+
+// corsPolicy is a CORS configuration prepared for use on each request.
+type corsPolicy struct {
+	anyOrigin   bool
+	origins     []string
+	wildcards   [][2]string
+	methods     []string
+	anyHeader   bool
+	headers     []string
+	exposed     string
+	credentials bool
+	maxAge      string
+}
+
+func newCORSPolicy(cors CORS) corsPolicy {
+	p := corsPolicy{
+		methods:     defaultCORSMethods,
+		exposed:     strings.Join(cors.ExposedHeaders, ", "),
+		credentials: cors.AllowCredentials,
+	}
+
+	for _, origin := range cors.AllowedOrigins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+
+		switch {
+		case origin == "*":
+			p.anyOrigin = true
+
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			p.wildcards = append(p.wildcards, [2]string{prefix, suffix})
+
+		default:
+			p.origins = append(p.origins, origin)
+		}
+	}
+
+	if len(cors.AllowedMethods) > 0 {
+		p.methods = make([]string, len(cors.AllowedMethods))
+		for i, method := range cors.AllowedMethods {
+			p.methods[i] = strings.ToUpper(strings.TrimSpace(method))
+		}
+	}
+
+	for _, header := range cors.AllowedHeaders {
+		header = http.CanonicalHeaderKey(strings.TrimSpace(header))
+		if header == "*" {
+			p.anyHeader = true
+			continue
+		}
+		p.headers = append(p.headers, header)
+	}
+
+	if cors.MaxAge > 0 {
+		p.maxAge = strconv.Itoa(int(cors.MaxAge.Seconds()))
+	}
+
+	return p
+}
+
+// allowOrigin reports whether the origin is allowed by the policy.
+func (p corsPolicy) allowOrigin(origin string) bool {
+	if origin == "" {
+		return false
+	}
+
+	if p.anyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	if slices.Contains(p.origins, origin) {
+		return true
+	}
+
+	for _, wc := range p.wildcards {
+		prefix, suffix := wc[0], wc[1]
+
+		if len(origin) <= len(prefix)+len(suffix) {
+			continue
+		}
+
+		if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+			continue
+		}
+
+		// The wildcard can only match subdomain labels.
+		if sub := origin[len(prefix) : len(origin)-len(suffix)]; !strings.ContainsAny(sub, "/:") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowHeaders reports whether all the requested headers are allowed.
+func (p corsPolicy) allowHeaders(requested []string) bool {
+	if p.anyHeader {
+		return true
+	}
+
+	for _, header := range requested {
+		if !slices.Contains(p.headers, http.CanonicalHeaderKey(header)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// setAllowOrigin sets the allow origin header. A wildcard can't be used
+// when credentials are allowed so the origin is reflected instead.
+//
+// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Access-Control-Allow-Origin
+func (p corsPolicy) setAllowOrigin(w http.ResponseWriter, origin string) {
+	switch {
+	case p.anyOrigin && !p.credentials:
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+	default:
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+	}
+
+	if p.credentials {
+		w.Header().Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// preflight answers a preflight request. Requests from origins, or for
+// methods and headers, that are not allowed are rejected with a 403 and no
+// CORS headers so the browser blocks the actual request.
+func (p corsPolicy) preflight(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Origin")
+	w.Header().Add("Vary", "Access-Control-Request-Method")
+	w.Header().Add("Vary", "Access-Control-Request-Headers")
+
+	origin := r.Header.Get("Origin")
+	if !p.allowOrigin(origin) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	method := strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))
+	if !slices.Contains(p.methods, method) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	requested := requestedHeaders(r)
+	if !p.allowHeaders(requested) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	p.setAllowOrigin(w, origin)
+
+	w.Header().Set("Access-Control-Allow-Methods", strings.Join(p.methods, ", "))
+
+	switch {
+	case p.anyHeader:
+		if len(requested) > 0 {
+			w.Header().Set("Access-Control-Allow-Headers", strings.Join(requested, ", "))
+		}
+
+	case len(p.headers) > 0:
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(p.headers, ", "))
+	}
+
+	if p.maxAge != "" {
+		w.Header().Set("Access-Control-Max-Age", p.maxAge)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// apply adds the CORS headers for an actual request from an allowed origin.
+func (p corsPolicy) apply(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Vary", "Origin")
+
+	origin := r.Header.Get("Origin")
+	if !p.allowOrigin(origin) {
+		return
+	}
+
+	p.setAllowOrigin(w, origin)
+
+	if p.exposed != "" {
+		w.Header().Set("Access-Control-Expose-Headers", p.exposed)
+	}
+}
+
+// =============================================================================
+
+func isPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions &&
+		r.Header.Get("Origin") != "" &&
+		r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+func requestedHeaders(r *http.Request) []string {
+	var headers []string
+
+	for _, value := range r.Header.Values("Access-Control-Request-Headers") {
+		for header := range strings.SplitSeq(value, ",") {
+			if header = strings.TrimSpace(header); header != "" {
+				headers = append(headers, header)
+			}
+		}
+	}
+
+	return headers
+}
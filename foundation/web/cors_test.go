@@ -0,0 +1,162 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/web"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func newApp() *web.App {
+	log := func(ctx context.Context, msg string, args ...any) {}
+
+	app := web.NewApp(log, noop.NewTracerProvider().Tracer(""))
+
+	h := func(ctx context.Context, r *http.Request) web.Encoder {
+		return nil
+	}
+
+	app.HandlerFunc(http.MethodGet, "v1", "/test", h)
+	app.HandlerFunc(http.MethodGet, "v2", "/test", h)
+
+	return app
+}
+
+func Test_CORSPreflight(t *testing.T) {
+	app := newApp()
+
+	app.EnableCORS(web.CORS{
+		AllowedOrigins: []string{"https://example.com", "https://*.example.org"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		MaxAge:         time.Hour,
+	})
+
+	tests := []struct {
+		name    string
+		origin  string
+		method  string
+		headers string
+		status  int
+		allowed string
+	}{
+		{"exact", "https://example.com", http.MethodPost, "content-type", http.StatusNoContent, "https://example.com"},
+		{"wildcard", "https://api.example.org", http.MethodGet, "", http.StatusNoContent, "https://api.example.org"},
+		{"wildcard-nested", "https://a.b.example.org", http.MethodGet, "", http.StatusNoContent, "https://a.b.example.org"},
+		{"wildcard-apex", "https://example.org", http.MethodGet, "", http.StatusForbidden, ""},
+		{"wildcard-port", "https://evil.com:1.example.org", http.MethodGet, "", http.StatusForbidden, ""},
+		{"bad-origin", "https://evil.com", http.MethodGet, "", http.StatusForbidden, ""},
+		{"bad-method", "https://example.com", http.MethodDelete, "", http.StatusForbidden, ""},
+		{"bad-header", "https://example.com", http.MethodGet, "X-Other", http.StatusForbidden, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodOptions, "/v1/test", nil)
+			r.Header.Set("Origin", tt.origin)
+			r.Header.Set("Access-Control-Request-Method", tt.method)
+			if tt.headers != "" {
+				r.Header.Set("Access-Control-Request-Headers", tt.headers)
+			}
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("Should get status %d, got %d", tt.status, w.Code)
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowed {
+				t.Errorf("Should get allow origin %q, got %q", tt.allowed, got)
+			}
+
+			if tt.status == http.StatusNoContent {
+				if got := w.Header().Get("Access-Control-Max-Age"); got != "3600" {
+					t.Errorf("Should get max age 3600, got %q", got)
+				}
+			}
+		})
+	}
+}
+
+func Test_CORSRequest(t *testing.T) {
+	app := newApp()
+
+	app.EnableCORS(web.CORS{
+		AllowedOrigins:   []string{"*"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+	})
+
+	app.EnableGroupCORS("v2", web.CORS{
+		AllowedOrigins: []string{"https://example.com"},
+	})
+
+	tests := []struct {
+		name    string
+		url     string
+		origin  string
+		allowed string
+		exposed string
+	}{
+		{"reflect-with-credentials", "/v1/test", "https://any.com", "https://any.com", "X-Request-ID"},
+		{"group-allowed", "/v2/test", "https://example.com", "https://example.com", ""},
+		{"group-denied", "/v2/test", "https://any.com", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			r.Header.Set("Origin", tt.origin)
+
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("Should get status %d, got %d", http.StatusNoContent, w.Code)
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.allowed {
+				t.Errorf("Should get allow origin %q, got %q", tt.allowed, got)
+			}
+
+			if got := w.Header().Get("Access-Control-Expose-Headers"); got != tt.exposed {
+				t.Errorf("Should get expose headers %q, got %q", tt.exposed, got)
+			}
+
+			if got := w.Header().Get("Vary"); got != "Origin" {
+				t.Errorf("Should get vary Origin, got %q", got)
+			}
+		})
+	}
+}
+
+func Test_HSTS(t *testing.T) {
+	app := newApp()
+
+	r := httptest.NewRequest(http.MethodGet, "/v1/test", nil)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	if got := w.Header().Get("Strict-Transport-Security"); got != "" {
+		t.Errorf("Should not get the HSTS header by default, got %q", got)
+	}
+
+	app.EnableHSTS(web.HSTS{
+		MaxAge:            2 * 365 * 24 * time.Hour,
+		IncludeSubDomains: true,
+		Preload:           true,
+	})
+
+	w = httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+
+	exp := "max-age=63072000; includeSubDomains; preload"
+	if got := w.Header().Get("Strict-Transport-Security"); got != exp {
+		t.Errorf("Should get %q, got %q", exp, got)
+	}
+}
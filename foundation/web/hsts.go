@@ -0,0 +1,33 @@
+package web
+
+import (
+	"fmt"
+	"time"
+)
+
+// HSTS represents the policy for the Strict-Transport-Security header which
+// tells browsers to only connect to the service using HTTPS.
+//
+// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Strict-Transport-Security
+type HSTS struct {
+	MaxAge            time.Duration
+	IncludeSubDomains bool
+	Preload           bool
+}
+
+// String returns the value to use for the header.
+func (h HSTS) String() string {
+	v := fmt.Sprintf("max-age=%d", int(h.MaxAge.Seconds()))
+
+	if h.IncludeSubDomains {
+		v += "; includeSubDomains"
+	}
+
+	// Preload is necessary for inclusion in all major web browsers' HSTS
+	// preload lists, like Chromium, Edge, and Firefox.
+	if h.Preload {
+		v += "; preload"
+	}
+
+	return v
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the header used to accept and echo the request id.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen limits the size of a request id provided by a client.
+const maxRequestIDLen = 128
+
+// SetRequestID stores the request id in the context. This is used by
+// transports other than http to carry a request id provided by a caller.
+func SetRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// GetRequestID returns the request id from the context.
+func GetRequestID(ctx context.Context) string {
+	v, ok := ctx.Value(requestIDKey).(string)
+	if !ok {
+		return ""
+	}
+
+	return v
+}
+
+// AddRequestIDToRequest adds the request id from the context to the outbound
+// request so the id follows the call into other services.
+func AddRequestIDToRequest(ctx context.Context, r *http.Request) {
+	if requestID := GetRequestID(ctx); requestID != "" {
+		r.Header.Set(RequestIDHeader, requestID)
+	}
+}
+
+// ValidRequestID reports whether a request id provided by a caller can be
+// trusted to be logged and echoed back.
+func ValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLen {
+		return false
+	}
+
+	for _, c := range requestID {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':', c == '/', c == '+', c == '=', c == '@':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// =============================================================================
+
+// requestID returns the request id provided by the client if it's valid
+// or generates a new one.
+func requestID(r *http.Request) string {
+	if requestID := r.Header.Get(RequestIDHeader); ValidRequestID(requestID) {
+		return requestID
+	}
+
+	return uuid.NewString()
+}
@@ -0,0 +1,62 @@
+package web_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ardanlabs/service/foundation/web"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func Test_RequestID(t *testing.T) {
+	log := func(ctx context.Context, msg string, args ...any) {}
+
+	app := web.NewApp(log, noop.NewTracerProvider().Tracer(""))
+
+	var got string
+	h := func(ctx context.Context, r *http.Request) web.Encoder {
+		got = web.GetRequestID(ctx)
+		return nil
+	}
+
+	app.HandlerFunc(http.MethodGet, "v1", "/test", h)
+
+	tests := []struct {
+		name      string
+		requestID string
+		accepted  bool
+	}{
+		{"accepted", "abc-123", true},
+		{"missing", "", false},
+		{"invalid", "abc 123\n", false},
+		{"too-long", strings.Repeat("a", 129), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/test", nil)
+			if tt.requestID != "" {
+				r.Header.Set(web.RequestIDHeader, tt.requestID)
+			}
+			w := httptest.NewRecorder()
+
+			app.ServeHTTP(w, r)
+
+			echoed := w.Header().Get(web.RequestIDHeader)
+			if echoed == "" {
+				t.Fatalf("Should echo a request id on the response")
+			}
+
+			if echoed != got {
+				t.Errorf("Should echo the request id from the context: got %q, exp %q", echoed, got)
+			}
+
+			if tt.accepted != (got == tt.requestID) {
+				t.Errorf("Should accept only valid request ids: accepted %v, got %q", tt.accepted, got)
+			}
+		})
+	}
+}
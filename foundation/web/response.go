@@ -29,6 +29,24 @@ type httpStatus interface {
 	HTTPStatus() int
 }
 
+// StatusCode returns the status code that is sent to the client for the
+// response.
+func StatusCode(resp Encoder) int {
+	switch v := resp.(type) {
+	case httpStatus:
+		return v.HTTPStatus()
+
+	case error:
+		return http.StatusInternalServerError
+	}
+
+	if resp == nil {
+		return http.StatusNoContent
+	}
+
+	return http.StatusOK
+}
+
 // Respond sends a response to the client.
 func Respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 	if _, ok := resp.(NoResponse); ok {
@@ -43,20 +61,7 @@ func Respond(ctx context.Context, w http.ResponseWriter, resp Encoder) error {
 		}
 	}
 
-	statusCode := http.StatusOK
-
-	switch v := resp.(type) {
-	case httpStatus:
-		statusCode = v.HTTPStatus()
-
-	case error:
-		statusCode = http.StatusInternalServerError
-
-	default:
-		if resp == nil {
-			statusCode = http.StatusNoContent
-		}
-	}
+	statusCode := StatusCode(resp)
 
 	_, span := addSpan(ctx, "web.send.response", attribute.Int("status", statusCode))
 	defer span.End()
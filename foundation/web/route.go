@@ -0,0 +1,17 @@
+package web
+
+// Route represents a route that has been registered with the App.
+type Route struct {
+	Method string
+	Group  string
+	Path   string
+}
+
+// Pattern returns the full path of the route including the group.
+func (r Route) Pattern() string {
+	if r.Group == "" {
+		return r.Path
+	}
+
+	return "/" + r.Group + r.Path
+}
@@ -0,0 +1,65 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ardanlabs/service/foundation/web"
+)
+
+func Test_SecureHeaders(t *testing.T) {
+	app := newApp()
+
+	app.EnableCORS(web.CORS{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedMethods: []string{http.MethodGet},
+		MaxAge:         time.Hour,
+	})
+
+	app.EnableSecureHeaders(web.SecureHeaders{
+		ContentSecurityPolicy: "default-src 'none'",
+		ContentTypeOptions:    "nosniff",
+		FrameOptions:          "DENY",
+	})
+
+	preflight := httptest.NewRequest(http.MethodOptions, "/v1/test", nil)
+	preflight.Header.Set("Origin", "https://example.com")
+	preflight.Header.Set("Access-Control-Request-Method", http.MethodGet)
+
+	tests := []struct {
+		name       string
+		r          *http.Request
+		statusCode int
+	}{
+		{"route", httptest.NewRequest(http.MethodGet, "/v1/test", nil), http.StatusNoContent},
+		{"not-found", httptest.NewRequest(http.MethodGet, "/v1/missing", nil), http.StatusNotFound},
+		{"method-not-allowed", httptest.NewRequest(http.MethodPost, "/v1/test", nil), http.StatusMethodNotAllowed},
+		{"preflight", preflight, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, tt.r)
+
+			if w.Code != tt.statusCode {
+				t.Fatalf("Should get status %d, got %d", tt.statusCode, w.Code)
+			}
+
+			exp := map[string]string{
+				"Content-Security-Policy": "default-src 'none'",
+				"X-Content-Type-Options":  "nosniff",
+				"X-Frame-Options":         "DENY",
+				"Referrer-Policy":         "",
+			}
+
+			for key, value := range exp {
+				if got := w.Header().Get(key); got != value {
+					t.Errorf("Should get %s %q, got %q", key, value, got)
+				}
+			}
+		})
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"net/http"
 	"path"
 	"regexp"
+	"slices"
+	"strings"
+	"sync"
 
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 	"go.opentelemetry.io/otel"
@@ -34,12 +37,16 @@ type Logger func(ctx context.Context, msg string, args ...any)
 // object for each of our http handlers. Feel free to add any configuration
 // data/logic on this App struct.
 type App struct {
-	log     Logger
-	tracer  trace.Tracer
-	mux     *http.ServeMux
-	otmux   http.Handler
-	mw      []MidFunc
-	origins []string
+	log    Logger
+	tracer trace.Tracer
+	mux    *http.ServeMux
+	otmux  http.Handler
+	mw     []MidFunc
+	cors   map[string]corsPolicy
+	hsts   string
+	secure map[string]string
+	mu     sync.RWMutex
+	routes []Route
 }
 
 // NewApp creates an App value that handle a set of routes for the application.
@@ -67,53 +74,83 @@ func NewApp(log Logger, tracer trace.Tracer, mw ...MidFunc) *App {
 // tracing. The opentelemetry mux then calls the application mux to handle
 // application traffic. This was set up in the NewApp function.
 func (a *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	if a.origins != nil {
-
-		// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Access-Control-Allow-Origin
-		// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Origin
-		//
-		// Limiting the possible Access-Control-Allow-Origin values to a set of
-		// allowed origins requires code on the server side to check the value of
-		// the Origin request header, compare that to a list of allowed origins, and
-		// then if the Origin value is in the list, set the
-		// Access-Control-Allow-Origin value to the same value as the Origin.
-
-		reqOrigin := r.Header.Get("Origin")
-		for _, origin := range a.origins {
-			if origin == "*" || origin == reqOrigin {
-				w.Header().Set("Access-Control-Allow-Origin", origin)
-				break
-			}
-		}
 
-		w.Header().Set("Access-Control-Allow-Methods", "POST, PATCH, GET, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
-		w.Header().Set("Access-Control-Max-Age", "86400")
+	// Clean the request path to prevent http.ServeMux from issuing 301 redirects
+	// for unclean paths (e.g. //v1/auth/register). Without this, clients following
+	// the redirect switch to GET, causing 405 on POST/PUT/DELETE routes.
+	r.URL.Path = path.Clean(r.URL.Path)
+
+	// Accept the request id provided by the client or generate a new one so
+	// the request can be correlated across logs and services.
+	requestID := requestID(r)
+	w.Header().Set(RequestIDHeader, requestID)
+	r = r.WithContext(SetRequestID(r.Context(), requestID))
+
+	if a.hsts != "" {
+		w.Header().Set("Strict-Transport-Security", a.hsts)
+	}
+
+	// Set the security headers here, and not in a middleware, so responses
+	// written by the mux itself and the CORS pre-flight get them as well.
+	for key, value := range a.secure {
+		w.Header().Set(key, value)
+	}
+
+	if policy, exists := a.corsPolicy(r.URL.Path); exists {
 
-		// Handle pre-flight by sending a 200 OK response.
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
+		// Handle the pre-flight here so the MethodNotAllowedHandler is not
+		// called for the OPTIONS request.
+		if isPreflight(r) {
+			policy.preflight(w, r)
 			return
 		}
-	}
 
-	// In the following example, max-age is set to 2 years, and is suffixed with
-	// preload, which is necessary for inclusion in all major web browsers' HSTS
-	// preload lists, like Chromium, Edge, and Firefox.
-	w.Header().Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains; preload")
+		policy.apply(w, r)
+	}
 
-	// Clean the request path to prevent http.ServeMux from issuing 301 redirects
-    // for unclean paths (e.g. //v1/auth/register). Without this, clients following
-    // the redirect switch to GET, causing 405 on POST/PUT/DELETE routes.
-	r.URL.Path = path.Clean(r.URL.Path)
-	
 	a.otmux.ServeHTTP(w, r)
 }
 
-// EnableCORS enables CORS preflight requests to work. It prevents the
-// MethodNotAllowedHandler from being called.
-func (a *App) EnableCORS(origins []string) {
-	a.origins = origins
+// EnableCORS applies the CORS policy to all routes that don't belong to a
+// group with its own policy.
+func (a *App) EnableCORS(cors CORS) {
+	a.EnableGroupCORS("", cors)
+}
+
+// EnableGroupCORS applies the CORS policy to the routes registered under the
+// specified group. This takes precedence over the policy set by EnableCORS.
+func (a *App) EnableGroupCORS(group string, cors CORS) {
+	if a.cors == nil {
+		a.cors = make(map[string]corsPolicy)
+	}
+
+	a.cors[group] = newCORSPolicy(cors)
+}
+
+// EnableHSTS sets the Strict-Transport-Security header on all responses.
+func (a *App) EnableHSTS(hsts HSTS) {
+	a.hsts = hsts.String()
+}
+
+// EnableSecureHeaders sets the security headers on all responses.
+func (a *App) EnableSecureHeaders(secure SecureHeaders) {
+	a.secure = secure.headers()
+}
+
+// corsPolicy returns the CORS policy for the group the path belongs to or
+// the policy for the app.
+func (a *App) corsPolicy(urlPath string) (corsPolicy, bool) {
+	if a.cors == nil {
+		return corsPolicy{}, false
+	}
+
+	group, _, _ := strings.Cut(strings.TrimPrefix(urlPath, "/"), "/")
+	if policy, exists := a.cors[group]; exists {
+		return policy, true
+	}
+
+	policy, exists := a.cors[""]
+	return policy, exists
 }
 
 // HandlerFuncNoMid sets a handler function for a given HTTP method and path
@@ -131,13 +168,7 @@ func (a *App) HandlerFuncNoMid(method string, group string, path string, handler
 		}
 	}
 
-	finalPath := path
-	if group != "" {
-		finalPath = "/" + group + path
-	}
-	finalPath = fmt.Sprintf("%s %s", method, finalPath)
-
-	a.mux.HandleFunc(finalPath, h)
+	a.mux.HandleFunc(a.register(method, group, path), h)
 }
 
 // HandlerFunc sets a handler function for a given HTTP method and path pair
@@ -160,13 +191,7 @@ func (a *App) HandlerFunc(method string, group string, path string, handlerFunc
 		}
 	}
 
-	finalPath := path
-	if group != "" {
-		finalPath = "/" + group + path
-	}
-	finalPath = fmt.Sprintf("%s %s", method, finalPath)
-
-	a.mux.HandleFunc(finalPath, h)
+	a.mux.HandleFunc(a.register(method, group, path), h)
 }
 
 // RawHandlerFunc sets a raw handler function for a given HTTP method and path
@@ -190,13 +215,33 @@ func (a *App) RawHandlerFunc(method string, group string, path string, rawHandle
 		handlerFunc(ctx, r)
 	}
 
-	finalPath := path
-	if group != "" {
-		finalPath = "/" + group + path
+	a.mux.HandleFunc(a.register(method, group, path), h)
+}
+
+// Routes returns the set of routes that have been registered with the
+// application mux through the handler functions.
+func (a *App) Routes() []Route {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return slices.Clone(a.routes)
+}
+
+// register records the route in the route registry and returns the pattern
+// to use with the application mux.
+func (a *App) register(method string, group string, path string) string {
+	rt := Route{
+		Method: method,
+		Group:  group,
+		Path:   path,
 	}
-	finalPath = fmt.Sprintf("%s %s", method, finalPath)
 
-	a.mux.HandleFunc(finalPath, h)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.routes = append(a.routes, rt)
+
+	return fmt.Sprintf("%s %s", method, rt.Pattern())
 }
 
 // FileServerReact starts a file server based on the specified file system and
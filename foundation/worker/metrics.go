@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var jobDuration metric.Float64Histogram
+
+func init() {
+	meter := otel.Meter("github.com/ardanlabs/service/foundation/worker")
+
+	var err error
+	jobDuration, err = meter.Float64Histogram("worker.job.duration",
+		metric.WithDescription("Duration of the jobs run by the worker by status."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func recordJob(ctx context.Context, job Job) {
+	jobDuration.Record(ctx, job.Duration().Seconds(), metric.WithAttributes(attribute.String("status", string(job.Status))))
+}
@@ -5,31 +5,122 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime/debug"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// ErrNotFound is returned when the work key is unknown or the job is no
+// longer retained.
+var ErrNotFound = errors.New("work not found")
+
+var tracer = otel.Tracer("github.com/ardanlabs/service/foundation/worker")
+
 // JobFn defines a function that can execute work for a specific job.
 type JobFn func(ctx context.Context)
 
+// ResultFn defines a function that can execute work for a specific job and
+// return a result that can be retrieved by the work key.
+type ResultFn func(ctx context.Context) (any, error)
+
+// Status represents the state of a job.
+type Status string
+
+// Set of job statuses.
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Job represents the state of a job started by the worker. Stack is set when
+// the job panicked.
+type Job struct {
+	Key      string
+	Status   Status
+	Result   any
+	Err      error
+	Stack    string
+	Queued   time.Time
+	Started  time.Time
+	Finished time.Time
+}
+
+// Duration returns how long the job ran, or has been running for.
+func (j Job) Duration() time.Duration {
+	switch {
+	case j.Started.IsZero():
+		return 0
+	case j.Finished.IsZero():
+		return time.Since(j.Started)
+	}
+
+	return j.Finished.Sub(j.Started)
+}
+
+// Done reports whether the job has finished.
+func (j Job) Done() bool {
+	switch j.Status {
+	case StatusSucceeded, StatusFailed, StatusCancelled:
+		return true
+	}
+
+	return false
+}
+
+// Options represent optional parameters.
+type Options struct {
+	retain int
+}
+
+// WithRetain sets the number of finished jobs whose status and result are
+// kept for retrieval. The default is 1000.
+func WithRetain(retain int) func(opts *Options) {
+	return func(opts *Options) {
+		opts.retain = retain
+	}
+}
+
+type work struct {
+	job     Job
+	cancel  context.CancelFunc
+	stopped bool
+	done    chan struct{}
+}
+
 // Worker manages jobs and the execution of those jobs concurrently.
 type Worker struct {
 	wg         sync.WaitGroup
 	mu         sync.RWMutex
 	sem        chan bool
 	isShutdown chan struct{}
-	running    map[string]context.CancelFunc
+	work       map[string]*work
+	finished   []string
+	retain     int
+	running    int
 }
 
 // New constructs a Worker for managing and executing jobs. The capacity value
 // represents the maximum number of G's that can be executing at any given time.
-func New(maxRunningJobs int) (*Worker, error) {
+func New(maxRunningJobs int, options ...func(opts *Options)) (*Worker, error) {
 	if maxRunningJobs <= 0 {
 		return nil, errors.New("max running jobs must be greater than 0")
 	}
 
+	opts := Options{
+		retain: 1000,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
 	sem := make(chan bool, maxRunningJobs)
 	for i := 0; i < maxRunningJobs; i++ {
 		sem <- true
@@ -38,7 +129,8 @@ func New(maxRunningJobs int) (*Worker, error) {
 	w := Worker{
 		sem:        sem,
 		isShutdown: make(chan struct{}),
-		running:    make(map[string]context.CancelFunc),
+		work:       make(map[string]*work),
+		retain:     max(opts.retain, 0),
 	}
 
 	return &w, nil
@@ -49,7 +141,7 @@ func (w *Worker) Running() int {
 	w.mu.RLock()
 	defer w.mu.RUnlock()
 
-	return len(w.running)
+	return w.running
 }
 
 // Shutdown waits for all jobs to complete before it returns.
@@ -60,11 +152,14 @@ func (w *Worker) Shutdown(ctx context.Context) error {
 
 	// Call the cancel function for all running goroutines.
 	func() {
-		w.mu.RLock()
-		defer w.mu.RUnlock()
-
-		for _, cancel := range w.running {
-			cancel()
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		for _, wk := range w.work {
+			if wk.cancel != nil && !wk.job.Done() {
+				wk.stopped = true
+				wk.cancel()
+			}
 		}
 	}()
 
@@ -87,33 +182,64 @@ func (w *Worker) Shutdown(ctx context.Context) error {
 }
 
 // Start lookups a job by key and launches a goroutine to perform the work. A
-// work key is returned so the caller can cancel work early.
+// work key is returned so the caller can cancel work early or check on the
+// status of the job.
 func (w *Worker) Start(ctx context.Context, jobFn JobFn) (string, error) {
+	f := func(ctx context.Context) (any, error) {
+		jobFn(ctx)
+		return nil, nil
+	}
+
+	return w.StartResult(ctx, f)
+}
+
+// StartResult launches a goroutine to perform the work like Start. The result
+// and error returned by the function are kept with the status of the job.
+//
+// The job runs with the deadline of the context, or one second when there is
+// none, and keeps the values of the context. It isn't cancelled when the
+// context is, so it can outlive the request that started it. The span for
+// the job is linked to the span in the context.
+func (w *Worker) StartResult(ctx context.Context, resultFn ResultFn) (string, error) {
+
+	// Need a unique key for this work.
+	workKey := uuid.NewString()
+
+	wk := work{
+		job: Job{
+			Key:    workKey,
+			Status: StatusQueued,
+			Queued: time.Now(),
+		},
+		done: make(chan struct{}),
+	}
+
+	w.trackWork(&wk)
 
 	// We need to block here waiting to capture a semaphore, timeout or shutdown.
 	// The shutdown is first to handle that event as priority.
 	select {
 	case <-w.isShutdown:
+		w.removeWork(workKey)
 		return "", errors.New("shutting down")
 	case <-ctx.Done():
+		w.removeWork(workKey)
 		return "", ctx.Err()
 	case <-w.sem:
 	}
 
-	// Need a unique key for this work.
-	workKey := uuid.NewString()
-
 	// Let's continue with the current context's deadline.
 	deadline, ok := ctx.Deadline()
 	if !ok {
 		deadline = time.Now().Add(time.Second)
 	}
 
+	link := trace.LinkFromContext(ctx)
+
 	// Create a cancel function and keep it for stop/shutdown purposes.
-	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	jobCtx, cancel := context.WithDeadline(context.WithoutCancel(ctx), deadline)
 
-	// Register this new G as running.
-	w.trackWork(workKey, cancel)
+	w.startWork(workKey, cancel)
 
 	// Launch a goroutine to perform the work.
 	w.wg.Add(1)
@@ -124,16 +250,31 @@ func (w *Worker) Start(ctx context.Context, jobFn JobFn) (string, error) {
 		// to be processed.
 		defer func() { w.sem <- true }()
 
-		// We must call cancel regardless, remove the work key and report
-		// to the outer G we are done.
+		// We must call cancel regardless and report to the outer G
+		// we are done.
 		defer func() {
 			cancel()
-			w.removeWork(workKey)
 			w.wg.Done()
 		}()
 
+		ctx, span := tracer.Start(jobCtx, "foundation.worker.job",
+			trace.WithNewRoot(),
+			trace.WithLinks(link),
+			trace.WithAttributes(attribute.String("work.key", workKey)),
+		)
+		defer span.End()
+
 		// Execute the actual workload.
-		jobFn(ctx)
+		result, stack, err := execute(ctx, resultFn)
+
+		job := w.finishWork(workKey, result, stack, err)
+
+		span.SetAttributes(attribute.String("work.status", string(job.Status)))
+		if job.Err != nil {
+			span.RecordError(job.Err)
+		}
+
+		recordJob(ctx, job)
 	}()
 
 	return workKey, nil
@@ -141,30 +282,133 @@ func (w *Worker) Start(ctx context.Context, jobFn JobFn) (string, error) {
 
 // Stop is used to cancel an existing job that is running.
 func (w *Worker) Stop(workKey string) error {
-	w.mu.RLock()
-	defer w.mu.RUnlock()
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	cancel, exists := w.running[workKey]
-	if !exists {
+	wk, exists := w.work[workKey]
+	if !exists || wk.job.Status != StatusRunning {
 		return fmt.Errorf("work[%s] is not running", workKey)
 	}
 
 	// Call cancel to stop the work.
-	cancel()
+	wk.stopped = true
+	wk.cancel()
 
 	return nil
 }
 
-func (w *Worker) trackWork(workKey string, cancel context.CancelFunc) {
+// Job returns the status of the job for the work key. Finished jobs are
+// kept until the number of retained jobs is reached.
+func (w *Worker) Job(workKey string) (Job, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	wk, exists := w.work[workKey]
+	if !exists {
+		return Job{}, fmt.Errorf("work[%s]: %w", workKey, ErrNotFound)
+	}
+
+	return wk.job, nil
+}
+
+// Wait blocks until the job for the work key finishes or the context is
+// done and returns the status of the job.
+func (w *Worker) Wait(ctx context.Context, workKey string) (Job, error) {
+	w.mu.RLock()
+	wk, exists := w.work[workKey]
+	w.mu.RUnlock()
+
+	if !exists {
+		return Job{}, fmt.Errorf("work[%s]: %w", workKey, ErrNotFound)
+	}
+
+	select {
+	case <-wk.done:
+	case <-ctx.Done():
+		return Job{}, ctx.Err()
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return wk.job, nil
+}
+
+// =============================================================================
+
+// execute runs the function and turns a panic into an error so a job can't
+// take down the process.
+func execute(ctx context.Context, resultFn ResultFn) (result any, stack string, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			result = nil
+			stack = string(debug.Stack())
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+
+	result, err = resultFn(ctx)
+
+	return result, "", err
+}
+
+func (w *Worker) trackWork(wk *work) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.work[wk.job.Key] = wk
+}
+
+func (w *Worker) startWork(workKey string, cancel context.CancelFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	wk := w.work[workKey]
+	wk.cancel = cancel
+	wk.job.Status = StatusRunning
+	wk.job.Started = time.Now()
+
+	w.running++
+}
+
+func (w *Worker) finishWork(workKey string, result any, stack string, err error) Job {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	w.running[workKey] = cancel
+	wk := w.work[workKey]
+	w.running--
+
+	wk.job.Result = result
+	wk.job.Err = err
+	wk.job.Stack = stack
+	wk.job.Finished = time.Now()
+
+	switch {
+	case stack != "":
+		wk.job.Status = StatusFailed
+	case wk.stopped:
+		wk.job.Status = StatusCancelled
+	case err != nil:
+		wk.job.Status = StatusFailed
+	default:
+		wk.job.Status = StatusSucceeded
+	}
+
+	close(wk.done)
+
+	// Keep the most recent finished jobs so their status can be retrieved.
+	w.finished = append(w.finished, workKey)
+	for len(w.finished) > w.retain {
+		delete(w.work, w.finished[0])
+		w.finished = w.finished[1:]
+	}
+
+	return wk.job
 }
 
 func (w *Worker) removeWork(workKey string) {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	delete(w.running, workKey)
+	delete(w.work, workKey)
 }
@@ -2,6 +2,8 @@ package worker_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -150,3 +152,138 @@ func Test_StopWorker(t *testing.T) {
 		t.Fatalf("Should be able to shutdown work cleanly : %s", err)
 	}
 }
+
+func Test_JobStatus(t *testing.T) {
+	w, err := worker.New(4)
+	if err != nil {
+		t.Fatalf("Should be able to create a worker with max 4 : %s", err)
+	}
+	defer w.Shutdown(context.Background())
+
+	started := make(chan struct{})
+
+	tests := []struct {
+		name   string
+		fn     worker.ResultFn
+		stop   bool
+		status worker.Status
+		result any
+		err    string
+	}{
+		{
+			name:   "succeeded",
+			fn:     func(ctx context.Context) (any, error) { return 42, nil },
+			status: worker.StatusSucceeded,
+			result: 42,
+		},
+		{
+			name:   "failed",
+			fn:     func(ctx context.Context) (any, error) { return nil, errors.New("failed") },
+			status: worker.StatusFailed,
+			err:    "failed",
+		},
+		{
+			name:   "panicked",
+			fn:     func(ctx context.Context) (any, error) { panic("boom") },
+			status: worker.StatusFailed,
+			err:    "panic: boom",
+		},
+		{
+			name: "cancelled",
+			fn: func(ctx context.Context) (any, error) {
+				close(started)
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+			stop:   true,
+			status: worker.StatusCancelled,
+			err:    context.Canceled.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			key, err := w.StartResult(ctx, tt.fn)
+			if err != nil {
+				t.Fatalf("Should be able to execute work : %s", err)
+			}
+
+			if tt.stop {
+				<-started
+				if err := w.Stop(key); err != nil {
+					t.Fatalf("Should be able to stop the work : %s", err)
+				}
+			}
+
+			job, err := w.Wait(ctx, key)
+			if err != nil {
+				t.Fatalf("Should be able to wait for the work : %s", err)
+			}
+
+			if job.Status != tt.status {
+				t.Errorf("Should get the status %s : got %s", tt.status, job.Status)
+			}
+
+			if job.Result != tt.result {
+				t.Errorf("Should get the result %v : got %v", tt.result, job.Result)
+			}
+
+			var gotErr string
+			if job.Err != nil {
+				gotErr = job.Err.Error()
+			}
+			if gotErr != tt.err {
+				t.Errorf("Should get the error %q : got %q", tt.err, gotErr)
+			}
+
+			if tt.name == "panicked" && !strings.Contains(job.Stack, "worker_test.go") {
+				t.Errorf("Should get the stack of the panic : got %q", job.Stack)
+			}
+
+			if job.Finished.Before(job.Started) {
+				t.Errorf("Should get the job finished after it started")
+			}
+		})
+	}
+}
+
+func Test_RetainWorker(t *testing.T) {
+	w, err := worker.New(1, worker.WithRetain(1))
+	if err != nil {
+		t.Fatalf("Should be able to create a worker with max 1 : %s", err)
+	}
+	defer w.Shutdown(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var keys []string
+	for i := 0; i < 2; i++ {
+		key, err := w.Start(ctx, func(ctx context.Context) {})
+		if err != nil {
+			t.Fatalf("Should be able to execute work : %s", err)
+		}
+
+		if _, err := w.Wait(ctx, key); err != nil {
+			t.Fatalf("Should be able to wait for the work : %s", err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if _, err := w.Job(keys[0]); !errors.Is(err, worker.ErrNotFound) {
+		t.Errorf("Should not find the oldest job : got %v", err)
+	}
+
+	job, err := w.Job(keys[1])
+	if err != nil {
+		t.Fatalf("Should find the newest job : %s", err)
+	}
+
+	if job.Status != worker.StatusSucceeded {
+		t.Errorf("Should get the status %s : got %s", worker.StatusSucceeded, job.Status)
+	}
+}